@@ -17,16 +17,28 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/nanobus/nanobus/pkg/channel/metadata"
+	"github.com/nanobus/nanobus/pkg/config"
 	"github.com/nanobus/nanobus/pkg/engine"
 	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
+	migrate_mssql "github.com/nanobus/nanobus/pkg/initialize/mssql"
+	migrate_mysql "github.com/nanobus/nanobus/pkg/initialize/mysql"
+	migrate_postgres "github.com/nanobus/nanobus/pkg/initialize/postgres"
+	migrate_sqlite "github.com/nanobus/nanobus/pkg/initialize/sqlite"
 	"github.com/nanobus/nanobus/pkg/logger"
 	"github.com/nanobus/nanobus/pkg/oci"
 	"github.com/nanobus/nanobus/pkg/runtime"
+	"github.com/nanobus/nanobus/pkg/schema"
+	"github.com/nanobus/nanobus/pkg/spec"
+	spec_apex "github.com/nanobus/nanobus/pkg/spec/apex"
 	"github.com/nanobus/nanobus/pkg/stream"
 )
 
@@ -48,6 +60,10 @@ var commands struct {
 	Push pushCmd `cmd:"" help:"Packages and pushes a NanoBus application to an OCI registry"`
 	// Pull retrieves an application from an OCI registry without running it.
 	Pull pullCmd `cmd:"" help:"Pulls a NanoBus application from an OCI registry without running it"`
+	// Schema generates a database migration from the entities in the interface definition.
+	Schema schemaCmd `cmd:"" help:"Generates a database migration from the entities in the interface definition"`
+	// Migrate runs the database migrations configured as initializers.
+	Migrate migrateCmd `cmd:"" help:"Runs the database migrations configured as initializers"`
 	// Version prints out the version of this program and runtime info.
 	Version versionCmd `cmd:"Display version information"`
 }
@@ -63,6 +79,10 @@ type defaultRunCmd struct {
 	DeveloperMode bool `name:"developer-mode" help:"Enables developer mode."`
 	// Turns on debug logging.
 	Debug bool `name:"debug" help:"Turns on debug logging"`
+	// SkipMigrations disables the migration initializers.
+	SkipMigrations bool `name:"skip-migrations" env:"NANOBUS_SKIP_MIGRATIONS" help:"Skips database migrations at startup"`
+	// Offline loads remote modules only from the cache.
+	Offline bool `name:"offline" env:"NANOBUS_OFFLINE" help:"Loads remote modules only from the cache"`
 }
 
 func (c *defaultRunCmd) Run() error {
@@ -76,11 +96,13 @@ func (c *defaultRunCmd) Run() error {
 		level = zapcore.DebugLevel
 	}
 	if _, err := engine.Start(ctx, &engine.Info{
-		Mode:          engine.ModeService,
-		Target:        "bus.yaml",
-		ResourcesFile: "resources.yaml",
-		LogLevel:      level,
-		DeveloperMode: c.DeveloperMode,
+		Mode:           engine.ModeService,
+		Target:         "bus.yaml",
+		ResourcesFile:  "resources.yaml",
+		LogLevel:       level,
+		DeveloperMode:  c.DeveloperMode,
+		SkipMigrations: c.SkipMigrations,
+		Offline:        c.Offline,
 	}); err != nil {
 		// Error is logged in `Start`.
 		cancel()
@@ -97,6 +119,10 @@ type runCmd struct {
 	ResourcesFile string `name:"resources" default:"resources.yaml" help:"The resources configuration"`
 	// Turns on debug logging.
 	Debug bool `name:"debug" help:"Turns on debug logging"`
+	// SkipMigrations disables the migration initializers.
+	SkipMigrations bool `name:"skip-migrations" env:"NANOBUS_SKIP_MIGRATIONS" help:"Skips database migrations at startup"`
+	// Offline loads remote modules only from the cache.
+	Offline bool `name:"offline" env:"NANOBUS_OFFLINE" help:"Loads remote modules only from the cache"`
 	// Args are arguments passed to the application.
 	Args []string `arg:"" optional:"" help:"Arguments to pass to the application"`
 }
@@ -111,12 +137,14 @@ func (c *runCmd) Run() error {
 	}
 
 	if _, err := engine.Start(ctx, &engine.Info{
-		Mode:          engine.ModeService,
-		Target:        c.Target,
-		LogLevel:      level,
-		ResourcesFile: c.ResourcesFile,
-		Process:       c.Args,
-		DeveloperMode: c.DeveloperMode,
+		Mode:           engine.ModeService,
+		Target:         c.Target,
+		LogLevel:       level,
+		ResourcesFile:  c.ResourcesFile,
+		Process:        c.Args,
+		DeveloperMode:  c.DeveloperMode,
+		SkipMigrations: c.SkipMigrations,
+		Offline:        c.Offline,
 	}); err != nil {
 		// Error is logged in `Start`.
 		cancel()
@@ -143,6 +171,8 @@ type invokeCmd struct {
 	Pretty bool `name:"pretty" default:"false" help:"Pretty print the JSON output"`
 	// Turns on debug logging.
 	Debug bool `name:"debug" help:"Turns on debug logging"`
+	// Offline loads remote modules only from the cache.
+	Offline bool `name:"offline" env:"NANOBUS_OFFLINE" help:"Loads remote modules only from the cache"`
 }
 
 func (c *invokeCmd) Run() error {
@@ -186,6 +216,7 @@ func (c *invokeCmd) Run() error {
 		ResourcesFile: c.ResourcesFile,
 		EntityID:      c.EntityID,
 		DeveloperMode: c.DeveloperMode,
+		Offline:       c.Offline,
 	}
 	e, err := engine.Start(ctx, &info)
 	if err != nil {
@@ -311,6 +342,342 @@ func (c *pullCmd) Run() error {
 	return nil
 }
 
+type schemaCmd struct {
+	// BusFile is the application configuration (not an OCI image reference).
+	BusFile string `type:"existingFile" arg:"" default:"bus.yaml" help:"The NanoBus application configuration"`
+	// Dialect is the database dialect to generate DDL for.
+	Dialect string `name:"dialect" optional:"" help:"The database dialect (postgres, mssql, mysql or sqlite). Defaults to the dialect of the migration initializer"`
+	// Dir is the migrations directory.
+	Dir string `name:"dir" optional:"" help:"The migrations directory. Defaults to the directory of the migration initializer"`
+	// Name is appended to the migration version in the file names.
+	Name string `name:"name" default:"schema" help:"The name of the migration"`
+	// DryRun is a flag denoting to print the migration instead of writing it.
+	DryRun bool `name:"dry-run" default:"false" help:"Print the migration instead of writing it"`
+	// Baseline is a flag denoting to record the entities as the schema of the
+	// existing migrations instead of generating a migration.
+	Baseline bool `name:"baseline" default:"false" help:"Record the entities as the schema the existing migrations produce instead of generating a migration"`
+}
+
+// migrationDialects maps migration initializers to schema dialects.
+var migrationDialects = map[string]string{
+	"nanobus.migrate.postgres/v1": "postgres",
+	"nanobus.migrate.mssql/v1":    "mssql",
+	"nanobus.migrate.mysql/v1":    "mysql",
+	"nanobus.migrate.sqlite/v1":   "sqlite",
+}
+
+func (c *schemaCmd) Run() error {
+	busFile, err := os.Open(c.BusFile)
+	if err != nil {
+		return err
+	}
+	defer busFile.Close()
+
+	absPath, err := filepath.Abs(c.BusFile)
+	if err != nil {
+		return err
+	}
+	baseDir := filepath.Dir(absPath)
+
+	conf, err := runtime.LoadBusYAML(baseDir, busFile)
+	if err != nil {
+		return err
+	}
+	if conf.Spec == nil {
+		return errors.New("spec is not defined in configuration")
+	}
+
+	dialectName, dir := c.Dialect, c.Dir
+	if dialectName == "" || dir == "" {
+		d, initializerDir := migrationInitializer(conf)
+		if dialectName == "" {
+			dialectName = d
+		}
+		if dir == "" {
+			dir = initializerDir
+		}
+	}
+	if dialectName == "" {
+		return errors.New("dialect is not defined")
+	}
+	if dir == "" {
+		return errors.New("migrations directory is not defined")
+	}
+	dialect, ok := schema.DialectByName(dialectName)
+	if !ok {
+		return fmt.Errorf("unknown dialect %q (expected one of %s)",
+			dialectName, strings.Join(schema.DialectNames(), ", "))
+	}
+
+	specFile, err := config.NormalizeUrl(*conf.Spec, baseDir)
+	if err != nil {
+		return err
+	}
+	nss, err := spec_apex.Loader(context.Background(), map[string]interface{}{
+		"filename": specFile,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	namespaces := make(spec.Namespaces, len(nss))
+	for _, ns := range nss {
+		namespaces[ns.Name] = ns
+	}
+
+	target, err := schema.FromSpec(namespaces)
+	if err != nil {
+		return err
+	}
+	if c.Baseline {
+		if err := schema.SaveSnapshot(dir, target); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", filepath.Join(dir, schema.SnapshotFile))
+		return nil
+	}
+	current, err := schema.LoadSnapshot(dir)
+	if errors.Is(err, schema.ErrNoSnapshot) {
+		return fmt.Errorf("%w: run with --baseline once the migrations in %s match the entities", err, dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	m := schema.Diff(dialect, current, target)
+	if m.Empty() {
+		fmt.Println("Schema is up to date")
+		return nil
+	}
+
+	if c.DryRun {
+		version, err := schema.NextVersion(dir)
+		if err != nil {
+			return err
+		}
+		up, down := schema.Filenames(version, c.Name)
+		fmt.Printf("-- %s\n%s\n-- %s\n%s", up, m.UpSQL(), down, m.DownSQL())
+		return nil
+	}
+
+	files, err := schema.WriteMigration(dir, c.Name, m, target)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Printf("Wrote %s\n", file)
+	}
+
+	return nil
+}
+
+// migrationInitializer returns the dialect and directory of the first
+// migration initializer in the configuration.
+func migrationInitializer(conf *runtime.BusConfig) (dialect, dir string) {
+	names := make([]string, 0, len(conf.Initializers))
+	for name := range conf.Initializers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		component := conf.Initializers[name]
+		d, ok := migrationDialects[component.Uses]
+		if !ok {
+			continue
+		}
+		if with, ok := component.With.(map[string]interface{}); ok {
+			dir, _ = with["directory"].(string)
+		}
+		return d, dir
+	}
+
+	return "", ""
+}
+
+type migrateCmd struct {
+	// BusFile is the application configuration (not an OCI image reference).
+	BusFile string `name:"bus" type:"existingFile" default:"bus.yaml" help:"The NanoBus application configuration"`
+	// Initializer restricts the command to one migration initializer.
+	Initializer string `name:"initializer" optional:"" help:"The name of the migration initializer (defaults to all)"`
+	// DryRun is a flag denoting to print the migrations that would run without applying them.
+	DryRun bool `name:"dry-run" default:"false" help:"Print the migrations that would run without applying them"`
+
+	Status migrateStatusCmd `cmd:"" help:"Shows the current version and which migrations are applied"`
+	Up     migrateUpCmd     `cmd:"" help:"Applies the next N pending migrations (all by default)"`
+	Down   migrateDownCmd   `cmd:"" help:"Reverts the last N applied migrations (one by default)"`
+	Goto   migrateGotoCmd   `cmd:"" help:"Migrates up or down to a version"`
+	Force  migrateForceCmd  `cmd:"" help:"Sets the version without running migrations and clears the dirty flag"`
+}
+
+type migrateStatusCmd struct{}
+
+func (c *migrateStatusCmd) Run() error {
+	return commands.Migrate.each(func(m *migration.Migrator) error {
+		version, dirty, err := m.Version()
+		if err != nil {
+			return err
+		}
+		migrations, err := m.Migrations()
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			fmt.Printf("  version %d (dirty)\n", version)
+		} else {
+			fmt.Printf("  version %d\n", version)
+		}
+		for _, mig := range migrations {
+			state := "pending"
+			if mig.Version <= version {
+				state = "applied"
+			}
+			fmt.Printf("  %-8s %d_%s\n", state, mig.Version, mig.Name)
+		}
+		return nil
+	})
+}
+
+type migrateUpCmd struct {
+	N int `arg:"" optional:"" help:"The number of migrations to apply"`
+}
+
+func (c *migrateUpCmd) Run() error {
+	return commands.Migrate.apply(func(m *migration.Migrator) ([]migration.Step, error) {
+		return m.PlanUp(c.N)
+	})
+}
+
+type migrateDownCmd struct {
+	N int `arg:"" optional:"" default:"1" help:"The number of migrations to revert"`
+}
+
+func (c *migrateDownCmd) Run() error {
+	if c.N <= 0 {
+		return errors.New("the number of migrations to revert must be positive")
+	}
+	return commands.Migrate.apply(func(m *migration.Migrator) ([]migration.Step, error) {
+		return m.PlanDown(c.N)
+	})
+}
+
+type migrateGotoCmd struct {
+	Version uint `arg:"" help:"The version to migrate to (0 reverts all migrations)"`
+}
+
+func (c *migrateGotoCmd) Run() error {
+	return commands.Migrate.apply(func(m *migration.Migrator) ([]migration.Step, error) {
+		return m.PlanGoto(c.Version)
+	})
+}
+
+type migrateForceCmd struct {
+	Version int `arg:"" help:"The version to set (-1 for none)"`
+}
+
+func (c *migrateForceCmd) Run() error {
+	return commands.Migrate.each(func(m *migration.Migrator) error {
+		if commands.Migrate.DryRun {
+			fmt.Printf("  force version %d (dry run)\n", c.Version)
+			return nil
+		}
+		if err := m.Force(c.Version); err != nil {
+			return err
+		}
+		fmt.Printf("  forced version %d\n", c.Version)
+		return nil
+	})
+}
+
+// apply runs the steps returned by plan, printing the time each one takes.
+func (c *migrateCmd) apply(plan func(m *migration.Migrator) ([]migration.Step, error)) error {
+	return c.each(func(m *migration.Migrator) error {
+		steps, err := plan(m)
+		if err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			fmt.Println("  no change")
+			return nil
+		}
+		if c.DryRun {
+			for _, step := range steps {
+				fmt.Printf("  %-4s %d_%s (dry run)\n", step.Direction, step.Version, step.Name)
+			}
+			return nil
+		}
+		return m.Apply(steps, func(step migration.Step, duration time.Duration) {
+			fmt.Printf("  %-4s %d_%s (%s)\n", step.Direction, step.Version, step.Name, duration.Round(time.Millisecond))
+		})
+	})
+}
+
+// each calls f with a Migrator for each migration initializer in the
+// configuration.
+func (c *migrateCmd) each(f func(m *migration.Migrator) error) error {
+	busFile, err := os.Open(c.BusFile)
+	if err != nil {
+		return err
+	}
+	defer busFile.Close()
+
+	absPath, err := filepath.Abs(c.BusFile)
+	if err != nil {
+		return err
+	}
+	conf, err := runtime.LoadBusYAML(filepath.Dir(absPath), busFile)
+	if err != nil {
+		return err
+	}
+
+	migrationRegistry := migration.Registry{}
+	migrationRegistry.Register(
+		migrate_mssql.MigrateMSSQLV1Migration,
+		migrate_mysql.MigrateMySQLV1Migration,
+		migrate_postgres.MigratePostgresV1Migration,
+		migrate_sqlite.MigrateSQLiteV1Migration)
+
+	names := make([]string, 0, len(conf.Initializers))
+	for name := range conf.Initializers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := context.Background()
+	found := false
+	for _, name := range names {
+		component := conf.Initializers[name]
+		loader, ok := migrationRegistry[component.Uses]
+		if !ok || (c.Initializer != "" && c.Initializer != name) {
+			continue
+		}
+		found = true
+		if with, ok := component.With.(map[string]interface{}); ok {
+			with["name"] = name
+		}
+
+		fmt.Printf("%s (%s)\n", name, component.Uses)
+		m, err := loader(ctx, component.With, nil)
+		if err != nil {
+			return fmt.Errorf("could not load migration %s: %w", name, err)
+		}
+		err = f(m)
+		m.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		if c.Initializer != "" {
+			return fmt.Errorf("migration initializer %q is not configured", c.Initializer)
+		}
+		return errors.New("no migration initializers are configured")
+	}
+
+	return nil
+}
+
 type versionCmd struct{}
 
 func (c *versionCmd) Run() error {
@@ -11,6 +11,7 @@ package actions
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 
 	"github.com/nanobus/nanobus/pkg/registry"
 )
@@ -38,3 +39,27 @@ var ErrStop = errors.New("processing stopped")
 func Stop() error {
 	return ErrStop
 }
+
+type stopObserverKey struct{}
+
+// WithStopObserver returns a context for running a nested pipeline and a
+// function reporting whether that pipeline was stopped with ErrStop. Pipelines
+// handle ErrStop themselves, so actions that run nested pipelines use this to
+// tell a stop apart from completion.
+func WithStopObserver(ctx context.Context) (context.Context, func() bool) {
+	var stopped atomic.Bool
+	ctx = context.WithValue(ctx, stopObserverKey{}, &stopped)
+	return ctx, stopped.Load
+}
+
+// ObserveStop is called by pipelines before running their steps. It returns a
+// context without the stop observer, so that it only applies to the pipeline
+// it was passed to, and a function that records a stop.
+func ObserveStop(ctx context.Context) (context.Context, func()) {
+	stopped, ok := ctx.Value(stopObserverKey{}).(*atomic.Bool)
+	if !ok || stopped == nil {
+		return ctx, func() {}
+	}
+	ctx = context.WithValue(ctx, stopObserverKey{}, (*atomic.Bool)(nil))
+	return ctx, func() { stopped.Store(true) }
+}
@@ -9,6 +9,7 @@
 package actions_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +29,22 @@ func TestClone(t *testing.T) {
 func TestStop(t *testing.T) {
 	assert.Equal(t, actions.ErrStop, actions.Stop())
 }
+
+func TestStopObserver(t *testing.T) {
+	ctx, stopped := actions.WithStopObserver(context.Background())
+	assert.False(t, stopped())
+
+	pipelineCtx, onStop := actions.ObserveStop(ctx)
+
+	// Nested pipelines do not report to the observer.
+	_, nestedStop := actions.ObserveStop(pipelineCtx)
+	nestedStop()
+	assert.False(t, stopped())
+
+	onStop()
+	assert.True(t, stopped())
+
+	// Without an observer, stops are ignored.
+	_, onStop = actions.ObserveStop(context.Background())
+	onStop()
+}
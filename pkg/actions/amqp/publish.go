@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func PublishLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := PublishConfig{
+		Codec:      "json",
+		Persistent: true,
+		Confirm:    true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	publisher, err := resource.Get[Publisher](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return PublishAction(publisher, &c, codec), nil
+}
+
+func PublishAction(
+	publisher Publisher,
+	config *PublishConfig,
+	codec codec.Codec) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			input, err = config.Data.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		routingKey, err := expr.EvalAsStringE(config.RoutingKey, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate routing key: %w", err)
+		}
+
+		payload, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		msg := amqp.Publishing{
+			ContentType: codec.ContentType(),
+			Timestamp:   time.Now(),
+			Body:        payload,
+		}
+		if config.Persistent {
+			msg.DeliveryMode = amqp.Persistent
+		}
+		if config.Expiration != nil {
+			msg.Expiration = strconv.FormatInt(config.Expiration.Milliseconds(), 10)
+		}
+		if config.Headers != nil {
+			headers, err := config.Headers.EvalMap(data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate headers: %w", err)
+			}
+			msg.Headers = make(amqp.Table, len(headers))
+			for k, v := range headers {
+				msg.Headers[k] = v
+			}
+		}
+		for _, p := range []struct {
+			expr   *expr.ValueExpr
+			target *string
+		}{
+			{config.Type, &msg.Type},
+			{config.MessageID, &msg.MessageId},
+			{config.CorrelationID, &msg.CorrelationId},
+			{config.ReplyTo, &msg.ReplyTo},
+		} {
+			if p.expr == nil {
+				continue
+			}
+			if *p.target, err = expr.EvalAsStringE(p.expr, data); err != nil {
+				return nil, err
+			}
+		}
+
+		err = publisher.Publish(ctx, config.Exchange, routingKey,
+			config.Mandatory, config.Confirm, msg)
+
+		return nil, resiliency.Retriable(err)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package amqp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/amqp"
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type mockPublisher struct {
+	exchange  string
+	key       string
+	mandatory bool
+	confirm   bool
+	msg       amqp091.Publishing
+	err       error
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, exchange, key string, mandatory, confirm bool, msg amqp091.Publishing) error {
+	m.exchange = exchange
+	m.key = key
+	m.mandatory = mandatory
+	m.confirm = confirm
+	m.msg = msg
+	return m.err
+}
+
+func getResolver(publisher amqp.Publisher) resolve.ResolveAs {
+	resources := resource.Resources{
+		"rabbitmq": publisher,
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	m := mockPublisher{}
+	action, err := amqp.PublishLoader(ctx, map[string]interface{}{
+		"resource":      "rabbitmq",
+		"exchange":      "orders",
+		"routingKey":    `"order." + input.event`,
+		"data":          `input.order`,
+		"headers":       `{"tenant": input.tenant}`,
+		"type":          `"order.created"`,
+		"correlationId": `input.order.id`,
+		"expiration":    "1m",
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	_, err = action(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"event":  "created",
+			"tenant": "acme",
+			"order": map[string]interface{}{
+				"id": "1234",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders", m.exchange)
+	assert.Equal(t, "order.created", m.key)
+	assert.False(t, m.mandatory)
+	assert.True(t, m.confirm)
+	assert.Equal(t, `{"id":"1234"}`, string(m.msg.Body))
+	assert.Equal(t, "application/json", m.msg.ContentType)
+	assert.Equal(t, amqp091.Persistent, m.msg.DeliveryMode)
+	assert.Equal(t, "order.created", m.msg.Type)
+	assert.Equal(t, "1234", m.msg.CorrelationId)
+	assert.Equal(t, "60000", m.msg.Expiration)
+	assert.Equal(t, amqp091.Table{"tenant": "acme"}, m.msg.Headers)
+}
+
+func TestPublishError(t *testing.T) {
+	ctx := context.Background()
+	m := mockPublisher{
+		err: amqp.ErrNotConfirmed,
+	}
+	action, err := amqp.PublishLoader(ctx, map[string]interface{}{
+		"resource":   "rabbitmq",
+		"routingKey": `"orders"`,
+		"confirm":    false,
+		"persistent": false,
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	_, err = action(ctx, actions.Data{})
+	assert.True(t, errors.Is(err, amqp.ErrNotConfirmed))
+	assert.False(t, m.confirm)
+	assert.Equal(t, uint8(0), m.msg.DeliveryMode)
+}
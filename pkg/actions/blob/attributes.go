@@ -0,0 +1,125 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package blob
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func AttributesLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := AttributesConfig{
+		NotFoundError: "not_found",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	bucket, err := resource.Get[*blob.Bucket](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return AttributesAction(bucket, &c), nil
+}
+
+func AttributesAction(
+	bucket *blob.Bucket,
+	config *AttributesConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		attrs, err := bucket.Attributes(ctx, key)
+		if err != nil {
+			if gcerrors.Code(err) == gcerrors.NotFound {
+				return nil, notFound(config.NotFoundError, config.Resource, key)
+			}
+			return nil, resiliency.Retriable(fmt.Errorf("could not read attributes of key %s: %w", key, err))
+		}
+
+		return attributesData(key, attrs), nil
+	}
+}
+
+func attributesData(key string, a *blob.Attributes) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(a.Metadata))
+	for k, v := range a.Metadata {
+		metadata[k] = v
+	}
+	result := map[string]interface{}{
+		"key":         key,
+		"size":        a.Size,
+		"contentType": a.ContentType,
+		"metadata":    metadata,
+		"modTime":     a.ModTime,
+	}
+	optional := map[string]string{
+		"cacheControl":       a.CacheControl,
+		"contentDisposition": a.ContentDisposition,
+		"contentEncoding":    a.ContentEncoding,
+		"contentLanguage":    a.ContentLanguage,
+		"etag":               a.ETag,
+	}
+	for k, v := range optional {
+		if v != "" {
+			result[k] = v
+		}
+	}
+	if !a.CreateTime.IsZero() {
+		result["createTime"] = a.CreateTime
+	}
+	if len(a.MD5) > 0 {
+		result["md5"] = hex.EncodeToString(a.MD5)
+	}
+	return result
+}
+
+func objectData(o *blob.ListObject) map[string]interface{} {
+	result := map[string]interface{}{
+		"key": o.Key,
+	}
+	if o.IsDir {
+		result["isDir"] = true
+		return result
+	}
+	result["size"] = o.Size
+	result["modTime"] = o.ModTime
+	if len(o.MD5) > 0 {
+		result["md5"] = hex.EncodeToString(o.MD5)
+	}
+	return result
+}
+
+func notFound(errorName string, resource resource.Ref, key string) error {
+	return errorz.Return(errorName, errorz.Metadata{
+		"resource": resource,
+		"key":      key,
+	})
+}
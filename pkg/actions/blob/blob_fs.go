@@ -10,6 +10,7 @@ package blob
 
 import (
 	"context"
+	"net/url"
 
 	"gocloud.dev/blob/fileblob"
 
@@ -21,6 +22,10 @@ import (
 type FSBlobConfig struct {
 	Dir    string `mapstructure:"dir" validate:"required"`
 	Create bool   `mapstructure:"create"`
+	// BaseURL and SecretKey enable signed URLs. URLs are created by adding
+	// the key and an HMAC signature to BaseURL.
+	BaseURL   string `mapstructure:"baseUrl" validate:"required_with=SecretKey"`
+	SecretKey string `mapstructure:"secretKey" validate:"required_with=BaseURL"`
 }
 
 // FSBlob is the NamedLoader for a filesystem blob.
@@ -34,8 +39,17 @@ func FSBlobLoader(ctx context.Context, with interface{}, resolver resolve.Resolv
 		return nil, err
 	}
 
-	// Create a *blob.Bucket.
-	return fileblob.OpenBucket(c.Dir, &fileblob.Options{
+	opts := fileblob.Options{
 		CreateDir: c.Create,
-	})
+	}
+	if c.BaseURL != "" {
+		baseURL, err := url.Parse(c.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		opts.URLSigner = fileblob.NewURLSignerHMAC(baseURL, []byte(c.SecretKey))
+	}
+
+	// Create a *blob.Bucket.
+	return fileblob.OpenBucket(c.Dir, &opts)
 }
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func CopyLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := CopyConfig{
+		NotFoundError: "not_found",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	bucket, err := resource.Get[*blob.Bucket](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+	source := bucket
+	if c.SourceResource != nil {
+		if source, err = resource.Get[*blob.Bucket](resources, *c.SourceResource); err != nil {
+			return nil, err
+		}
+	}
+
+	return CopyAction(source, bucket, &c), nil
+}
+
+func CopyAction(
+	source, bucket *blob.Bucket,
+	config *CopyConfig) actions.Action {
+	sourceResource := config.Resource
+	if config.SourceResource != nil {
+		sourceResource = *config.SourceResource
+	}
+
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		sourceKey, err := expr.EvalAsStringE(config.SourceKey, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate source key: %w", err)
+		}
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		if source == bucket {
+			err = bucket.Copy(ctx, key, sourceKey, nil)
+		} else {
+			err = copyBetween(ctx, source, bucket, sourceKey, key)
+		}
+		if err != nil {
+			if gcerrors.Code(err) == gcerrors.NotFound {
+				return nil, notFound(config.NotFoundError, sourceResource, sourceKey)
+			}
+			return nil, resiliency.Retriable(fmt.Errorf("could not copy key %s to %s: %w", sourceKey, key, err))
+		}
+
+		return nil, nil
+	}
+}
+
+// copyBetween streams a blob from one bucket to another and copies its
+// attributes.
+func copyBetween(ctx context.Context, source, bucket *blob.Bucket, sourceKey, key string) error {
+	attrs, err := source.Attributes(ctx, sourceKey)
+	if err != nil {
+		return err
+	}
+
+	reader, err := source.NewReader(ctx, sourceKey, nil)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// Canceling the context aborts the write if copying fails.
+	writeCtx, cancelWrite := context.WithCancel(ctx)
+	defer cancelWrite()
+
+	writer, err := bucket.NewWriter(writeCtx, key, &blob.WriterOptions{
+		CacheControl:       attrs.CacheControl,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentLanguage:    attrs.ContentLanguage,
+		ContentType:        attrs.ContentType,
+		Metadata:           attrs.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		cancelWrite()
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
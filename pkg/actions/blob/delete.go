@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package blob
+
+import (
+	"context"
+	"fmt"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func DeleteLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c DeleteConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	bucket, err := resource.Get[*blob.Bucket](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteAction(bucket, &c), nil
+}
+
+func DeleteAction(
+	bucket *blob.Bucket,
+	config *DeleteConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		if err := bucket.Delete(ctx, key); err != nil {
+			if gcerrors.Code(err) == gcerrors.NotFound {
+				if config.NotFoundError != nil {
+					return nil, notFound(*config.NotFoundError, config.Resource, key)
+				}
+				return nil, nil
+			}
+			return nil, resiliency.Retriable(fmt.Errorf("could not delete key %s: %w", key, err))
+		}
+
+		return nil, nil
+	}
+}
@@ -3,6 +3,8 @@
 package blob
 
 import (
+	"time"
+
 	"github.com/nanobus/nanobus/pkg/actions"
 	"github.com/nanobus/nanobus/pkg/expr"
 	"github.com/nanobus/nanobus/pkg/resource"
@@ -41,8 +43,105 @@ type WriteConfig struct {
 	CodecArgs       []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
 	DelimiterString *string       `json:"delimiterString,omitempty" yaml:"delimiterString,omitempty" msgpack:"delimiterString,omitempty" mapstructure:"delimiterString"`
 	DelimiterBytes  []byte        `json:"delimiterBytes,omitempty" yaml:"delimiterBytes,omitempty" msgpack:"delimiterBytes,omitempty" mapstructure:"delimiterBytes"`
+	// The MIME type of the blob. It is detected from the data if not set.
+	ContentType *expr.ValueExpr `json:"contentType,omitempty" yaml:"contentType,omitempty" msgpack:"contentType,omitempty" mapstructure:"contentType"`
+	// The Cache-Control header returned when the blob is served.
+	CacheControl *expr.ValueExpr `json:"cacheControl,omitempty" yaml:"cacheControl,omitempty" msgpack:"cacheControl,omitempty" mapstructure:"cacheControl"`
+	// Metadata evaluates to a map of custom metadata to store with the blob.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
 }
 
 func Write() (string, actions.Loader) {
 	return "@blob/write", WriteLoader
 }
+
+// Lists the blobs in a Blob store in lexicographical order of their keys. When
+// the action is streamed, every blob from the page token onward is sent to the
+// stream. Otherwise, a page of blobs and the token of the next page are returned.
+type ListConfig struct {
+	// The blob store resource to list.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Only blobs whose keys start with the prefix are listed.
+	Prefix *expr.ValueExpr `json:"prefix,omitempty" yaml:"prefix,omitempty" msgpack:"prefix,omitempty" mapstructure:"prefix"`
+	// Groups keys that contain the delimiter (e.g. /) after the prefix into
+	// directories, which are listed with `isDir` set.
+	Delimiter *string `json:"delimiter,omitempty" yaml:"delimiter,omitempty" msgpack:"delimiter,omitempty" mapstructure:"delimiter"`
+	// The maximum number of blobs to return. Defaults to 1000.
+	PageSize *expr.ValueExpr `json:"pageSize,omitempty" yaml:"pageSize,omitempty" msgpack:"pageSize,omitempty" mapstructure:"pageSize"`
+	// The token returned with the previous page.
+	PageToken *expr.ValueExpr `json:"pageToken,omitempty" yaml:"pageToken,omitempty" msgpack:"pageToken,omitempty" mapstructure:"pageToken"`
+}
+
+func List() (string, actions.Loader) {
+	return "@blob/list", ListLoader
+}
+
+// Deletes a blob from a Blob store.
+type DeleteConfig struct {
+	// The blob store resource to delete from.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The key to delete.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// NotFoundError is the error to return if the key is not found. Missing keys
+	// are ignored if it is not set.
+	NotFoundError *string `json:"notFoundError,omitempty" yaml:"notFoundError,omitempty" msgpack:"notFoundError,omitempty" mapstructure:"notFoundError"`
+}
+
+func Delete() (string, actions.Loader) {
+	return "@blob/delete", DeleteLoader
+}
+
+// Copies a blob, including its attributes, within a Blob store or between two
+// Blob stores.
+type CopyConfig struct {
+	// The blob store resource to copy to.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The key to copy to.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The blob store resource to copy from. Defaults to `resource`.
+	SourceResource *resource.Ref `json:"sourceResource,omitempty" yaml:"sourceResource,omitempty" msgpack:"sourceResource,omitempty" mapstructure:"sourceResource"`
+	// The key to copy from.
+	SourceKey *expr.ValueExpr `json:"sourceKey" yaml:"sourceKey" msgpack:"sourceKey" mapstructure:"sourceKey" validate:"required"`
+	// NotFoundError is the error to return if the source key is not found.
+	NotFoundError string `json:"notFoundError" yaml:"notFoundError" msgpack:"notFoundError" mapstructure:"notFoundError" validate:"required"`
+}
+
+func Copy() (string, actions.Loader) {
+	return "@blob/copy", CopyLoader
+}
+
+// Reads the attributes of a blob: its size, content type, metadata, ETag and
+// modification time.
+type AttributesConfig struct {
+	// The blob store resource to read.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The key to read.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// NotFoundError is the error to return if the key is not found.
+	NotFoundError string `json:"notFoundError" yaml:"notFoundError" msgpack:"notFoundError" mapstructure:"notFoundError" validate:"required"`
+}
+
+func Attributes() (string, actions.Loader) {
+	return "@blob/attributes", AttributesLoader
+}
+
+// Creates a URL that grants access to a blob until it expires, so that clients
+// can upload or download it directly. File system buckets require `baseUrl` and
+// `secretKey` to be configured and the URLs must be verified by the server that
+// serves them.
+type SignedURLConfig struct {
+	// The blob store resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The key to grant access to.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The HTTP method the URL is used with (GET, PUT or DELETE).
+	Method string `json:"method" yaml:"method" msgpack:"method" mapstructure:"method" validate:"oneof=GET PUT DELETE"`
+	// How long the URL is valid for. Defaults to 1 hour.
+	Expiry *time.Duration `json:"expiry,omitempty" yaml:"expiry,omitempty" msgpack:"expiry,omitempty" mapstructure:"expiry"`
+	// For PUT, the content type the client must upload with.
+	ContentType *expr.ValueExpr `json:"contentType,omitempty" yaml:"contentType,omitempty" msgpack:"contentType,omitempty" mapstructure:"contentType"`
+}
+
+func SignedURL() (string, actions.Loader) {
+	return "@blob/signed_url", SignedURLLoader
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package blob
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"gocloud.dev/blob"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/stream"
+)
+
+const defaultPageSize = 1000
+
+func ListLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c ListConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	bucket, err := resource.Get[*blob.Bucket](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListAction(bucket, &c), nil
+}
+
+func ListAction(
+	bucket *blob.Bucket,
+	config *ListConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var opts blob.ListOptions
+		var err error
+		if config.Prefix != nil {
+			if opts.Prefix, err = expr.EvalAsStringE(config.Prefix, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate prefix: %w", err)
+			}
+		}
+		if config.Delimiter != nil {
+			opts.Delimiter = *config.Delimiter
+		}
+
+		pageSize := int64(defaultPageSize)
+		if config.PageSize != nil {
+			if pageSize, err = expr.EvalAsInt64E(config.PageSize, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate page size: %w", err)
+			}
+		}
+
+		pageToken := blob.FirstPageToken
+		if config.PageToken != nil {
+			token, err := expr.EvalAsStringE(config.PageToken, data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate page token: %w", err)
+			}
+			if token != "" {
+				if pageToken, err = base64.RawURLEncoding.DecodeString(token); err != nil {
+					return nil, fmt.Errorf("invalid page token: %w", err)
+				}
+			}
+		}
+
+		s, _ := stream.SinkFromContext(ctx)
+
+		if s != nil {
+			for len(pageToken) > 0 {
+				objects, next, err := bucket.ListPage(ctx, pageToken, int(pageSize), &opts)
+				if err != nil {
+					return nil, resiliency.Retriable(fmt.Errorf("could not list blobs: %w", err))
+				}
+				for _, o := range objects {
+					if err := s.Next(objectData(o), nil); err != nil {
+						return nil, err
+					}
+				}
+				pageToken = next
+			}
+
+			return nil, nil
+		}
+
+		objects, next, err := bucket.ListPage(ctx, pageToken, int(pageSize), &opts)
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not list blobs: %w", err))
+		}
+
+		items := make([]interface{}, len(objects))
+		for i, o := range objects {
+			items[i] = objectData(o)
+		}
+		result := map[string]interface{}{
+			"items": items,
+		}
+		if len(next) > 0 {
+			result["nextPageToken"] = base64.RawURLEncoding.EncodeToString(next)
+		}
+
+		return result, nil
+	}
+}
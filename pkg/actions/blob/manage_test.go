@@ -0,0 +1,245 @@
+package blob_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gcblob "gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/blob"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/stream"
+)
+
+func newResolver(resources resource.Resources) resolve.ResolveAs {
+	logger := logr.Discard()
+	codecs := codec.Codecs{
+		"json": json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "system:logger":
+			return resolve.As(logger, target)
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func run(t *testing.T, ctx context.Context, resolver resolve.ResolveAs, loader actions.Loader, with map[string]any, input any) (any, error) {
+	t.Helper()
+	a, err := loader(ctx, with, resolver)
+	require.NoError(t, err)
+	return a(ctx, actions.Data{"input": input})
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+
+	m := memblob.OpenBucket(&memblob.Options{})
+	for _, key := range []string{"a/1", "a/2", "a/3", "a/sub/4", "b/1"} {
+		require.NoError(t, m.WriteAll(ctx, key, []byte(key), nil))
+	}
+	resolver := newResolver(resource.Resources{"test": m})
+
+	with := map[string]any{
+		"resource":  "test",
+		"prefix":    "input.prefix",
+		"delimiter": "/",
+		"pageSize":  "2",
+		"pageToken": "input.token",
+	}
+	result, err := run(t, ctx, resolver, blob.ListLoader, with, map[string]any{"prefix": "a/", "token": ""})
+	require.NoError(t, err)
+	page := result.(map[string]any)
+	items := page["items"].([]any)
+	require.Len(t, items, 2)
+	assert.Equal(t, "a/1", items[0].(map[string]any)["key"])
+	assert.Equal(t, int64(3), items[0].(map[string]any)["size"])
+	require.Contains(t, page, "nextPageToken")
+
+	result, err = run(t, ctx, resolver, blob.ListLoader, with, map[string]any{"prefix": "a/", "token": page["nextPageToken"]})
+	require.NoError(t, err)
+	page = result.(map[string]any)
+	items = page["items"].([]any)
+	require.Len(t, items, 2)
+	assert.Equal(t, "a/3", items[0].(map[string]any)["key"])
+	// Keys below the delimiter are grouped into a directory.
+	assert.Equal(t, map[string]any{"key": "a/sub/", "isDir": true}, items[1])
+	assert.NotContains(t, page, "nextPageToken")
+
+	// Streaming sends every blob regardless of the page size.
+	s := &mockSink{}
+	_, err = run(t, stream.SinkNewContext(ctx, s), resolver, blob.ListLoader, map[string]any{
+		"resource": "test",
+		"pageSize": "2",
+	}, nil)
+	require.NoError(t, err)
+	var keys []string
+	for _, o := range s.data {
+		keys = append(keys, o.(map[string]any)["key"].(string))
+	}
+	assert.Equal(t, []string{"a/1", "a/2", "a/3", "a/sub/4", "b/1"}, keys)
+}
+
+func TestWriteAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	m := memblob.OpenBucket(&memblob.Options{})
+	resolver := newResolver(resource.Resources{"test": m})
+
+	_, err := run(t, ctx, resolver, blob.WriteLoader, map[string]any{
+		"resource":     "test",
+		"key":          `"report.json"`,
+		"codec":        "json",
+		"contentType":  `"application/json"`,
+		"cacheControl": `"max-age=60"`,
+		"metadata":     `{"owner": input.owner}`,
+	}, map[string]any{"owner": "alice"})
+	require.NoError(t, err)
+
+	result, err := run(t, ctx, resolver, blob.AttributesLoader, map[string]any{
+		"resource": "test",
+		"key":      `"report.json"`,
+	}, nil)
+	require.NoError(t, err)
+	attrs := result.(map[string]any)
+	assert.Equal(t, "report.json", attrs["key"])
+	assert.Equal(t, int64(len(`{"owner":"alice"}`)), attrs["size"])
+	assert.Equal(t, "application/json", attrs["contentType"])
+	assert.Equal(t, "max-age=60", attrs["cacheControl"])
+	assert.Equal(t, map[string]any{"owner": "alice"}, attrs["metadata"])
+	assert.NotEmpty(t, attrs["etag"])
+
+	_, err = run(t, ctx, resolver, blob.AttributesLoader, map[string]any{
+		"resource": "test",
+		"key":      `"missing.json"`,
+	}, nil)
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+}
+
+func TestCopyDelete(t *testing.T) {
+	ctx := context.Background()
+
+	src := memblob.OpenBucket(&memblob.Options{})
+	dst, err := fileblob.OpenBucket(t.TempDir(), nil)
+	require.NoError(t, err)
+	defer dst.Close()
+	require.NoError(t, src.WriteAll(ctx, "a", []byte("hello"), &gcblob.WriterOptions{
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"owner": "alice"},
+	}))
+	resolver := newResolver(resource.Resources{"src": src, "dst": dst})
+
+	// Within a bucket.
+	_, err = run(t, ctx, resolver, blob.CopyLoader, map[string]any{
+		"resource":  "src",
+		"sourceKey": `"a"`,
+		"key":       `"b"`,
+	}, nil)
+	require.NoError(t, err)
+	b, err := src.ReadAll(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	// Between buckets.
+	_, err = run(t, ctx, resolver, blob.CopyLoader, map[string]any{
+		"resource":       "dst",
+		"sourceResource": "src",
+		"sourceKey":      `"a"`,
+		"key":            `"copies/a"`,
+	}, nil)
+	require.NoError(t, err)
+	b, err = dst.ReadAll(ctx, "copies/a")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	attrs, err := dst.Attributes(ctx, "copies/a")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", attrs.ContentType)
+	assert.Equal(t, map[string]string{"owner": "alice"}, attrs.Metadata)
+
+	_, err = run(t, ctx, resolver, blob.CopyLoader, map[string]any{
+		"resource":  "src",
+		"sourceKey": `"missing"`,
+		"key":       `"c"`,
+	}, nil)
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+
+	with := map[string]any{
+		"resource": "dst",
+		"key":      `"copies/a"`,
+	}
+	_, err = run(t, ctx, resolver, blob.DeleteLoader, with, nil)
+	require.NoError(t, err)
+	exists, err := dst.Exists(ctx, "copies/a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Missing keys are ignored unless notFoundError is set.
+	_, err = run(t, ctx, resolver, blob.DeleteLoader, with, nil)
+	require.NoError(t, err)
+	with["notFoundError"] = "not_found"
+	_, err = run(t, ctx, resolver, blob.DeleteLoader, with, nil)
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+}
+
+func TestSignedURL(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := blob.FSBlobLoader(ctx, map[string]any{
+		"dir":       t.TempDir(),
+		"baseUrl":   "https://files.example.com/blobs",
+		"secretKey": "secret",
+	}, nil)
+	require.NoError(t, err)
+	bucket := b.(*gcblob.Bucket)
+	defer bucket.Close()
+	resolver := newResolver(resource.Resources{"test": bucket})
+
+	result, err := run(t, ctx, resolver, blob.SignedURLLoader, map[string]any{
+		"resource":    "test",
+		"key":         `"uploads/" + input.name`,
+		"method":      "PUT",
+		"expiry":      "5m",
+		"contentType": `"image/png"`,
+	}, map[string]any{"name": "avatar.png"})
+	require.NoError(t, err)
+	signed := result.(map[string]any)
+	assert.Equal(t, "PUT", signed["method"])
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), signed["expires"].(time.Time), time.Minute)
+
+	u, err := url.Parse(signed["url"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "files.example.com", u.Host)
+	assert.Equal(t, "uploads/avatar.png", u.Query().Get("obj"))
+	assert.Equal(t, "PUT", u.Query().Get("method"))
+	assert.NotEmpty(t, u.Query().Get("signature"))
+
+	// Buckets that cannot sign URLs return an error.
+	resolver = newResolver(resource.Resources{"test": memblob.OpenBucket(nil)})
+	_, err = run(t, ctx, resolver, blob.SignedURLLoader, map[string]any{
+		"resource": "test",
+		"key":      `"a"`,
+	}, nil)
+	assert.Error(t, err)
+}
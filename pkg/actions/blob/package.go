@@ -16,4 +16,9 @@ import (
 var All = []actions.NamedLoader{
 	Read,
 	Write,
+	List,
+	Delete,
+	Copy,
+	Attributes,
+	SignedURL,
 }
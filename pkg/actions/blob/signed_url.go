@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package blob
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gocloud.dev/blob"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func SignedURLLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := SignedURLConfig{
+		Method: "GET",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	bucket, err := resource.Get[*blob.Bucket](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return SignedURLAction(bucket, &c), nil
+}
+
+func SignedURLAction(
+	bucket *blob.Bucket,
+	config *SignedURLConfig) actions.Action {
+	expiry := blob.DefaultSignedURLExpiry
+	if config.Expiry != nil {
+		expiry = *config.Expiry
+	}
+
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		opts := blob.SignedURLOptions{
+			Expiry: expiry,
+			Method: config.Method,
+		}
+		if config.ContentType != nil {
+			if opts.ContentType, err = expr.EvalAsStringE(config.ContentType, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate content type: %w", err)
+			}
+		}
+
+		expires := time.Now().Add(expiry)
+		url, err := bucket.SignedURL(ctx, key, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not sign URL for key %s: %w", key, err)
+		}
+
+		return map[string]interface{}{
+			"url":     url,
+			"method":  config.Method,
+			"expires": expires,
+		}, nil
+	}
+}
@@ -76,7 +76,12 @@ func WriteAction(
 		writeCtx, cancelWrite := context.WithCancel(context.Background())
 		defer cancelWrite()
 
-		writer, err := bucket.NewWriter(writeCtx, key, nil)
+		opts, err := writerOptions(config, data)
+		if err != nil {
+			return nil, err
+		}
+
+		writer, err := bucket.NewWriter(writeCtx, key, opts)
 		if err != nil {
 			return nil, resiliency.Retriable(fmt.Errorf("could create writer: %w", err))
 		}
@@ -143,3 +148,24 @@ func WriteAction(
 		return nil, nil
 	}
 }
+
+func writerOptions(config *WriteConfig, data actions.Data) (*blob.WriterOptions, error) {
+	var opts blob.WriterOptions
+	var err error
+	if config.ContentType != nil {
+		if opts.ContentType, err = expr.EvalAsStringE(config.ContentType, data); err != nil {
+			return nil, fmt.Errorf("could not evaluate content type: %w", err)
+		}
+	}
+	if config.CacheControl != nil {
+		if opts.CacheControl, err = expr.EvalAsStringE(config.CacheControl, data); err != nil {
+			return nil, fmt.Errorf("could not evaluate cache control: %w", err)
+		}
+	}
+	if config.Metadata != nil {
+		if opts.Metadata, err = config.Metadata.EvalMap(data); err != nil {
+			return nil, fmt.Errorf("could not evaluate metadata: %w", err)
+		}
+	}
+	return &opts, nil
+}
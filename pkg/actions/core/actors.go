@@ -0,0 +1,235 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actors"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func RegisterReminderLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c RegisterReminderConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var actorRuntime *actors.Runtime
+	if err := resolve.Resolve(resolver,
+		"actors:runtime", &actorRuntime); err != nil {
+		return nil, err
+	}
+
+	return RegisterReminderAction(actorRuntime, &c), nil
+}
+
+func RegisterReminderAction(
+	actorRuntime *actors.Runtime,
+	config *RegisterReminderConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		entity, err := targetEntity(ctx, data, config.Service, config.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		input, err := actionInput(data, config.Input)
+		if err != nil {
+			return nil, err
+		}
+
+		var period time.Duration
+		if config.Period != nil {
+			period = *config.Period
+		}
+		dueTime := time.Now().Add(dueIn(config.DueTime, period)).UTC()
+
+		if err := actorRuntime.RegisterReminder(ctx, &actors.Reminder{
+			Type:      entity.Type,
+			ID:        entity.ID,
+			Name:      config.Name,
+			Operation: config.Operation,
+			Input:     input,
+			DueTime:   dueTime,
+			Period:    period,
+		}); err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"name":    config.Name,
+			"dueTime": dueTime,
+		}, nil
+	}
+}
+
+func UnregisterReminderLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c UnregisterReminderConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var actorRuntime *actors.Runtime
+	if err := resolve.Resolve(resolver,
+		"actors:runtime", &actorRuntime); err != nil {
+		return nil, err
+	}
+
+	return UnregisterReminderAction(actorRuntime, &c), nil
+}
+
+func UnregisterReminderAction(
+	actorRuntime *actors.Runtime,
+	config *UnregisterReminderConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		entity, err := targetEntity(ctx, data, config.Service, config.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		removed, err := actorRuntime.UnregisterReminder(ctx, entity.Type, entity.ID, config.Name)
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"removed": removed,
+		}, nil
+	}
+}
+
+func RegisterTimerLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c RegisterTimerConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var actorRuntime *actors.Runtime
+	if err := resolve.Resolve(resolver,
+		"actors:runtime", &actorRuntime); err != nil {
+		return nil, err
+	}
+
+	return RegisterTimerAction(actorRuntime, &c), nil
+}
+
+func RegisterTimerAction(
+	actorRuntime *actors.Runtime,
+	config *RegisterTimerConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		entity, err := targetEntity(ctx, data, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		input, err := actionInput(data, config.Input)
+		if err != nil {
+			return nil, err
+		}
+
+		var period time.Duration
+		if config.Period != nil {
+			period = *config.Period
+		}
+		due := dueIn(config.DueTime, period)
+
+		if err := actorRuntime.RegisterTimer(entity.Type, entity.ID, actors.Timer{
+			Name:      config.Name,
+			Operation: config.Operation,
+			Input:     input,
+			DueTime:   due,
+			Period:    period,
+		}); err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"name":    config.Name,
+			"dueTime": time.Now().Add(due).UTC(),
+		}, nil
+	}
+}
+
+func UnregisterTimerLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c UnregisterTimerConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var actorRuntime *actors.Runtime
+	if err := resolve.Resolve(resolver,
+		"actors:runtime", &actorRuntime); err != nil {
+		return nil, err
+	}
+
+	return UnregisterTimerAction(actorRuntime, &c), nil
+}
+
+func UnregisterTimerAction(
+	actorRuntime *actors.Runtime,
+	config *UnregisterTimerConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		entity, err := targetEntity(ctx, data, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"removed": actorRuntime.UnregisterTimer(entity.Type, entity.ID, config.Name),
+		}, nil
+	}
+}
+
+// targetEntity returns the entity processing the call with the configured
+// service and ID overrides.
+func targetEntity(ctx context.Context, data actions.Data, service *string, id *expr.ValueExpr) (actors.Entity, error) {
+	entity, _ := actors.FromContext(ctx)
+	if service != nil {
+		entity.Type = *service
+	}
+	if id != nil {
+		value, err := id.Eval(data)
+		if err != nil {
+			return entity, err
+		}
+		entity.ID = fmt.Sprintf("%v", value)
+	}
+
+	if entity.Type == "" || entity.ID == "" {
+		return entity, errorz.New(errorz.FailedPrecondition,
+			"the call is not processed by an entity of a stateful service")
+	}
+
+	return entity, nil
+}
+
+func actionInput(data actions.Data, input *expr.DataExpr) (interface{}, error) {
+	if input == nil {
+		return data["input"], nil
+	}
+
+	return input.Eval(data)
+}
+
+// dueIn returns the delay before the first call, which defaults to the
+// period.
+func dueIn(dueTime *time.Duration, period time.Duration) time.Duration {
+	if dueTime != nil {
+		return *dueTime
+	}
+
+	return period
+}
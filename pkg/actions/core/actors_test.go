@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/core"
+	"github.com/nanobus/nanobus/pkg/actors"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+func TestReminderActions(t *testing.T) {
+	ctx := context.Background()
+	store := actors.NewMemoryStore()
+	rt := actors.New(logr.Discard(), store, actors.Config{})
+	defer rt.Close()
+	resolver := actorsResolver(rt)
+
+	name, loader := core.RegisterReminder()
+	assert.Equal(t, "register_reminder", name)
+	register, err := loader(ctx, map[string]interface{}{
+		"name":      "renew",
+		"operation": "renew",
+		"input":     `{"id": input.id}`,
+		"period":    "24h",
+	}, resolver)
+	require.NoError(t, err)
+
+	data := actions.Data{
+		"input": map[string]interface{}{
+			"id": "1234",
+		},
+	}
+
+	// Reminders are registered for the entity processing the call.
+	_, err = register(ctx, data)
+	var errz *errorz.Error
+	require.ErrorAs(t, err, &errz)
+	assert.Equal(t, errorz.FailedPrecondition, errz.Code)
+
+	entityCtx := actors.NewContext(ctx, actors.Entity{Type: "subscriptions.v1.Subscription", ID: "1234"})
+	before := time.Now()
+	result, err := register(entityCtx, data)
+	require.NoError(t, err)
+	output := result.(map[string]interface{})
+	assert.Equal(t, "renew", output["name"])
+	dueTime := output["dueTime"].(time.Time)
+	assert.WithinDuration(t, before.Add(24*time.Hour), dueTime, time.Second)
+
+	due, err := store.DueReminders(ctx, dueTime, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "subscriptions.v1.Subscription", due[0].Type)
+	assert.Equal(t, "1234", due[0].ID)
+	assert.Equal(t, "renew", due[0].Operation)
+	assert.Equal(t, map[string]interface{}{"id": "1234"}, due[0].Input)
+	assert.Equal(t, 24*time.Hour, due[0].Period)
+
+	// Other entities are targeted with service and id.
+	_, loader = core.UnregisterReminder()
+	unregister, err := loader(ctx, map[string]interface{}{
+		"name":    "renew",
+		"service": "subscriptions.v1.Subscription",
+		"id":      "input.id",
+	}, resolver)
+	require.NoError(t, err)
+
+	result, err = unregister(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"removed": true}, result)
+	result, err = unregister(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"removed": false}, result)
+}
+
+func TestTimerActions(t *testing.T) {
+	ctx := context.Background()
+	rt := actors.New(logr.Discard(), actors.NewMemoryStore(), actors.Config{})
+	defer rt.Close()
+	resolver := actorsResolver(rt)
+
+	calls := make(chan handler.Handler, 10)
+	rt.SetInvoker(func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		assert.Equal(t, "1234", id)
+		assert.Equal(t, map[string]interface{}{"id": "1234"}, input)
+		calls <- h
+		return nil, nil
+	})
+
+	_, loader := core.RegisterTimer()
+	register, err := loader(ctx, map[string]interface{}{
+		"name":      "poll",
+		"operation": "poll",
+		"dueTime":   "1ms",
+		"period":    "1h",
+	}, resolver)
+	require.NoError(t, err)
+	_, loader = core.UnregisterTimer()
+	unregister, err := loader(ctx, map[string]interface{}{
+		"name": "poll",
+	}, resolver)
+	require.NoError(t, err)
+
+	_, err = rt.Invoke(ctx, "jobs.v1.Job", "1234", func(ctx context.Context) (interface{}, error) {
+		return register(ctx, actions.Data{
+			"input": map[string]interface{}{"id": "1234"},
+		})
+	})
+	require.NoError(t, err)
+
+	select {
+	case h := <-calls:
+		assert.Equal(t, handler.Handler{Interface: "jobs.v1.Job", Operation: "poll"}, h)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer was not called")
+	}
+
+	result, err := rt.Invoke(ctx, "jobs.v1.Job", "1234", func(ctx context.Context) (interface{}, error) {
+		return unregister(ctx, actions.Data{})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"removed": true}, result)
+}
+
+func actorsResolver(rt *actors.Runtime) resolve.ResolveAs {
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "actors:runtime":
+			return resolve.As(rt, target)
+		}
+		return false
+	}
+}
@@ -5,10 +5,12 @@ package core
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/nanobus/nanobus/pkg/actions"
 	"github.com/nanobus/nanobus/pkg/expr"
 	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resource"
 	"github.com/nanobus/nanobus/pkg/runtime"
 )
 
@@ -52,6 +54,23 @@ func CallProvider() (string, actions.Loader) {
 	return "call_provider", CallProviderLoader
 }
 
+// Cancels a delayed invocation created by the `schedule` action. Returns
+// `cancelled` indicating whether a pending invocation was removed.
+type CancelScheduleConfig struct {
+	// The redis or Postgres resource the invocation was scheduled in. Defaults to
+	// an in-memory store shared within the process.
+	Resource *resource.Ref `json:"resource,omitempty" yaml:"resource,omitempty" msgpack:"resource,omitempty" mapstructure:"resource"`
+	// The key of the invocation to cancel.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The prefix of the redis keys of delayed invocations, used as a hash tag so
+	// that they share a Redis Cluster slot. Defaults to nanobus:delayed:.
+	KeyPrefix *string `json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty" msgpack:"keyPrefix,omitempty" mapstructure:"keyPrefix"`
+}
+
+func CancelSchedule() (string, actions.Loader) {
+	return "cancel_schedule", CancelScheduleLoader
+}
+
 // TODO
 type DecodeConfig struct {
 	TypeField string `json:"typeField" yaml:"typeField" msgpack:"typeField" mapstructure:"typeField" validate:"required"`
@@ -191,6 +210,55 @@ func ReCaptcha() (string, actions.Loader) {
 	return "recaptcha", ReCaptchaLoader
 }
 
+// Registers a durable reminder that calls an operation of a stateful entity.
+// Reminders are persisted in the actor state store and survive restarts.
+// Registering a reminder with the same name replaces it. Returns the reminder's
+// `name` and first `dueTime`.
+type RegisterReminderConfig struct {
+	// The name of the reminder.
+	Name string `json:"name" yaml:"name" msgpack:"name" mapstructure:"name" validate:"required"`
+	// The operation of the entity to call.
+	Operation string `json:"operation" yaml:"operation" msgpack:"operation" mapstructure:"operation" validate:"required"`
+	// The input passed to the operation. Defaults to the pipeline input.
+	Input *expr.DataExpr `json:"input,omitempty" yaml:"input,omitempty" msgpack:"input,omitempty" mapstructure:"input"`
+	// How long to wait before the first call. Defaults to the period.
+	DueTime *time.Duration `json:"dueTime,omitempty" yaml:"dueTime,omitempty" msgpack:"dueTime,omitempty" mapstructure:"dueTime"`
+	// How often the operation is called after the first call. When not set, the
+	// reminder is removed after the first call.
+	Period *time.Duration `json:"period,omitempty" yaml:"period,omitempty" msgpack:"period,omitempty" mapstructure:"period"`
+	// The stateful service of the entity. Defaults to the entity processing the
+	// call.
+	Service *string `json:"service,omitempty" yaml:"service,omitempty" msgpack:"service,omitempty" mapstructure:"service"`
+	// The ID of the entity. Defaults to the entity processing the call.
+	ID *expr.ValueExpr `json:"id,omitempty" yaml:"id,omitempty" msgpack:"id,omitempty" mapstructure:"id"`
+}
+
+func RegisterReminder() (string, actions.Loader) {
+	return "register_reminder", RegisterReminderLoader
+}
+
+// Registers a timer that calls an operation of the entity processing the call.
+// Timers are kept in memory and cleared when the entity is deactivated.
+// Registering a timer with the same name replaces it. Returns the timer's `name`
+// and first `dueTime`.
+type RegisterTimerConfig struct {
+	// The name of the timer.
+	Name string `json:"name" yaml:"name" msgpack:"name" mapstructure:"name" validate:"required"`
+	// The operation of the entity to call.
+	Operation string `json:"operation" yaml:"operation" msgpack:"operation" mapstructure:"operation" validate:"required"`
+	// The input passed to the operation. Defaults to the pipeline input.
+	Input *expr.DataExpr `json:"input,omitempty" yaml:"input,omitempty" msgpack:"input,omitempty" mapstructure:"input"`
+	// How long to wait before the first call. Defaults to the period.
+	DueTime *time.Duration `json:"dueTime,omitempty" yaml:"dueTime,omitempty" msgpack:"dueTime,omitempty" mapstructure:"dueTime"`
+	// How often the operation is called after the first call. When not set, the
+	// timer is removed after the first call.
+	Period *time.Duration `json:"period,omitempty" yaml:"period,omitempty" msgpack:"period,omitempty" mapstructure:"period"`
+}
+
+func RegisterTimer() (string, actions.Loader) {
+	return "register_timer", RegisterTimerLoader
+}
+
 // TODO
 type RouteConfig struct {
 	// Selection defines the selection mode: single or multi.
@@ -262,3 +330,83 @@ func (e *SelectionMode) UnmarshalJSON(b []byte) error {
 	}
 	return e.FromString(str)
 }
+
+// Stores an invocation of a handler to run at a future time. The
+// `nanobus.transport.delayed/v1` transport delivers due invocations at least
+// once. Returns the `key`, the `runAt` time and whether the invocation was
+// `scheduled` or a pending invocation with the same key already exists.
+type ScheduleConfig struct {
+	// The redis or Postgres resource to store the invocation in. Defaults to an
+	// in-memory store shared within the process.
+	Resource *resource.Ref `json:"resource,omitempty" yaml:"resource,omitempty" msgpack:"resource,omitempty" mapstructure:"resource"`
+	// The handler to invoke.
+	Handler handler.Handler `json:"handler" yaml:"handler" msgpack:"handler" mapstructure:"handler" validate:"required"`
+	// The input passed to the handler. Defaults to the pipeline input.
+	Input *expr.DataExpr `json:"input,omitempty" yaml:"input,omitempty" msgpack:"input,omitempty" mapstructure:"input"`
+	// How long to wait before invoking the handler.
+	Delay *time.Duration `json:"delay,omitempty" yaml:"delay,omitempty" msgpack:"delay,omitempty" mapstructure:"delay"`
+	// The time to invoke the handler, as a time or RFC 3339 string. Takes
+	// precedence over delay.
+	RunAt *expr.ValueExpr `json:"runAt,omitempty" yaml:"runAt,omitempty" msgpack:"runAt,omitempty" mapstructure:"runAt"`
+	// Deduplicates invocations while one with the same key is pending. Defaults to
+	// a random UUID.
+	Key *expr.ValueExpr `json:"key,omitempty" yaml:"key,omitempty" msgpack:"key,omitempty" mapstructure:"key"`
+	// The ID of the entity that handles the invocation when the handler is an
+	// actor. Invocations are not routed to an entity by default.
+	EntityID *expr.ValueExpr `json:"entityId,omitempty" yaml:"entityId,omitempty" msgpack:"entityId,omitempty" mapstructure:"entityId"`
+	// The prefix of the redis keys of delayed invocations, used as a hash tag so
+	// that they share a Redis Cluster slot. Defaults to nanobus:delayed:.
+	KeyPrefix *string `json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty" msgpack:"keyPrefix,omitempty" mapstructure:"keyPrefix"`
+}
+
+func Schedule() (string, actions.Loader) {
+	return "schedule", ScheduleLoader
+}
+
+// Runs steps within a database transaction on a Postgres or SQL resource. The
+// `@postgres/*` and `@sql/*` actions inside the block use the transaction, which
+// is committed when the steps succeed and rolled back when a step fails or stops
+// the pipeline. A block nested inside another block on the same resource uses a
+// savepoint. Nesting fails on databases without savepoints, such as Snowflake.
+type TransactionConfig struct {
+	// The Postgres or SQL resource to start the transaction on.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The isolation level (read_uncommitted, read_committed, repeatable_read or
+	// serializable). Defaults to the database default. Ignored for nested blocks.
+	Isolation *string `json:"isolation,omitempty" yaml:"isolation,omitempty" msgpack:"isolation,omitempty" mapstructure:"isolation" validate:"omitempty,oneof=read_uncommitted read_committed repeatable_read serializable"`
+	// Starts a read-only transaction. Ignored for nested blocks.
+	ReadOnly bool `json:"readOnly" yaml:"readOnly" msgpack:"readOnly" mapstructure:"readOnly"`
+	// The steps to run within the transaction.
+	Steps []runtime.Step `json:"steps" yaml:"steps" msgpack:"steps" mapstructure:"steps" validate:"dive"`
+}
+
+func Transaction() (string, actions.Loader) {
+	return "transaction", TransactionLoader
+}
+
+// Removes a reminder registered by `register_reminder`. Returns `removed`
+// indicating whether the reminder existed.
+type UnregisterReminderConfig struct {
+	// The name of the reminder.
+	Name string `json:"name" yaml:"name" msgpack:"name" mapstructure:"name" validate:"required"`
+	// The stateful service of the entity. Defaults to the entity processing the
+	// call.
+	Service *string `json:"service,omitempty" yaml:"service,omitempty" msgpack:"service,omitempty" mapstructure:"service"`
+	// The ID of the entity. Defaults to the entity processing the call.
+	ID *expr.ValueExpr `json:"id,omitempty" yaml:"id,omitempty" msgpack:"id,omitempty" mapstructure:"id"`
+}
+
+func UnregisterReminder() (string, actions.Loader) {
+	return "unregister_reminder", UnregisterReminderLoader
+}
+
+// Stops a timer registered by `register_timer` on the entity processing the
+// call. Returns `removed` indicating whether the timer existed.
+type UnregisterTimerConfig struct {
+	// The name of the timer.
+	Name string `json:"name" yaml:"name" msgpack:"name" mapstructure:"name" validate:"required"`
+}
+
+func UnregisterTimer() (string, actions.Loader) {
+	return "unregister_timer", UnregisterTimerLoader
+}
@@ -23,6 +23,7 @@ var All = []actions.NamedLoader{
 	Authorize,
 	CallInterface,
 	CallProvider,
+	CancelSchedule,
 	Decode,
 	Expr,
 	Filter,
@@ -33,7 +34,13 @@ var All = []actions.NamedLoader{
 	JQ,
 	Log,
 	ReCaptcha,
+	RegisterReminder,
+	RegisterTimer,
 	Route,
+	Schedule,
+	Transaction,
+	UnregisterReminder,
+	UnregisterTimer,
 }
 
 type Processor interface {
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/delayed"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func ScheduleLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c ScheduleConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	store, err := delayed.NewStore(ctx, resources, c.Resource, c.KeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return ScheduleAction(store, &c), nil
+}
+
+func ScheduleAction(
+	store delayed.Store,
+	config *ScheduleConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		input := data["input"]
+		if config.Input != nil {
+			var err error
+			input, err = config.Input.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		runAt := time.Now()
+		if config.Delay != nil {
+			runAt = runAt.Add(*config.Delay)
+		}
+		if config.RunAt != nil {
+			value, err := config.RunAt.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			if runAt, err = toTime(value); err != nil {
+				return nil, err
+			}
+		}
+
+		key := uuid.New().String()
+		if config.Key != nil {
+			value, err := config.Key.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			key = fmt.Sprintf("%v", value)
+		}
+
+		var entityID string
+		if config.EntityID != nil {
+			value, err := config.EntityID.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			entityID = fmt.Sprintf("%v", value)
+		}
+
+		scheduled, err := store.Schedule(ctx, delayed.Invocation{
+			ID:       key,
+			EntityID: entityID,
+			Handler:  config.Handler,
+			Input:    input,
+			RunAt:    runAt.UTC(),
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"key":       key,
+			"runAt":     runAt.UTC(),
+			"scheduled": scheduled,
+		}, nil
+	}
+}
+
+func CancelScheduleLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c CancelScheduleConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	store, err := delayed.NewStore(ctx, resources, c.Resource, c.KeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return CancelScheduleAction(store, &c), nil
+}
+
+func CancelScheduleAction(
+	store delayed.Store,
+	config *CancelScheduleConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		value, err := config.Key.Eval(data)
+		if err != nil {
+			return nil, err
+		}
+
+		cancelled, err := store.Cancel(ctx, fmt.Sprintf("%v", value))
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"cancelled": cancelled,
+		}, nil
+	}
+}
+
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid runAt %q: %w", v, err)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid runAt of type %T", value)
+}
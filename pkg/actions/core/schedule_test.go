@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/core"
+	"github.com/nanobus/nanobus/pkg/delayed"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func TestSchedule(t *testing.T) {
+	ctx := context.Background()
+	store := delayed.NewMemoryStore()
+	resolver := scheduleResolver(store)
+
+	name, loader := core.Schedule()
+	assert.Equal(t, "schedule", name)
+	schedule, err := loader(ctx, map[string]interface{}{
+		"resource": "delayed",
+		"handler":  "orders.v1.Orders::expire",
+		"input":    `{"id": input.id}`,
+		"delay":    "30m",
+		"key":      `"expire-" + input.id`,
+		"entityId": `input.id`,
+	}, resolver)
+	require.NoError(t, err)
+
+	data := actions.Data{
+		"input": map[string]interface{}{
+			"id": "1234",
+		},
+	}
+	before := time.Now()
+	result, err := schedule(ctx, data)
+	require.NoError(t, err)
+	output := result.(map[string]interface{})
+	assert.Equal(t, "expire-1234", output["key"])
+	assert.Equal(t, true, output["scheduled"])
+	runAt := output["runAt"].(time.Time)
+	assert.WithinDuration(t, before.Add(30*time.Minute), runAt, time.Second)
+
+	result, err = schedule(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["scheduled"])
+
+	due, err := store.Due(ctx, runAt, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, handler.Handler{Interface: "orders.v1.Orders", Operation: "expire"}, due[0].Handler)
+	assert.Equal(t, map[string]interface{}{"id": "1234"}, due[0].Input)
+	assert.Equal(t, "1234", due[0].EntityID)
+
+	_, loader = core.CancelSchedule()
+	cancel, err := loader(ctx, map[string]interface{}{
+		"resource": "delayed",
+		"key":      `"expire-" + input.id`,
+	}, resolver)
+	require.NoError(t, err)
+
+	result, err = cancel(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cancelled": true}, result)
+	result, err = cancel(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cancelled": false}, result)
+}
+
+func TestScheduleRunAt(t *testing.T) {
+	ctx := context.Background()
+	_, loader := core.Schedule()
+	action, err := loader(ctx, map[string]interface{}{
+		"resource": "delayed",
+		"handler":  "reminders::send",
+		"runAt":    `input.at`,
+	}, scheduleResolver(delayed.NewMemoryStore()))
+	require.NoError(t, err)
+
+	result, err := action(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"at": "2030-01-02T15:04:05Z",
+		},
+	})
+	require.NoError(t, err)
+	output := result.(map[string]interface{})
+	assert.Equal(t, time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC), output["runAt"])
+	assert.NotEmpty(t, output["key"])
+
+	_, err = action(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"at": "tomorrow",
+		},
+	})
+	assert.ErrorContains(t, err, "invalid runAt")
+}
+
+func scheduleResolver(store delayed.Store) resolve.ResolveAs {
+	resources := resource.Resources{
+		"delayed": store,
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		}
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/runtime"
+	"github.com/nanobus/nanobus/pkg/transaction"
+)
+
+func TransactionLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c TransactionConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var processor Processor
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"system:processor", &processor,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	res, ok := resources[string(c.Resource)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", c.Resource)
+	}
+
+	steps, err := processor.LoadPipeline(&runtime.Pipeline{
+		Name:  "transaction",
+		Steps: c.Steps,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return TransactionAction(&c, res, steps), nil
+}
+
+func TransactionAction(
+	config *TransactionConfig,
+	res interface{},
+	steps runtime.Runnable) actions.Action {
+	opts := transaction.Options{
+		ReadOnly: config.ReadOnly,
+	}
+	if config.Isolation != nil {
+		opts.Isolation = *config.Isolation
+	}
+
+	return func(ctx context.Context, data actions.Data) (output interface{}, err error) {
+		txCtx, tx, err := transaction.Begin(ctx, res, opts)
+		if err != nil {
+			if errors.Is(err, transaction.ErrNotSupported) {
+				return nil, fmt.Errorf("resource %q does not support transactions", config.Resource)
+			}
+			return nil, err
+		}
+
+		done := false
+		defer func() {
+			if !done {
+				// A step panicked.
+				_ = tx.Rollback(ctx)
+			}
+		}()
+
+		txCtx, stopped := actions.WithStopObserver(txCtx)
+		output, err = steps(txCtx, data)
+		done = true
+		if err != nil || stopped() {
+			if rerr := tx.Rollback(ctx); rerr != nil && err == nil {
+				err = rerr
+			}
+			if err == nil {
+				// Stop the enclosing pipeline as well.
+				err = actions.ErrStop
+			}
+			return nil, err
+		}
+
+		if err = tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+
+		return output, nil
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/core"
+	"github.com/nanobus/nanobus/pkg/transaction"
+)
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db := sqlx.NewDb(sqlDB, "sqlmock")
+
+	// insert runs a statement in the transaction opened by the block.
+	insert := func(ctx context.Context, data actions.Data) (interface{}, error) {
+		tx, ok := transaction.SQL(ctx, db)
+		require.True(t, ok)
+		_, err := tx.ExecContext(ctx, "INSERT INTO orders")
+		return "inserted", err
+	}
+
+	tests := []struct {
+		name   string
+		steps  func(ctx context.Context, data actions.Data) (interface{}, error)
+		expect func()
+		output interface{}
+		err    error
+	}{
+		{
+			name:  "commit",
+			steps: insert,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			output: "inserted",
+		},
+		{
+			name:  "rollback on error",
+			steps: insert,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO orders").WillReturnError(errors.New("duplicate key"))
+				mock.ExpectRollback()
+			},
+			err: errors.New("duplicate key"),
+		},
+		{
+			name: "rollback on stop",
+			steps: func(ctx context.Context, data actions.Data) (interface{}, error) {
+				if _, err := insert(ctx, data); err != nil {
+					return nil, err
+				}
+				// The pipeline handles ErrStop and reports it to the block.
+				_, onStop := actions.ObserveStop(ctx)
+				onStop()
+				return nil, nil
+			},
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectRollback()
+			},
+			err: actions.ErrStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+			action := core.TransactionAction(&core.TransactionConfig{
+				Resource: "db",
+			}, db, tt.steps)
+
+			output, err := action(ctx, actions.Data{})
+			if tt.err != nil {
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.output, output)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionNotSupported(t *testing.T) {
+	action := core.TransactionAction(&core.TransactionConfig{
+		Resource: "cache",
+	}, struct{}{}, nil)
+
+	_, err := action(context.Background(), actions.Data{})
+	assert.EqualError(t, err, `resource "cache" does not support transactions`)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"sync"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func GetConfigurationLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := GetConfigurationConfig{
+		Resource:  "dapr",
+		Subscribe: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetConfigurationAction(ctx, client, &c), nil
+}
+
+// GetConfigurationAction returns the configuration items. When subscribing,
+// the subscription is bound to ctx, which is the lifetime of the action.
+func GetConfigurationAction(
+	ctx context.Context,
+	client dapr.Client,
+	config *GetConfigurationConfig) actions.Action {
+	opts := make([]dapr.ConfigurationOpt, 0, len(config.Metadata))
+	for k, v := range config.Metadata {
+		opts = append(opts, dapr.WithConfigurationMetadata(k, v))
+	}
+
+	if !config.Subscribe {
+		return func(callCtx context.Context, data actions.Data) (interface{}, error) {
+			items, err := client.GetConfigurationItems(callCtx, config.Store, config.Keys, opts...)
+			if err != nil {
+				return nil, resiliency.Retriable(err)
+			}
+
+			return configurationResponse(items), nil
+		}
+	}
+
+	cache := configurationCache{
+		ctx:    ctx,
+		client: client,
+		config: config,
+		opts:   opts,
+	}
+
+	return func(callCtx context.Context, data actions.Data) (interface{}, error) {
+		items, err := cache.get(callCtx)
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return configurationResponse(items), nil
+	}
+}
+
+// configurationCache holds the items of a configuration store that are kept
+// up to date by a subscription. The cache is loaded on first use and reloaded
+// after the subscription ends.
+type configurationCache struct {
+	ctx    context.Context
+	client dapr.Client
+	config *GetConfigurationConfig
+	opts   []dapr.ConfigurationOpt
+
+	mu         sync.Mutex
+	items      map[string]*dapr.ConfigurationItem
+	loaded     bool
+	subscribed bool
+}
+
+func (c *configurationCache) get(ctx context.Context) (map[string]*dapr.ConfigurationItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded {
+		return c.items, nil
+	}
+
+	// Subscribe before loading so that no changes are missed.
+	if !c.subscribed {
+		c.subscribed = true
+		c.items = make(map[string]*dapr.ConfigurationItem)
+		go c.subscribe()
+	}
+
+	items, err := c.client.GetConfigurationItems(ctx, c.config.Store, c.config.Keys, c.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]*dapr.ConfigurationItem, len(items))
+	for k, v := range items {
+		merged[k] = v
+	}
+	// Changes received while loading are newer.
+	for k, v := range c.items {
+		merged[k] = v
+	}
+	c.items = merged
+	c.loaded = true
+
+	return c.items, nil
+}
+
+func (c *configurationCache) subscribe() {
+	_ = c.client.SubscribeConfigurationItems(c.ctx, c.config.Store, c.config.Keys, c.update, c.opts...)
+
+	// Without a subscription, the cache could become stale so the items are
+	// reloaded by the next call.
+	c.mu.Lock()
+	c.items = nil
+	c.loaded = false
+	c.subscribed = false
+	c.mu.Unlock()
+}
+
+func (c *configurationCache) update(id string, items map[string]*dapr.ConfigurationItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Copy on write since callers may be reading the returned items.
+	updated := make(map[string]*dapr.ConfigurationItem, len(c.items)+len(items))
+	for k, v := range c.items {
+		updated[k] = v
+	}
+	for k, v := range items {
+		updated[k] = v
+	}
+	c.items = updated
+}
+
+func configurationResponse(items map[string]*dapr.ConfigurationItem) map[string]interface{} {
+	response := make(map[string]interface{}, len(items))
+	for k, item := range items {
+		metadata := make(map[string]interface{}, len(item.Metadata))
+		for mk, mv := range item.Metadata {
+			metadata[mk] = mv
+		}
+		response[k] = map[string]interface{}{
+			"value":    item.Value,
+			"version":  item.Version,
+			"metadata": metadata,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonv1 "github.com/dapr/go-sdk/dapr/proto/common/v1"
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestConfigurationGet(t *testing.T) {
+	ctx := context.Background()
+	s := fakeSidecar{
+		configurationResp: map[string]*commonv1.ConfigurationItem{
+			"feature": {Value: "off", Version: "1"},
+		},
+	}
+	resolver := startSidecar(t, &s)
+
+	action, err := dapr.GetConfigurationLoader(ctx, map[string]any{
+		"store":     "config",
+		"keys":      []any{"feature"},
+		"subscribe": false,
+	}, resolver)
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		output, err := action(ctx, actions.Data{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"feature": map[string]any{
+				"value":    "off",
+				"version":  "1",
+				"metadata": map[string]any{},
+			},
+		}, output)
+		assert.Equal(t, i, s.configurationGets)
+	}
+	assert.Equal(t, "config", s.configurationReq.StoreName)
+	assert.Equal(t, []string{"feature"}, s.configurationReq.Keys)
+}
+
+func TestConfigurationGetSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := fakeSidecar{
+		configurationResp: map[string]*commonv1.ConfigurationItem{
+			"feature": {Value: "off", Version: "1"},
+		},
+		subscriptions: make(chan pb.Dapr_SubscribeConfigurationAlpha1Server, 1),
+	}
+	resolver := startSidecar(t, &s)
+
+	action, err := dapr.GetConfigurationLoader(ctx, map[string]any{
+		"store": "config",
+		"keys":  []any{"feature"},
+	}, resolver)
+	require.NoError(t, err)
+
+	feature := func() any {
+		output, err := action(ctx, actions.Data{})
+		require.NoError(t, err)
+		return output.(map[string]any)["feature"].(map[string]any)["value"]
+	}
+
+	assert.Equal(t, "off", feature())
+	var stream pb.Dapr_SubscribeConfigurationAlpha1Server
+	select {
+	case stream = <-s.subscriptions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not subscribed")
+	}
+
+	// Changes are applied to the cache without loading the items again.
+	require.NoError(t, stream.Send(&pb.SubscribeConfigurationResponse{
+		Id: "subscription",
+		Items: map[string]*commonv1.ConfigurationItem{
+			"feature": {Value: "on", Version: "2"},
+		},
+	}))
+	require.Eventually(t, func() bool {
+		return feature() == "on"
+	}, 5*time.Second, 5*time.Millisecond)
+
+	s.mu.Lock()
+	assert.Equal(t, 1, s.configurationGets)
+	s.mu.Unlock()
+}
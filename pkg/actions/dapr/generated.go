@@ -5,6 +5,7 @@ package dapr
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/nanobus/nanobus/pkg/actions"
 	"github.com/nanobus/nanobus/pkg/expr"
@@ -162,6 +163,211 @@ func InvokeActor() (string, actions.Loader) {
 	return "@dapr/invoke_actor", InvokeActorLoader
 }
 
+// Gets a secret from a Dapr secret store. Returns the secret's values by name
+// since some stores hold multiple values per secret.
+type GetSecretConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of the secret store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The key of the secret to get.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Optional data expression for the request metadata.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+	// The error to return if the secret is not found.
+	NotFoundError string `json:"notFoundError" yaml:"notFoundError" msgpack:"notFoundError" mapstructure:"notFoundError" validate:"required"`
+}
+
+func GetSecret() (string, actions.Loader) {
+	return "@dapr/get_secret", GetSecretLoader
+}
+
+// Gets items from a Dapr configuration store. Returns the `value`, `version` and
+// `metadata` of each item by key. When `subscribe` is enabled, the items are
+// cached and kept up to date by subscribing to changes so that calls do not
+// reach the store.
+type GetConfigurationConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of the configuration store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The keys of the items to get. Gets all items when not set.
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty" msgpack:"keys,omitempty" mapstructure:"keys" validate:"dive"`
+	// The request metadata.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata" validate:"dive"`
+	// Caches the items and subscribes to their changes.
+	Subscribe bool `json:"subscribe" yaml:"subscribe" msgpack:"subscribe" mapstructure:"subscribe"`
+}
+
+func GetConfiguration() (string, actions.Loader) {
+	return "@dapr/get_configuration", GetConfigurationLoader
+}
+
+// Tries to acquire a lock from a Dapr lock store. Returns `success` indicating
+// whether the lock was acquired.
+type TryLockConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of the lock store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The resource to lock.
+	ResourceID *expr.ValueExpr `json:"resourceId" yaml:"resourceId" msgpack:"resourceId" mapstructure:"resourceId" validate:"required"`
+	// The owner of the lock. Only the owner can unlock it.
+	Owner *expr.ValueExpr `json:"owner" yaml:"owner" msgpack:"owner" mapstructure:"owner" validate:"required"`
+	// How long the lock is held before it expires. Rounded up to seconds.
+	Expiry time.Duration `json:"expiry" yaml:"expiry" msgpack:"expiry" mapstructure:"expiry" validate:"required"`
+	// The error to return if the lock is held by another owner. Returns `success`
+	// false when not set.
+	LockedError *string `json:"lockedError,omitempty" yaml:"lockedError,omitempty" msgpack:"lockedError,omitempty" mapstructure:"lockedError"`
+}
+
+func TryLock() (string, actions.Loader) {
+	return "@dapr/try_lock", TryLockLoader
+}
+
+// Releases a lock acquired by `@dapr/try_lock`. Returns the `status` of the
+// unlock (`SUCCESS`, `LOCK_DOES_NOT_EXIST`, `LOCK_BELONGS_TO_OTHERS` or
+// `INTERNAL_ERROR`).
+type UnlockConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of the lock store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The locked resource.
+	ResourceID *expr.ValueExpr `json:"resourceId" yaml:"resourceId" msgpack:"resourceId" mapstructure:"resourceId" validate:"required"`
+	// The owner of the lock.
+	Owner *expr.ValueExpr `json:"owner" yaml:"owner" msgpack:"owner" mapstructure:"owner" validate:"required"`
+}
+
+func Unlock() (string, actions.Loader) {
+	return "@dapr/unlock", UnlockLoader
+}
+
+// Publishes a list of events to a topic in a single request. Fails with the IDs
+// of the entries that could not be published, which are the indexes of the
+// events in the list.
+type BulkPublishConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of pubsub to invoke.
+	Pubsub string `json:"pubsub" yaml:"pubsub" msgpack:"pubsub" mapstructure:"pubsub" validate:"required"`
+	// Topic is the name of the topic to publish to.
+	Topic string `json:"topic" yaml:"topic" msgpack:"topic" mapstructure:"topic" validate:"required"`
+	// The configured codec to use for encoding the events.
+	Codec CodecRef `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec"`
+	// The arguments for the codec, if any.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+	// The list of events to publish. Defaults to the pipeline input.
+	Items *expr.ValueExpr `json:"items,omitempty" yaml:"items,omitempty" msgpack:"items,omitempty" mapstructure:"items"`
+	// Optional data expression to transform each event, available as `item`.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// Optional value to use for each event's key, if supported.
+	Key *expr.ValueExpr `json:"key,omitempty" yaml:"key,omitempty" msgpack:"key,omitempty" mapstructure:"key"`
+	// The request metadata.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+	// Enables/disables propogating the distributed tracing context (e.g. W3C
+	// TraceContext standard).
+	PropogateTracing bool `json:"propogateTracing" yaml:"propogateTracing" msgpack:"propogateTracing" mapstructure:"propogateTracing"`
+}
+
+func BulkPublish() (string, actions.Loader) {
+	return "@dapr/bulk_publish", BulkPublishLoader
+}
+
+// Queries a Dapr state store that supports the query API. Returns the decoded
+// `results` with their `key`, `data` and `etag` and the `token` of the next page.
+type QueryStateConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of state store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The filter of the query (e.g. `{"EQ": {"state": input.state}}`).
+	Filter *expr.DataExpr `json:"filter,omitempty" yaml:"filter,omitempty" msgpack:"filter,omitempty" mapstructure:"filter"`
+	// The sort order of the results as a list of `{"key": ..., "order": "ASC" |
+	// "DESC"}` objects.
+	Sort *expr.DataExpr `json:"sort,omitempty" yaml:"sort,omitempty" msgpack:"sort,omitempty" mapstructure:"sort"`
+	// The maximum number of results to return.
+	Limit *expr.ValueExpr `json:"limit,omitempty" yaml:"limit,omitempty" msgpack:"limit,omitempty" mapstructure:"limit"`
+	// The token of the page to return from a previous query.
+	Token *expr.ValueExpr `json:"token,omitempty" yaml:"token,omitempty" msgpack:"token,omitempty" mapstructure:"token"`
+	// The configured codec to use for decoding the state.
+	Codec CodecRef `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec"`
+	// The arguments for the codec, if any.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+	// Optional data expression for the request metadata.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+}
+
+func QueryState() (string, actions.Loader) {
+	return "@dapr/query_state", QueryStateLoader
+}
+
+// Applies upserts and deletes to multiple keys of a Dapr state store in a single
+// transaction.
+type TransactStateConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Name of state store to invoke.
+	Store string `json:"store" yaml:"store" msgpack:"store" mapstructure:"store" validate:"required"`
+	// The configured codec to use for encoding the state.
+	Codec CodecRef `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec"`
+	// The arguments for the codec, if any.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+	// The operations to apply.
+	Operations []TransactStateOperation `json:"operations" yaml:"operations" msgpack:"operations" mapstructure:"operations" validate:"dive"`
+	// Optional data expression for the transaction metadata.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+}
+
+func TransactState() (string, actions.Loader) {
+	return "@dapr/transact_state", TransactStateLoader
+}
+
+// An operation of a state transaction.
+type TransactStateOperation struct {
+	// The type of operation.
+	Type StateOperationType `json:"type" yaml:"type" msgpack:"type" mapstructure:"type"`
+	// The key of the item.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// An optional expression returning a list to create an operation for each
+	// element, available as `item`.
+	ForEach *expr.ValueExpr `json:"forEach,omitempty" yaml:"forEach,omitempty" msgpack:"forEach,omitempty" mapstructure:"forEach"`
+	// Optional data expression to tranform the data to set.
+	Value *expr.DataExpr `json:"value,omitempty" yaml:"value,omitempty" msgpack:"value,omitempty" mapstructure:"value"`
+	// Etag value of the item
+	Etag *expr.ValueExpr `json:"etag,omitempty" yaml:"etag,omitempty" msgpack:"etag,omitempty" mapstructure:"etag"`
+	// Optional data expression for the key's metadata.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+	// The desired concurrency level
+	Concurrency Concurrency `json:"concurrency" yaml:"concurrency" msgpack:"concurrency" mapstructure:"concurrency"`
+	// The desired consistency level
+	Consistency Consistency `json:"consistency" yaml:"consistency" msgpack:"consistency" mapstructure:"consistency"`
+}
+
+// Invokes a method of another Dapr application through service invocation and
+// returns the decoded response.
+type InvokeServiceConfig struct {
+	// The name of the Dapr client resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The ID of the application to invoke.
+	AppID string `json:"appId" yaml:"appId" msgpack:"appId" mapstructure:"appId" validate:"required"`
+	// The method to invoke, which may include a query string.
+	Method *expr.ValueExpr `json:"method" yaml:"method" msgpack:"method" mapstructure:"method" validate:"required"`
+	// The HTTP verb of the call.
+	Verb string `json:"verb" yaml:"verb" msgpack:"verb" mapstructure:"verb" validate:"required"`
+	// The input sent. Defaults to the pipeline input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// The configured codec to use for encoding the input and decoding the
+	// response.
+	Codec CodecRef `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec"`
+	// The arguments for the codec, if any.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func InvokeService() (string, actions.Loader) {
+	return "@dapr/invoke_service", InvokeServiceLoader
+}
+
 // TODO
 type Concurrency int32
 
@@ -273,3 +479,55 @@ func (e *Consistency) UnmarshalJSON(b []byte) error {
 	}
 	return e.FromString(str)
 }
+
+// The type of a state transaction operation.
+type StateOperationType int32
+
+const (
+	// Sets the item
+	StateOperationTypeUpsert StateOperationType = 1
+	// Deletes the item
+	StateOperationTypeDelete StateOperationType = 2
+)
+
+var toStringStateOperationType = map[StateOperationType]string{
+	StateOperationTypeUpsert: "upsert",
+	StateOperationTypeDelete: "delete",
+}
+
+var toIDStateOperationType = map[string]StateOperationType{
+	"upsert": StateOperationTypeUpsert,
+	"delete": StateOperationTypeDelete,
+}
+
+func (e StateOperationType) String() string {
+	str, ok := toStringStateOperationType[e]
+	if !ok {
+		return "unknown"
+	}
+	return str
+}
+
+func (e *StateOperationType) FromString(str string) error {
+	var ok bool
+	*e, ok = toIDStateOperationType[str]
+	if !ok {
+		return errors.New("unknown value \"" + str + "\" for StateOperationType")
+	}
+	return nil
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (e StateOperationType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON unmashals a quoted json string to the enum value
+func (e *StateOperationType) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	return e.FromString(str)
+}
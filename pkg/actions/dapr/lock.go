@@ -0,0 +1,136 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func TryLockLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := TryLockConfig{
+		Resource: "dapr",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return TryLockAction(client, &c), nil
+}
+
+func TryLockAction(
+	client dapr.Client,
+	config *TryLockConfig) actions.Action {
+	// Dapr expires locks in seconds.
+	expiry := int32((config.Expiry + time.Second - 1) / time.Second)
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		resourceID, owner, err := lockTarget(data, config.ResourceID, config.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.TryLockAlpha1(ctx, config.Store, &dapr.LockRequest{
+			ResourceID:      resourceID,
+			LockOwner:       owner,
+			ExpiryInSeconds: expiry,
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		if !resp.Success && config.LockedError != nil {
+			return nil, errorz.Return(*config.LockedError, errorz.Metadata{
+				"resource":   config.Resource,
+				"resourceId": resourceID,
+			})
+		}
+
+		return map[string]interface{}{
+			"success": resp.Success,
+		}, nil
+	}
+}
+
+func UnlockLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := UnlockConfig{
+		Resource: "dapr",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnlockAction(client, &c), nil
+}
+
+func UnlockAction(
+	client dapr.Client,
+	config *UnlockConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		resourceID, owner, err := lockTarget(data, config.ResourceID, config.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.UnlockAlpha1(ctx, config.Store, &dapr.UnlockRequest{
+			ResourceID: resourceID,
+			LockOwner:  owner,
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"status": resp.Status,
+		}, nil
+	}
+}
+
+func lockTarget(data actions.Data, resourceIDExpr, ownerExpr *expr.ValueExpr) (resourceID, owner string, err error) {
+	if resourceID, err = expr.EvalAsStringE(resourceIDExpr, data); err != nil {
+		return "", "", fmt.Errorf("could not evaluate resourceId: %w", err)
+	}
+	if owner, err = expr.EvalAsStringE(ownerExpr, data); err != nil {
+		return "", "", fmt.Errorf("could not evaluate owner: %w", err)
+	}
+
+	return resourceID, owner, nil
+}
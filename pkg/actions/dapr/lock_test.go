@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestTryLock(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   map[string]any
+		lockResp bool
+		output   any
+		err      string
+	}{
+		{
+			name: "acquired",
+			config: map[string]any{
+				"store":      "locks",
+				"resourceId": `"order-" + input.id`,
+				"owner":      `input.owner`,
+				"expiry":     "1500ms",
+			},
+			lockResp: true,
+			output:   map[string]any{"success": true},
+		},
+		{
+			name: "held by another owner",
+			config: map[string]any{
+				"store":      "locks",
+				"resourceId": `"order-" + input.id`,
+				"owner":      `input.owner`,
+				"expiry":     "1500ms",
+			},
+			output: map[string]any{"success": false},
+		},
+		{
+			name: "locked error",
+			config: map[string]any{
+				"store":       "locks",
+				"resourceId":  `"order-" + input.id`,
+				"owner":       `input.owner`,
+				"expiry":      "1500ms",
+				"lockedError": "order_locked",
+			},
+			err: "order_locked\n[resource] dapr\n[resourceId] order-1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSidecar{lockResp: tt.lockResp}
+			action, err := dapr.TryLockLoader(ctx, tt.config, startSidecar(t, &s))
+			require.NoError(t, err)
+			output, err := action(ctx, actions.Data{
+				"input": map[string]any{"id": "1234", "owner": "worker-1"},
+			})
+
+			require.NotNil(t, s.lockReq)
+			assert.Equal(t, "locks", s.lockReq.StoreName)
+			assert.Equal(t, "order-1234", s.lockReq.ResourceId)
+			assert.Equal(t, "worker-1", s.lockReq.LockOwner)
+			assert.Equal(t, int32(2), s.lockReq.ExpiryInSeconds)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.output, output)
+			}
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	ctx := context.Background()
+	s := fakeSidecar{unlockResp: pb.UnlockResponse_LOCK_BELONGS_TO_OTHERS}
+	action, err := dapr.UnlockLoader(ctx, map[string]any{
+		"store":      "locks",
+		"resourceId": `"order-" + input.id`,
+		"owner":      `input.owner`,
+	}, startSidecar(t, &s))
+	require.NoError(t, err)
+
+	output, err := action(ctx, actions.Data{
+		"input": map[string]any{"id": "1234", "owner": "worker-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"status": "LOCK_BELONGS_TO_OTHERS"}, output)
+	assert.Equal(t, "locks", s.unlockReq.StoreName)
+	assert.Equal(t, "order-1234", s.unlockReq.ResourceId)
+	assert.Equal(t, "worker-1", s.unlockReq.LockOwner)
+}
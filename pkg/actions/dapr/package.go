@@ -20,4 +20,12 @@ var All = []actions.NamedLoader{
 	SetState,
 	InvokeActor,
 	InvokeBinding,
+	GetSecret,
+	GetConfiguration,
+	TryLock,
+	Unlock,
+	BulkPublish,
+	QueryState,
+	TransactState,
+	InvokeService,
 }
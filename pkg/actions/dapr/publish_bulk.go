@@ -0,0 +1,172 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dapr "github.com/dapr/go-sdk/client"
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"go.opentelemetry.io/otel"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/telemetry/tracing"
+)
+
+func BulkPublishLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := BulkPublishConfig{
+		Resource:         "dapr",
+		Codec:            "json",
+		PropogateTracing: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return BulkPublishAction(client, &c, codec), nil
+}
+
+func BulkPublishAction(
+	client dapr.Client,
+	config *BulkPublishConfig,
+	codec codec.Codec) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var itemsInt interface{} = data["input"]
+		if config.Items != nil {
+			if itemsInt, err = config.Items.Eval(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate items: %w", err)
+			}
+		}
+		items, ok := itemsInt.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("items must be a list of events, got %T", itemsInt)
+		}
+
+		var metadata map[string]string
+		if config.Metadata != nil {
+			if metadata, err = config.Metadata.EvalMap(data); err != nil {
+				return nil, err
+			}
+		}
+		if metadata == nil {
+			metadata = make(map[string]string, 1)
+		}
+		metadata["rawPayload"] = "true"
+
+		entries := make([]*pb.BulkPublishRequestEntry, len(items))
+		for i, item := range items {
+			if entries[i], err = createBulkPublishEntry(ctx, data, i, item, config, codec); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := client.GrpcClient().BulkPublishEventAlpha1(ctx, &pb.BulkPublishRequest{
+			PubsubName: config.Pubsub,
+			Topic:      config.Topic,
+			Entries:    entries,
+			Metadata:   metadata,
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		if len(resp.FailedEntries) > 0 {
+			failed := make([]string, len(resp.FailedEntries))
+			for i, entry := range resp.FailedEntries {
+				failed[i] = entry.EntryId
+			}
+			return nil, resiliency.Retriable(fmt.Errorf("could not publish entries %s: %s",
+				strings.Join(failed, ", "), resp.FailedEntries[0].Error))
+		}
+
+		return nil, nil
+	}
+}
+
+func createBulkPublishEntry(
+	ctx context.Context,
+	data actions.Data,
+	index int,
+	item interface{},
+	config *BulkPublishConfig,
+	codec codec.Codec) (*pb.BulkPublishRequestEntry, error) {
+	variables := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		variables[k] = v
+	}
+	variables["item"] = item
+
+	var err error
+	event := item
+	if config.Data != nil {
+		if event, err = config.Data.Eval(variables); err != nil {
+			return nil, fmt.Errorf("could not evaluate data: %w", err)
+		}
+	}
+
+	var metadata map[string]string
+	if config.Key != nil {
+		key, err := expr.EvalAsStringE(config.Key, variables)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+		metadata = map[string]string{
+			"partitionKey": key,
+		}
+	}
+
+	// Propogate distributed tracing fields
+	// per the the W3C TraceContext standard.
+	if config.PropogateTracing {
+		if m, ok := event.(map[string]interface{}); ok {
+			otel.GetTextMapPropagator().Inject(ctx, tracing.MapCarrier(m))
+		}
+	}
+
+	eventBytes, err := codec.Encode(event, config.CodecArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BulkPublishRequestEntry{
+		EntryId:     strconv.Itoa(index),
+		Event:       eventBytes,
+		ContentType: codec.ContentType(),
+		Metadata:    metadata,
+	}, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestBulkPublish(t *testing.T) {
+	ctx := context.Background()
+	config := map[string]any{
+		"pubsub":           "pubsub",
+		"topic":            "orders",
+		"items":            `input.orders`,
+		"data":             `{"id": item.id, "status": "created"}`,
+		"key":              `item.id`,
+		"propogateTracing": false,
+	}
+	data := actions.Data{
+		"input": map[string]any{
+			"orders": []any{
+				map[string]any{"id": "1"},
+				map[string]any{"id": "2"},
+			},
+		},
+	}
+
+	s := fakeSidecar{}
+	action, err := dapr.BulkPublishLoader(ctx, config, startSidecar(t, &s))
+	require.NoError(t, err)
+	_, err = action(ctx, data)
+	require.NoError(t, err)
+
+	req := s.bulkPublishReq
+	require.NotNil(t, req)
+	assert.Equal(t, "pubsub", req.PubsubName)
+	assert.Equal(t, "orders", req.Topic)
+	assert.Equal(t, "true", req.Metadata["rawPayload"])
+	require.Len(t, req.Entries, 2)
+	for i, entry := range req.Entries {
+		id := []string{"1", "2"}[i]
+		assert.Equal(t, strconv.Itoa(i), entry.EntryId)
+		assert.JSONEq(t, `{"id":"`+id+`","status":"created"}`, string(entry.Event))
+		assert.Equal(t, "application/json", entry.ContentType)
+		assert.Equal(t, map[string]string{"partitionKey": id}, entry.Metadata)
+	}
+
+	// Failed entries are reported by their index.
+	s = fakeSidecar{
+		bulkPublishFailed: []*pb.BulkPublishResponseFailedEntry{
+			{EntryId: "1", Error: "broker unavailable"},
+		},
+	}
+	action, err = dapr.BulkPublishLoader(ctx, config, startSidecar(t, &s))
+	require.NoError(t, err)
+	_, err = action(ctx, data)
+	assert.EqualError(t, err, "could not publish entries 1: broker unavailable")
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func GetSecretLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := GetSecretConfig{
+		Resource:      "dapr",
+		NotFoundError: "not_found",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSecretAction(client, &c), nil
+}
+
+func GetSecretAction(
+	client dapr.Client,
+	config *GetSecretConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		var metadata map[string]string
+		if config.Metadata != nil {
+			if metadata, err = config.Metadata.EvalMap(data); err != nil {
+				return nil, err
+			}
+		}
+
+		values, err := client.GetSecret(ctx, config.Store, key, metadata)
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		if len(values) == 0 && config.NotFoundError != "" {
+			return nil, errorz.Return(config.NotFoundError, errorz.Metadata{
+				"resource": config.Resource,
+				"key":      key,
+			})
+		}
+
+		response := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			response[k] = v
+		}
+
+		return response, nil
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestSecretGet(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		config     map[string]any
+		secretData map[string]string
+		output     any
+		err        string
+	}{
+		{
+			name: "found",
+			config: map[string]any{
+				"store":    "vault",
+				"key":      `input.name`,
+				"metadata": `{"version": "2"}`,
+			},
+			secretData: map[string]string{
+				"username": "admin",
+				"password": "secret",
+			},
+			output: map[string]any{
+				"username": "admin",
+				"password": "secret",
+			},
+		},
+		{
+			name: "not found",
+			config: map[string]any{
+				"store":         "vault",
+				"key":           `input.name`,
+				"notFoundError": "secret_not_found",
+			},
+			err: "secret_not_found\n[key] db\n[resource] dapr",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSidecar{secretData: tt.secretData}
+			action, err := dapr.GetSecretLoader(ctx, tt.config, startSidecar(t, &s))
+			require.NoError(t, err)
+			output, err := action(ctx, actions.Data{
+				"input": map[string]any{"name": "db"},
+			})
+
+			require.NotNil(t, s.secretReq)
+			assert.Equal(t, "vault", s.secretReq.StoreName)
+			assert.Equal(t, "db", s.secretReq.Key)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{"version": "2"}, s.secretReq.Metadata)
+				assert.Equal(t, tt.output, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func InvokeServiceLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := InvokeServiceConfig{
+		Resource: "dapr",
+		Verb:     "POST",
+		Codec:    "json",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+	if c.CodecArgs == nil {
+		c.CodecArgs = []any{}
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return InvokeServiceAction(client, codec, &c), nil
+}
+
+func InvokeServiceAction(
+	client dapr.Client,
+	codec codec.Codec,
+	config *InvokeServiceConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		method, err := expr.EvalAsStringE(config.Method, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate method: %w", err)
+		}
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			if input, err = config.Data.Eval(data); err != nil {
+				return nil, err
+			}
+		}
+
+		var content *dapr.DataContent
+		if input != nil {
+			dataBytes, err := codec.Encode(input, config.CodecArgs...)
+			if err != nil {
+				return nil, err
+			}
+			content = &dapr.DataContent{
+				Data:        dataBytes,
+				ContentType: codec.ContentType(),
+			}
+		}
+
+		var resp []byte
+		if content != nil {
+			resp, err = client.InvokeMethodWithContent(ctx, config.AppID, method, config.Verb, content)
+		} else {
+			resp, err = client.InvokeMethod(ctx, config.AppID, method, config.Verb)
+		}
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		var response interface{}
+		if len(resp) > 0 {
+			response, _, err = codec.Decode(resp, config.CodecArgs...)
+		}
+
+		return response, err
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestServiceInvoke(t *testing.T) {
+	ctx := context.Background()
+	s := fakeSidecar{
+		invokeResp: []byte(`{"total":42}`),
+	}
+	action, err := dapr.InvokeServiceLoader(ctx, map[string]any{
+		"appId":  "billing",
+		"method": `"invoices/" + input.id`,
+		"data":   `{"amount": input.amount}`,
+	}, startSidecar(t, &s))
+	require.NoError(t, err)
+
+	output, err := action(ctx, actions.Data{
+		"input": map[string]any{"id": "1234", "amount": 42},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"total": int64(42)}, output)
+
+	req := s.invokeReq
+	require.NotNil(t, req)
+	assert.Equal(t, "billing", req.Id)
+	assert.Equal(t, "invoices/1234", req.Message.Method)
+	assert.Equal(t, "application/json", req.Message.ContentType)
+	assert.Equal(t, "POST", req.Message.HttpExtension.Verb.String())
+	assert.JSONEq(t, `{"amount":42}`, string(req.Message.Data.Value))
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	dapr "github.com/dapr/go-sdk/client"
+	commonv1 "github.com/dapr/go-sdk/dapr/proto/common/v1"
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// fakeSidecar is an in-process Dapr sidecar that records the requests it
+// receives and answers with the configured responses.
+type fakeSidecar struct {
+	pb.UnimplementedDaprServer
+
+	mu sync.Mutex
+
+	// Secrets
+	secretReq  *pb.GetSecretRequest
+	secretData map[string]string
+
+	// Configuration
+	configurationGets int
+	configurationReq  *pb.GetConfigurationRequest
+	configurationResp map[string]*commonv1.ConfigurationItem
+	subscriptions     chan pb.Dapr_SubscribeConfigurationAlpha1Server
+
+	// Locks
+	lockReq    *pb.TryLockRequest
+	lockResp   bool
+	unlockReq  *pb.UnlockRequest
+	unlockResp pb.UnlockResponse_Status
+
+	// Bulk publish
+	bulkPublishReq    *pb.BulkPublishRequest
+	bulkPublishFailed []*pb.BulkPublishResponseFailedEntry
+
+	// Query state
+	queryReq  *pb.QueryStateRequest
+	queryResp *pb.QueryStateResponse
+
+	// Transactions
+	transactionReq *pb.ExecuteStateTransactionRequest
+
+	// Service invocation
+	invokeReq  *pb.InvokeServiceRequest
+	invokeResp []byte
+}
+
+func (s *fakeSidecar) GetSecret(ctx context.Context, req *pb.GetSecretRequest) (*pb.GetSecretResponse, error) {
+	s.secretReq = req
+	return &pb.GetSecretResponse{Data: s.secretData}, nil
+}
+
+func (s *fakeSidecar) GetConfigurationAlpha1(ctx context.Context, req *pb.GetConfigurationRequest) (*pb.GetConfigurationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configurationGets++
+	s.configurationReq = req
+	return &pb.GetConfigurationResponse{Items: s.configurationResp}, nil
+}
+
+func (s *fakeSidecar) SubscribeConfigurationAlpha1(req *pb.SubscribeConfigurationRequest, stream pb.Dapr_SubscribeConfigurationAlpha1Server) error {
+	if err := stream.Send(&pb.SubscribeConfigurationResponse{Id: "subscription"}); err != nil {
+		return err
+	}
+	s.subscriptions <- stream
+	<-stream.Context().Done()
+	return nil
+}
+
+func (s *fakeSidecar) UnsubscribeConfigurationAlpha1(ctx context.Context, req *pb.UnsubscribeConfigurationRequest) (*pb.UnsubscribeConfigurationResponse, error) {
+	return &pb.UnsubscribeConfigurationResponse{Ok: true}, nil
+}
+
+func (s *fakeSidecar) TryLockAlpha1(ctx context.Context, req *pb.TryLockRequest) (*pb.TryLockResponse, error) {
+	s.lockReq = req
+	return &pb.TryLockResponse{Success: s.lockResp}, nil
+}
+
+func (s *fakeSidecar) UnlockAlpha1(ctx context.Context, req *pb.UnlockRequest) (*pb.UnlockResponse, error) {
+	s.unlockReq = req
+	return &pb.UnlockResponse{Status: s.unlockResp}, nil
+}
+
+func (s *fakeSidecar) BulkPublishEventAlpha1(ctx context.Context, req *pb.BulkPublishRequest) (*pb.BulkPublishResponse, error) {
+	s.bulkPublishReq = req
+	return &pb.BulkPublishResponse{FailedEntries: s.bulkPublishFailed}, nil
+}
+
+func (s *fakeSidecar) QueryStateAlpha1(ctx context.Context, req *pb.QueryStateRequest) (*pb.QueryStateResponse, error) {
+	s.queryReq = req
+	return s.queryResp, nil
+}
+
+func (s *fakeSidecar) ExecuteStateTransaction(ctx context.Context, req *pb.ExecuteStateTransactionRequest) (*emptypb.Empty, error) {
+	s.transactionReq = req
+	return &emptypb.Empty{}, nil
+}
+
+func (s *fakeSidecar) InvokeService(ctx context.Context, req *pb.InvokeServiceRequest) (*commonv1.InvokeResponse, error) {
+	s.invokeReq = req
+	return &commonv1.InvokeResponse{
+		Data:        &anypb.Any{Value: s.invokeResp},
+		ContentType: "application/json",
+	}, nil
+}
+
+// startSidecar serves s over an in-memory connection and returns a resolver
+// for a Dapr client connected to it.
+func startSidecar(t *testing.T, s *fakeSidecar) resolve.ResolveAs {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterDaprServer(server, s)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	client := dapr.NewClientWithConnection(conn)
+	t.Cleanup(client.Close)
+
+	r := resource.Resources{
+		"dapr": client,
+	}
+	c := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(r, target)
+		case "codec:lookup":
+			return resolve.As(c, target)
+		}
+		return false
+	}
+}
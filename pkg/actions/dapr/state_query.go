@@ -0,0 +1,162 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/coalesce"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func QueryStateLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := QueryStateConfig{
+		Resource: "dapr",
+		Codec:    "json",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+	if c.CodecArgs == nil {
+		c.CodecArgs = []any{}
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryStateAction(client, codec, &c), nil
+}
+
+func QueryStateAction(
+	client dapr.Client,
+	codec codec.Codec,
+	config *QueryStateConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		query, err := stateQuery(data, config)
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata map[string]string
+		if config.Metadata != nil {
+			if metadata, err = config.Metadata.EvalMap(data); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := client.QueryStateAlpha1(ctx, config.Store, query, metadata)
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		results := make([]interface{}, len(resp.Results))
+		for i := range resp.Results {
+			item := &resp.Results[i]
+			result := map[string]interface{}{
+				"key": item.Key,
+			}
+			if len(item.Value) > 0 {
+				value, _, err := codec.Decode(item.Value, config.CodecArgs...)
+				if err != nil {
+					return nil, fmt.Errorf("could not decode value of %q: %w", item.Key, err)
+				}
+				result["data"] = value
+			}
+			if item.Etag != "" {
+				result["etag"] = item.Etag
+			}
+			if item.Error != "" {
+				result["error"] = item.Error
+			}
+			results[i] = result
+		}
+
+		response := map[string]interface{}{
+			"results": results,
+		}
+		if resp.Token != "" {
+			response["token"] = resp.Token
+		}
+
+		return response, nil
+	}
+}
+
+// stateQuery returns the JSON query from the evaluated filter, sort and page
+// expressions.
+func stateQuery(data actions.Data, config *QueryStateConfig) (string, error) {
+	query := make(map[string]interface{}, 3)
+	if config.Filter != nil {
+		filter, err := config.Filter.Eval(data)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate filter: %w", err)
+		}
+		query["filter"] = coalesce.ValueIItoSI(filter, true)
+	}
+	if config.Sort != nil {
+		sort, err := config.Sort.Eval(data)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate sort: %w", err)
+		}
+		query["sort"] = coalesce.ValueIItoSI(sort, true)
+	}
+
+	page := make(map[string]interface{}, 2)
+	if config.Limit != nil {
+		limit, err := expr.EvalAsInt64E(config.Limit, data)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate limit: %w", err)
+		}
+		page["limit"] = limit
+	}
+	if config.Token != nil {
+		token, err := expr.EvalAsStringE(config.Token, data)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate token: %w", err)
+		}
+		if token != "" {
+			page["token"] = token
+		}
+	}
+	if len(page) > 0 {
+		query["page"] = page
+	}
+
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize query: %w", err)
+	}
+
+	return string(queryBytes), nil
+}
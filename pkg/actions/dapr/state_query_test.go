@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestStateQuery(t *testing.T) {
+	ctx := context.Background()
+	s := fakeSidecar{
+		queryResp: &pb.QueryStateResponse{
+			Results: []*pb.QueryStateItem{
+				{Key: "1", Data: []byte(`{"id":"1","state":"WA"}`), Etag: "3"},
+				{Key: "2", Data: []byte(`{"id":"2","state":"WA"}`)},
+			},
+			Token: "2",
+		},
+	}
+	action, err := dapr.QueryStateLoader(ctx, map[string]any{
+		"store":    "test",
+		"filter":   `{"EQ": {"state": input.state}}`,
+		"sort":     `[{"key": "id", "order": "DESC"}]`,
+		"limit":    `input.limit`,
+		"token":    `input.token`,
+		"metadata": `{"contentType": "application/json"}`,
+	}, startSidecar(t, &s))
+	require.NoError(t, err)
+
+	output, err := action(ctx, actions.Data{
+		"input": map[string]any{
+			"state": "WA",
+			"limit": 2,
+			"token": "",
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, s.queryReq)
+	assert.Equal(t, "test", s.queryReq.StoreName)
+	assert.JSONEq(t, `{
+		"filter": {"EQ": {"state": "WA"}},
+		"sort": [{"key": "id", "order": "DESC"}],
+		"page": {"limit": 2}
+	}`, s.queryReq.Query)
+	assert.Equal(t, map[string]string{"contentType": "application/json"}, s.queryReq.Metadata)
+	assert.Equal(t, map[string]any{
+		"results": []any{
+			map[string]any{
+				"key":  "1",
+				"data": map[string]any{"id": "1", "state": "WA"},
+				"etag": "3",
+			},
+			map[string]any{
+				"key":  "2",
+				"data": map[string]any{"id": "2", "state": "WA"},
+			},
+		},
+		"token": "2",
+	}, output)
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"context"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func TransactStateLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := TransactStateConfig{
+		Resource: "dapr",
+		Codec:    "json",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+	for i := range c.Operations {
+		if c.Operations[i].Type == 0 {
+			c.Operations[i].Type = StateOperationTypeUpsert
+		}
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	client, err := resource.Get[dapr.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return TransactStateAction(client, codec, &c), nil
+}
+
+func TransactStateAction(
+	client dapr.Client,
+	codec codec.Codec,
+	config *TransactStateConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		ops := make([]*dapr.StateOperation, 0, len(config.Operations))
+
+		for i := range config.Operations {
+			configOp := &config.Operations[i]
+			var items []any
+			if configOp.ForEach != nil {
+				itemsInt, err := configOp.ForEach.Eval(data)
+				if err != nil {
+					return nil, fmt.Errorf("could not evaluate data: %w", err)
+				}
+				var ok bool
+				if items, ok = itemsInt.([]any); !ok {
+					return nil, fmt.Errorf("forEach expression %q did not return a slice of items", configOp.ForEach.Expr())
+				}
+			}
+
+			if items == nil {
+				op, err := createStateOperation(data, nil, configOp, codec, config.CodecArgs)
+				if err != nil {
+					return nil, err
+				}
+
+				ops = append(ops, op)
+			} else {
+				for _, item := range items {
+					op, err := createStateOperation(data, item, configOp, codec, config.CodecArgs)
+					if err != nil {
+						return nil, err
+					}
+
+					ops = append(ops, op)
+				}
+			}
+		}
+
+		var metadata map[string]string
+		if config.Metadata != nil {
+			var err error
+			if metadata, err = config.Metadata.EvalMap(data); err != nil {
+				return nil, err
+			}
+		}
+
+		err := client.ExecuteStateTransaction(ctx, config.Store, metadata, ops)
+
+		return nil, resiliency.Retriable(err)
+	}
+}
+
+func createStateOperation(
+	data actions.Data,
+	item any,
+	config *TransactStateOperation,
+	codec codec.Codec, args []any) (*dapr.StateOperation, error) {
+	it, err := createSetItem(data, item, &SetStateItem{
+		Key:      config.Key,
+		Value:    config.Value,
+		Etag:     config.Etag,
+		Metadata: config.Metadata,
+	}, codec, args)
+	if err != nil {
+		return nil, err
+	}
+	it.Options = &dapr.StateOptions{
+		Concurrency: dapr.StateConcurrency(config.Concurrency),
+		Consistency: dapr.StateConsistency(config.Consistency),
+	}
+
+	opType := dapr.StateOperationTypeUpsert
+	if config.Type == StateOperationTypeDelete {
+		opType = dapr.StateOperationTypeDelete
+		it.Value = nil
+	}
+
+	return &dapr.StateOperation{
+		Type: opType,
+		Item: it,
+	}, nil
+}
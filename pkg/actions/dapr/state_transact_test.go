@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr_test
+
+import (
+	"context"
+	"testing"
+
+	commonv1 "github.com/dapr/go-sdk/dapr/proto/common/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/dapr"
+)
+
+func TestStateTransact(t *testing.T) {
+	ctx := context.Background()
+	s := fakeSidecar{}
+	action, err := dapr.TransactStateLoader(ctx, map[string]any{
+		"store": "test",
+		"operations": []any{
+			map[string]any{
+				"key":         `input.id`,
+				"etag":        `input.etag`,
+				"concurrency": "firstWrite",
+			},
+			map[string]any{
+				"type":    "delete",
+				"key":     `"line-" + item`,
+				"forEach": `input.removed`,
+			},
+		},
+		"metadata": `{"partitionKey": input.id}`,
+	}, startSidecar(t, &s))
+	require.NoError(t, err)
+
+	_, err = action(ctx, actions.Data{
+		"input": map[string]any{
+			"id":      "1234",
+			"etag":    "5",
+			"removed": []any{"1", "2"},
+		},
+	})
+	require.NoError(t, err)
+
+	req := s.transactionReq
+	require.NotNil(t, req)
+	assert.Equal(t, "test", req.StoreName)
+	assert.Equal(t, map[string]string{"partitionKey": "1234"}, req.Metadata)
+	require.Len(t, req.Operations, 3)
+
+	upsert := req.Operations[0]
+	assert.Equal(t, "upsert", upsert.OperationType)
+	assert.Equal(t, "1234", upsert.Request.Key)
+	assert.Equal(t, "5", upsert.Request.Etag.Value)
+	assert.Equal(t, commonv1.StateOptions_CONCURRENCY_FIRST_WRITE, upsert.Request.Options.Concurrency)
+	assert.JSONEq(t, `{"id":"1234","etag":"5","removed":["1","2"]}`, string(upsert.Request.Value))
+
+	for i, key := range []string{"line-1", "line-2"} {
+		del := req.Operations[i+1]
+		assert.Equal(t, "delete", del.OperationType)
+		assert.Equal(t, key, del.Request.Key)
+		assert.Empty(t, del.Request.Value)
+	}
+}
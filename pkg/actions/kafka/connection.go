@@ -0,0 +1,149 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type ConnectionConfig struct {
+	// Brokers are the seed broker addresses (e.g. localhost:9092).
+	Brokers  []string    `mapstructure:"brokers" validate:"required,min=1"`
+	ClientID string      `mapstructure:"clientId"`
+	SASL     *SASLConfig `mapstructure:"sasl"`
+	// TLS enables TLS using the system root certificates.
+	TLS bool `mapstructure:"tls"`
+	// Idempotent enables the idempotent producer, which guarantees records
+	// are written exactly once and in order per partition. Requires acks=all.
+	Idempotent bool `mapstructure:"idempotent"`
+	// Acks is the number of acknowledgements required: all, leader or none.
+	Acks string `mapstructure:"acks" validate:"oneof=all leader none"`
+	// Linger is how long to wait for more records before sending a batch.
+	Linger time.Duration `mapstructure:"linger"`
+	// Compression is the batch compression codec: none, gzip, snappy, lz4 or zstd.
+	Compression string `mapstructure:"compression" validate:"omitempty,oneof=none gzip snappy lz4 zstd"`
+}
+
+type SASLConfig struct {
+	// Mechanism is one of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512.
+	Mechanism string `mapstructure:"mechanism" validate:"required,oneof=PLAIN SCRAM-SHA-256 SCRAM-SHA-512"`
+	Username  string `mapstructure:"username" validate:"required"`
+	Password  string `mapstructure:"password" validate:"required"`
+}
+
+// Producer produces records to Kafka. *kgo.Client satisfies this interface.
+type Producer interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+}
+
+// Connection is the NamedLoader for a Kafka connection.
+func Connection() (string, resource.Loader) {
+	return "nanobus.resource.kafka/v1", ConnectionLoader
+}
+
+func ConnectionLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	c := ConnectionConfig{
+		Idempotent: true,
+		Acks:       "all",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	opts, err := ClientOptions(&c)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast if the brokers are unreachable.
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// ClientOptions converts a connection configuration into franz-go client options.
+func ClientOptions(c *ConnectionConfig) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(c.Brokers...),
+	}
+	if c.ClientID != "" {
+		opts = append(opts, kgo.ClientID(c.ClientID))
+	}
+	if c.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if c.SASL != nil {
+		switch c.SASL.Mechanism {
+		case "PLAIN":
+			opts = append(opts, kgo.SASL(plain.Auth{
+				User: c.SASL.Username,
+				Pass: c.SASL.Password,
+			}.AsMechanism()))
+		case "SCRAM-SHA-256":
+			opts = append(opts, kgo.SASL(scram.Auth{
+				User: c.SASL.Username,
+				Pass: c.SASL.Password,
+			}.AsSha256Mechanism()))
+		case "SCRAM-SHA-512":
+			opts = append(opts, kgo.SASL(scram.Auth{
+				User: c.SASL.Username,
+				Pass: c.SASL.Password,
+			}.AsSha512Mechanism()))
+		}
+	}
+
+	switch c.Acks {
+	case "leader":
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()))
+	case "none":
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()))
+	}
+	if c.Idempotent && c.Acks != "all" {
+		return nil, fmt.Errorf("idempotent producers require acks=all, got %q", c.Acks)
+	}
+	if !c.Idempotent {
+		opts = append(opts, kgo.DisableIdempotentWrite())
+	}
+	if c.Linger > 0 {
+		opts = append(opts, kgo.ProducerLinger(c.Linger))
+	}
+	switch c.Compression {
+	case "none":
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.NoCompression()))
+	case "gzip":
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.GzipCompression()))
+	case "snappy":
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.SnappyCompression()))
+	case "lz4":
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.Lz4Compression()))
+	case "zstd":
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.ZstdCompression()))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func ProduceLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := ProduceConfig{
+		Codec:            "json",
+		PropagateTracing: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	producer, err := resource.Get[Producer](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return ProduceAction(producer, &c, codec), nil
+}
+
+func ProduceAction(
+	producer Producer,
+	config *ProduceConfig,
+	codec codec.Codec) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			input, err = config.Data.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		topic, err := expr.EvalAsStringE(config.Topic, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate topic: %w", err)
+		}
+
+		value, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		record := kgo.Record{
+			Topic: topic,
+			Value: value,
+		}
+
+		if config.Key != nil {
+			key, err := expr.EvalAsStringE(config.Key, data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate key: %w", err)
+			}
+			record.Key = []byte(key)
+		}
+
+		headers := map[string]string{}
+		if config.Headers != nil {
+			if headers, err = config.Headers.EvalMap(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate headers: %w", err)
+			}
+			if headers == nil {
+				headers = map[string]string{}
+			}
+		}
+
+		// Propagate distributed tracing fields
+		// per the W3C TraceContext standard.
+		if config.PropagateTracing {
+			otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+		}
+
+		keys := make([]string, 0, len(headers))
+		for k := range headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			record.Headers = append(record.Headers, kgo.RecordHeader{
+				Key:   k,
+				Value: []byte(headers[k]),
+			})
+		}
+
+		r, err := producer.ProduceSync(ctx, &record).First()
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+
+		return map[string]interface{}{
+			"topic":     r.Topic,
+			"partition": r.Partition,
+			"offset":    r.Offset,
+		}, nil
+	}
+}
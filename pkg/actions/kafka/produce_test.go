@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/kafka"
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type mockProducer struct {
+	record *kgo.Record
+	err    error
+}
+
+func (m *mockProducer) ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	m.record = rs[0]
+	m.record.Partition = 3
+	m.record.Offset = 42
+	return kgo.ProduceResults{{Record: rs[0], Err: m.err}}
+}
+
+func getResolver(producer kafka.Producer) resolve.ResolveAs {
+	resources := resource.Resources{
+		"kafka": producer,
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func TestProduce(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	m := mockProducer{}
+	action, err := kafka.ProduceLoader(ctx, map[string]interface{}{
+		"resource": "kafka",
+		"topic":    `"orders"`,
+		"key":      `input.order.id`,
+		"data":     `input.order`,
+		"headers":  `{"ce_type": "order.created"}`,
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	result, err := action(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"order": map[string]interface{}{
+				"id": "1234",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"topic":     "orders",
+		"partition": int32(3),
+		"offset":    int64(42),
+	}, result)
+	assert.Equal(t, "orders", m.record.Topic)
+	assert.Equal(t, []byte("1234"), m.record.Key)
+	assert.Equal(t, []byte(`{"id":"1234"}`), m.record.Value)
+	assert.Equal(t, []kgo.RecordHeader{
+		{Key: "ce_type", Value: []byte("order.created")},
+		{Key: "traceparent", Value: []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")},
+	}, m.record.Headers)
+}
+
+func TestProduceError(t *testing.T) {
+	ctx := context.Background()
+	m := mockProducer{err: errors.New("not leader")}
+	action, err := kafka.ProduceLoader(ctx, map[string]interface{}{
+		"resource":         "kafka",
+		"topic":            `"orders"`,
+		"propagateTracing": false,
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	_, err = action(ctx, actions.Data{"input": "test"})
+	assert.EqualError(t, err, "not leader")
+	assert.Nil(t, m.record.Key)
+	assert.Empty(t, m.record.Headers)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mqtt
+
+import (
+	"context"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type ConnectionConfig struct {
+	// Brokers are the broker URLs (e.g. tcp://localhost:1883).
+	Brokers []string `mapstructure:"brokers" validate:"required,min=1"`
+	// ClientID is the MQTT client identifier.
+	ClientID string `mapstructure:"clientId"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// CleanSession discards any previous session state on connect.
+	CleanSession bool `mapstructure:"cleanSession"`
+	// Store is a directory used to persist in-flight messages.
+	// If not set, messages are kept in memory.
+	Store          string        `mapstructure:"store"`
+	KeepAlive      time.Duration `mapstructure:"keepAlive"`
+	ConnectTimeout time.Duration `mapstructure:"connectTimeout"`
+}
+
+// Connection is the NamedLoader for an MQTT connection.
+func Connection() (string, resource.Loader) {
+	return "nanobus.resource.mqtt/v1", ConnectionLoader
+}
+
+func ConnectionLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	c := ConnectionConfig{
+		CleanSession:   true,
+		KeepAlive:      30 * time.Second,
+		ConnectTimeout: 30 * time.Second,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client := mqtt.NewClient(ClientOptions(&c))
+	token := client.Connect()
+	if !token.WaitTimeout(c.ConnectTimeout) {
+		client.Disconnect(0)
+		return nil, mqtt.ErrNotConnected
+	}
+	if err := token.Error(); err != nil {
+		client.Disconnect(0)
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// ClientOptions converts a connection configuration into Paho client options.
+func ClientOptions(c *ConnectionConfig) *mqtt.ClientOptions {
+	opts := mqtt.NewClientOptions()
+	for _, broker := range c.Brokers {
+		opts.AddBroker(broker)
+	}
+	opts.SetClientID(c.ClientID)
+	opts.SetUsername(c.Username)
+	opts.SetPassword(c.Password)
+	opts.SetCleanSession(c.CleanSession)
+	opts.SetKeepAlive(c.KeepAlive)
+	opts.SetConnectTimeout(c.ConnectTimeout)
+	opts.SetAutoReconnect(true)
+	if c.Store != "" {
+		opts.SetStore(mqtt.NewFileStore(c.Store))
+	}
+
+	return opts
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func PublishLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := PublishConfig{
+		Codec: "json",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	client, err := resource.Get[mqtt.Client](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return PublishAction(client, &c, codec), nil
+}
+
+func PublishAction(
+	client mqtt.Client,
+	config *PublishConfig,
+	codec codec.Codec) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			input, err = config.Data.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		topic, err := expr.EvalAsStringE(config.Topic, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate topic: %w", err)
+		}
+
+		payload, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		token := client.Publish(topic, config.Qos, config.Retained, payload)
+		select {
+		case <-token.Done():
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return nil, resiliency.Retriable(token.Error())
+	}
+}
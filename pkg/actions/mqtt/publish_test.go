@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mqtt_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/mqtt"
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type mockToken struct {
+	paho.Token
+	err error
+}
+
+func (m *mockToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+func (m *mockToken) Error() error {
+	return m.err
+}
+
+type mockClient struct {
+	paho.Client
+
+	topic    string
+	qos      byte
+	retained bool
+	payload  interface{}
+	err      error
+}
+
+func (m *mockClient) Publish(topic string, qos byte, retained bool, payload interface{}) paho.Token {
+	m.topic = topic
+	m.qos = qos
+	m.retained = retained
+	m.payload = payload
+	return &mockToken{err: m.err}
+}
+
+func getResolver(client paho.Client) resolve.ResolveAs {
+	resources := resource.Resources{
+		"mqtt": client,
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		input    actions.Data
+		topic    string
+		qos      byte
+		retained bool
+		payload  []byte
+	}{
+		{
+			name: "defaults",
+			config: map[string]interface{}{
+				"resource": "mqtt",
+				"topic":    `"devices/" + input.id`,
+			},
+			input: actions.Data{
+				"input": map[string]interface{}{
+					"id": "sensor-1",
+				},
+			},
+			topic:   "devices/sensor-1",
+			payload: []byte(`{"id":"sensor-1"}`),
+		},
+		{
+			name: "with data, qos and retained",
+			config: map[string]interface{}{
+				"resource": "mqtt",
+				"topic":    `"devices"`,
+				"qos":      1,
+				"retained": true,
+				"data":     `input.data`,
+			},
+			input: actions.Data{
+				"input": map[string]interface{}{
+					"data": map[string]interface{}{
+						"value": 42,
+					},
+				},
+			},
+			topic:    "devices",
+			qos:      1,
+			retained: true,
+			payload:  []byte(`{"value":42}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mockClient{}
+			action, err := mqtt.PublishLoader(ctx, tt.config, getResolver(&m))
+			require.NoError(t, err)
+			_, err = action(ctx, tt.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.topic, m.topic)
+			assert.Equal(t, tt.qos, m.qos)
+			assert.Equal(t, tt.retained, m.retained)
+			assert.Equal(t, tt.payload, m.payload)
+		})
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	ctx := context.Background()
+	m := mockClient{
+		err: errors.New("not connected"),
+	}
+	action, err := mqtt.PublishLoader(ctx, map[string]interface{}{
+		"resource": "mqtt",
+		"topic":    `"devices"`,
+	}, getResolver(&m))
+	require.NoError(t, err)
+	_, err = action(ctx, actions.Data{})
+	assert.EqualError(t, err, "not connected")
+}
+
+func TestPublishInvalidQos(t *testing.T) {
+	ctx := context.Background()
+	m := mockClient{}
+	_, err := mqtt.PublishLoader(ctx, map[string]interface{}{
+		"resource": "mqtt",
+		"topic":    `"devices"`,
+		"qos":      3,
+	}, getResolver(&m))
+	assert.Error(t, err)
+}
+
+func TestConnectionLoaderFailure(t *testing.T) {
+	client, err := mqtt.ConnectionLoader(context.Background(), map[string]interface{}{
+		"brokers":        []string{"tcp://127.0.0.1:1"},
+		"connectTimeout": "2s",
+	}, nil)
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Nil(t, client)
+}
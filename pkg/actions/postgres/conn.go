@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/transaction"
+)
+
+// Conn is implemented by pools, pooled connections and transactions.
+type Conn interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// querier returns the transaction in ctx for pool, if any, or pool.
+func querier(ctx context.Context, pool *pgxpool.Pool) Conn {
+	if tx, ok := transaction.Pgx(ctx, pool); ok {
+		return tx
+	}
+	return pool
+}
+
+// acquireFunc calls f with the transaction in ctx for pool, if any, or a
+// connection acquired from pool.
+func acquireFunc(ctx context.Context, pool *pgxpool.Pool, f func(conn Conn) error) error {
+	if tx, ok := transaction.Pgx(ctx, pool); ok {
+		return f(tx)
+	}
+	return pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
+		return f(conn)
+	})
+}
+
+// beginFunc calls f in a transaction that is nested in the transaction in ctx
+// for pool, if any.
+func beginFunc(ctx context.Context, pool *pgxpool.Pool, f func(tx pgx.Tx) error) error {
+	if tx, ok := transaction.Pgx(ctx, pool); ok {
+		return tx.BeginFunc(ctx, f)
+	}
+	return pool.BeginFunc(ctx, f)
+}
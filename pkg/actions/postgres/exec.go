@@ -59,7 +59,7 @@ func ExecAction(
 		}
 
 		if multi, ok := input.([]interface{}); ok {
-			if err = pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+			if err = beginFunc(ctx, pool, func(tx pgx.Tx) error {
 				for _, item := range multi {
 					if single, ok := item.(map[string]interface{}); ok {
 						args := make([]interface{}, len(config.Args))
@@ -93,7 +93,7 @@ func ExecAction(
 				}
 			}
 
-			_, err := pool.Exec(ctx, config.SQL, args...)
+			_, err := querier(ctx, pool).Exec(ctx, config.SQL, args...)
 			if err != nil {
 				return nil, err
 			}
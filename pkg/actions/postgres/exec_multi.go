@@ -49,7 +49,7 @@ func ExecMultiAction(
 	config *ExecMultiConfig,
 	pool *pgxpool.Pool) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
-		err := pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := beginFunc(ctx, pool, func(tx pgx.Tx) error {
 			for _, stmt := range config.Statements {
 				var err error
 				var input interface{} = map[string]interface{}(data)
@@ -64,7 +64,7 @@ func FindOneAction(
 	pool *pgxpool.Pool) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
 		var result map[string]interface{}
-		err := pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) (err error) {
+		err := acquireFunc(ctx, pool, func(conn Conn) (err error) {
 			result, err = findOne(ctx, conn, t, data, config.Where, config.Preload)
 			return err
 		})
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/entity/find"
+	"github.com/nanobus/nanobus/pkg/expr"
+)
+
+func TestFindByIdKeyColumn(t *testing.T) {
+	conn := newConn(t,
+		expectedQuery{
+			sql:  `SELECT customer_id, name FROM "customers" WHERE customer_id = $1`,
+			args: []interface{}{"c1"},
+			rows: [][]interface{}{{"c1", "Ada"}},
+		},
+	)
+
+	record, err := findById(context.Background(), conn, entityType(t, "Customer"), "c1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"customerId": "c1", "name": "Ada"}, record)
+}
+
+func TestGetManyAndCount(t *testing.T) {
+	typ := entityType(t, "Order")
+	columns, err := find.Columns(typ, nil)
+	require.NoError(t, err)
+
+	var customer expr.ValueExpr
+	require.NoError(t, customer.FromString(`input.customer`))
+	where := []Where{{Query: "customer_id = ?", Value: &customer}}
+	input := map[string]interface{}{
+		"input": map[string]interface{}{"customer": "c1"},
+	}
+
+	q := find.New(find.Dollar)
+	q.Limit = 2
+	q.Offset = 4
+	require.NoError(t, q.SetOrderBy("-version", columns))
+	require.NoError(t, q.AddFilter(map[string]interface{}{"version[gt]": 1}, columns))
+
+	conn := newConn(t,
+		expectedQuery{
+			sql:  `SELECT count(1) FROM "orders" WHERE customer_id = $1 AND version > $2`,
+			args: []interface{}{"c1", 1},
+			rows: [][]interface{}{{int64(7)}},
+		},
+		expectedQuery{
+			sql:  `SELECT id, customer_id, version, 1 FROM "orders" WHERE customer_id = $1 AND version > $2 ORDER BY version DESC LIMIT 2 OFFSET 4`,
+			args: []interface{}{"c1", 1},
+			rows: [][]interface{}{{"o1", "c1", int64(3), int32(1)}},
+		},
+	)
+
+	total, err := getCount(context.Background(), conn, typ, input, where, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), total)
+
+	results, err := getMany(context.Background(), conn, typ, input, where, nil, q)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "o1", results[0]["id"])
+}
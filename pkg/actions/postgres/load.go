@@ -76,7 +76,7 @@ func LoadAction(
 		}
 
 		var result map[string]interface{}
-		if err = pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) (err error) {
+		if err = acquireFunc(ctx, pool, func(conn Conn) (err error) {
 			result, err = findById(ctx, conn, t, keyValue, config.Preload)
 			return err
 		}); err != nil {
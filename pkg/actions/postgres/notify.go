@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func NotifyLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := NotifyConfig{
+		Codec: "json",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	pool, err := resource.Get[*pgxpool.Pool](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return NotifyAction(&c, codec, pool), nil
+}
+
+func NotifyAction(
+	config *NotifyConfig,
+	codec codec.Codec,
+	pool *pgxpool.Pool) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			input, err = config.Data.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		channel, err := expr.EvalAsStringE(config.Channel, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate channel: %w", err)
+		}
+
+		payload, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Using the transaction in ctx defers delivery until it commits.
+		_, err = querier(ctx, pool).Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(payload))
+		return nil, err
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func OutboxLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := OutboxConfig{
+		Table: "outbox",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	pool, err := resource.Get[*pgxpool.Pool](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return OutboxAction(&c, pool), nil
+}
+
+func OutboxAction(
+	config *OutboxConfig,
+	pool *pgxpool.Pool) actions.Action {
+	insertSQL := fmt.Sprintf(`INSERT INTO %s (topic, event_key, event_type, payload, metadata) VALUES ($1, $2, $3, $4, $5)`,
+		pgx.Identifier(strings.Split(config.Table, ".")).Sanitize())
+
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		topic, err := expr.EvalAsStringE(config.Topic, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate topic: %w", err)
+		}
+		key, err := evalOptionalString(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+		eventType, err := evalOptionalString(config.Type, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate type: %w", err)
+		}
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			if input, err = config.Data.Eval(data); err != nil {
+				return nil, err
+			}
+		}
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+		var metadata *string
+		if config.Metadata != nil {
+			m, err := config.Metadata.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			metadataJSON, err := json.Marshal(m)
+			if err != nil {
+				return nil, err
+			}
+			s := string(metadataJSON)
+			metadata = &s
+		}
+
+		return nil, beginFunc(ctx, pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, insertSQL, topic, key, eventType, string(payload), metadata); err != nil {
+				return err
+			}
+			if config.Notify != nil {
+				if _, err := tx.Exec(ctx, "SELECT pg_notify($1, $2)", *config.Notify, topic); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// evalOptionalString evaluates e as a string, returning nil if e is not set
+// or evaluates to nil.
+func evalOptionalString(e *expr.ValueExpr, data actions.Data) (*string, error) {
+	if e == nil {
+		return nil, nil
+	}
+	v, err := e.Eval(data)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	s := fmt.Sprint(v)
+	return &s, nil
+}
@@ -21,5 +21,11 @@ var All = []actions.NamedLoader{
 	QueryOne,
 	Exec,
 	ExecMulti,
+	Insert,
+	Update,
+	Upsert,
+	Delete,
+	Notify,
+	Outbox,
 	Test,
 }
@@ -63,7 +63,7 @@ func QueryAction(
 			}
 		}
 
-		rows, err := pool.Query(ctx, config.SQL, args...)
+		rows, err := querier(ctx, pool).Query(ctx, config.SQL, args...)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/spec"
+)
+
+func UpsertLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := UpsertConfig{
+		ConflictError: "aborted",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var namespaces spec.Namespaces
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"spec:namespaces", &namespaces,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	poolI, ok := resources[string(c.Resource)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", c.Resource)
+	}
+	pool, ok := poolI.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not a *pgxpool.Pool", c.Resource)
+	}
+
+	ns, ok := namespaces[c.Entity.Namespace]
+	if !ok {
+		return nil, fmt.Errorf("namespace %q is not found", c.Entity.Namespace)
+	}
+	t, ok := ns.Type(c.Entity.Type)
+	if !ok {
+		return nil, fmt.Errorf("type %q is not found", c.Entity.Type)
+	}
+
+	return UpsertAction(&c, t, pool), nil
+}
+
+func UpsertAction(
+	config *UpsertConfig,
+	t *spec.Type,
+	pool *pgxpool.Pool) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		output, err := writeEach(ctx, pool, data, config.Data, func(ctx context.Context, w *writer, item map[string]interface{}) (map[string]interface{}, error) {
+			return w.upsert(ctx, t, item)
+		})
+		if err != nil {
+			return nil, translateWriteError(err, config.Resource, "not_found", config.ConflictError)
+		}
+
+		return output, nil
+	}
+}
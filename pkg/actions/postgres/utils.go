@@ -21,9 +21,11 @@ import (
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4/pgxpool"
 
+	"github.com/nanobus/nanobus/pkg/entity/find"
 	"github.com/nanobus/nanobus/pkg/logger"
 	"github.com/nanobus/nanobus/pkg/spec"
 	"github.com/nanobus/nanobus/pkg/stream"
+	"github.com/nanobus/nanobus/pkg/transaction"
 )
 
 func annotationValue(a spec.Annotator, annotation, argument, defaultValue string) string {
@@ -35,8 +37,8 @@ func annotationValue(a spec.Annotator, annotation, argument, defaultValue string
 	return defaultValue
 }
 
-func findById(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, idValue interface{}, toPreload []Preload) (map[string]interface{}, error) {
-	idColumn := keyColumn(t)
+func findById(ctx context.Context, conn Conn, t *spec.Type, idValue interface{}, toPreload []Preload) (map[string]interface{}, error) {
+	idColumn := find.KeyColumn(t)
 	sql := generateTableSQL(t) + " WHERE " + idColumn + " = $1"
 	rows, err := conn.Query(ctx, sql, idValue)
 	if err != nil {
@@ -71,7 +73,7 @@ func findById(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, idValue int
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, conn, ex.Type.ItemType.Type,
 						key.ValueString()+" = $1", []interface{}{record[keyName]},
 						preload.Preload)
@@ -90,7 +92,7 @@ func findById(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, idValue int
 	return nil, nil
 }
 
-func findOne(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload) (map[string]interface{}, error) {
+func findOne(ctx context.Context, conn Conn, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload) (map[string]interface{}, error) {
 	sql := generateTableSQL(t)
 	args := []interface{}{}
 	if len(where) > 0 {
@@ -151,7 +153,7 @@ func findOne(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[st
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, conn, ex.Type.ItemType.Type,
 						key.ValueString()+" = $1", []interface{}{record[keyName]},
 						preload.Preload)
@@ -170,7 +172,7 @@ func findOne(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[st
 	return nil, nil
 }
 
-func join(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, where string, args []interface{}, toPreload []Preload) ([]map[string]interface{}, error) {
+func join(ctx context.Context, conn Conn, t *spec.Type, where string, args []interface{}, toPreload []Preload) ([]map[string]interface{}, error) {
 	sql := generateTableSQL(t)
 	if len(where) > 0 {
 		sql += " WHERE "
@@ -217,7 +219,7 @@ func join(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, where string, a
 					}
 				} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 					if key, ok := hasMany.Argument("key"); ok {
-						keyName := keyField(t)
+						keyName := find.KeyField(t)
 						res, err = join(ctx, conn, ex.Type.ItemType.Type,
 							key.ValueString()+" = $1", []interface{}{record[keyName]},
 							preload.Preload)
@@ -235,8 +237,8 @@ func join(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, where string, a
 	return results, nil
 }
 
-func getMany(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, offset, limit int64) ([]map[string]interface{}, error) {
-	sql, args, err := generateSQL(t, where, input, offset, limit)
+func getMany(ctx context.Context, conn Conn, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, q *find.Query) ([]map[string]interface{}, error) {
+	sql, args, err := generateSQL(t, where, input, q)
 	if err != nil {
 		return nil, err
 	}
@@ -265,15 +267,32 @@ func getMany(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[st
 
 	rows.Close()
 
-	if err := preload(ctx, nil, conn, t, results, toPreload); err != nil {
+	if err := preload(ctx, conn, t, results, toPreload); err != nil {
 		return nil, err
 	}
 
 	return results, nil
 }
 
-func streamMany(ctx context.Context, s stream.Sink, pool *pgxpool.Pool, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, offset, limit int64) error {
-	sql, args, err := generateSQL(t, where, input, offset, limit)
+func streamMany(ctx context.Context, s stream.Sink, pool *pgxpool.Pool, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, q *find.Query) error {
+	if tx, ok := transaction.Pgx(ctx, pool); ok {
+		// A transaction has a single connection, which cannot preload while
+		// the rows are still being read.
+		results, err := getMany(ctx, tx, t, input, where, toPreload, q)
+		if err != nil {
+			s.Error(err)
+			return err
+		}
+		for _, r := range results {
+			if err = s.Next(r, nil); err != nil {
+				s.Error(err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	sql, args, err := generateSQL(t, where, input, q)
 	if err != nil {
 		return err
 	}
@@ -294,9 +313,14 @@ func streamMany(ctx context.Context, s stream.Sink, pool *pgxpool.Pool, t *spec.
 	results := make([]map[string]interface{}, 0, bufferSize)
 
 	flush := func() error {
-		if err := preload(ctx, pool, nil, t, results, toPreload); err != nil {
-			s.Error(err)
-			return err
+		if len(toPreload) > 0 {
+			// Preload on another connection while rows are read from conn.
+			if err := acquireFunc(ctx, pool, func(conn Conn) error {
+				return preload(ctx, conn, t, results, toPreload)
+			}); err != nil {
+				s.Error(err)
+				return err
+			}
 		}
 
 		for _, r := range results {
@@ -341,19 +365,11 @@ func streamMany(ctx context.Context, s stream.Sink, pool *pgxpool.Pool, t *spec.
 	return nil
 }
 
-func preload(ctx context.Context, pool *pgxpool.Pool, conn *pgxpool.Conn, t *spec.Type, results []map[string]interface{}, toPreload []Preload) error {
+func preload(ctx context.Context, conn Conn, t *spec.Type, results []map[string]interface{}, toPreload []Preload) error {
 	if len(toPreload) == 0 {
 		return nil
 	}
 
-	if conn == nil {
-		conn, err := pool.Acquire(ctx)
-		if err != nil {
-			return err
-		}
-		defer conn.Release()
-	}
-
 	for _, record := range results {
 		for _, preload := range toPreload {
 			ex, ok := t.Field(preload.Field)
@@ -370,7 +386,7 @@ func preload(ctx context.Context, pool *pgxpool.Pool, conn *pgxpool.Conn, t *spe
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, conn, ex.Type.ItemType.Type,
 						key.ValueString()+" = $1", []interface{}{record[keyName]},
 						preload.Preload)
@@ -387,72 +403,50 @@ func preload(ctx context.Context, pool *pgxpool.Pool, conn *pgxpool.Conn, t *spe
 	return nil
 }
 
-func generateSQL(t *spec.Type, where []Where, input map[string]interface{}, offset, limit int64) (string, []interface{}, error) {
-	sql := generateTableSQL(t)
-	var args []interface{}
-	if len(where) > 0 {
-		dollarIndex := 1
-		for i, part := range where {
-			val, err := part.Value.Eval(input)
-			if err != nil {
-				return "", nil, err
-			}
-			if isNil(val) {
-				continue
-			}
-			if i > 0 {
-				sql += " AND "
-			} else {
-				sql += " WHERE "
-			}
-			query := part.Query
-			for strings.Contains(query, "?") {
-				query = strings.Replace(query, "?", fmt.Sprintf("$%d", dollarIndex), 1)
-				dollarIndex++
-			}
-			sql += query
-			args = append(args, val)
-		}
+func generateSQL(t *spec.Type, where []Where, input map[string]interface{}, q *find.Query) (string, []interface{}, error) {
+	conditions, args, err := whereConditions(where, input)
+	if err != nil {
+		return "", nil, err
 	}
-	if offset > 0 {
-		sql += " OFFSET " + strconv.FormatInt(offset, 10)
+	whereSQL, args := q.WhereSQL(conditions, args, true)
+	sql := generateTableSQL(t) + whereSQL + q.OrderBySQL()
+	if q.Limit > 0 {
+		sql += " LIMIT " + strconv.FormatInt(q.Limit, 10)
 	}
-	if limit > 0 {
-		sql += " LIMIT " + strconv.FormatInt(limit, 10)
+	if q.Offset > 0 {
+		sql += " OFFSET " + strconv.FormatInt(q.Offset, 10)
 	}
 
 	return sql, args, nil
 }
 
-func getCount(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[string]interface{}, where []Where) (int64, error) {
-	sql := generateCountSQL(t)
+// whereConditions returns the configured where parts that have a value.
+func whereConditions(where []Where, input map[string]interface{}) ([]string, []interface{}, error) {
+	var conditions []string
 	var args []interface{}
-	if len(where) > 0 {
-		dollarIndex := 1
-		for i, part := range where {
-			val, err := part.Value.Eval(input)
-			if err != nil {
-				return 0, err
-			}
-			if isNil(val) {
-				continue
-			}
-			if i > 0 {
-				sql += " AND "
-			} else {
-				sql += " WHERE "
-			}
-			query := part.Query
-			for strings.Contains(query, "?") {
-				query = strings.Replace(query, "?", fmt.Sprintf("$%d", dollarIndex), 1)
-				dollarIndex++
-			}
-			sql += query
-			args = append(args, val)
+	for _, part := range where {
+		val, err := part.Value.Eval(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isNil(val) {
+			continue
 		}
+		conditions = append(conditions, part.Query)
+		args = append(args, val)
 	}
 
-	rows, err := conn.Query(ctx, sql, args...)
+	return conditions, args, nil
+}
+
+func getCount(ctx context.Context, conn Conn, t *spec.Type, input map[string]interface{}, where []Where, q *find.Query) (int64, error) {
+	conditions, args, err := whereConditions(where, input)
+	if err != nil {
+		return 0, err
+	}
+	whereSQL, args := q.WhereSQL(conditions, args, false)
+
+	rows, err := conn.Query(ctx, generateCountSQL(t)+whereSQL, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -465,27 +459,6 @@ func getCount(ctx context.Context, conn *pgxpool.Conn, t *spec.Type, input map[s
 	return count, err
 }
 
-func keyField(t *spec.Type) string {
-	for _, f := range t.Fields {
-		if _, ok := f.Annotation("key"); ok {
-			return f.Name
-		}
-	}
-	return ""
-}
-
-func keyColumn(t *spec.Type) string {
-	if _, ok := t.Annotation("primaryKey"); ok {
-		return annotationValue(t, "primaryKey", "name", "")
-	}
-	for _, f := range t.Fields {
-		if _, ok := f.Annotation("key"); ok {
-			return annotationValue(t, "column", "name", f.Name)
-		}
-	}
-	return ""
-}
-
 func generateTableSQL(t *spec.Type) string {
 	var buf strings.Builder
 
@@ -0,0 +1,522 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/entity/find"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/spec"
+)
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("version conflict")
+)
+
+// keyError reports the entity that was not found or whose version did not
+// match.
+type keyError struct {
+	err error
+	t   *spec.Type
+	key interface{}
+}
+
+func (e *keyError) Error() string {
+	return fmt.Sprintf("%s %v: %v", e.t.Name, e.key, e.err)
+}
+
+func (e *keyError) Unwrap() error {
+	return e.err
+}
+
+// translateWriteError converts not found and version conflict errors to the
+// configured errors.
+func translateWriteError(err error, res resource.Ref, notFoundError, conflictError string) error {
+	var ke *keyError
+	if !errors.As(err, &ke) {
+		return err
+	}
+	template := notFoundError
+	if errors.Is(ke.err, errConflict) {
+		template = conflictError
+	}
+	return errorz.Return(template, errorz.Metadata{
+		"resource": res,
+		"type":     ke.t.Name,
+		"key":      ke.key,
+	})
+}
+
+// writeEach evaluates the data expression and calls f within a transaction
+// for the resulting entity or each entity in the resulting list.
+func writeEach(ctx context.Context, pool *pgxpool.Pool, data actions.Data, dataExpr *expr.DataExpr, f func(ctx context.Context, w *writer, item map[string]interface{}) (map[string]interface{}, error)) (interface{}, error) {
+	var err error
+	var input interface{} = map[string]interface{}(data)
+	if dataExpr != nil {
+		if input, err = dataExpr.Eval(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var output interface{}
+	err = beginFunc(ctx, pool, func(tx pgx.Tx) error {
+		w := newWriter(tx)
+		switch v := input.(type) {
+		case map[string]interface{}:
+			stored, err := f(ctx, w, v)
+			output = stored
+			return err
+		case []interface{}:
+			results := make([]interface{}, len(v))
+			for i, item := range v {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("expected an object but got %T", item)
+				}
+				if results[i], err = f(ctx, w, m); err != nil {
+					return err
+				}
+			}
+			output = results
+			return nil
+		}
+		return fmt.Errorf("expected an object or a list but got %T", input)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// entityTable describes how an entity type maps to a table.
+type entityTable struct {
+	t    *spec.Type
+	name string
+	// columns are the scalar and hasOne columns in field order.
+	columns []entityColumn
+	key     *entityColumn
+	version *entityColumn
+	hasMany []entityRelation
+}
+
+type entityColumn struct {
+	field *spec.Field
+	name  string
+	// hasOne is the related type for hasOne relationships.
+	hasOne *spec.Type
+}
+
+type entityRelation struct {
+	field *spec.Field
+	t     *spec.Type
+	// column references the parent's key in t's table.
+	column string
+}
+
+func entityOf(t *spec.Type) (*entityTable, error) {
+	e := entityTable{
+		t:    t,
+		name: annotationValue(t, "entity", "table", t.Name),
+	}
+	for _, f := range t.Fields {
+		if hasMany, ok := f.Annotation("hasMany"); ok {
+			key, ok := hasMany.Argument("key")
+			if !ok {
+				continue
+			}
+			ref := unwrapOptional(f.Type)
+			if ref.ItemType == nil || unwrapOptional(ref.ItemType).Type == nil {
+				return nil, fmt.Errorf("hasMany field %s of %s is not a list of types", f.Name, t.Name)
+			}
+			e.hasMany = append(e.hasMany, entityRelation{
+				field:  f,
+				t:      unwrapOptional(ref.ItemType).Type,
+				column: key.ValueString(),
+			})
+			continue
+		}
+
+		c := entityColumn{
+			field: f,
+			name:  find.ColumnName(f),
+		}
+		if _, ok := f.Annotation("hasOne"); ok {
+			c.hasOne = unwrapOptional(f.Type).Type
+		}
+		e.columns = append(e.columns, c)
+	}
+
+	for i := range e.columns {
+		c := &e.columns[i]
+		if _, ok := c.field.Annotation("key"); ok {
+			c.name = find.KeyColumn(t)
+			e.key = c
+		}
+		if _, ok := c.field.Annotation("version"); ok {
+			e.version = c
+		}
+	}
+	if e.key == nil {
+		return nil, fmt.Errorf("type %s does not have a key field", t.Name)
+	}
+
+	return &e, nil
+}
+
+func (e *entityTable) isKey(c entityColumn) bool {
+	return c.field == e.key.field
+}
+
+func (e *entityTable) isVersion(c entityColumn) bool {
+	return e.version != nil && c.field == e.version.field
+}
+
+func (e *entityTable) table() string {
+	return `"` + e.name + `"`
+}
+
+func (e *entityTable) columnList() string {
+	var buf strings.Builder
+	for i, c := range e.columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(c.name)
+	}
+	return buf.String()
+}
+
+func unwrapOptional(t *spec.TypeRef) *spec.TypeRef {
+	for t.OptionalType != nil {
+		t = t.OptionalType
+	}
+	return t
+}
+
+// writer inserts, updates and deletes entities and their relationships. It
+// is used within a transaction so that nested writes are atomic.
+type writer struct {
+	conn Conn
+}
+
+func newWriter(conn Conn) *writer {
+	return &writer{
+		conn: conn,
+	}
+}
+
+func (w *writer) insert(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	return w.insertOrUpsert(ctx, t, data, false)
+}
+
+// upsert uses INSERT ... ON CONFLICT to update the entity if it exists and
+// otherwise insert it.
+func (w *writer) upsert(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	return w.insertOrUpsert(ctx, t, data, true)
+}
+
+func (w *writer) insertOrUpsert(ctx context.Context, t *spec.Type, data map[string]interface{}, upsert bool) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	nested, err := w.writeHasOne(ctx, e, data)
+	if err != nil {
+		return nil, err
+	}
+
+	keyValue, hasKey := data[e.key.field.Name]
+	hasKey = hasKey && !isNil(keyValue)
+
+	var columns, placeholders, sets []string
+	var args []interface{}
+	for _, c := range e.columns {
+		v, ok := data[c.field.Name]
+		if e.isVersion(c) && (!ok || isNil(v)) {
+			v, ok = 1, true
+		}
+		if !ok || (e.isKey(c) && isNil(v)) {
+			// Let the database generate a value.
+			continue
+		}
+		if child, ok := nested[c.field.Name]; ok {
+			v = child[find.KeyField(c.hasOne)]
+		}
+		args = append(args, v)
+		columns = append(columns, c.name)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		if !e.isKey(c) && !e.isVersion(c) {
+			sets = append(sets, c.name+" = EXCLUDED."+c.name)
+		}
+	}
+
+	query := "INSERT INTO " + e.table() +
+		" (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.Join(placeholders, ", ") + ")"
+	version, checkVersion := w.versionOf(e, data)
+	if upsert && hasKey {
+		if e.version != nil {
+			sets = append(sets, e.version.name+" = "+e.table()+"."+e.version.name+" + 1")
+		}
+		if len(sets) == 0 {
+			// DO NOTHING would not return the existing row.
+			sets = append(sets, e.key.name+" = EXCLUDED."+e.key.name)
+		}
+		query += " ON CONFLICT (" + e.key.name + ") DO UPDATE SET " + strings.Join(sets, ", ")
+		if checkVersion {
+			args = append(args, version)
+			query += fmt.Sprintf(" WHERE %s.%s = $%d", e.table(), e.version.name, len(args))
+		}
+	}
+	query += " RETURNING " + e.columnList()
+
+	stored, err := w.queryRow(ctx, e, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		// Only a failed version check skips the row.
+		return nil, &keyError{errConflict, t, keyValue}
+	}
+
+	return w.writeRelations(ctx, e, data, stored, nested, upsert)
+}
+
+func (w *writer) update(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	keyValue, ok := data[e.key.field.Name]
+	if !ok || isNil(keyValue) {
+		return nil, fmt.Errorf("%s requires a value for key field %s", t.Name, e.key.field.Name)
+	}
+
+	nested, err := w.writeHasOne(ctx, e, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	var args []interface{}
+	for _, c := range e.columns {
+		if e.isKey(c) || e.isVersion(c) {
+			continue
+		}
+		v, ok := data[c.field.Name]
+		if !ok {
+			continue
+		}
+		if child, ok := nested[c.field.Name]; ok {
+			v = child[find.KeyField(c.hasOne)]
+		}
+		args = append(args, v)
+		sets = append(sets, fmt.Sprintf("%s = $%d", c.name, len(args)))
+	}
+	if e.version != nil {
+		sets = append(sets, e.version.name+" = "+e.version.name+" + 1")
+	}
+
+	var stored map[string]interface{}
+	if len(sets) == 0 {
+		if stored, err = w.selectByKey(ctx, e, keyValue); err != nil {
+			return nil, err
+		}
+	} else {
+		args = append(args, keyValue)
+		query := "UPDATE " + e.table() + " SET " + strings.Join(sets, ", ") +
+			fmt.Sprintf(" WHERE %s = $%d", e.key.name, len(args))
+		version, checkVersion := w.versionOf(e, data)
+		if checkVersion {
+			args = append(args, version)
+			query += fmt.Sprintf(" AND %s = $%d", e.version.name, len(args))
+		}
+		query += " RETURNING " + e.columnList()
+
+		if stored, err = w.queryRow(ctx, e, query, args...); err != nil {
+			return nil, err
+		}
+		if stored == nil && checkVersion {
+			existing, err := w.selectByKey(ctx, e, keyValue)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, &keyError{errConflict, t, keyValue}
+			}
+		}
+	}
+	if stored == nil {
+		return nil, &keyError{errNotFound, t, keyValue}
+	}
+
+	return w.writeRelations(ctx, e, data, stored, nested, true)
+}
+
+func (w *writer) delete(ctx context.Context, t *spec.Type, keyValue, version interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "DELETE FROM " + e.table() + " WHERE " + e.key.name + " = $1"
+	args := []interface{}{keyValue}
+	checkVersion := e.version != nil && !isNil(version)
+	if checkVersion {
+		query += " AND " + e.version.name + " = $2"
+		args = append(args, version)
+	}
+	query += " RETURNING " + e.columnList()
+
+	deleted, err := w.queryRow(ctx, e, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == nil && checkVersion {
+		existing, err := w.selectByKey(ctx, e, keyValue)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, &keyError{errConflict, t, keyValue}
+		}
+	}
+	if deleted == nil {
+		return nil, &keyError{errNotFound, t, keyValue}
+	}
+
+	return deleted, nil
+}
+
+func (w *writer) versionOf(e *entityTable, data map[string]interface{}) (interface{}, bool) {
+	if e.version == nil {
+		return nil, false
+	}
+	v, ok := data[e.version.field.Name]
+	return v, ok && !isNil(v)
+}
+
+func (w *writer) selectByKey(ctx context.Context, e *entityTable, keyValue interface{}) (map[string]interface{}, error) {
+	return w.queryRow(ctx, e,
+		"SELECT "+e.columnList()+" FROM "+e.table()+" WHERE "+e.key.name+" = $1",
+		keyValue)
+}
+
+// queryRow returns the single row returned by query, or nil if there is none.
+func (w *writer) queryRow(ctx context.Context, e *entityTable, query string, args ...interface{}) (map[string]interface{}, error) {
+	rows, err := w.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values, err := rows.Values()
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		record[e.columns[i].field.Name] = normalizeValue(v)
+	}
+	return record, nil
+}
+
+// writeHasOne upserts the related entities that are given as objects so
+// that their keys can be stored in the foreign key columns.
+func (w *writer) writeHasOne(ctx context.Context, e *entityTable, data map[string]interface{}) (map[string]map[string]interface{}, error) {
+	var nested map[string]map[string]interface{}
+	for _, c := range e.columns {
+		if c.hasOne == nil {
+			continue
+		}
+		related, ok := data[c.field.Name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		child, err := w.upsert(ctx, c.hasOne, related)
+		if err != nil {
+			return nil, err
+		}
+		if nested == nil {
+			nested = make(map[string]map[string]interface{})
+		}
+		nested[c.field.Name] = child
+	}
+	return nested, nil
+}
+
+// writeRelations writes the hasMany items in data with their foreign key set
+// to the stored key and merges the nested entities into stored.
+func (w *writer) writeRelations(ctx context.Context, e *entityTable, data, stored map[string]interface{}, nested map[string]map[string]interface{}, upsert bool) (map[string]interface{}, error) {
+	for field, child := range nested {
+		stored[field] = child
+	}
+
+	for _, r := range e.hasMany {
+		items, ok := data[r.field.Name].([]interface{})
+		if !ok {
+			continue
+		}
+		child, err := entityOf(r.t)
+		if err != nil {
+			return nil, err
+		}
+		var fkField string
+		for _, c := range child.columns {
+			if c.name == r.column {
+				fkField = c.field.Name
+				break
+			}
+		}
+		if fkField == "" {
+			return nil, fmt.Errorf("%s does not have a field for column %s", r.t.Name, r.column)
+		}
+
+		results := make([]interface{}, len(items))
+		for i, item := range items {
+			itemData, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s items must be objects", r.field.Name)
+			}
+			copied := make(map[string]interface{}, len(itemData)+1)
+			for k, v := range itemData {
+				copied[k] = v
+			}
+			copied[fkField] = stored[e.key.field.Name]
+			if upsert {
+				results[i], err = w.upsert(ctx, r.t, copied)
+			} else {
+				results[i], err = w.insert(ctx, r.t, copied)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		stored[r.field.Name] = results
+	}
+
+	return stored, nil
+}
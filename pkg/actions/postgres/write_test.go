@@ -0,0 +1,234 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/spec/apex"
+)
+
+const schema = `
+namespace "shop"
+
+type Order @entity(table: "orders") {
+  id: string @key
+  customerId: string
+  version: i64 @version
+  items: [LineItem] @hasMany(key: "order_id")
+}
+
+type LineItem @entity(table: "line_items") {
+  id: i64 @key
+  orderId: string
+  sku: string
+}
+
+type Customer @entity(table: "customers") {
+  customerId: string @key
+  name: string
+}
+`
+
+// expectedQuery is a query the fake connection expects and the rows it
+// returns.
+type expectedQuery struct {
+	sql  string
+	args []interface{}
+	rows [][]interface{}
+}
+
+// fakeConn returns rows for the expected queries in order.
+type fakeConn struct {
+	t        *testing.T
+	expected []expectedQuery
+}
+
+func newConn(t *testing.T, expected ...expectedQuery) *fakeConn {
+	c := &fakeConn{t: t, expected: expected}
+	t.Cleanup(func() {
+		assert.Empty(t, c.expected, "expected queries were not run")
+	})
+	return c
+}
+
+func (c *fakeConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return nil, errors.New("unexpected Exec")
+}
+
+func (c *fakeConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	require.NotEmpty(c.t, c.expected, "unexpected query %s", sql)
+	e := c.expected[0]
+	c.expected = c.expected[1:]
+	assert.Equal(c.t, e.sql, sql)
+	assert.Equal(c.t, e.args, args)
+	return &fakeRows{rows: e.rows, i: -1}, nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	c.t.Fatalf("unexpected QueryRow %s", sql)
+	return nil
+}
+
+type fakeRows struct {
+	pgx.Rows
+	rows [][]interface{}
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i < len(r.rows)
+}
+
+func (r *fakeRows) Values() ([]interface{}, error) {
+	return r.rows[r.i], nil
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.rows[r.i][i]))
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error { return nil }
+func (r *fakeRows) Close()     {}
+
+func entityType(t *testing.T, name string) *spec.Type {
+	ns, err := apex.Parse([]byte(schema))
+	require.NoError(t, err)
+	typ, ok := ns.Type(name)
+	require.True(t, ok)
+	return typ
+}
+
+func TestInsert(t *testing.T) {
+	w := newWriter(newConn(t,
+		expectedQuery{
+			sql:  `INSERT INTO "orders" (id, customer_id, version) VALUES ($1, $2, $3) RETURNING id, customer_id, version`,
+			args: []interface{}{"o1", "c1", 1},
+			rows: [][]interface{}{{"o1", "c1", int64(1)}},
+		},
+		expectedQuery{
+			sql:  `INSERT INTO "line_items" (order_id, sku) VALUES ($1, $2) RETURNING id, order_id, sku`,
+			args: []interface{}{"o1", "abc"},
+			rows: [][]interface{}{{int64(10), "o1", "abc"}},
+		},
+	))
+
+	stored, err := w.insert(context.Background(), entityType(t, "Order"), map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c1",
+		"items": []interface{}{
+			map[string]interface{}{"sku": "abc"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c1",
+		"version":    int64(1),
+		"items": []interface{}{
+			map[string]interface{}{"id": int64(10), "orderId": "o1", "sku": "abc"},
+		},
+	}, stored)
+}
+
+func TestUpsertVersionConflict(t *testing.T) {
+	w := newWriter(newConn(t,
+		expectedQuery{
+			sql: `INSERT INTO "orders" (id, customer_id, version) VALUES ($1, $2, $3)` +
+				` ON CONFLICT (id) DO UPDATE SET customer_id = EXCLUDED.customer_id, version = "orders".version + 1` +
+				` WHERE "orders".version = $4 RETURNING id, customer_id, version`,
+			args: []interface{}{"o1", "c2", 3, 3},
+		},
+	))
+
+	_, err := w.upsert(context.Background(), entityType(t, "Order"), map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c2",
+		"version":    3,
+	})
+	err = translateWriteError(err, "db", "not_found", "aborted")
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "aborted", te.Template)
+	assert.Equal(t, "o1", te.Metadata["key"])
+}
+
+func TestUpdate(t *testing.T) {
+	w := newWriter(newConn(t,
+		expectedQuery{
+			sql:  `UPDATE "orders" SET customer_id = $1, version = version + 1 WHERE id = $2 AND version = $3 RETURNING id, customer_id, version`,
+			args: []interface{}{"c2", "o1", 3},
+		},
+		expectedQuery{
+			sql:  `SELECT id, customer_id, version FROM "orders" WHERE id = $1`,
+			args: []interface{}{"o1"},
+			rows: [][]interface{}{{"o1", "c1", int64(4)}},
+		},
+		expectedQuery{
+			sql:  `UPDATE "orders" SET customer_id = $1, version = version + 1 WHERE id = $2 RETURNING id, customer_id, version`,
+			args: []interface{}{"c2", "o1"},
+			rows: [][]interface{}{{"o1", "c2", int64(5)}},
+		},
+	))
+	typ := entityType(t, "Order")
+
+	// The row exists with another version.
+	_, err := w.update(context.Background(), typ, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c2",
+		"version":    3,
+	})
+	assert.True(t, errors.Is(err, errConflict))
+
+	stored, err := w.update(context.Background(), typ, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c2",
+		"version":    int64(5),
+	}, stored)
+
+	_, err = w.update(context.Background(), typ, map[string]interface{}{
+		"customerId": "c2",
+	})
+	assert.EqualError(t, err, "Order requires a value for key field id")
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	w := newWriter(newConn(t,
+		expectedQuery{
+			sql:  `DELETE FROM "orders" WHERE id = $1 RETURNING id, customer_id, version`,
+			args: []interface{}{"o1"},
+		},
+	))
+
+	_, err := w.delete(context.Background(), entityType(t, "Order"), "o1", nil)
+	err = translateWriteError(err, "db", "not_found", "aborted")
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+	assert.Equal(t, "Order", te.Metadata["type"])
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	nanopubsub "github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func PublishLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := PublishConfig{
+		Resource:         "pubsub",
+		Codec:            "json",
+		PropagateTracing: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	var daprPubSub string
+	if c.Pubsub != nil {
+		daprPubSub = *c.Pubsub
+	}
+	ps, err := nanopubsub.Get(resources, c.Resource, daprPubSub)
+	if err != nil {
+		return nil, err
+	}
+
+	return PublishAction(ps, &c, codec), nil
+}
+
+func PublishAction(
+	ps nanopubsub.PubSub,
+	config *PublishConfig,
+	codec codec.Codec) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var err error
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			input, err = config.Data.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		topic, err := expr.EvalAsStringE(config.Topic, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate topic: %w", err)
+		}
+
+		dataBytes, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata map[string]string
+		if config.Metadata != nil {
+			if metadata, err = config.Metadata.EvalMap(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate metadata: %w", err)
+			}
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+
+		if config.Key != nil {
+			key, err := expr.EvalAsStringE(config.Key, data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate key: %w", err)
+			}
+			metadata["partitionKey"] = key
+		}
+
+		// Propagate distributed tracing fields
+		// per the W3C TraceContext standard.
+		if config.PropagateTracing {
+			otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(metadata))
+		}
+
+		err = ps.Publish(ctx, topic, dataBytes, metadata)
+
+		return nil, resiliency.Retriable(err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/pubsub"
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	nanopubsub "github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+type mockPubSub struct {
+	topic    string
+	data     []byte
+	metadata map[string]string
+	err      error
+}
+
+func (m *mockPubSub) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	m.topic = topic
+	m.data = data
+	m.metadata = metadata
+	return m.err
+}
+
+func (m *mockPubSub) Subscribe(ctx context.Context, topic string, handler nanopubsub.Handler) error {
+	return nil
+}
+
+func getResolver(ps nanopubsub.PubSub) resolve.ResolveAs {
+	resources := resource.Resources{
+		"pubsub": ps,
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func TestPublish(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	m := mockPubSub{}
+	action, err := pubsub.PublishLoader(ctx, map[string]interface{}{
+		"topic":    `"orders"`,
+		"key":      `input.order.id`,
+		"data":     `input.order`,
+		"metadata": `{"type": "order.created"}`,
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	result, err := action(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"order": map[string]interface{}{
+				"id": "1234",
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	assert.Equal(t, "orders", m.topic)
+	assert.Equal(t, []byte(`{"id":"1234"}`), m.data)
+	assert.Equal(t, map[string]string{
+		"type":         "order.created",
+		"partitionKey": "1234",
+		"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}, m.metadata)
+}
+
+func TestPublishError(t *testing.T) {
+	ctx := context.Background()
+	m := mockPubSub{err: errors.New("broker unavailable")}
+	action, err := pubsub.PublishLoader(ctx, map[string]interface{}{
+		"topic":            `"orders"`,
+		"propagateTracing": false,
+	}, getResolver(&m))
+	require.NoError(t, err)
+
+	_, err = action(ctx, actions.Data{"input": "test"})
+	assert.EqualError(t, err, "broker unavailable")
+	assert.Equal(t, []byte(`"test"`), m.data)
+	assert.Empty(t, m.metadata)
+}
+
+func TestPublishResource(t *testing.T) {
+	ctx := context.Background()
+	_, err := pubsub.PublishLoader(ctx, map[string]interface{}{
+		"resource": "missing",
+		"topic":    `"orders"`,
+	}, getResolver(&mockPubSub{}))
+	assert.EqualError(t, err, `resource "missing" is not registered`)
+}
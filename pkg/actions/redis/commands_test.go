@@ -0,0 +1,282 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	nanoredis "github.com/nanobus/nanobus/pkg/actions/redis"
+	"github.com/nanobus/nanobus/pkg/codec"
+	json_codec "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func newResolver(t *testing.T) (*redis.Client, resolve.ResolveAs) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	t.Cleanup(func() { client.Close() })
+
+	resources := resource.Resources{
+		"test": client,
+	}
+	codecs := codec.Codecs{
+		"json": json_codec.NewCodec(),
+	}
+
+	return client, func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func run(t *testing.T, resolver resolve.ResolveAs, loader actions.Loader, with map[string]any, input any) any {
+	t.Helper()
+	ctx := context.Background()
+	with["resource"] = "test"
+	action, err := loader(ctx, with, resolver)
+	require.NoError(t, err)
+	result, err := action(ctx, actions.Data{"input": input})
+	require.NoError(t, err)
+	return result
+}
+
+func TestHash(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "user:1")
+
+	added := run(t, resolver, nanoredis.HSetLoader, map[string]any{
+		"key": `"user:" + input.id`,
+		"fields": `{
+			"name": input.name,
+			"address": input.address,
+		}`,
+		"ttl": time.Minute,
+	}, map[string]any{
+		"id":      "1",
+		"name":    "Alice",
+		"address": map[string]any{"city": "Berlin"},
+	})
+	assert.Equal(t, int64(2), added)
+	assert.Greater(t, client.TTL(ctx, "user:1").Val(), time.Duration(0))
+
+	name := run(t, resolver, nanoredis.HGetLoader, map[string]any{
+		"key":   `"user:1"`,
+		"field": `"name"`,
+	}, nil)
+	assert.Equal(t, "Alice", name)
+
+	missing := run(t, resolver, nanoredis.HGetLoader, map[string]any{
+		"key":   `"user:1"`,
+		"field": `"email"`,
+	}, nil)
+	assert.Nil(t, missing)
+
+	// Objects are encoded as JSON without a codec.
+	all := run(t, resolver, nanoredis.HGetAllLoader, map[string]any{
+		"key": `"user:1"`,
+	}, nil)
+	assert.Equal(t, map[string]any{
+		"name":    "Alice",
+		"address": `{"city":"Berlin"}`,
+	}, all)
+}
+
+func TestCounter(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "requests")
+
+	with := map[string]any{
+		"key": `"requests"`,
+		"by":  "input.n",
+		"ttl": time.Minute,
+	}
+	assert.Equal(t, int64(2), run(t, resolver, nanoredis.IncrLoader, with, map[string]any{"n": 2}))
+	assert.Equal(t, int64(5), run(t, resolver, nanoredis.IncrLoader, with, map[string]any{"n": 3}))
+	assert.Greater(t, client.TTL(ctx, "requests").Val(), time.Duration(0))
+
+	assert.Equal(t, true, run(t, resolver, nanoredis.ExpireLoader, map[string]any{
+		"key": `"requests"`,
+		"ttl": time.Hour,
+	}, nil))
+	assert.Greater(t, client.TTL(ctx, "requests").Val(), time.Minute)
+}
+
+func TestList(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "jobs")
+
+	length := run(t, resolver, nanoredis.PushLoader, map[string]any{
+		"key": `"jobs"`,
+	}, []any{
+		map[string]any{"id": 1},
+		map[string]any{"id": 2},
+		map[string]any{"id": 3},
+	})
+	assert.Equal(t, int64(3), length)
+
+	jobs := run(t, resolver, nanoredis.LRangeLoader, map[string]any{
+		"key":   `"jobs"`,
+		"start": "1",
+		"codec": "json",
+	}, nil)
+	assert.Equal(t, []any{
+		map[string]any{"id": int64(2)},
+		map[string]any{"id": int64(3)},
+	}, jobs)
+
+	job := run(t, resolver, nanoredis.PopLoader, map[string]any{
+		"key":   `"jobs"`,
+		"codec": "json",
+	}, nil)
+	assert.Equal(t, map[string]any{"id": int64(1)}, job)
+
+	job = run(t, resolver, nanoredis.PopLoader, map[string]any{
+		"key": `"jobs"`,
+		"end": "right",
+	}, nil)
+	assert.Equal(t, `{"id":3}`, job)
+}
+
+func TestSortedSet(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "leaderboard")
+
+	for name, score := range map[string]int{"a": 10, "b": 20, "c": 30} {
+		run(t, resolver, nanoredis.ZAddLoader, map[string]any{
+			"key":    `"leaderboard"`,
+			"member": "input.name",
+			"score":  "input.score",
+		}, map[string]any{"name": name, "score": score})
+	}
+
+	members := run(t, resolver, nanoredis.ZRangeByScoreLoader, map[string]any{
+		"key": `"leaderboard"`,
+		"min": `"(10"`,
+	}, nil)
+	assert.Equal(t, []any{"b", "c"}, members)
+
+	top := run(t, resolver, nanoredis.ZRangeByScoreLoader, map[string]any{
+		"key":        `"leaderboard"`,
+		"reverse":    true,
+		"withScores": true,
+		"count":      "2",
+	}, nil)
+	assert.Equal(t, []any{
+		map[string]any{"member": "c", "score": 30.0},
+		map[string]any{"member": "b", "score": 20.0},
+	}, top)
+}
+
+func TestMGet(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "missing")
+	client.Set(ctx, "a", `{"n":1}`, 0)
+	client.Set(ctx, "b", `{"n":2}`, 0)
+
+	values := run(t, resolver, nanoredis.MGetLoader, map[string]any{
+		"keys":  `["a", "missing", "b"]`,
+		"codec": "json",
+	}, nil)
+	assert.Equal(t, []any{
+		map[string]any{"n": int64(1)},
+		nil,
+		map[string]any{"n": int64(2)},
+	}, values)
+}
+
+func TestEval(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Set(ctx, "stock", "5", 0)
+
+	script := `
+local stock = tonumber(redis.call("GET", KEYS[1]))
+local n = tonumber(ARGV[1])
+if stock < n then return 0 end
+redis.call("DECRBY", KEYS[1], n)
+return 1`
+	with := map[string]any{
+		"script": script,
+		"keys":   []any{`"stock"`},
+		"args":   []any{"input.n"},
+	}
+	assert.Equal(t, int64(1), run(t, resolver, nanoredis.EvalLoader, with, map[string]any{"n": 3}))
+	assert.Equal(t, int64(0), run(t, resolver, nanoredis.EvalLoader, with, map[string]any{"n": 3}))
+	assert.Equal(t, "2", client.Get(ctx, "stock").Val())
+}
+
+func TestPipeline(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "p:counter", "p:missing")
+
+	for _, transaction := range []bool{false, true} {
+		client.Del(ctx, "p:counter")
+		results := run(t, resolver, nanoredis.PipelineLoader, map[string]any{
+			"transaction": transaction,
+			"commands": []any{
+				map[string]any{"command": "INCRBY", "args": []any{`"p:counter"`, "input.n"}},
+				map[string]any{"command": "GET", "args": []any{`"p:missing"`}},
+				map[string]any{"command": "INCR", "args": []any{`"p:counter"`}},
+			},
+		}, map[string]any{"n": 5})
+		assert.Equal(t, []any{int64(5), nil, int64(6)}, results)
+	}
+}
+
+func TestXAdd(t *testing.T) {
+	client, resolver := newResolver(t)
+	ctx := context.Background()
+	client.Del(ctx, "events")
+
+	with := map[string]any{
+		"stream":      `"events"`,
+		"fields":      `{"type": input.kind}`,
+		"maxLen":      2,
+		"approximate": false,
+	}
+	for i := 1; i <= 3; i++ {
+		result := run(t, resolver, nanoredis.XAddLoader, with, map[string]any{
+			"kind": "counted",
+			"n":    i,
+		})
+		assert.Equal(t, "events", result.(map[string]any)["stream"])
+	}
+
+	entries, err := client.XRange(ctx, "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]any{
+		"type": "counted",
+		"data": `{"kind":"counted","n":2}`,
+	}, entries[0].Values)
+}
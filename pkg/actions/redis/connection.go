@@ -10,7 +10,12 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 
@@ -20,9 +25,42 @@ import (
 )
 
 type ConnectionConfig struct {
-	Address  string `mapstructure:"address" validate:"required"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	// Address is the address of a single redis server (e.g. localhost:6379).
+	Address string `mapstructure:"address" validate:"required_without=Addresses"`
+	// Addresses are the seed addresses of a cluster or the sentinel
+	// addresses when MasterName is set.
+	Addresses []string `mapstructure:"addresses" validate:"required_without=Address"`
+	// Cluster connects to a redis cluster even when there is one address.
+	Cluster bool `mapstructure:"cluster"`
+	// MasterName is the sentinel master set name. Addresses are sentinels.
+	MasterName       string `mapstructure:"masterName"`
+	SentinelPassword string `mapstructure:"sentinelPassword"`
+	Username         string `mapstructure:"username"`
+	Password         string `mapstructure:"password"`
+	// DB is the database to select. It is not supported by clusters.
+	DB  int        `mapstructure:"db"`
+	TLS *TLSConfig `mapstructure:"tls"`
+	// PoolSize is the maximum number of connections per node. Defaults to 10
+	// per CPU.
+	PoolSize     int           `mapstructure:"poolSize"`
+	MinIdleConns int           `mapstructure:"minIdleConns"`
+	MaxRetries   int           `mapstructure:"maxRetries"`
+	DialTimeout  time.Duration `mapstructure:"dialTimeout"`
+	ReadTimeout  time.Duration `mapstructure:"readTimeout"`
+	WriteTimeout time.Duration `mapstructure:"writeTimeout"`
+	PoolTimeout  time.Duration `mapstructure:"poolTimeout"`
+	IdleTimeout  time.Duration `mapstructure:"idleTimeout"`
+}
+
+type TLSConfig struct {
+	// CAFile is a PEM file of the certificate authorities used to verify the
+	// server. Defaults to the system roots.
+	CAFile string `mapstructure:"caFile"`
+	// CertFile and KeyFile are a PEM client certificate and key.
+	CertFile           string `mapstructure:"certFile" validate:"required_with=KeyFile"`
+	KeyFile            string `mapstructure:"keyFile" validate:"required_with=CertFile"`
+	ServerName         string `mapstructure:"serverName"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
 }
 
 // Connection is the NamedLoader for a redis connection.
@@ -36,14 +74,101 @@ func ConnectionLoader(ctx context.Context, with interface{}, resolver resolve.Re
 		return nil, err
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:     c.Address,
-		Password: c.Password,
-		DB:       c.DB,
-	})
+	client, err := NewClient(&c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast if the server is unreachable.
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// NewClient creates a client for a single server, a sentinel master set or
+// a cluster depending on the configuration.
+func NewClient(c *ConnectionConfig) (redis.UniversalClient, error) {
+	opts, err := UniversalOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Cluster {
+		return redis.NewClusterClient(opts.Cluster()), nil
+	}
+	return redis.NewUniversalClient(opts), nil
+}
+
+// UniversalOptions converts a connection configuration into go-redis options.
+func UniversalOptions(c *ConnectionConfig) (*redis.UniversalOptions, error) {
+	addrs := c.Addresses
+	if c.Address != "" {
+		addrs = append([]string{c.Address}, addrs...)
+	}
+	if c.Cluster && c.MasterName != "" {
+		return nil, errors.New("cluster and masterName cannot be used together")
+	}
+	if c.Cluster && c.DB != 0 {
+		return nil, errors.New("redis clusters only support db 0")
+	}
+
+	opts := redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       c.MasterName,
+		SentinelPassword: c.SentinelPassword,
+		Username:         c.Username,
+		Password:         c.Password,
+		DB:               c.DB,
+		PoolSize:         c.PoolSize,
+		MinIdleConns:     c.MinIdleConns,
+		MaxRetries:       c.MaxRetries,
+		DialTimeout:      c.DialTimeout,
+		ReadTimeout:      c.ReadTimeout,
+		WriteTimeout:     c.WriteTimeout,
+		PoolTimeout:      c.PoolTimeout,
+		IdleTimeout:      c.IdleTimeout,
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.Config()
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return &opts, nil
+}
+
+// Config loads the certificates and returns the TLS configuration.
+func (c *TLSConfig) Config() (*tls.Config, error) {
+	tlsConfig := tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+	}
 
-	pong, err := client.Ping(client.Context()).Result()
-	fmt.Println(pong, err)
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
 
-	return client, err
+	return &tlsConfig, nil
 }
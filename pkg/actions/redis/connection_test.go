@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis_test
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nanoredis "github.com/nanobus/nanobus/pkg/actions/redis"
+)
+
+func TestUniversalOptions(t *testing.T) {
+	opts, err := nanoredis.UniversalOptions(&nanoredis.ConnectionConfig{
+		Addresses:  []string{"sentinel-1:26379", "sentinel-2:26379"},
+		MasterName: "mymaster",
+		Password:   "secret",
+		DB:         2,
+		TLS: &nanoredis.TLSConfig{
+			ServerName: "redis.local",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, opts.Addrs)
+	assert.Equal(t, "mymaster", opts.MasterName)
+	assert.Equal(t, 2, opts.DB)
+	require.NotNil(t, opts.TLSConfig)
+	assert.Equal(t, "redis.local", opts.TLSConfig.ServerName)
+
+	_, err = nanoredis.UniversalOptions(&nanoredis.ConnectionConfig{
+		Addresses:  []string{"localhost:6379"},
+		Cluster:    true,
+		MasterName: "mymaster",
+	})
+	assert.EqualError(t, err, "cluster and masterName cannot be used together")
+
+	_, err = nanoredis.UniversalOptions(&nanoredis.ConnectionConfig{
+		Address: "localhost:6379",
+		Cluster: true,
+		DB:      1,
+	})
+	assert.EqualError(t, err, "redis clusters only support db 0")
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := nanoredis.NewClient(&nanoredis.ConnectionConfig{
+		Address: "localhost:6379",
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.IsType(t, &redis.Client{}, client)
+
+	client, err = nanoredis.NewClient(&nanoredis.ConnectionConfig{
+		Address: "localhost:7000",
+		Cluster: true,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.IsType(t, &redis.ClusterClient{}, client)
+
+	client, err = nanoredis.NewClient(&nanoredis.ConnectionConfig{
+		Addresses:  []string{"localhost:26379"},
+		MasterName: "mymaster",
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.IsType(t, &redis.Client{}, client)
+}
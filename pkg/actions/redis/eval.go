@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/cast"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func EvalLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c EvalConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, _, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvalAction(&c, client), nil
+}
+
+func EvalAction(
+	config *EvalConfig,
+	client redis.UniversalClient) actions.Action {
+	script := redis.NewScript(config.Script)
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		keys := make([]string, len(config.Keys))
+		for i, e := range config.Keys {
+			value, err := e.Eval(data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate key: %w", err)
+			}
+			if keys[i], err = cast.ToStringE(value); err != nil {
+				return nil, fmt.Errorf("could not evaluate key: %w", err)
+			}
+		}
+		args, err := evalValues(config.Args, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate args: %w", err)
+		}
+
+		// Run uses EVALSHA and falls back to EVAL when the script is not cached.
+		result, err := script.Run(ctx, client, keys, args...).Result()
+		if isNil(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not run script: %w", err)
+		}
+
+		return result, nil
+	}
+}
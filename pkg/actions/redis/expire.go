@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func ExpireLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c ExpireConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, _, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExpireAction(&c, client), nil
+}
+
+func ExpireAction(
+	config *ExpireConfig,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		ok, err := client.Expire(ctx, key, config.TTL).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not expire key: %w", err))
+		}
+
+		return ok, nil
+	}
+}
@@ -3,6 +3,8 @@
 package redis
 
 import (
+	"time"
+
 	"github.com/nanobus/nanobus/pkg/actions"
 	"github.com/nanobus/nanobus/pkg/expr"
 	"github.com/nanobus/nanobus/pkg/resource"
@@ -32,6 +34,8 @@ type SetConfig struct {
 	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
 	// The data to write.
 	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// TTL expires the key after a duration.
+	TTL *time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" msgpack:"ttl,omitempty" mapstructure:"ttl"`
 	// Codec is the name of the codec to use for decoding.
 	Codec string `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec" validate:"required"`
 	// codecArgs are the arguments to pass to the decode function.
@@ -57,3 +61,294 @@ type RemoveConfig struct {
 func Remove() (string, actions.Loader) {
 	return "@redis/remove", RemoveLoader
 }
+
+// Reads the values of several keys. Missing keys are returned as null.
+type MGetConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Keys evaluates to the list of keys to read.
+	Keys *expr.ValueExpr `json:"keys" yaml:"keys" msgpack:"keys" mapstructure:"keys" validate:"required"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func MGet() (string, actions.Loader) {
+	return "@redis/mget", MGetLoader
+}
+
+// Reads a field of a hash. A missing field is returned as null.
+type HGetConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The hash key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The field to read.
+	Field *expr.ValueExpr `json:"field" yaml:"field" msgpack:"field" mapstructure:"field" validate:"required"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func HGet() (string, actions.Loader) {
+	return "@redis/hget", HGetLoader
+}
+
+// Sets fields of a hash and returns the number of fields that were added.
+type HSetConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The hash key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Fields evaluates to a map of the fields to set. Defaults to the input.
+	Fields *expr.DataExpr `json:"fields,omitempty" yaml:"fields,omitempty" msgpack:"fields,omitempty" mapstructure:"fields"`
+	// TTL expires the key after a duration.
+	TTL *time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" msgpack:"ttl,omitempty" mapstructure:"ttl"`
+	// Codec is the name of the codec to use for encoding values. Objects and arrays
+	// are encoded as JSON if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the encode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func HSet() (string, actions.Loader) {
+	return "@redis/hset", HSetLoader
+}
+
+// Reads all fields of a hash as a map.
+type HGetAllConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The hash key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func HGetAll() (string, actions.Loader) {
+	return "@redis/hgetall", HGetAllLoader
+}
+
+// Increments a counter and returns its new value.
+type IncrConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The counter key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// By is the amount to increment by. Defaults to 1.
+	By *expr.ValueExpr `json:"by,omitempty" yaml:"by,omitempty" msgpack:"by,omitempty" mapstructure:"by"`
+	// TTL expires the counter a duration after it is created (e.g. for rate limiting
+	// windows).
+	TTL *time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" msgpack:"ttl,omitempty" mapstructure:"ttl"`
+}
+
+func Incr() (string, actions.Loader) {
+	return "@redis/incr", IncrLoader
+}
+
+// Pushes values onto a list and returns the length of the list.
+type PushConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The list key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Values evaluates to a value or an array of values to push. Defaults to the
+	// input.
+	Values *expr.DataExpr `json:"values,omitempty" yaml:"values,omitempty" msgpack:"values,omitempty" mapstructure:"values"`
+	// The end of the list to push to (left or right).
+	End string `json:"end" yaml:"end" msgpack:"end" mapstructure:"end" validate:"oneof=left right"`
+	// TTL expires the key after a duration.
+	TTL *time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" msgpack:"ttl,omitempty" mapstructure:"ttl"`
+	// Codec is the name of the codec to use for encoding values. Objects and arrays
+	// are encoded as JSON if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the encode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func Push() (string, actions.Loader) {
+	return "@redis/push", PushLoader
+}
+
+// Removes and returns a value from a list. An empty list returns null.
+type PopConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The list key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The end of the list to pop from (left or right).
+	End string `json:"end" yaml:"end" msgpack:"end" mapstructure:"end" validate:"oneof=left right"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func Pop() (string, actions.Loader) {
+	return "@redis/pop", PopLoader
+}
+
+// Reads a range of a list.
+type LRangeConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The list key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Start is the index of the first value. Defaults to 0.
+	Start *expr.ValueExpr `json:"start,omitempty" yaml:"start,omitempty" msgpack:"start,omitempty" mapstructure:"start"`
+	// Stop is the index of the last value. Negative indexes count from the end.
+	// Defaults to -1.
+	Stop *expr.ValueExpr `json:"stop,omitempty" yaml:"stop,omitempty" msgpack:"stop,omitempty" mapstructure:"stop"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func LRange() (string, actions.Loader) {
+	return "@redis/lrange", LRangeLoader
+}
+
+// Adds a member to a sorted set, or updates its score, and returns the number of
+// members added.
+type ZAddConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The sorted set key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// The member to add.
+	Member *expr.ValueExpr `json:"member" yaml:"member" msgpack:"member" mapstructure:"member" validate:"required"`
+	// The member's score.
+	Score *expr.ValueExpr `json:"score" yaml:"score" msgpack:"score" mapstructure:"score" validate:"required"`
+	// TTL expires the key after a duration.
+	TTL *time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" msgpack:"ttl,omitempty" mapstructure:"ttl"`
+}
+
+func ZAdd() (string, actions.Loader) {
+	return "@redis/zadd", ZAddLoader
+}
+
+// Reads the members of a sorted set with scores in a range.
+type ZRangeByScoreConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The sorted set key.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Min is the minimum score. Prefix with ( for an exclusive bound. Defaults to
+	// -inf.
+	Min *expr.ValueExpr `json:"min,omitempty" yaml:"min,omitempty" msgpack:"min,omitempty" mapstructure:"min"`
+	// Max is the maximum score. Prefix with ( for an exclusive bound. Defaults to
+	// +inf.
+	Max *expr.ValueExpr `json:"max,omitempty" yaml:"max,omitempty" msgpack:"max,omitempty" mapstructure:"max"`
+	// Offset is the number of members to skip.
+	Offset *expr.ValueExpr `json:"offset,omitempty" yaml:"offset,omitempty" msgpack:"offset,omitempty" mapstructure:"offset"`
+	// Count is the maximum number of members to return.
+	Count *expr.ValueExpr `json:"count,omitempty" yaml:"count,omitempty" msgpack:"count,omitempty" mapstructure:"count"`
+	// Reverse returns members from the highest score to the lowest.
+	Reverse bool `json:"reverse" yaml:"reverse" msgpack:"reverse" mapstructure:"reverse"`
+	// WithScores returns objects with `member` and `score` instead of members.
+	WithScores bool `json:"withScores" yaml:"withScores" msgpack:"withScores" mapstructure:"withScores"`
+	// Codec is the name of the codec to use for decoding values. Values are returned
+	// as strings if it is not set.
+	Codec *string `json:"codec,omitempty" yaml:"codec,omitempty" msgpack:"codec,omitempty" mapstructure:"codec"`
+	// codecArgs are the arguments to pass to the decode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+}
+
+func ZRangeByScore() (string, actions.Loader) {
+	return "@redis/zrangebyscore", ZRangeByScoreLoader
+}
+
+// Sets the time to live of a key. Returns false if the key does not exist.
+type ExpireConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The key to expire.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// TTL is the time to live.
+	TTL time.Duration `json:"ttl" yaml:"ttl" msgpack:"ttl" mapstructure:"ttl" validate:"required"`
+}
+
+func Expire() (string, actions.Loader) {
+	return "@redis/expire", ExpireLoader
+}
+
+// Runs a Lua script on the server. The script is cached by its SHA1 digest.
+type EvalConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The Lua script.
+	Script string `json:"script" yaml:"script" msgpack:"script" mapstructure:"script" validate:"required"`
+	// Keys are the expressions of the keys passed as KEYS.
+	Keys []*expr.ValueExpr `json:"keys,omitempty" yaml:"keys,omitempty" msgpack:"keys,omitempty" mapstructure:"keys" validate:"dive"`
+	// Args are the expressions of the arguments passed as ARGV.
+	Args []*expr.ValueExpr `json:"args,omitempty" yaml:"args,omitempty" msgpack:"args,omitempty" mapstructure:"args" validate:"dive"`
+}
+
+func Eval() (string, actions.Loader) {
+	return "@redis/eval", EvalLoader
+}
+
+// Sends several commands in one round trip and returns the result of each.
+type PipelineConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The commands to send.
+	Commands []Command `json:"commands" yaml:"commands" msgpack:"commands" mapstructure:"commands" validate:"required,min=1,dive"`
+	// Transaction wraps the commands in MULTI/EXEC so that they run atomically.
+	Transaction bool `json:"transaction" yaml:"transaction" msgpack:"transaction" mapstructure:"transaction"`
+}
+
+func Pipeline() (string, actions.Loader) {
+	return "@redis/pipeline", PipelineLoader
+}
+
+type Command struct {
+	// The command name (e.g. HSET).
+	Command string `json:"command" yaml:"command" msgpack:"command" mapstructure:"command" validate:"required"`
+	// Args are the expressions of the command's arguments.
+	Args []*expr.ValueExpr `json:"args,omitempty" yaml:"args,omitempty" msgpack:"args,omitempty" mapstructure:"args" validate:"dive"`
+}
+
+// Adds an entry to a stream and returns its ID. The data is encoded into a single
+// field of the entry.
+type XAddConfig struct {
+	// The redis store resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The stream to add to.
+	Stream *expr.ValueExpr `json:"stream" yaml:"stream" msgpack:"stream" mapstructure:"stream" validate:"required"`
+	// The data to add. Defaults to the input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// The entry field that holds the encoded data.
+	Field string `json:"field" yaml:"field" msgpack:"field" mapstructure:"field" validate:"required"`
+	// Fields evaluates to additional fields of the entry (e.g. a type used to
+	// select a handler).
+	Fields *expr.DataExpr `json:"fields,omitempty" yaml:"fields,omitempty" msgpack:"fields,omitempty" mapstructure:"fields"`
+	// Codec is the name of the codec to use for encoding the data.
+	Codec string `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec" validate:"required"`
+	// codecArgs are the arguments to pass to the encode function.
+	CodecArgs []interface{} `json:"codecArgs,omitempty" yaml:"codecArgs,omitempty" msgpack:"codecArgs,omitempty" mapstructure:"codecArgs" validate:"dive"`
+	// The entry ID. Defaults to an ID generated by the server.
+	ID *expr.ValueExpr `json:"id,omitempty" yaml:"id,omitempty" msgpack:"id,omitempty" mapstructure:"id"`
+	// MaxLen trims the stream to this many entries.
+	MaxLen *int64 `json:"maxLen,omitempty" yaml:"maxLen,omitempty" msgpack:"maxLen,omitempty" mapstructure:"maxLen"`
+	// Approximate trims the stream with ~, which is more efficient but may keep a
+	// few more entries than maxLen.
+	Approximate bool `json:"approximate" yaml:"approximate" msgpack:"approximate" mapstructure:"approximate"`
+	// Enables/disables propagating the distributed tracing context (e.g. W3C
+	// TraceContext standard) in the entry fields.
+	PropagateTracing bool `json:"propagateTracing" yaml:"propagateTracing" msgpack:"propagateTracing" mapstructure:"propagateTracing"`
+}
+
+func XAdd() (string, actions.Loader) {
+	return "@redis/xadd", XAddLoader
+}
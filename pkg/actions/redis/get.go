@@ -44,7 +44,7 @@ func GetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs
 		return nil, fmt.Errorf("unknown codec %q", c.Codec)
 	}
 
-	client, err := resource.Get[*redis.Client](resources, c.Resource)
+	client, err := resource.Get[redis.UniversalClient](resources, c.Resource)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,7 @@ func GetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs
 func GetAction(
 	config *GetConfig,
 	codec codec.Codec,
-	client *redis.Client) actions.Action {
+	client redis.UniversalClient) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
 		key, err := expr.EvalAsStringE(config.Key, data)
 		if err != nil {
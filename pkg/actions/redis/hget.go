@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func HGetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c HGetConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return HGetAction(&c, codec, client), nil
+}
+
+func HGetAction(
+	config *HGetConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+		field, err := expr.EvalAsStringE(config.Field, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate field: %w", err)
+		}
+
+		value, err := client.HGet(ctx, key, field).Result()
+		if isNil(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not read field: %w", err))
+		}
+
+		return decodeValue(codec, config.CodecArgs, value)
+	}
+}
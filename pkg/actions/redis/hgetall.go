@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func HGetAllLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c HGetAllConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return HGetAllAction(&c, codec, client), nil
+}
+
+func HGetAllAction(
+	config *HGetAllConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		fields, err := client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not read hash: %w", err))
+		}
+
+		result := make(map[string]interface{}, len(fields))
+		for field, value := range fields {
+			if result[field], err = decodeValue(codec, config.CodecArgs, value); err != nil {
+				return nil, fmt.Errorf("could not decode field %q: %w", field, err)
+			}
+		}
+
+		return result, nil
+	}
+}
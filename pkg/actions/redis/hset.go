@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func HSetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c HSetConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return HSetAction(&c, codec, client), nil
+}
+
+func HSetAction(
+	config *HSetConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		input := data["input"]
+		if config.Fields != nil {
+			if input, err = config.Fields.Eval(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate fields: %w", err)
+			}
+		}
+		fields, ok := input.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("fields must be an object, got %T", input)
+		}
+		if len(fields) == 0 {
+			return int64(0), nil
+		}
+
+		values := make([]interface{}, 0, len(fields)*2)
+		for field, value := range fields {
+			encoded, err := encodeValue(codec, config.CodecArgs, value)
+			if err != nil {
+				return nil, fmt.Errorf("could not encode field %q: %w", field, err)
+			}
+			values = append(values, field, encoded)
+		}
+
+		added, err := withTTL(ctx, client, key, config.TTL, func(c redis.Cmdable) *redis.IntCmd {
+			return c.HSet(ctx, key, values...)
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not write hash: %w", err))
+		}
+
+		return added, nil
+	}
+}
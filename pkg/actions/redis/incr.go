@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func IncrLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c IncrConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, _, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return IncrAction(&c, client), nil
+}
+
+func IncrAction(
+	config *IncrConfig,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		by := int64(1)
+		if config.By != nil {
+			if by, err = expr.EvalAsInt64E(config.By, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate by: %w", err)
+			}
+		}
+
+		value, err := client.IncrBy(ctx, key, by).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not increment counter: %w", err))
+		}
+
+		// The counter was created by this increment so the window starts now.
+		if config.TTL != nil && value == by {
+			if err := client.Expire(ctx, key, *config.TTL).Err(); err != nil {
+				return nil, resiliency.Retriable(fmt.Errorf("could not expire counter: %w", err))
+			}
+		}
+
+		return value, nil
+	}
+}
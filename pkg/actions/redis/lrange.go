@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func LRangeLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c LRangeConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return LRangeAction(&c, codec, client), nil
+}
+
+func LRangeAction(
+	config *LRangeConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		start, stop := int64(0), int64(-1)
+		if config.Start != nil {
+			if start, err = expr.EvalAsInt64E(config.Start, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate start: %w", err)
+			}
+		}
+		if config.Stop != nil {
+			if stop, err = expr.EvalAsInt64E(config.Stop, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate stop: %w", err)
+			}
+		}
+
+		values, err := client.LRange(ctx, key, start, stop).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not read list: %w", err))
+		}
+
+		return decodeValues(codec, config.CodecArgs, values)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/cast"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func MGetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c MGetConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return MGetAction(&c, codec, client), nil
+}
+
+func MGetAction(
+	config *MGetConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		value, err := config.Keys.Eval(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate keys: %w", err)
+		}
+		keys, err := cast.ToStringSliceE(value)
+		if err != nil {
+			return nil, fmt.Errorf("keys must be a list of strings: %w", err)
+		}
+		if len(keys) == 0 {
+			return []interface{}{}, nil
+		}
+
+		values, err := client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not read keys: %w", err))
+		}
+
+		for i, value := range values {
+			if s, ok := value.(string); ok {
+				if values[i], err = decodeValue(codec, config.CodecArgs, s); err != nil {
+					return nil, fmt.Errorf("could not decode value of %q: %w", keys[i], err)
+				}
+			}
+		}
+
+		return values, nil
+	}
+}
@@ -17,4 +17,18 @@ var All = []actions.NamedLoader{
 	Get,
 	Set,
 	Remove,
+	MGet,
+	HGet,
+	HSet,
+	HGetAll,
+	Incr,
+	Push,
+	Pop,
+	LRange,
+	ZAdd,
+	ZRangeByScore,
+	Expire,
+	Eval,
+	Pipeline,
+	XAdd,
 }
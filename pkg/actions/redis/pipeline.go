@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func PipelineLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c PipelineConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, _, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return PipelineAction(&c, client), nil
+}
+
+func PipelineAction(
+	config *PipelineConfig,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		commands := make([][]interface{}, len(config.Commands))
+		for i := range config.Commands {
+			command := &config.Commands[i]
+			args, err := evalValues(command.Args, data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate args of command %d (%s): %w", i, command.Command, err)
+			}
+			commands[i] = append([]interface{}{command.Command}, args...)
+		}
+
+		fn := func(pipe redis.Pipeliner) error {
+			for _, args := range commands {
+				pipe.Do(ctx, args...)
+			}
+			return nil
+		}
+		var cmds []redis.Cmder
+		if config.Transaction {
+			cmds, _ = client.TxPipelined(ctx, fn)
+		} else {
+			cmds, _ = client.Pipelined(ctx, fn)
+		}
+
+		results := make([]interface{}, len(cmds))
+		for i, cmd := range cmds {
+			result, err := cmd.(*redis.Cmd).Result()
+			// A missing key is a result, not a failure.
+			if err != nil && !isNil(err) {
+				_, reply := err.(redis.Error)
+				err = fmt.Errorf("command %d (%s) failed: %w", i, config.Commands[i].Command, err)
+				// Error replies are not retried.
+				if !reply {
+					err = resiliency.Retriable(err)
+				}
+				return nil, err
+			}
+			results[i] = result
+		}
+
+		return results, nil
+	}
+}
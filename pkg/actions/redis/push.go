@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func PushLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := PushConfig{
+		End: "right",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return PushAction(&c, codec, client), nil
+}
+
+func PushAction(
+	config *PushConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		input := data["input"]
+		if config.Values != nil {
+			if input, err = config.Values.Eval(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate values: %w", err)
+			}
+		}
+		items, ok := input.([]interface{})
+		if !ok {
+			items = []interface{}{input}
+		}
+		if len(items) == 0 {
+			return client.LLen(ctx, key).Result()
+		}
+
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			if values[i], err = encodeValue(codec, config.CodecArgs, item); err != nil {
+				return nil, fmt.Errorf("could not encode value: %w", err)
+			}
+		}
+
+		length, err := withTTL(ctx, client, key, config.TTL, func(c redis.Cmdable) *redis.IntCmd {
+			if config.End == "left" {
+				return c.LPush(ctx, key, values...)
+			}
+			return c.RPush(ctx, key, values...)
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not push values: %w", err))
+		}
+
+		return length, nil
+	}
+}
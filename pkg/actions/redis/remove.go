@@ -43,7 +43,7 @@ func RemoveLoader(ctx context.Context, with interface{}, resolver resolve.Resolv
 		return nil, fmt.Errorf("unknown codec %q", c.Codec)
 	}
 
-	client, err := resource.Get[*redis.Client](resources, c.Resource)
+	client, err := resource.Get[redis.UniversalClient](resources, c.Resource)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,7 @@ func RemoveLoader(ctx context.Context, with interface{}, resolver resolve.Resolv
 func RemoveAction(
 	config *RemoveConfig,
 	codec codec.Codec,
-	client *redis.Client) actions.Action {
+	client redis.UniversalClient) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
 		key, err := expr.EvalAsStringE(config.Key, data)
 		if err != nil {
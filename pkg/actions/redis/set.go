@@ -11,6 +11,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 
@@ -43,7 +44,7 @@ func SetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs
 		return nil, fmt.Errorf("unknown codec %q", c.Codec)
 	}
 
-	client, err := resource.Get[*redis.Client](resources, c.Resource)
+	client, err := resource.Get[redis.UniversalClient](resources, c.Resource)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +55,7 @@ func SetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs
 func SetAction(
 	config *SetConfig,
 	codec codec.Codec,
-	client *redis.Client) actions.Action {
+	client redis.UniversalClient) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
 		key, err := expr.EvalAsStringE(config.Key, data)
 		if err != nil {
@@ -66,6 +67,11 @@ func SetAction(
 			return nil, fmt.Errorf("could not evaluate value: %w", err)
 		}
 
-		return client.Set(ctx, key, value, 0).Result()
+		var ttl time.Duration
+		if config.TTL != nil {
+			ttl = *config.TTL
+		}
+
+		return client.Set(ctx, key, value, ttl).Result()
 	}
 }
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// resolveClient returns the redis client registered as ref and the codecs.
+func resolveClient(resolver resolve.ResolveAs, ref resource.Ref) (redis.UniversalClient, codec.Codecs, error) {
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := resource.Get[redis.UniversalClient](resources, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, codecs, nil
+}
+
+// lookupCodec returns the named codec or nil if name is not set.
+func lookupCodec(codecs codec.Codecs, name *string) (codec.Codec, error) {
+	if name == nil {
+		return nil, nil
+	}
+	c, ok := codecs[*name]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", *name)
+	}
+	return c, nil
+}
+
+// encodeValue converts a value into a command argument. Without a codec,
+// objects and arrays are encoded as JSON and other values are passed as is.
+func encodeValue(c codec.Codec, args []interface{}, value interface{}) (interface{}, error) {
+	if c != nil {
+		return c.Encode(value, args...)
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if b, ok := value.([]byte); ok {
+			return b, nil
+		}
+		return json.Marshal(value)
+	}
+
+	return value, nil
+}
+
+// decodeValue converts a value read from redis. Without a codec, the value
+// is returned as a string.
+func decodeValue(c codec.Codec, args []interface{}, value string) (interface{}, error) {
+	if c == nil {
+		return value, nil
+	}
+	result, _, err := c.Decode([]byte(value), args...)
+	return result, err
+}
+
+func decodeValues(c codec.Codec, args []interface{}, values []string) ([]interface{}, error) {
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		result, err := decodeValue(c, args, value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// evalValues evaluates expressions into command arguments.
+func evalValues(exprs []*expr.ValueExpr, data map[string]interface{}) ([]interface{}, error) {
+	values := make([]interface{}, len(exprs))
+	for i, e := range exprs {
+		value, err := e.Eval(data)
+		if err != nil {
+			return nil, err
+		}
+		if values[i], err = encodeValue(nil, nil, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// withTTL runs a command and, if ttl is set, expires key in the same
+// transaction.
+func withTTL(ctx context.Context, client redis.UniversalClient, key string, ttl *time.Duration, fn func(c redis.Cmdable) *redis.IntCmd) (int64, error) {
+	if ttl == nil {
+		return fn(client).Result()
+	}
+
+	var cmd *redis.IntCmd
+	if _, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		cmd = fn(pipe)
+		pipe.Expire(ctx, key, *ttl)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return cmd.Result()
+}
+
+// isNil returns true if err is the reply for a missing key.
+func isNil(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
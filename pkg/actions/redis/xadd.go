@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func XAddLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := XAddConfig{
+		Field:            "data",
+		Codec:            "json",
+		Approximate:      true,
+		PropagateTracing: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[c.Codec]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", c.Codec)
+	}
+
+	return XAddAction(&c, codec, client), nil
+}
+
+func XAddAction(
+	config *XAddConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		stream, err := expr.EvalAsStringE(config.Stream, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate stream: %w", err)
+		}
+
+		var input interface{} = data["input"]
+		if config.Data != nil {
+			if input, err = config.Data.Eval(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate data: %w", err)
+			}
+		}
+		value, err := codec.Encode(input, config.CodecArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode data: %w", err)
+		}
+
+		fields := map[string]string{}
+		if config.Fields != nil {
+			if fields, err = config.Fields.EvalMap(data); err != nil {
+				return nil, fmt.Errorf("could not evaluate fields: %w", err)
+			}
+			if fields == nil {
+				fields = map[string]string{}
+			}
+		}
+
+		// Propagate distributed tracing fields
+		// per the W3C TraceContext standard.
+		if config.PropagateTracing {
+			otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(fields))
+		}
+
+		values := make(map[string]interface{}, len(fields)+1)
+		for k, v := range fields {
+			values[k] = v
+		}
+		values[config.Field] = value
+
+		args := redis.XAddArgs{
+			Stream: stream,
+			Values: values,
+			Approx: config.Approximate,
+		}
+		if config.ID != nil {
+			if args.ID, err = expr.EvalAsStringE(config.ID, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate id: %w", err)
+			}
+		}
+		if config.MaxLen != nil {
+			args.MaxLen = *config.MaxLen
+		}
+
+		id, err := client.XAdd(ctx, &args).Result()
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not add entry: %w", err))
+		}
+
+		return map[string]interface{}{
+			"stream": stream,
+			"id":     id,
+		}, nil
+	}
+}
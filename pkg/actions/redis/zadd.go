@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/cast"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func ZAddLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c ZAddConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, _, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return ZAddAction(&c, client), nil
+}
+
+func ZAddAction(
+	config *ZAddConfig,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+		member, err := config.Member.Eval(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate member: %w", err)
+		}
+		if member, err = encodeValue(nil, nil, member); err != nil {
+			return nil, fmt.Errorf("could not encode member: %w", err)
+		}
+		value, err := config.Score.Eval(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate score: %w", err)
+		}
+		score, err := cast.ToFloat64E(value)
+		if err != nil {
+			return nil, fmt.Errorf("score must be a number: %w", err)
+		}
+
+		added, err := withTTL(ctx, client, key, config.TTL, func(c redis.Cmdable) *redis.IntCmd {
+			return c.ZAdd(ctx, key, &redis.Z{Score: score, Member: member})
+		})
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not add member: %w", err))
+		}
+
+		return added, nil
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func ZRangeByScoreLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	var c ZRangeByScoreConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, codecs, err := resolveClient(resolver, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := lookupCodec(codecs, c.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return ZRangeByScoreAction(&c, codec, client), nil
+}
+
+func ZRangeByScoreAction(
+	config *ZRangeByScoreConfig,
+	codec codec.Codec,
+	client redis.UniversalClient) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		opt := redis.ZRangeBy{
+			Min: "-inf",
+			Max: "+inf",
+		}
+		if config.Min != nil {
+			if opt.Min, err = expr.EvalAsStringE(config.Min, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate min: %w", err)
+			}
+		}
+		if config.Max != nil {
+			if opt.Max, err = expr.EvalAsStringE(config.Max, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate max: %w", err)
+			}
+		}
+		if config.Offset != nil {
+			if opt.Offset, err = expr.EvalAsInt64E(config.Offset, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate offset: %w", err)
+			}
+		}
+		if config.Count != nil {
+			if opt.Count, err = expr.EvalAsInt64E(config.Count, data); err != nil {
+				return nil, fmt.Errorf("could not evaluate count: %w", err)
+			}
+		} else if opt.Offset != 0 {
+			// LIMIT requires a count.
+			opt.Count = -1
+		}
+
+		if !config.WithScores {
+			var members []string
+			if config.Reverse {
+				members, err = client.ZRevRangeByScore(ctx, key, &opt).Result()
+			} else {
+				members, err = client.ZRangeByScore(ctx, key, &opt).Result()
+			}
+			if err != nil {
+				return nil, resiliency.Retriable(fmt.Errorf("could not read sorted set: %w", err))
+			}
+			return decodeValues(codec, config.CodecArgs, members)
+		}
+
+		var zs []redis.Z
+		if config.Reverse {
+			zs, err = client.ZRevRangeByScoreWithScores(ctx, key, &opt).Result()
+		} else {
+			zs, err = client.ZRangeByScoreWithScores(ctx, key, &opt).Result()
+		}
+		if err != nil {
+			return nil, resiliency.Retriable(fmt.Errorf("could not read sorted set: %w", err))
+		}
+
+		results := make([]interface{}, len(zs))
+		for i, z := range zs {
+			member, err := decodeValue(codec, config.CodecArgs, z.Member.(string))
+			if err != nil {
+				return nil, fmt.Errorf("could not decode member: %w", err)
+			}
+			results[i] = map[string]interface{}{
+				"member": member,
+				"score":  z.Score,
+			}
+		}
+
+		return results, nil
+	}
+}
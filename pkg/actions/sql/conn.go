@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/nanobus/nanobus/pkg/transaction"
+)
+
+// querier returns the transaction in ctx for db, if any, or db.
+func querier(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := transaction.SQL(ctx, db); ok {
+		return tx
+	}
+	return db
+}
+
+// beginFunc calls f in a transaction, or in a savepoint if ctx already carries
+// a transaction for db.
+func beginFunc(ctx context.Context, db *sqlx.DB, f func(ctx context.Context, tx sqlx.ExtContext) error) error {
+	ctx, tx, err := transaction.Begin(ctx, db, transaction.Options{})
+	if err != nil {
+		return err
+	}
+
+	if err := f(ctx, querier(ctx, db)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -10,7 +10,10 @@ package sql
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -20,14 +23,29 @@ import (
 	_ "github.com/microsoft/go-mssqldb/azuread" // Azure AD driver module
 	_ "github.com/sijms/go-ora/v2"              // Oracle
 	_ "github.com/snowflakedb/gosnowflake"      // Snowflake
+	_ "modernc.org/sqlite"                      // SQLite
 
 	"github.com/nanobus/nanobus/pkg/config"
 	"github.com/nanobus/nanobus/pkg/resolve"
 	"github.com/nanobus/nanobus/pkg/resource"
 )
 
+func init() {
+	// Bind variables for drivers sqlx does not know about.
+	sqlx.BindDriver("oracle", sqlx.NAMED)
+	sqlx.BindDriver("azuresql", sqlx.AT)
+	sqlx.BindDriver("sqlite", sqlx.QUESTION)
+}
+
+// DefaultBusyTimeout is how long SQLite connections wait for a lock held by
+// another connection, such as the outbox relay, before failing with
+// SQLITE_BUSY.
+const DefaultBusyTimeout = 5 * time.Second
+
 type ConnectionConfig struct {
-	Driver     string `mapstructure:"driver"`
+	Driver string `mapstructure:"driver"`
+	// DataSource is the driver specific connection string. SQLite data
+	// sources without a busy_timeout pragma get DefaultBusyTimeout.
 	DataSource string `mapstructure:"dataSource"`
 }
 
@@ -44,10 +62,30 @@ func ConnectionLoader(ctx context.Context, with interface{}, resolver resolve.Re
 
 	// This Pings the database trying to connect
 	// use sqlx.Open() for sql.Open() semantics
-	db, err := sqlx.Connect(c.Driver, c.DataSource)
+	db, err := sqlx.Connect(c.Driver, DataSource(c.Driver, c.DataSource))
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	return db, nil
 }
+
+// DataSource returns dataSource with a busy timeout of DefaultBusyTimeout for
+// SQLite unless one is already set. Other drivers' data sources are returned
+// as is.
+func DataSource(driverName, dataSource string) string {
+	switch driverName {
+	case "sqlite", "sqlite3":
+	default:
+		return dataSource
+	}
+	if strings.Contains(dataSource, "busy_timeout") {
+		return dataSource
+	}
+
+	sep := "?"
+	if strings.Contains(dataSource, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%s_pragma=busy_timeout(%d)", dataSource, sep, DefaultBusyTimeout.Milliseconds())
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dialect captures the differences between the drivers linked into this
+// package that matter when generating SQL. Placeholders are always written
+// as `?` and rebound by sqlx.
+type dialect struct {
+	// quote is the identifier quote character for table names.
+	quote byte
+	// returning is set for databases that support a RETURNING clause.
+	returning bool
+	// lastInsertID is set for drivers that report generated keys.
+	lastInsertID bool
+	// fetch is set for databases that page with OFFSET ... FETCH instead of
+	// LIMIT and OFFSET.
+	fetch bool
+	// orderedFetch is set when OFFSET ... FETCH requires an ORDER BY.
+	orderedFetch bool
+	// noLimit is the LIMIT used when only an OFFSET is requested on
+	// databases that do not allow OFFSET on its own.
+	noLimit string
+}
+
+func dialectOf(driverName string) dialect {
+	switch driverName {
+	case "postgres", "pgx":
+		return dialect{quote: '"', returning: true}
+	case "sqlite", "sqlite3":
+		// RETURNING is available since SQLite 3.35.
+		return dialect{quote: '"', returning: true, noLimit: "-1"}
+	case "mysql":
+		return dialect{quote: '`', lastInsertID: true, noLimit: "18446744073709551615"}
+	case "sqlserver", "mssql", "azuresql":
+		return dialect{quote: '"', fetch: true, orderedFetch: true}
+	case "oracle":
+		return dialect{quote: '"', fetch: true}
+	}
+	// Snowflake and others: the stored row is read back by key.
+	return dialect{quote: '"'}
+}
+
+func (d dialect) table(name string) string {
+	q := string(d.quote)
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+// pageSQL returns the clause that skips offset rows and returns at most
+// limit rows. ordered reports whether the query has an ORDER BY.
+func (d dialect) pageSQL(ordered bool, limit, offset int64) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	if d.fetch {
+		if !ordered && d.orderedFetch {
+			buf.WriteString(" ORDER BY (SELECT NULL)")
+		}
+		buf.WriteString(" OFFSET " + strconv.FormatInt(offset, 10) + " ROWS")
+		if limit > 0 {
+			buf.WriteString(" FETCH NEXT " + strconv.FormatInt(limit, 10) + " ROWS ONLY")
+		}
+		return buf.String()
+	}
+
+	if limit > 0 {
+		buf.WriteString(" LIMIT " + strconv.FormatInt(limit, 10))
+	} else if d.noLimit != "" {
+		buf.WriteString(" LIMIT " + d.noLimit)
+	}
+	if offset > 0 {
+		buf.WriteString(" OFFSET " + strconv.FormatInt(offset, 10))
+	}
+	return buf.String()
+}
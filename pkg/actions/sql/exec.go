@@ -53,30 +53,30 @@ func ExecAction(
 		}
 
 		if multi, ok := input.([]interface{}); ok {
-			tx, err := db.Begin()
-			if err != nil {
-				return nil, err
-			}
-			defer tx.Rollback() // nolint:errcheck
-
-			for _, item := range multi {
-				if single, ok := item.(map[string]interface{}); ok {
-					args := make([]interface{}, len(config.Args))
-					for i, expr := range config.Args {
-						var err error
-						if args[i], err = expr.Eval(single); err != nil {
-							return nil, err
+			if err = beginFunc(ctx, db, func(ctx context.Context, tx sqlx.ExtContext) error {
+				for _, item := range multi {
+					if single, ok := item.(map[string]interface{}); ok {
+						args := make([]interface{}, len(config.Args))
+						for i, expr := range config.Args {
+							var err error
+							if args[i], err = expr.Eval(single); err != nil {
+								return err
+							}
 						}
-					}
 
-					_, err := tx.Exec(config.SQL, args...)
-					if err != nil {
-						return nil, err
+						_, err := tx.ExecContext(ctx, config.SQL, args...)
+						if err != nil {
+							return err
+						}
+						// if tag.RowsAffected() == 0 {
+						// 	return errors.New("no rows effected")
+						// }
 					}
-					// if tag.RowsAffected() == 0 {
-					// 	return errors.New("no rows effected")
-					// }
 				}
+
+				return nil
+			}); err != nil {
+				return nil, err
 			}
 		} else if single, ok := input.(map[string]interface{}); ok {
 			args := make([]interface{}, len(config.Args))
@@ -87,7 +87,7 @@ func ExecAction(
 				}
 			}
 
-			_, err := db.Exec(config.SQL, args...)
+			_, err := querier(ctx, db).ExecContext(ctx, config.SQL, args...)
 			if err != nil {
 				return nil, err
 			}
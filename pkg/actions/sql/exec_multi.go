@@ -10,7 +10,6 @@ package sql
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/jmoiron/sqlx"
 
@@ -44,71 +43,69 @@ func ExecMultiAction(
 	db *sqlx.DB,
 	config *ExecMultiConfig) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
-		tx, err := db.Begin()
-		if err != nil {
-			return nil, fmt.Errorf("could not start transaction: %w", err)
-		}
-		defer tx.Rollback() // nolint:errcheck
-
-		for _, stmt := range config.Statements {
-			var err error
-			var input interface{} = map[string]interface{}(data)
-			if stmt.Data != nil {
-				input, err = stmt.Data.Eval(data)
-				if err != nil {
-					return nil, err
+		err := beginFunc(ctx, db, func(ctx context.Context, tx sqlx.ExtContext) error {
+			for _, stmt := range config.Statements {
+				var err error
+				var input interface{} = map[string]interface{}(data)
+				if stmt.Data != nil {
+					input, err = stmt.Data.Eval(data)
+					if err != nil {
+						return err
+					}
 				}
-			}
 
-			if multi, ok := input.([]interface{}); ok {
-				for _, item := range multi {
-					if single, ok := item.(map[string]interface{}); ok {
-						single["$root"] = data
-						args := make([]interface{}, len(stmt.Args))
-						for i, expr := range stmt.Args {
-							var err error
-							if args[i], err = expr.Eval(single); err != nil {
+				if multi, ok := input.([]interface{}); ok {
+					for _, item := range multi {
+						if single, ok := item.(map[string]interface{}); ok {
+							single["$root"] = data
+							args := make([]interface{}, len(stmt.Args))
+							for i, expr := range stmt.Args {
+								var err error
+								if args[i], err = expr.Eval(single); err != nil {
+									delete(single, "$root")
+									return err
+								}
+							}
+
+							_, err := tx.ExecContext(ctx, stmt.SQL, args...)
+							if err != nil {
 								delete(single, "$root")
-								return nil, err
+								return err
 							}
+							// if tag.RowsAffected() == 0 {
+							// 	delete(single, "$root")
+							// 	return errors.New("no rows effected")
+							// }
+							delete(single, "$root")
 						}
-
-						_, err := tx.Exec(stmt.SQL, args...)
-						if err != nil {
+					}
+				} else if single, ok := input.(map[string]interface{}); ok {
+					single["$root"] = data
+					args := make([]interface{}, len(stmt.Args))
+					for i, expr := range stmt.Args {
+						var err error
+						if args[i], err = expr.Eval(single); err != nil {
 							delete(single, "$root")
-							return nil, err
+							return err
 						}
-						// if tag.RowsAffected() == 0 {
-						// 	delete(single, "$root")
-						// 	return errors.New("no rows effected")
-						// }
-						delete(single, "$root")
 					}
-				}
-			} else if single, ok := input.(map[string]interface{}); ok {
-				single["$root"] = data
-				args := make([]interface{}, len(stmt.Args))
-				for i, expr := range stmt.Args {
-					var err error
-					if args[i], err = expr.Eval(single); err != nil {
+
+					_, err := tx.ExecContext(ctx, stmt.SQL, args...)
+					if err != nil {
 						delete(single, "$root")
-						return nil, err
+						return err
 					}
-				}
-
-				_, err := tx.Exec(stmt.SQL, args...)
-				if err != nil {
+					// if tag.RowsAffected() == 0 {
+					// 	delete(single, "$root")
+					// 	return errors.New("no rows effected")
+					// }
 					delete(single, "$root")
-					return nil, err
 				}
-				// if tag.RowsAffected() == 0 {
-				// 	delete(single, "$root")
-				// 	return errors.New("no rows effected")
-				// }
-				delete(single, "$root")
 			}
-		}
 
-		return nil, tx.Commit()
+			return nil
+		})
+
+		return nil, err
 	}
 }
@@ -17,6 +17,7 @@ import (
 
 	"github.com/nanobus/nanobus/pkg/actions"
 	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/entity/find"
 	"github.com/nanobus/nanobus/pkg/expr"
 	"github.com/nanobus/nanobus/pkg/resolve"
 	"github.com/nanobus/nanobus/pkg/resource"
@@ -51,6 +52,9 @@ func FindLoader(ctx context.Context, with interface{}, resolver resolve.ResolveA
 	if !ok {
 		return nil, fmt.Errorf("type %q is not found", c.Entity.Type)
 	}
+	if _, _, err := findColumns(&c, t); err != nil {
+		return nil, err
+	}
 
 	return FindAction(&c, t, ns, db), nil
 }
@@ -60,52 +64,90 @@ func FindAction(
 	t *spec.Type,
 	ns *spec.Namespace,
 	db *sqlx.DB) actions.Action {
+	sortable, filterable, columnsErr := findColumns(config, t)
+
 	return func(ctx context.Context, data actions.Data) (_ interface{}, err error) {
+		if columnsErr != nil {
+			return nil, columnsErr
+		}
+
 		var results []map[string]interface{}
 		var total int64
-		offset := int64(0)
-		limit := int64(1000)
+		q := find.New(find.Question)
+		q.Limit = 1000
 
 		if config.Offset != nil {
 			v, err := config.Offset.Eval(data)
 			if err != nil {
 				return nil, err
 			}
-			offset, err = cast.ToInt64E(v)
+			q.Offset, err = cast.ToInt64E(v)
 			if err != nil {
 				return nil, err
 			}
 		}
 
 		if config.Limit != nil {
-			limit, err = expr.EvalAsInt64E(config.Limit, data)
+			q.Limit, err = expr.EvalAsInt64E(config.Limit, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if config.OrderBy != nil {
+			v, err := config.OrderBy.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			if err = q.SetOrderBy(v, sortable); err != nil {
+				return nil, err
+			}
+		}
+
+		if config.Filter != nil {
+			v, err := config.Filter.Eval(data)
+			if err != nil {
+				return nil, err
+			}
+			if err = q.AddFilter(v, filterable); err != nil {
+				return nil, err
+			}
+		}
+
+		if config.Cursor != nil {
+			token, err := expr.EvalAsStringE(config.Cursor, data)
 			if err != nil {
 				return nil, err
 			}
+			if err = q.SetCursor(t, token); err != nil {
+				return nil, err
+			}
+			q.Offset = 0
 		}
 
 		s, _ := stream.SinkFromContext(ctx)
 
 		if s != nil {
-			if err := streamMany(ctx, s, db, t, data, config.Where, config.Preload, offset, limit); err != nil {
+			if err := streamMany(ctx, s, db, t, data, config.Where, config.Preload, q); err != nil {
 				return nil, err
 			}
 
 			return nil, nil
 		} else {
 			if config.Pagination != nil {
-				total, err = getCount(ctx, db, t, data, config.Where)
+				total, err = getCount(ctx, querier(ctx, db), t, data, config.Where, q)
 				if err != nil {
 					return nil, err
 				}
 			}
-			results, err = getMany(ctx, db, t, data, config.Where, config.Preload, offset, limit)
+			results, err = getMany(ctx, querier(ctx, db), t, data, config.Where, config.Preload, q)
 			if err != nil {
 				return nil, err
 			}
 
 			if config.Pagination != nil {
 				p := config.Pagination
+				offset, limit := q.Offset, q.Limit
 				count := int64(len(results))
 				wrapper := map[string]interface{}{
 					p.Items: results,
@@ -128,6 +170,11 @@ func FindAction(
 				if p.Limit != nil {
 					wrapper[*p.Limit] = config.Limit
 				}
+				if p.Cursor != nil && config.Cursor != nil && count > 0 && count == limit {
+					if wrapper[*p.Cursor], err = q.NextCursor(results[count-1]); err != nil {
+						return nil, err
+					}
+				}
 
 				return wrapper, nil
 			}
@@ -136,3 +183,17 @@ func FindAction(
 		}
 	}
 }
+
+// findColumns returns the columns that can be sorted by and filtered.
+func findColumns(config *FindConfig, t *spec.Type) (sortable, filterable map[string]string, err error) {
+	if sortable, err = find.Columns(t, config.Sortable); err != nil {
+		return nil, nil, err
+	}
+	if filterable, err = find.Columns(t, config.Filterable); err != nil {
+		return nil, nil, err
+	}
+	if config.Cursor != nil && find.KeyField(t) == "" {
+		return nil, nil, fmt.Errorf("cursor pagination requires %s to have a key field", t.Name)
+	}
+	return sortable, filterable, nil
+}
@@ -59,7 +59,7 @@ func FindOneAction(
 	ns *spec.Namespace,
 	db *sqlx.DB) actions.Action {
 	return func(ctx context.Context, data actions.Data) (interface{}, error) {
-		result, err := findOne(ctx, db, t, data, config.Where, config.Preload)
+		result, err := findOne(ctx, querier(ctx, db), t, data, config.Where, config.Preload)
 		if err != nil {
 			return nil, err
 		}
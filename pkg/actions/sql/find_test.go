@@ -0,0 +1,115 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/sql"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+)
+
+func valueExpr(t *testing.T, value string) *expr.ValueExpr {
+	var ve expr.ValueExpr
+	require.NoError(t, ve.FromString(value))
+	return &ve
+}
+
+func TestFindCursor(t *testing.T) {
+	db, mock, ns := newDB(t, "postgres")
+	cursorField := "next"
+	action := sql.FindAction(&sql.FindConfig{
+		Resource: "db",
+		OrderBy:  valueExpr(t, `input.sort`),
+		Filter:   valueExpr(t, `input.filter`),
+		Cursor:   valueExpr(t, `input.cursor`),
+		Limit:    valueExpr(t, `2`),
+		Pagination: &sql.Pagination{
+			Items:  "items",
+			Cursor: &cursorField,
+		},
+	}, entityType(t, ns, "Order"), ns, db)
+
+	columns := []string{"id", "customer_id", "version", "1"}
+	mock.ExpectQuery(`SELECT count(1) FROM "orders" WHERE customer_id IN ($1, $2) AND version >= $3`).
+		WithArgs("c1", "c2", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT id, customer_id, version, 1 FROM "orders" WHERE customer_id IN ($1, $2) AND version >= $3 ORDER BY customer_id DESC, id LIMIT 2`).
+		WithArgs("c1", "c2", 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("o1", "c2", 2, 1).
+			AddRow("o2", "c1", 3, 1))
+
+	input := map[string]interface{}{
+		"sort": "-customerId",
+		"filter": map[string]interface{}{
+			"customerId[in]": "c1,c2",
+			"version[gte]":   2,
+			"id":             "",
+		},
+		"cursor": "",
+	}
+	output, err := action(context.Background(), actions.Data{"input": input})
+	require.NoError(t, err)
+	page := output.(map[string]interface{})
+	assert.Len(t, page["items"], 2)
+	next, ok := page["next"].(string)
+	require.True(t, ok)
+
+	mock.ExpectQuery(`SELECT count(1) FROM "orders" WHERE customer_id IN ($1, $2) AND version >= $3`).
+		WithArgs("c1", "c2", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT id, customer_id, version, 1 FROM "orders" WHERE customer_id IN ($1, $2) AND version >= $3 AND ((customer_id < $4) OR (customer_id = $5 AND id > $6)) ORDER BY customer_id DESC, id LIMIT 2`).
+		WithArgs("c1", "c2", 2, "c1", "c1", "o2").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("o3", "c1", 5, 1))
+
+	input["cursor"] = next
+	output, err = action(context.Background(), actions.Data{"input": input})
+	require.NoError(t, err)
+	page = output.(map[string]interface{})
+	assert.Len(t, page["items"], 1)
+	assert.NotContains(t, page, "next")
+
+	// The cursor is bound to the sort order.
+	input["sort"] = "customerId"
+	_, err = action(context.Background(), actions.Data{"input": input})
+	var errz *errorz.Error
+	require.ErrorAs(t, err, &errz)
+	assert.Equal(t, errorz.InvalidArgument, errz.Code)
+}
+
+func TestFindRejectsUnknownFields(t *testing.T) {
+	db, _, ns := newDB(t, "postgres")
+	action := sql.FindAction(&sql.FindConfig{
+		Resource: "db",
+		OrderBy:  valueExpr(t, `input.sort`),
+		Filter:   valueExpr(t, `input.filter`),
+		Sortable: []string{"customerId"},
+	}, entityType(t, ns, "Order"), ns, db)
+
+	for _, input := range []map[string]interface{}{
+		{"sort": "version"},
+		{"sort": "customerId; DROP TABLE orders"},
+		{"filter": map[string]interface{}{"items": "x"}},
+		{"filter": map[string]interface{}{"version[regex]": "x"}},
+	} {
+		_, err := action(context.Background(), actions.Data{"input": input})
+		var errz *errorz.Error
+		require.ErrorAs(t, err, &errz, "%v", input)
+		assert.Equal(t, errorz.InvalidArgument, errz.Code)
+	}
+}
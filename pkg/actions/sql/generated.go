@@ -93,6 +93,22 @@ type FindConfig struct {
 	Offset *expr.ValueExpr `json:"offset,omitempty" yaml:"offset,omitempty" msgpack:"offset,omitempty" mapstructure:"offset"`
 	// Limit is the query limit.
 	Limit *expr.ValueExpr `json:"limit,omitempty" yaml:"limit,omitempty" msgpack:"limit,omitempty" mapstructure:"limit"`
+	// OrderBy evaluates to the sort order as a comma-separated string or a list of
+	// field names, each optionally prefixed with `-` for descending order.
+	OrderBy *expr.ValueExpr `json:"orderBy,omitempty" yaml:"orderBy,omitempty" msgpack:"orderBy,omitempty" mapstructure:"orderBy"`
+	// Sortable lists the fields that can be sorted by. Defaults to all fields.
+	Sortable []string `json:"sortable,omitempty" yaml:"sortable,omitempty" msgpack:"sortable,omitempty" mapstructure:"sortable" validate:"dive"`
+	// Filter evaluates to a map of field filters, typically from query parameters.
+	// Keys are field names, optionally followed by an operator in brackets, such as
+	// `total[gte]`. The operators are `eq`, `ne`, `gt`, `gte`, `lt`, `lte`, `like`
+	// and `in`, which accepts a list or a comma-separated string. A value can also
+	// be a map of operators to values. Empty values are ignored.
+	Filter *expr.ValueExpr `json:"filter,omitempty" yaml:"filter,omitempty" msgpack:"filter,omitempty" mapstructure:"filter"`
+	// Filterable lists the fields that can be filtered. Defaults to all fields.
+	Filterable []string `json:"filterable,omitempty" yaml:"filterable,omitempty" msgpack:"filterable,omitempty" mapstructure:"filterable" validate:"dive"`
+	// Cursor evaluates to the cursor token returned for the previous page. Setting
+	// it switches from offset to keyset pagination, which requires a key field.
+	Cursor *expr.ValueExpr `json:"cursor,omitempty" yaml:"cursor,omitempty" msgpack:"cursor,omitempty" mapstructure:"cursor"`
 }
 
 func Find() (string, actions.Loader) {
@@ -107,7 +123,9 @@ type Pagination struct {
 	Limit     *string `json:"limit,omitempty" yaml:"limit,omitempty" msgpack:"limit,omitempty" mapstructure:"limit"`
 	Count     *string `json:"count,omitempty" yaml:"count,omitempty" msgpack:"count,omitempty" mapstructure:"count"`
 	Total     *string `json:"total,omitempty" yaml:"total,omitempty" msgpack:"total,omitempty" mapstructure:"total"`
-	Items     string  `json:"items" yaml:"items" msgpack:"items" mapstructure:"items" validate:"required"`
+	// Cursor is the field for the next page's cursor, set when more may follow.
+	Cursor *string `json:"cursor,omitempty" yaml:"cursor,omitempty" msgpack:"cursor,omitempty" mapstructure:"cursor"`
+	Items  string  `json:"items" yaml:"items" msgpack:"items" mapstructure:"items" validate:"required"`
 }
 
 // TODO
@@ -128,6 +146,77 @@ func Load() (string, actions.Loader) {
 	return "@sql/load", LoadLoader
 }
 
+// InsertConfig inserts an entity along with the related entities given for its
+// hasOne and hasMany fields and returns the stored row.
+type InsertConfig struct {
+	// Resource is the name of the connection resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The entity type to insert.
+	Entity entity.Entity `json:"entity" yaml:"entity" msgpack:"entity" mapstructure:"entity" validate:"required"`
+	// Data is the entity or list of entities to insert. Defaults to the input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+}
+
+func Insert() (string, actions.Loader) {
+	return "@sql/insert", InsertLoader
+}
+
+// UpdateConfig updates the fields of an entity that are present in the data. If
+// the entity has a field annotated with @version and the data contains it, the
+// update only succeeds if the stored version matches.
+type UpdateConfig struct {
+	// Resource is the name of the connection resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The entity type to update.
+	Entity entity.Entity `json:"entity" yaml:"entity" msgpack:"entity" mapstructure:"entity" validate:"required"`
+	// Data is the entity or list of entities to update. Defaults to the input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// NotFoundError is the error to return if the key is not found.
+	NotFoundError string `json:"notFoundError" yaml:"notFoundError" msgpack:"notFoundError" mapstructure:"notFoundError" validate:"required"`
+	// ConflictError is the error to return if the version does not match.
+	ConflictError string `json:"conflictError" yaml:"conflictError" msgpack:"conflictError" mapstructure:"conflictError" validate:"required"`
+}
+
+func Update() (string, actions.Loader) {
+	return "@sql/update", UpdateLoader
+}
+
+// UpsertConfig updates an entity if it exists and otherwise inserts it.
+type UpsertConfig struct {
+	// Resource is the name of the connection resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The entity type to upsert.
+	Entity entity.Entity `json:"entity" yaml:"entity" msgpack:"entity" mapstructure:"entity" validate:"required"`
+	// Data is the entity or list of entities to upsert. Defaults to the input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// ConflictError is the error to return if the version does not match.
+	ConflictError string `json:"conflictError" yaml:"conflictError" msgpack:"conflictError" mapstructure:"conflictError" validate:"required"`
+}
+
+func Upsert() (string, actions.Loader) {
+	return "@sql/upsert", UpsertLoader
+}
+
+// DeleteConfig deletes an entity by key and returns the deleted row.
+type DeleteConfig struct {
+	// Resource is the name of the connection resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The entity type to delete.
+	Entity entity.Entity `json:"entity" yaml:"entity" msgpack:"entity" mapstructure:"entity" validate:"required"`
+	// Key is the entity identifier expression.
+	Key *expr.ValueExpr `json:"key" yaml:"key" msgpack:"key" mapstructure:"key" validate:"required"`
+	// Version is the expected version of the entity, if any.
+	Version *expr.ValueExpr `json:"version,omitempty" yaml:"version,omitempty" msgpack:"version,omitempty" mapstructure:"version"`
+	// NotFoundError is the error to return if the key is not found.
+	NotFoundError string `json:"notFoundError" yaml:"notFoundError" msgpack:"notFoundError" mapstructure:"notFoundError" validate:"required"`
+	// ConflictError is the error to return if the version does not match.
+	ConflictError string `json:"conflictError" yaml:"conflictError" msgpack:"conflictError" mapstructure:"conflictError" validate:"required"`
+}
+
+func Delete() (string, actions.Loader) {
+	return "@sql/delete", DeleteLoader
+}
+
 // TODO
 type QueryOneConfig struct {
 	// Resource is the name of the connection resource to use.
@@ -157,3 +246,28 @@ type QueryConfig struct {
 func Query() (string, actions.Loader) {
 	return "@sql/query", QueryLoader
 }
+
+// OutboxConfig writes an event to the outbox table, in the current transaction if
+// there is one. The `nanobus.transport.outbox/v1` transport publishes it once the
+// transaction commits.
+type OutboxConfig struct {
+	// Resource is the name of the connection resource to use.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// Table is the outbox table.
+	Table string `json:"table" yaml:"table" msgpack:"table" mapstructure:"table" validate:"required"`
+	// Topic is the topic the relay publishes the event to.
+	Topic *expr.ValueExpr `json:"topic" yaml:"topic" msgpack:"topic" mapstructure:"topic" validate:"required"`
+	// Key orders events: events with the same key are published in the order they
+	// were written.
+	Key *expr.ValueExpr `json:"key,omitempty" yaml:"key,omitempty" msgpack:"key,omitempty" mapstructure:"key"`
+	// Type is the event type, if any.
+	Type *expr.ValueExpr `json:"type,omitempty" yaml:"type,omitempty" msgpack:"type,omitempty" mapstructure:"type"`
+	// Data is the event payload. Defaults to the input.
+	Data *expr.DataExpr `json:"data,omitempty" yaml:"data,omitempty" msgpack:"data,omitempty" mapstructure:"data"`
+	// Metadata is passed to the publish action with the event.
+	Metadata *expr.DataExpr `json:"metadata,omitempty" yaml:"metadata,omitempty" msgpack:"metadata,omitempty" mapstructure:"metadata"`
+}
+
+func Outbox() (string, actions.Loader) {
+	return "@sql/outbox", OutboxLoader
+}
@@ -71,7 +71,7 @@ func LoadAction(
 			return nil, err
 		}
 
-		result, err := findById(ctx, db, t, keyValue, config.Preload)
+		result, err := findById(ctx, querier(ctx, db), t, keyValue, config.Preload)
 		if err != nil {
 			return nil, err
 		}
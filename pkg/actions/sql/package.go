@@ -21,4 +21,9 @@ var All = []actions.NamedLoader{
 	QueryOne,
 	Exec,
 	ExecMulti,
+	Insert,
+	Update,
+	Upsert,
+	Delete,
+	Outbox,
 }
@@ -57,7 +57,7 @@ func QueryAction(
 			}
 		}
 
-		rows, err := db.QueryxContext(ctx, config.SQL, args...)
+		rows, err := querier(ctx, db).QueryxContext(ctx, config.SQL, args...)
 		if err != nil {
 			return nil, err
 		}
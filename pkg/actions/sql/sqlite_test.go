@@ -0,0 +1,153 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/sql"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	dbschema "github.com/nanobus/nanobus/pkg/schema"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/spec/apex"
+)
+
+// newSQLite returns a database file with tables for the test schema.
+func newSQLite(t *testing.T) (*sqlx.DB, *spec.Namespace) {
+	db, err := sqlx.Connect("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ns, err := apex.Parse([]byte(schema))
+	require.NoError(t, err)
+	tables, err := dbschema.FromSpec(spec.Namespaces{ns.Name: ns})
+	require.NoError(t, err)
+	for _, stmt := range dbschema.Diff(dbschema.SQLite{}, nil, tables).Up {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	return db, ns
+}
+
+func TestSQLiteEntities(t *testing.T) {
+	ctx := context.Background()
+	db, ns := newSQLite(t)
+	order := entityType(t, ns, "Order")
+
+	insert := sql.InsertAction(&sql.InsertConfig{Resource: "db"}, order, db)
+	for _, data := range []actions.Data{
+		{"id": "o1", "customerId": "c1", "items": []interface{}{
+			map[string]interface{}{"sku": "a"},
+			map[string]interface{}{"sku": "b"},
+		}},
+		{"id": "o2", "customerId": "c2"},
+		{"id": "o3", "customerId": "c1"},
+	} {
+		_, err := insert(ctx, data)
+		require.NoError(t, err)
+	}
+
+	load := sql.LoadAction(&sql.LoadConfig{
+		Resource:      "db",
+		Key:           valueExpr(t, `input.id`),
+		Preload:       []sql.Preload{{Field: "items"}},
+		NotFoundError: "not_found",
+	}, order, ns, db)
+	output, err := load(ctx, actions.Data{"input": map[string]interface{}{"id": "o1"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c1",
+		"version":    int64(1),
+		"items": []map[string]interface{}{
+			{"id": int64(1), "orderId": "o1", "sku": "a"},
+			{"id": int64(2), "orderId": "o1", "sku": "b"},
+		},
+	}, output)
+
+	find := sql.FindAction(&sql.FindConfig{
+		Resource: "db",
+		OrderBy:  valueExpr(t, `"-id"`),
+		Filter:   valueExpr(t, `input.filter`),
+		Offset:   valueExpr(t, `1`),
+	}, order, ns, db)
+	output, err = find(ctx, actions.Data{"input": map[string]interface{}{
+		"filter": map[string]interface{}{"customerId": "c1"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "o1", "customerId": "c1", "version": int64(1), "items": int64(1)},
+	}, output)
+
+	update := sql.UpdateAction(&sql.UpdateConfig{
+		Resource:      "db",
+		NotFoundError: "not_found",
+		ConflictError: "aborted",
+	}, order, db)
+	output, err = update(ctx, actions.Data{"id": "o2", "customerId": "c3", "version": 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "o2", "customerId": "c3", "version": int64(2)}, output)
+	_, err = update(ctx, actions.Data{"id": "o2", "customerId": "c4", "version": 1})
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "aborted", te.Template)
+
+	remove := sql.DeleteAction(&sql.DeleteConfig{
+		Resource:      "db",
+		Key:           valueExpr(t, `input.id`),
+		NotFoundError: "not_found",
+		ConflictError: "aborted",
+	}, order, db)
+	_, err = remove(ctx, actions.Data{"input": map[string]interface{}{"id": "o3"}})
+	require.NoError(t, err)
+	_, err = load(ctx, actions.Data{"input": map[string]interface{}{"id": "o3"}})
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+}
+
+// TestSQLiteKeyColumn checks that key fields without @column use the snake
+// case column that the schema migrations create, like every other field.
+func TestSQLiteKeyColumn(t *testing.T) {
+	ctx := context.Background()
+	db, ns := newSQLite(t)
+	customer := entityType(t, ns, "Customer")
+
+	insert := sql.InsertAction(&sql.InsertConfig{Resource: "db"}, customer, db)
+	_, err := insert(ctx, actions.Data{"customerId": "c1", "name": "Ada"})
+	require.NoError(t, err)
+
+	load := sql.LoadAction(&sql.LoadConfig{
+		Resource: "db",
+		Key:      valueExpr(t, `input.id`),
+	}, customer, ns, db)
+	output, err := load(ctx, actions.Data{"input": map[string]interface{}{"id": "c1"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"customerId": "c1", "name": "Ada"}, output)
+
+	update := sql.UpdateAction(&sql.UpdateConfig{Resource: "db"}, customer, db)
+	output, err = update(ctx, actions.Data{"customerId": "c1", "name": "Grace"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"customerId": "c1", "name": "Grace"}, output)
+}
+
+func TestDataSource(t *testing.T) {
+	assert.Equal(t, "file:test.db?_pragma=busy_timeout(5000)", sql.DataSource("sqlite", "file:test.db"))
+	assert.Equal(t, "file:test.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)",
+		sql.DataSource("sqlite", "file:test.db?_pragma=foreign_keys(1)"))
+	assert.Equal(t, "file:test.db?_pragma=busy_timeout(100)", sql.DataSource("sqlite", "file:test.db?_pragma=busy_timeout(100)"))
+	assert.Equal(t, "postgres://localhost/db", sql.DataSource("postgres", "postgres://localhost/db"))
+}
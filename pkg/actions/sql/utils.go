@@ -21,9 +21,11 @@ import (
 	"github.com/jackc/pgtype"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/nanobus/nanobus/pkg/entity/find"
 	"github.com/nanobus/nanobus/pkg/logger"
 	"github.com/nanobus/nanobus/pkg/spec"
 	"github.com/nanobus/nanobus/pkg/stream"
+	"github.com/nanobus/nanobus/pkg/transaction"
 )
 
 func annotationValue(a spec.Annotator, annotation, argument, defaultValue string) string {
@@ -35,10 +37,10 @@ func annotationValue(a spec.Annotator, annotation, argument, defaultValue string
 	return defaultValue
 }
 
-func findById(ctx context.Context, db *sqlx.DB, t *spec.Type, idValue interface{}, toPreload []Preload) (map[string]interface{}, error) {
-	idColumn := keyColumn(t)
-	sql := generateTableSQL(t) + " WHERE " + idColumn + " = $1"
-	rows, err := db.QueryxContext(ctx, sql, idValue)
+func findById(ctx context.Context, db sqlx.ExtContext, t *spec.Type, idValue interface{}, toPreload []Preload) (map[string]interface{}, error) {
+	idColumn := find.KeyColumn(t)
+	sql := generateTableSQL(dialectOf(db.DriverName()), t) + " WHERE " + idColumn + " = ?"
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), idValue)
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +53,7 @@ func findById(ctx context.Context, db *sqlx.DB, t *spec.Type, idValue interface{
 			return nil, err
 		}
 		for i, v := range values {
-			v = normalizeValue(v)
-			record[t.Fields[i].Name] = v
+			record[t.Fields[i].Name] = normalizeField(t.Fields[i], v)
 		}
 
 		rows.Close()
@@ -71,9 +72,9 @@ func findById(ctx context.Context, db *sqlx.DB, t *spec.Type, idValue interface{
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, db, ex.Type.ItemType.Type,
-						key.ValueString()+" = $1", []interface{}{record[keyName]},
+						key.ValueString()+" = ?", []interface{}{record[keyName]},
 						preload.Preload)
 					if err != nil {
 						return nil, err
@@ -90,11 +91,10 @@ func findById(ctx context.Context, db *sqlx.DB, t *spec.Type, idValue interface{
 	return nil, nil
 }
 
-func findOne(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload) (map[string]interface{}, error) {
-	sql := generateTableSQL(t)
+func findOne(ctx context.Context, db sqlx.ExtContext, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload) (map[string]interface{}, error) {
+	sql := generateTableSQL(dialectOf(db.DriverName()), t)
 	args := []interface{}{}
 	if len(where) > 0 {
-		dollarIndex := 1
 		for i, part := range where {
 			val, err := part.Value.Eval(input)
 			if err != nil {
@@ -108,17 +108,12 @@ func findOne(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 			} else {
 				sql += " WHERE "
 			}
-			query := part.Query
-			for strings.Contains(query, "?") {
-				query = strings.Replace(query, "?", fmt.Sprintf("$%d", dollarIndex), 1)
-				dollarIndex++
-			}
-			sql += query
+			sql += part.Query
 			args = append(args, val)
 		}
 	}
 
-	rows, err := db.QueryxContext(ctx, sql, args...)
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +126,7 @@ func findOne(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 			return nil, err
 		}
 		for i, v := range values {
-			v = normalizeValue(v)
-			record[t.Fields[i].Name] = v
+			record[t.Fields[i].Name] = normalizeField(t.Fields[i], v)
 		}
 
 		rows.Close()
@@ -151,9 +145,9 @@ func findOne(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, db, ex.Type.ItemType.Type,
-						key.ValueString()+" = $1", []interface{}{record[keyName]},
+						key.ValueString()+" = ?", []interface{}{record[keyName]},
 						preload.Preload)
 					if err != nil {
 						return nil, err
@@ -170,14 +164,14 @@ func findOne(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 	return nil, nil
 }
 
-func join(ctx context.Context, db *sqlx.DB, t *spec.Type, where string, args []interface{}, toPreload []Preload) ([]map[string]interface{}, error) {
-	sql := generateTableSQL(t)
+func join(ctx context.Context, db sqlx.ExtContext, t *spec.Type, where string, args []interface{}, toPreload []Preload) ([]map[string]interface{}, error) {
+	sql := generateTableSQL(dialectOf(db.DriverName()), t)
 	if len(where) > 0 {
 		sql += " WHERE "
 		sql += where
 	}
 
-	rows, err := db.QueryxContext(ctx, sql, args...)
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -192,8 +186,7 @@ func join(ctx context.Context, db *sqlx.DB, t *spec.Type, where string, args []i
 			return nil, err
 		}
 		for i, v := range values {
-			v = normalizeValue(v)
-			record[t.Fields[i].Name] = v
+			record[t.Fields[i].Name] = normalizeField(t.Fields[i], v)
 		}
 
 		results = append(results, record)
@@ -217,9 +210,9 @@ func join(ctx context.Context, db *sqlx.DB, t *spec.Type, where string, args []i
 					}
 				} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 					if key, ok := hasMany.Argument("key"); ok {
-						keyName := keyField(t)
+						keyName := find.KeyField(t)
 						res, err = join(ctx, db, ex.Type.ItemType.Type,
-							key.ValueString()+" = $1", []interface{}{record[keyName]},
+							key.ValueString()+" = ?", []interface{}{record[keyName]},
 							preload.Preload)
 						if err != nil {
 							return nil, err
@@ -235,13 +228,13 @@ func join(ctx context.Context, db *sqlx.DB, t *spec.Type, where string, args []i
 	return results, nil
 }
 
-func getMany(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, offset, limit int64) ([]map[string]interface{}, error) {
-	sql, args, err := generateSQL(t, where, input, offset, limit)
+func getMany(ctx context.Context, db sqlx.ExtContext, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, q *find.Query) ([]map[string]interface{}, error) {
+	sql, args, err := generateSQL(dialectOf(db.DriverName()), t, where, input, q)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := db.QueryxContext(ctx, sql, args...)
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -256,8 +249,7 @@ func getMany(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 			return nil, err
 		}
 		for i, v := range values {
-			v = normalizeValue(v)
-			record[t.Fields[i].Name] = v
+			record[t.Fields[i].Name] = normalizeField(t.Fields[i], v)
 		}
 
 		results = append(results, record)
@@ -272,13 +264,30 @@ func getMany(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]in
 	return results, nil
 }
 
-func streamMany(ctx context.Context, s stream.Sink, db *sqlx.DB, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, offset, limit int64) error {
-	sql, args, err := generateSQL(t, where, input, offset, limit)
+func streamMany(ctx context.Context, s stream.Sink, db *sqlx.DB, t *spec.Type, input map[string]interface{}, where []Where, toPreload []Preload, q *find.Query) error {
+	if tx, ok := transaction.SQL(ctx, db); ok {
+		// A transaction has a single connection, which cannot preload while
+		// the rows are still being read.
+		results, err := getMany(ctx, tx, t, input, where, toPreload, q)
+		if err != nil {
+			s.Error(err)
+			return err
+		}
+		for _, r := range results {
+			if err = s.Next(r, nil); err != nil {
+				s.Error(err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	sql, args, err := generateSQL(dialectOf(db.DriverName()), t, where, input, q)
 	if err != nil {
 		return err
 	}
 
-	rows, err := db.QueryxContext(ctx, sql, args...)
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), args...)
 	if err != nil {
 		return err
 	}
@@ -313,8 +322,7 @@ func streamMany(ctx context.Context, s stream.Sink, db *sqlx.DB, t *spec.Type, i
 			return err
 		}
 		for i, v := range values {
-			v = normalizeValue(v)
-			record[t.Fields[i].Name] = v
+			record[t.Fields[i].Name] = normalizeField(t.Fields[i], v)
 		}
 
 		results = append(results, record)
@@ -335,7 +343,7 @@ func streamMany(ctx context.Context, s stream.Sink, db *sqlx.DB, t *spec.Type, i
 	return nil
 }
 
-func preload(ctx context.Context, db *sqlx.DB, t *spec.Type, results []map[string]interface{}, toPreload []Preload) error {
+func preload(ctx context.Context, db sqlx.ExtContext, t *spec.Type, results []map[string]interface{}, toPreload []Preload) error {
 	if len(toPreload) == 0 {
 		return nil
 	}
@@ -356,9 +364,9 @@ func preload(ctx context.Context, db *sqlx.DB, t *spec.Type, results []map[strin
 				}
 			} else if hasMany, ok := ex.Annotation("hasMany"); ok {
 				if key, ok := hasMany.Argument("key"); ok {
-					keyName := keyField(t)
+					keyName := find.KeyField(t)
 					res, err = join(ctx, db, ex.Type.ItemType.Type,
-						key.ValueString()+" = $1", []interface{}{record[keyName]},
+						key.ValueString()+" = ?", []interface{}{record[keyName]},
 						preload.Preload)
 					if err != nil {
 						return err
@@ -373,72 +381,46 @@ func preload(ctx context.Context, db *sqlx.DB, t *spec.Type, results []map[strin
 	return nil
 }
 
-func generateSQL(t *spec.Type, where []Where, input map[string]interface{}, offset, limit int64) (string, []interface{}, error) {
-	sql := generateTableSQL(t)
-	var args []interface{}
-	if len(where) > 0 {
-		dollarIndex := 1
-		for i, part := range where {
-			val, err := part.Value.Eval(input)
-			if err != nil {
-				return "", nil, err
-			}
-			if isNil(val) {
-				continue
-			}
-			if i > 0 {
-				sql += " AND "
-			} else {
-				sql += " WHERE "
-			}
-			query := part.Query
-			for strings.Contains(query, "?") {
-				query = strings.Replace(query, "?", fmt.Sprintf("$%d", dollarIndex), 1)
-				dollarIndex++
-			}
-			sql += query
-			args = append(args, val)
-		}
-	}
-	if offset > 0 {
-		sql += " OFFSET " + strconv.FormatInt(offset, 10)
-	}
-	if limit > 0 {
-		sql += " LIMIT " + strconv.FormatInt(limit, 10)
+func generateSQL(d dialect, t *spec.Type, where []Where, input map[string]interface{}, q *find.Query) (string, []interface{}, error) {
+	conditions, args, err := whereConditions(where, input)
+	if err != nil {
+		return "", nil, err
 	}
+	whereSQL, args := q.WhereSQL(conditions, args, true)
+	sql := generateTableSQL(d, t) + whereSQL + q.OrderBySQL()
+	sql += d.pageSQL(q.Ordered(), q.Limit, q.Offset)
 
 	return sql, args, nil
 }
 
-func getCount(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]interface{}, where []Where) (int64, error) {
-	sql := generateCountSQL(t)
+// whereConditions returns the configured where parts that have a value.
+func whereConditions(where []Where, input map[string]interface{}) ([]string, []interface{}, error) {
+	var conditions []string
 	var args []interface{}
-	if len(where) > 0 {
-		dollarIndex := 1
-		for i, part := range where {
-			val, err := part.Value.Eval(input)
-			if err != nil {
-				return 0, err
-			}
-			if isNil(val) {
-				continue
-			}
-			if i > 0 {
-				sql += " AND "
-			} else {
-				sql += " WHERE "
-			}
-			query := part.Query
-			for strings.Contains(query, "?") {
-				query = strings.Replace(query, "?", fmt.Sprintf("$%d", dollarIndex), 1)
-				dollarIndex++
-			}
-			sql += query
-			args = append(args, val)
+	for _, part := range where {
+		val, err := part.Value.Eval(input)
+		if err != nil {
+			return nil, nil, err
 		}
+		if isNil(val) {
+			continue
+		}
+		conditions = append(conditions, part.Query)
+		args = append(args, val)
 	}
 
-	rows, err := db.QueryxContext(ctx, sql, args...)
+	return conditions, args, nil
+}
+
+func getCount(ctx context.Context, db sqlx.ExtContext, t *spec.Type, input map[string]interface{}, where []Where, q *find.Query) (int64, error) {
+	conditions, args, err := whereConditions(where, input)
+	if err != nil {
+		return 0, err
+	}
+	whereSQL, args := q.WhereSQL(conditions, args, false)
+	sql := generateCountSQL(dialectOf(db.DriverName()), t) + whereSQL
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(sql), args...)
 	if err != nil {
 		return 0, err
 	}
@@ -451,28 +433,7 @@ func getCount(ctx context.Context, db *sqlx.DB, t *spec.Type, input map[string]i
 	return count, err
 }
 
-func keyField(t *spec.Type) string {
-	for _, f := range t.Fields {
-		if _, ok := f.Annotation("key"); ok {
-			return f.Name
-		}
-	}
-	return ""
-}
-
-func keyColumn(t *spec.Type) string {
-	if _, ok := t.Annotation("primaryKey"); ok {
-		return annotationValue(t, "primaryKey", "name", "")
-	}
-	for _, f := range t.Fields {
-		if _, ok := f.Annotation("key"); ok {
-			return annotationValue(t, "column", "name", f.Name)
-		}
-	}
-	return ""
-}
-
-func generateTableSQL(t *spec.Type) string {
+func generateTableSQL(d dialect, t *spec.Type) string {
 	var buf strings.Builder
 
 	buf.WriteString("SELECT ")
@@ -495,24 +456,13 @@ func generateTableSQL(t *spec.Type) string {
 		buf.WriteString(column)
 	}
 	buf.WriteString(" FROM ")
-	table := annotationValue(t, "entity", "table", t.Name)
-	buf.WriteByte('"')
-	buf.WriteString(table)
-	buf.WriteByte('"')
+	buf.WriteString(d.table(annotationValue(t, "entity", "table", t.Name)))
 
 	return buf.String()
 }
 
-func generateCountSQL(t *spec.Type) string {
-	var buf strings.Builder
-
-	buf.WriteString("SELECT count(1) FROM ")
-	table := annotationValue(t, "entity", "table", t.Name)
-	buf.WriteByte('"')
-	buf.WriteString(table)
-	buf.WriteByte('"')
-
-	return buf.String()
+func generateCountSQL(d dialect, t *spec.Type) string {
+	return "SELECT count(1) FROM " + d.table(annotationValue(t, "entity", "table", t.Name))
 }
 
 func isNil(val interface{}) bool {
@@ -540,3 +490,38 @@ func normalizeValue(v interface{}) interface{} {
 	}
 	return v
 }
+
+// normalizeField converts values that drivers return in a storage format to
+// the field's type. MySQL returns text and decimals as bytes and SQLite
+// stores booleans as integers.
+func normalizeField(f *spec.Field, v interface{}) interface{} {
+	v = normalizeValue(v)
+	kind := f.Type.Kind
+	if kind == spec.KindOptional {
+		kind = f.Type.OptionalType.Kind
+	}
+
+	if b, ok := v.([]byte); ok && kind != spec.KindBytes {
+		v = string(b)
+	}
+	switch kind {
+	case spec.KindBool:
+		if n, ok := v.(int64); ok {
+			return n != 0
+		}
+	case spec.KindI64, spec.KindI32, spec.KindI16, spec.KindI8,
+		spec.KindU64, spec.KindU32, spec.KindU16, spec.KindU8:
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		}
+	case spec.KindF64, spec.KindF32:
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseFloat(s, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return v
+}
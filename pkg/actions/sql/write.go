@@ -0,0 +1,551 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/entity/find"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/spec"
+)
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("version conflict")
+)
+
+// keyError reports the entity that was not found or whose version did not
+// match.
+type keyError struct {
+	err error
+	t   *spec.Type
+	key interface{}
+}
+
+func (e *keyError) Error() string {
+	return fmt.Sprintf("%s %v: %v", e.t.Name, e.key, e.err)
+}
+
+func (e *keyError) Unwrap() error {
+	return e.err
+}
+
+// translateWriteError converts not found and version conflict errors to the
+// configured errors.
+func translateWriteError(err error, res resource.Ref, notFoundError, conflictError string) error {
+	var ke *keyError
+	if !errors.As(err, &ke) {
+		return err
+	}
+	template := notFoundError
+	if errors.Is(ke.err, errConflict) {
+		template = conflictError
+	}
+	return errorz.Return(template, errorz.Metadata{
+		"resource": res,
+		"type":     ke.t.Name,
+		"key":      ke.key,
+	})
+}
+
+// writeEach evaluates the data expression and calls f within a transaction
+// for the resulting entity or each entity in the resulting list.
+func writeEach(ctx context.Context, db *sqlx.DB, data actions.Data, dataExpr *expr.DataExpr, f func(ctx context.Context, w *writer, item map[string]interface{}) (map[string]interface{}, error)) (interface{}, error) {
+	var err error
+	var input interface{} = map[string]interface{}(data)
+	if dataExpr != nil {
+		if input, err = dataExpr.Eval(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var output interface{}
+	err = beginFunc(ctx, db, func(ctx context.Context, tx sqlx.ExtContext) error {
+		w := newWriter(tx)
+		switch v := input.(type) {
+		case map[string]interface{}:
+			stored, err := f(ctx, w, v)
+			output = stored
+			return err
+		case []interface{}:
+			results := make([]interface{}, len(v))
+			for i, item := range v {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("expected an object but got %T", item)
+				}
+				if results[i], err = f(ctx, w, m); err != nil {
+					return err
+				}
+			}
+			output = results
+			return nil
+		}
+		return fmt.Errorf("expected an object or a list but got %T", input)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// entityTable describes how an entity type maps to a table.
+type entityTable struct {
+	t    *spec.Type
+	name string
+	// columns are the scalar and hasOne columns in field order.
+	columns []entityColumn
+	key     *entityColumn
+	version *entityColumn
+	hasMany []entityRelation
+}
+
+type entityColumn struct {
+	field *spec.Field
+	name  string
+	// hasOne is the related type for hasOne relationships.
+	hasOne *spec.Type
+}
+
+type entityRelation struct {
+	field *spec.Field
+	t     *spec.Type
+	// column references the parent's key in t's table.
+	column string
+}
+
+func entityOf(t *spec.Type) (*entityTable, error) {
+	e := entityTable{
+		t:    t,
+		name: annotationValue(t, "entity", "table", t.Name),
+	}
+	for _, f := range t.Fields {
+		if hasMany, ok := f.Annotation("hasMany"); ok {
+			key, ok := hasMany.Argument("key")
+			if !ok {
+				continue
+			}
+			ref := unwrapOptional(f.Type)
+			if ref.ItemType == nil || unwrapOptional(ref.ItemType).Type == nil {
+				return nil, fmt.Errorf("hasMany field %s of %s is not a list of types", f.Name, t.Name)
+			}
+			e.hasMany = append(e.hasMany, entityRelation{
+				field:  f,
+				t:      unwrapOptional(ref.ItemType).Type,
+				column: key.ValueString(),
+			})
+			continue
+		}
+
+		c := entityColumn{
+			field: f,
+			name:  find.ColumnName(f),
+		}
+		if _, ok := f.Annotation("hasOne"); ok {
+			c.hasOne = unwrapOptional(f.Type).Type
+		}
+		e.columns = append(e.columns, c)
+	}
+
+	for i := range e.columns {
+		c := &e.columns[i]
+		if _, ok := c.field.Annotation("key"); ok {
+			c.name = find.KeyColumn(t)
+			e.key = c
+		}
+		if _, ok := c.field.Annotation("version"); ok {
+			e.version = c
+		}
+	}
+	if e.key == nil {
+		return nil, fmt.Errorf("type %s does not have a key field", t.Name)
+	}
+
+	return &e, nil
+}
+
+func (e *entityTable) isKey(c entityColumn) bool {
+	return c.field == e.key.field
+}
+
+func (e *entityTable) isVersion(c entityColumn) bool {
+	return e.version != nil && c.field == e.version.field
+}
+
+func unwrapOptional(t *spec.TypeRef) *spec.TypeRef {
+	for t.OptionalType != nil {
+		t = t.OptionalType
+	}
+	return t
+}
+
+// writer inserts, updates and deletes entities and their relationships. It
+// is used within a transaction so that nested writes are atomic.
+type writer struct {
+	db      sqlx.ExtContext
+	dialect dialect
+}
+
+func newWriter(db sqlx.ExtContext) *writer {
+	return &writer{
+		db:      db,
+		dialect: dialectOf(db.DriverName()),
+	}
+}
+
+func (w *writer) table(e *entityTable) string {
+	return w.dialect.table(e.name)
+}
+
+func (w *writer) columnList(e *entityTable) string {
+	var buf strings.Builder
+	for i, c := range e.columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(c.name)
+	}
+	return buf.String()
+}
+
+func (w *writer) insert(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	nested, err := w.writeHasOne(ctx, e, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	var args []interface{}
+	for _, c := range e.columns {
+		v, ok := data[c.field.Name]
+		if e.isVersion(c) && (!ok || isNil(v)) {
+			v, ok = 1, true
+		}
+		if !ok || (e.isKey(c) && isNil(v)) {
+			// Let the database generate a value.
+			continue
+		}
+		if child, ok := nested[c.field.Name]; ok {
+			v = child[find.KeyField(c.hasOne)]
+		}
+		columns = append(columns, c.name)
+		args = append(args, v)
+	}
+
+	query := "INSERT INTO " + w.table(e) +
+		" (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	keyValue := data[e.key.field.Name]
+	stored, err := w.execReturning(ctx, e, query, args, keyValue, true)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("inserted %s could not be read back", t.Name)
+	}
+
+	return w.writeRelations(ctx, e, data, stored, nested, false)
+}
+
+func (w *writer) update(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	keyValue, ok := data[e.key.field.Name]
+	if !ok || isNil(keyValue) {
+		return nil, fmt.Errorf("%s requires a value for key field %s", t.Name, e.key.field.Name)
+	}
+
+	nested, err := w.writeHasOne(ctx, e, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	var args []interface{}
+	for _, c := range e.columns {
+		if e.isKey(c) || e.isVersion(c) {
+			continue
+		}
+		v, ok := data[c.field.Name]
+		if !ok {
+			continue
+		}
+		if child, ok := nested[c.field.Name]; ok {
+			v = child[find.KeyField(c.hasOne)]
+		}
+		sets = append(sets, c.name+" = ?")
+		args = append(args, v)
+	}
+	if e.version != nil {
+		sets = append(sets, e.version.name+" = "+e.version.name+" + 1")
+	}
+
+	var stored map[string]interface{}
+	if len(sets) == 0 {
+		if stored, err = w.selectByKey(ctx, e, keyValue); err != nil {
+			return nil, err
+		}
+	} else {
+		query := "UPDATE " + w.table(e) + " SET " + strings.Join(sets, ", ") +
+			" WHERE " + e.key.name + " = ?"
+		args = append(args, keyValue)
+		version, checkVersion := w.versionOf(e, data)
+		if checkVersion {
+			query += " AND " + e.version.name + " = ?"
+			args = append(args, version)
+		}
+
+		if stored, err = w.execReturning(ctx, e, query, args, keyValue, false); err != nil {
+			return nil, err
+		}
+		if stored == nil && checkVersion {
+			existing, err := w.selectByKey(ctx, e, keyValue)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, &keyError{errConflict, t, keyValue}
+			}
+		}
+	}
+	if stored == nil {
+		return nil, &keyError{errNotFound, t, keyValue}
+	}
+
+	return w.writeRelations(ctx, e, data, stored, nested, true)
+}
+
+// upsert updates the entity if it exists and otherwise inserts it. Checking
+// for the row first is portable across all the supported databases.
+func (w *writer) upsert(ctx context.Context, t *spec.Type, data map[string]interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyValue, ok := data[e.key.field.Name]; ok && !isNil(keyValue) {
+		existing, err := w.selectByKey(ctx, e, keyValue)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return w.update(ctx, t, data)
+		}
+	}
+
+	return w.insert(ctx, t, data)
+}
+
+func (w *writer) delete(ctx context.Context, t *spec.Type, keyValue, version interface{}) (map[string]interface{}, error) {
+	e, err := entityOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := w.selectByKey(ctx, e, keyValue)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, &keyError{errNotFound, t, keyValue}
+	}
+
+	query := "DELETE FROM " + w.table(e) + " WHERE " + e.key.name + " = ?"
+	args := []interface{}{keyValue}
+	if e.version != nil && !isNil(version) {
+		query += " AND " + e.version.name + " = ?"
+		args = append(args, version)
+	}
+
+	result, err := w.db.ExecContext(ctx, w.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, &keyError{errConflict, t, keyValue}
+	}
+
+	return existing, nil
+}
+
+func (w *writer) versionOf(e *entityTable, data map[string]interface{}) (interface{}, bool) {
+	if e.version == nil {
+		return nil, false
+	}
+	v, ok := data[e.version.field.Name]
+	return v, ok && !isNil(v)
+}
+
+// execReturning runs an insert or update and returns the stored row, or nil
+// if no row was affected.
+func (w *writer) execReturning(ctx context.Context, e *entityTable, query string, args []interface{}, keyValue interface{}, insert bool) (map[string]interface{}, error) {
+	if w.dialect.returning {
+		query += " RETURNING " + w.columnList(e)
+		rows, err := w.db.QueryxContext(ctx, w.db.Rebind(query), args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+		return w.scan(e, rows)
+	}
+
+	result, err := w.db.ExecContext(ctx, w.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	// Without a version column, MySQL reports zero affected rows for updates
+	// that do not change any values so the row is read back to check.
+	if affected == 0 && (insert || e.version != nil) {
+		return nil, nil
+	}
+	if isNil(keyValue) && insert {
+		if !w.dialect.lastInsertID {
+			return nil, fmt.Errorf("%s requires a value for key field %s", e.t.Name, e.key.field.Name)
+		}
+		if keyValue, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.selectByKey(ctx, e, keyValue)
+}
+
+func (w *writer) selectByKey(ctx context.Context, e *entityTable, keyValue interface{}) (map[string]interface{}, error) {
+	query := "SELECT " + w.columnList(e) + " FROM " + w.table(e) +
+		" WHERE " + e.key.name + " = ?"
+	rows, err := w.db.QueryxContext(ctx, w.db.Rebind(query), keyValue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return w.scan(e, rows)
+}
+
+func (w *writer) scan(e *entityTable, rows *sqlx.Rows) (map[string]interface{}, error) {
+	values, err := rows.SliceScan()
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		record[e.columns[i].field.Name] = normalizeField(e.columns[i].field, v)
+	}
+	return record, nil
+}
+
+// writeHasOne upserts the related entities that are given as objects so
+// that their keys can be stored in the foreign key columns.
+func (w *writer) writeHasOne(ctx context.Context, e *entityTable, data map[string]interface{}) (map[string]map[string]interface{}, error) {
+	var nested map[string]map[string]interface{}
+	for _, c := range e.columns {
+		if c.hasOne == nil {
+			continue
+		}
+		related, ok := data[c.field.Name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		child, err := w.upsert(ctx, c.hasOne, related)
+		if err != nil {
+			return nil, err
+		}
+		if nested == nil {
+			nested = make(map[string]map[string]interface{})
+		}
+		nested[c.field.Name] = child
+	}
+	return nested, nil
+}
+
+// writeRelations writes the hasMany items in data with their foreign key set
+// to the stored key and merges the nested entities into stored.
+func (w *writer) writeRelations(ctx context.Context, e *entityTable, data, stored map[string]interface{}, nested map[string]map[string]interface{}, upsert bool) (map[string]interface{}, error) {
+	for field, child := range nested {
+		stored[field] = child
+	}
+
+	for _, r := range e.hasMany {
+		items, ok := data[r.field.Name].([]interface{})
+		if !ok {
+			continue
+		}
+		child, err := entityOf(r.t)
+		if err != nil {
+			return nil, err
+		}
+		var fkField string
+		for _, c := range child.columns {
+			if c.name == r.column {
+				fkField = c.field.Name
+				break
+			}
+		}
+		if fkField == "" {
+			return nil, fmt.Errorf("%s does not have a field for column %s", r.t.Name, r.column)
+		}
+
+		results := make([]interface{}, len(items))
+		for i, item := range items {
+			itemData, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s items must be objects", r.field.Name)
+			}
+			copied := make(map[string]interface{}, len(itemData)+1)
+			for k, v := range itemData {
+				copied[k] = v
+			}
+			copied[fkField] = stored[e.key.field.Name]
+			itemData = copied
+			if upsert {
+				results[i], err = w.upsert(ctx, r.t, itemData)
+			} else {
+				results[i], err = w.insert(ctx, r.t, itemData)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		stored[r.field.Name] = results
+	}
+
+	return stored, nil
+}
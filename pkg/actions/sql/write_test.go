@@ -0,0 +1,204 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/sql"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/spec/apex"
+)
+
+const schema = `
+namespace "shop"
+
+type Order @entity(table: "orders") {
+  id: string @key
+  customerId: string
+  version: i64 @version
+  items: [LineItem] @hasMany(key: "order_id")
+}
+
+type LineItem @entity(table: "line_items") {
+  id: i64 @key
+  orderId: string
+  sku: string
+}
+
+type Customer @entity(table: "customers") {
+  customerId: string @key
+  name: string
+}
+`
+
+func newDB(t *testing.T, driverName string) (*sqlx.DB, sqlmock.Sqlmock, *spec.Namespace) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	ns, err := apex.Parse([]byte(schema))
+	require.NoError(t, err)
+	return sqlx.NewDb(db, driverName), mock, ns
+}
+
+func entityType(t *testing.T, ns *spec.Namespace, name string) *spec.Type {
+	typ, ok := ns.Type(name)
+	require.True(t, ok)
+	return typ
+}
+
+func TestInsertReturning(t *testing.T) {
+	db, mock, ns := newDB(t, "postgres")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "orders" (id, customer_id, version) VALUES ($1, $2, $3) RETURNING id, customer_id, version`).
+		WithArgs("o1", "c1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "version"}).AddRow("o1", "c1", 1))
+	mock.ExpectQuery(`INSERT INTO "line_items" (order_id, sku) VALUES ($1, $2) RETURNING id, order_id, sku`).
+		WithArgs("o1", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "sku"}).AddRow(10, "o1", "abc"))
+	mock.ExpectCommit()
+
+	action := sql.InsertAction(&sql.InsertConfig{
+		Resource: "db",
+	}, entityType(t, ns, "Order"), db)
+	output, err := action(context.Background(), actions.Data{
+		"id":         "o1",
+		"customerId": "c1",
+		"items": []interface{}{
+			map[string]interface{}{"sku": "abc"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c1",
+		"version":    int64(1),
+		"items": []interface{}{
+			map[string]interface{}{"id": int64(10), "orderId": "o1", "sku": "abc"},
+		},
+	}, output)
+}
+
+func TestInsertGeneratedKey(t *testing.T) {
+	db, mock, ns := newDB(t, "mysql")
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `line_items` (order_id, sku) VALUES (?, ?)").
+		WithArgs("o1", "abc").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectQuery("SELECT id, order_id, sku FROM `line_items` WHERE id = ?").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "sku"}).AddRow(7, "o1", "abc"))
+	mock.ExpectCommit()
+
+	action := sql.InsertAction(&sql.InsertConfig{
+		Resource: "db",
+		Data:     expr.MustParseDataExpr(`input.item`),
+	}, entityType(t, ns, "LineItem"), db)
+	output, err := action(context.Background(), actions.Data{
+		"input": map[string]interface{}{
+			"item": map[string]interface{}{"orderId": "o1", "sku": "abc"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":      int64(7),
+		"orderId": "o1",
+		"sku":     "abc",
+	}, output)
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	db, mock, ns := newDB(t, "mysql")
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `orders` SET customer_id = ?, version = version + 1 WHERE id = ? AND version = ?").
+		WithArgs("c2", "o1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, customer_id, version FROM `orders` WHERE id = ?").
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "version"}).AddRow("o1", "c1", 4))
+	mock.ExpectRollback()
+
+	action := sql.UpdateAction(&sql.UpdateConfig{
+		Resource:      "db",
+		NotFoundError: "not_found",
+		ConflictError: "aborted",
+	}, entityType(t, ns, "Order"), db)
+	_, err := action(context.Background(), actions.Data{
+		"id":         "o1",
+		"customerId": "c2",
+		"version":    3,
+	})
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "aborted", te.Template)
+	assert.Equal(t, "o1", te.Metadata["key"])
+}
+
+func TestUpsertExisting(t *testing.T) {
+	db, mock, ns := newDB(t, "postgres")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, customer_id, version FROM "orders" WHERE id = $1`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "version"}).AddRow("o1", "c1", 1))
+	mock.ExpectQuery(`UPDATE "orders" SET customer_id = $1, version = version + 1 WHERE id = $2 RETURNING id, customer_id, version`).
+		WithArgs("c2", "o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "version"}).AddRow("o1", "c2", 2))
+	mock.ExpectCommit()
+
+	action := sql.UpsertAction(&sql.UpsertConfig{
+		Resource:      "db",
+		ConflictError: "aborted",
+	}, entityType(t, ns, "Order"), db)
+	output, err := action(context.Background(), actions.Data{
+		"id":         "o1",
+		"customerId": "c2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "o1",
+		"customerId": "c2",
+		"version":    int64(2),
+	}, output)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	db, mock, ns := newDB(t, "postgres")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, customer_id, version FROM "orders" WHERE id = $1`).
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "version"}))
+	mock.ExpectRollback()
+
+	var key expr.ValueExpr
+	require.NoError(t, key.FromString(`input.id`))
+	action := sql.DeleteAction(&sql.DeleteConfig{
+		Resource:      "db",
+		Key:           &key,
+		NotFoundError: "not_found",
+		ConflictError: "aborted",
+	}, entityType(t, ns, "Order"), db)
+	_, err := action(context.Background(), actions.Data{
+		"input": map[string]interface{}{"id": "o1"},
+	})
+	var te *errorz.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "not_found", te.Template)
+}
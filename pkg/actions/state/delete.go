@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	nanostate "github.com/nanobus/nanobus/pkg/state"
+)
+
+func DeleteLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := DeleteConfig{
+		Resource:      "state",
+		ConflictError: "aborted",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources); err != nil {
+		return nil, err
+	}
+
+	store, err := getStore(resources, c.Resource, c.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteAction(store, &c), nil
+}
+
+func DeleteAction(
+	store nanostate.Store,
+	config *DeleteConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		var etag *string
+		if config.Etag != nil {
+			value, err := expr.EvalAsStringE(config.Etag, data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate etag: %w", err)
+			}
+			etag = &value
+		}
+
+		if err = store.Delete(ctx, key, etag); err != nil {
+			return nil, conflict(err, config.ConflictError, config.Resource, key)
+		}
+
+		return nil, nil
+	}
+}
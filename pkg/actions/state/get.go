@@ -0,0 +1,112 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/resiliency"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	nanostate "github.com/nanobus/nanobus/pkg/state"
+)
+
+func GetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := GetConfig{
+		Resource:      "state",
+		Codec:         "json",
+		NotFoundError: "not_found",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	store, err := getStore(resources, c.Resource, c.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAction(store, codec, &c), nil
+}
+
+func GetAction(
+	store nanostate.Store,
+	codec codec.Codec,
+	config *GetConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		key, err := expr.EvalAsStringE(config.Key, data)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate key: %w", err)
+		}
+
+		item, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, resiliency.Retriable(err)
+		}
+		if item == nil {
+			return nil, errorz.Return(config.NotFoundError, errorz.Metadata{
+				"resource": config.Resource,
+				"key":      key,
+			})
+		}
+
+		value, _, err := codec.Decode(item.Data, config.CodecArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.WithEtag {
+			return map[string]interface{}{
+				"data": value,
+				"etag": item.Etag,
+			}, nil
+		}
+
+		return value, nil
+	}
+}
+
+func getStore(resources resource.Resources, name resource.Ref, daprStore *string) (nanostate.Store, error) {
+	var store string
+	if daprStore != nil {
+		store = *daprStore
+	}
+	return nanostate.Get(resources, name, store)
+}
+
+// conflict returns the configured error for etag mismatches.
+func conflict(err error, template string, resource resource.Ref, key string) error {
+	if errors.Is(err, nanostate.ErrEtagMismatch) {
+		return errorz.Return(template, errorz.Metadata{
+			"resource": resource,
+			"key":      key,
+		})
+	}
+	return resiliency.Retriable(err)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	nanostate "github.com/nanobus/nanobus/pkg/state"
+)
+
+func SetLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (actions.Action, error) {
+	c := SetConfig{
+		Resource:      "state",
+		Codec:         "json",
+		ConflictError: "aborted",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var codecs codec.Codecs
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"codec:lookup", &codecs); err != nil {
+		return nil, err
+	}
+
+	codec, ok := codecs[string(c.Codec)]
+	if !ok {
+		return nil, fmt.Errorf("codec %q not found", c.Codec)
+	}
+
+	store, err := getStore(resources, c.Resource, c.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetAction(store, codec, &c), nil
+}
+
+func SetAction(
+	store nanostate.Store,
+	codec codec.Codec,
+	config *SetConfig) actions.Action {
+	return func(ctx context.Context, data actions.Data) (interface{}, error) {
+		var r []*nanostate.SetItem
+
+		for i := range config.Items {
+			configItem := &config.Items[i]
+			if configItem.ForEach == nil {
+				it, err := createSetItem(data, nil, configItem, codec, config.CodecArgs)
+				if err != nil {
+					return nil, err
+				}
+				r = append(r, it)
+				continue
+			}
+
+			itemsInt, err := configItem.ForEach.Eval(data)
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate data: %w", err)
+			}
+			items, ok := itemsInt.([]any)
+			if !ok {
+				return nil, fmt.Errorf("forEach expression %q did not return a slice of items", configItem.ForEach.Expr())
+			}
+			for _, item := range items {
+				it, err := createSetItem(data, item, configItem, codec, config.CodecArgs)
+				if err != nil {
+					return nil, err
+				}
+				r = append(r, it)
+			}
+		}
+
+		if err := store.Set(ctx, r...); err != nil {
+			keys := make([]string, len(r))
+			for i, it := range r {
+				keys[i] = it.Key
+			}
+			return nil, conflict(err, config.ConflictError, config.Resource, strings.Join(keys, ","))
+		}
+
+		return nil, nil
+	}
+}
+
+func createSetItem(
+	data actions.Data,
+	item any,
+	config *SetItem,
+	codec codec.Codec, args []any) (*nanostate.SetItem, error) {
+	variables := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		variables[k] = v
+	}
+	variables["item"] = item
+
+	it := nanostate.SetItem{}
+	keyInt, err := config.Key.Eval(variables)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate key: %w", err)
+	}
+	it.Key = fmt.Sprintf("%v", keyInt)
+
+	var value any = variables["input"]
+	if config.Value != nil {
+		if value, err = config.Value.Eval(variables); err != nil {
+			return nil, fmt.Errorf("could not evaluate value: %w", err)
+		}
+	}
+	if config.Etag != nil {
+		etagInt, err := config.Etag.Eval(variables)
+		if err != nil {
+			return nil, fmt.Errorf("could not evaluate etag: %w", err)
+		}
+		etag := fmt.Sprintf("%v", etagInt)
+		it.Etag = &etag
+	}
+
+	if it.Data, err = codec.Encode(value, args...); err != nil {
+		return nil, fmt.Errorf("could not serialize value: %w", err)
+	}
+
+	return &it, nil
+}
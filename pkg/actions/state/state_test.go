@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/state"
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	nanostate "github.com/nanobus/nanobus/pkg/state"
+)
+
+func getResolver(store nanostate.Store) resolve.ResolveAs {
+	resources := resource.Resources{
+		"state": store,
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	return func(name string, target interface{}) bool {
+		switch name {
+		case "resource:lookup":
+			return resolve.As(resources, target)
+		case "codec:lookup":
+			return resolve.As(codecs, target)
+		}
+		return false
+	}
+}
+
+func TestState(t *testing.T) {
+	ctx := context.Background()
+	resolver := getResolver(nanostate.NewMemory())
+
+	set, err := state.SetLoader(ctx, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"forEach": "input.items",
+				"key":     "item.id",
+				"value":   "item",
+			},
+		},
+	}, resolver)
+	require.NoError(t, err)
+	get, err := state.GetLoader(ctx, map[string]interface{}{
+		"key":      "input.id",
+		"withEtag": true,
+	}, resolver)
+	require.NoError(t, err)
+	update, err := state.SetLoader(ctx, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"key":   "input.id",
+				"value": `{"id": input.id, "name": input.name}`,
+				"etag":  "input.etag",
+			},
+		},
+	}, resolver)
+	require.NoError(t, err)
+	del, err := state.DeleteLoader(ctx, map[string]interface{}{
+		"key":  "input.id",
+		"etag": "input.etag",
+	}, resolver)
+	require.NoError(t, err)
+
+	_, err = set(ctx, actions.Data{
+		"input": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": "1", "name": "one"},
+				map[string]interface{}{"id": "2", "name": "two"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := get(ctx, actions.Data{"input": map[string]interface{}{"id": "1"}})
+	require.NoError(t, err)
+	output := result.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "one"}, output["data"])
+	etag := output["etag"]
+	assert.NotEmpty(t, etag)
+
+	_, err = update(ctx, actions.Data{"input": map[string]interface{}{
+		"id":   "1",
+		"name": "uno",
+		"etag": etag,
+	}})
+	require.NoError(t, err)
+
+	// Writes with a stale etag fail with the conflict error.
+	_, err = update(ctx, actions.Data{"input": map[string]interface{}{
+		"id":   "1",
+		"name": "eins",
+		"etag": etag,
+	}})
+	assertTemplateError(t, err, "aborted", "1")
+	_, err = del(ctx, actions.Data{"input": map[string]interface{}{
+		"id":   "1",
+		"etag": etag,
+	}})
+	assertTemplateError(t, err, "aborted", "1")
+
+	result, err = get(ctx, actions.Data{"input": map[string]interface{}{"id": "1"}})
+	require.NoError(t, err)
+	output = result.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "uno"}, output["data"])
+
+	_, err = del(ctx, actions.Data{"input": map[string]interface{}{
+		"id":   "1",
+		"etag": output["etag"],
+	}})
+	require.NoError(t, err)
+
+	_, err = get(ctx, actions.Data{"input": map[string]interface{}{"id": "1"}})
+	assertTemplateError(t, err, "not_found", "1")
+}
+
+func TestGetData(t *testing.T) {
+	ctx := context.Background()
+	store := nanostate.NewMemory()
+	require.NoError(t, store.Set(ctx, &nanostate.SetItem{Key: "1", Data: []byte(`{"id":"1"}`)}))
+
+	get, err := state.GetLoader(ctx, map[string]interface{}{
+		"key": "input.id",
+	}, getResolver(store))
+	require.NoError(t, err)
+
+	result, err := get(ctx, actions.Data{"input": map[string]interface{}{"id": "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "1"}, result)
+}
+
+func assertTemplateError(t *testing.T, err error, template, key string) {
+	t.Helper()
+	var terr *errorz.TemplateError
+	require.ErrorAs(t, err, &terr)
+	assert.Equal(t, template, terr.Template)
+	assert.Equal(t, key, terr.Metadata["key"])
+}
@@ -0,0 +1,460 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package actors is the runtime for services annotated with `@actor`,
+// `@stateful` or `@workflow`. Each entity (service and ID) is activated on
+// its first call and processes one call at a time. Its state is loaded from
+// the store one key at a time as it is read, and the mutation returned by
+// each call is persisted before the result is returned. Entities that have
+// not been called for the idle timeout are deactivated.
+//
+// When the store is shared by multiple replicas, an entity is only active on
+// the replica that holds its lease. Calls to an entity that is active on
+// another replica fail as unavailable so that they can be retried, ideally
+// after being routed to the owning replica.
+//
+// Entities schedule calls to their own operations with timers, which are kept
+// in memory and cleared on deactivation, and reminders, which are persisted
+// in the store and survive restarts.
+package actors
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+// StateInterface is the interface exported to compute modules to read the
+// state of the entity they are processing.
+const StateInterface = "nanobus.actors.v1.State"
+
+type Config struct {
+	// IdleTimeout is how long an entity stays active without receiving calls.
+	IdleTimeout time.Duration
+	// ScanInterval is how often idle entities are deactivated.
+	ScanInterval time.Duration
+	// LeaseTimeout is how long a replica holds an entity before renewing its
+	// lease. It is only used by stores that implement Leaser.
+	LeaseTimeout time.Duration
+	// ReminderInterval is how often the store is polled for due reminders.
+	ReminderInterval time.Duration
+	// Owner identifies this replica in leases. Defaults to the host name and
+	// process ID.
+	Owner string
+}
+
+type Runtime struct {
+	log    logr.Logger
+	store  Store
+	leaser Leaser
+	config Config
+
+	mu     sync.Mutex
+	active map[entityKey]*activation
+	// releasing has the entities whose leases are being released. The
+	// channels are closed when the release completes.
+	releasing map[entityKey]chan struct{}
+	invoker   transport.Invoker
+	done      chan struct{}
+	once      sync.Once
+}
+
+type entityKey struct {
+	actorType string
+	id        string
+}
+
+type activation struct {
+	key entityKey
+	// turn is held by the call being processed.
+	turn chan struct{}
+
+	// Guarded by Runtime.mu.
+	pending  int
+	lastUsed time.Time
+	timers   map[string]*time.Timer
+
+	// Guarded by turn.
+	leaseExpires time.Time
+
+	mu sync.Mutex
+	// state caches the loaded items. A nil item is known to be missing.
+	state map[string]*stateful.RawItem
+}
+
+// Entity identifies the entity processing a call.
+type Entity struct {
+	Type string
+	ID   string
+}
+
+type entityKeyCtx struct{}
+
+// NewContext returns a context for a call processed by entity.
+func NewContext(ctx context.Context, entity Entity) context.Context {
+	return context.WithValue(ctx, entityKeyCtx{}, entity)
+}
+
+// FromContext returns the entity processing the call, if any.
+func FromContext(ctx context.Context) (Entity, bool) {
+	entity, ok := ctx.Value(entityKeyCtx{}).(Entity)
+	return entity, ok
+}
+
+func New(log logr.Logger, store Store, config Config) *Runtime {
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = time.Minute
+	}
+	if config.ScanInterval <= 0 {
+		config.ScanInterval = 10 * time.Second
+	}
+	if config.LeaseTimeout <= 0 {
+		config.LeaseTimeout = 30 * time.Second
+	}
+	if config.ReminderInterval <= 0 {
+		config.ReminderInterval = time.Second
+	}
+	if config.Owner == "" {
+		hostname, _ := os.Hostname()
+		config.Owner = hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+	leaser, _ := store.(Leaser)
+
+	return &Runtime{
+		log:       log,
+		store:     store,
+		leaser:    leaser,
+		config:    config,
+		active:    make(map[entityKey]*activation),
+		releasing: make(map[entityKey]chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Invoke processes a call to an entity with fn once the entity's previous
+// calls have completed. If fn returns a `stateful.Response`, or a map with a
+// `mutation` key that decodes to one, the mutation is persisted and the
+// response's result is returned.
+func (r *Runtime) Invoke(ctx context.Context, actorType, id string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	a := r.activate(entityKey{actorType, id})
+	defer r.release(a)
+
+	select {
+	case a.turn <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-a.turn }()
+
+	if err := r.lease(ctx, a); err != nil {
+		return nil, err
+	}
+
+	result, err := fn(NewContext(ctx, Entity{Type: actorType, ID: id}))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, ok, err := toResponse(result)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return result, nil
+	}
+
+	if err := r.apply(ctx, a, &resp.Mutation); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+// Get returns the item stored under key for an entity or nil if there is
+// none. Items of active entities are cached.
+func (r *Runtime) Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error) {
+	r.mu.Lock()
+	a, ok := r.active[entityKey{actorType, id}]
+	r.mu.Unlock()
+	if !ok {
+		return r.store.Get(ctx, actorType, id, key)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if item, ok := a.state[key]; ok {
+		return item, nil
+	}
+	item, err := r.store.Get(ctx, actorType, id, key)
+	if err != nil {
+		return nil, err
+	}
+	a.state[key] = item
+
+	return item, nil
+}
+
+// GetState returns the data stored under key for an entity or nil if there
+// is none. It implements `compute.StateInvoker`.
+func (r *Runtime) GetState(ctx context.Context, actorType, id, key string) ([]byte, error) {
+	item, err := r.Get(ctx, actorType, id, key)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return item.Data, nil
+}
+
+// GetStateAction is the `get` operation of StateInterface. Its input has the
+// entity `type`, `id` and the `key` to read.
+func (r *Runtime) GetStateAction(ctx context.Context, data actions.Data) (interface{}, error) {
+	input, _ := data["input"].(map[string]interface{})
+	actorType, _ := input["type"].(string)
+	id, _ := input["id"].(string)
+	key, _ := input["key"].(string)
+	if actorType == "" || id == "" || key == "" {
+		return nil, errorz.New(errorz.InvalidArgument, "type, id and key are required")
+	}
+
+	item, err := r.Get(ctx, actorType, id, key)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Run deactivates idle entities and fires due reminders until the runtime is
+// closed.
+func (r *Runtime) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(r.config.ScanInterval)
+	defer ticker.Stop()
+	reminders := time.NewTicker(r.config.ReminderInterval)
+	defer reminders.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return nil
+		case now := <-ticker.C:
+			r.deactivate(func(a *activation) bool {
+				return now.Sub(a.lastUsed) >= r.config.IdleTimeout
+			})
+		case now := <-reminders.C:
+			r.fireReminders(ctx, now)
+		}
+	}
+}
+
+// Close stops Run and deactivates the entities that are not processing
+// calls.
+func (r *Runtime) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+		r.deactivate(func(a *activation) bool { return true })
+	})
+	return nil
+}
+
+func (r *Runtime) activate(key entityKey) *activation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.active[key]
+	if !ok {
+		a = &activation{
+			key:    key,
+			turn:   make(chan struct{}, 1),
+			timers: make(map[string]*time.Timer),
+			state:  make(map[string]*stateful.RawItem),
+		}
+		r.active[key] = a
+	}
+	a.pending++
+
+	return a
+}
+
+func (r *Runtime) release(a *activation) {
+	r.mu.Lock()
+	a.pending--
+	a.lastUsed = time.Now()
+	r.mu.Unlock()
+}
+
+// deactivate removes the entities without pending calls for which idle
+// returns true, clears their timers and releases their leases. Leases are
+// released after the lock is released so that calls to other entities are
+// not held up by the store.
+func (r *Runtime) deactivate(idle func(a *activation) bool) {
+	var release []entityKey
+
+	r.mu.Lock()
+	for key, a := range r.active {
+		if a.pending > 0 || !idle(a) {
+			continue
+		}
+		delete(r.active, key)
+		for _, t := range a.timers {
+			t.Stop()
+		}
+		r.log.V(10).Info("Deactivated entity", "type", key.actorType, "id", key.id)
+
+		if r.leaser != nil && !a.leaseExpires.IsZero() {
+			r.releasing[key] = make(chan struct{})
+			release = append(release, key)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, key := range release {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := r.leaser.Release(ctx, key.actorType, key.id, r.config.Owner); err != nil {
+			r.log.Error(err, "could not release entity lease", "type", key.actorType, "id", key.id)
+		}
+		cancel()
+
+		r.mu.Lock()
+		close(r.releasing[key])
+		delete(r.releasing, key)
+		r.mu.Unlock()
+	}
+}
+
+// waitForRelease waits until the lease of a deactivated entity is released
+// so that a new activation does not renew a lease that is about to be
+// released.
+func (r *Runtime) waitForRelease(ctx context.Context, key entityKey) error {
+	r.mu.Lock()
+	released, ok := r.releasing[key]
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-released:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lease acquires or renews the entity's lease when it expires within half of
+// the lease timeout. The cached state is discarded when the lease had
+// expired because another replica may have changed it.
+func (r *Runtime) lease(ctx context.Context, a *activation) error {
+	if r.leaser == nil {
+		return nil
+	}
+
+	if now := time.Now(); now.Before(a.leaseExpires.Add(-r.config.LeaseTimeout / 2)) {
+		return nil
+	}
+	if err := r.waitForRelease(ctx, a.key); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	acquired, err := r.leaser.Acquire(ctx, a.key.actorType, a.key.id, r.config.Owner, r.config.LeaseTimeout)
+	if err != nil {
+		return fmt.Errorf("could not acquire entity lease: %w", err)
+	}
+	if !acquired {
+		return errorz.New(errorz.Unavailable,
+			fmt.Sprintf("%s/%s is active on another replica", a.key.actorType, a.key.id))
+	}
+
+	if now.After(a.leaseExpires) {
+		a.mu.Lock()
+		a.state = make(map[string]*stateful.RawItem)
+		a.mu.Unlock()
+	}
+	a.leaseExpires = now.Add(r.config.LeaseTimeout)
+
+	return nil
+}
+
+func (r *Runtime) apply(ctx context.Context, a *activation, m *stateful.Mutation) error {
+	if len(m.Set) == 0 && len(m.Remove) == 0 {
+		return nil
+	}
+
+	// Normalize items so that the cache matches what is read from the store.
+	for key, item := range m.Set {
+		if len(item.Data) == 0 && item.DataBase64 != "" {
+			data, err := base64.StdEncoding.DecodeString(item.DataBase64)
+			if err != nil {
+				return errorz.Wrap(err, errorz.InvalidArgument, fmt.Sprintf("invalid base64 data for %q", key))
+			}
+			item.Data = data
+			item.DataBase64 = ""
+			m.Set[key] = item
+		}
+	}
+
+	if err := r.store.Apply(ctx, a.key.actorType, a.key.id, m); err != nil {
+		return fmt.Errorf("could not save entity state: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, item := range m.Set {
+		item := item
+		a.state[key] = &item
+	}
+	for _, key := range m.Remove {
+		a.state[key] = nil
+	}
+
+	return nil
+}
+
+func toResponse(result interface{}) (*stateful.Response, bool, error) {
+	switch v := result.(type) {
+	case *stateful.Response:
+		return v, v != nil, nil
+	case stateful.Response:
+		return &v, true, nil
+	case map[string]interface{}:
+		if _, ok := v["mutation"]; !ok {
+			return nil, false, nil
+		}
+		data, err := msgpack.Marshal(v)
+		if err != nil {
+			return nil, false, err
+		}
+		var resp stateful.Response
+		if err := msgpack.Unmarshal(data, &resp); err != nil {
+			return nil, false, errors.New("invalid entity response: " + err.Error())
+		}
+		return &resp, true, nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,344 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actors"
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	migrate_sqlite "github.com/nanobus/nanobus/pkg/initialize/sqlite"
+)
+
+// increment returns a call that increments the entity's counter.
+func increment(rt *actors.Runtime) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		entity, _ := actors.FromContext(ctx)
+		count, err := rt.GetState(ctx, entity.Type, entity.ID, "count")
+		if err != nil {
+			return nil, err
+		}
+		next := []byte{1}
+		if count != nil {
+			next = []byte{count[0] + 1}
+		}
+		return map[string]interface{}{
+			"mutation": map[string]interface{}{
+				"set": map[string]interface{}{
+					"count": map[string]interface{}{"data": next},
+				},
+			},
+			"result": int(next[0]),
+		}, nil
+	}
+}
+
+func TestTurnBasedConcurrency(t *testing.T) {
+	ctx := context.Background()
+	store := actors.NewMemoryStore()
+	rt := actors.New(logr.Discard(), store, actors.Config{})
+	defer rt.Close()
+
+	var running, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := rt.Invoke(ctx, "test.Counter", "1", func(ctx context.Context) (interface{}, error) {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				defer running.Add(-1)
+				time.Sleep(time.Millisecond)
+				return increment(rt)(ctx)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(t, overlaps.Load())
+	item, err := store.Get(ctx, "test.Counter", "1", "count")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{20}, item.Data)
+}
+
+func TestMutations(t *testing.T) {
+	ctx := context.Background()
+	store := actors.NewMemoryStore()
+	rt := actors.New(logr.Discard(), store, actors.Config{})
+	defer rt.Close()
+
+	result, err := rt.Invoke(ctx, "test.Cart", "a", func(ctx context.Context) (interface{}, error) {
+		entity, ok := actors.FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, actors.Entity{Type: "test.Cart", ID: "a"}, entity)
+		return &stateful.Response{
+			Mutation: stateful.Mutation{
+				Set: map[string]stateful.RawItem{
+					"items": {Type: "Items", Data: []byte("apple")},
+					"owner": {DataBase64: "YWxpY2U="},
+				},
+			},
+			Result: "added",
+		}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "added", result)
+
+	owner, err := rt.GetState(ctx, "test.Cart", "a", "owner")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", string(owner))
+
+	// Results without a mutation are returned as is.
+	result, err = rt.Invoke(ctx, "test.Cart", "a", func(ctx context.Context) (interface{}, error) {
+		return map[string]interface{}{"items": 1}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"items": 1}, result)
+
+	_, err = rt.Invoke(ctx, "test.Cart", "a", func(ctx context.Context) (interface{}, error) {
+		return &stateful.Response{
+			Mutation: stateful.Mutation{Remove: []string{"items"}},
+		}, nil
+	})
+	require.NoError(t, err)
+
+	item, err := store.Get(ctx, "test.Cart", "a", "items")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	// Compute modules read state through the exported `get` operation.
+	got, err := rt.GetStateAction(ctx, actions.Data{"input": map[string]interface{}{
+		"type": "test.Cart",
+		"id":   "a",
+		"key":  "owner",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("alice"), got.(*stateful.RawItem).Data)
+}
+
+func newDB(t *testing.T) *sqlx.DB {
+	dataSource := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+	sourceURL := actors.SourceScheme + "://sqlite"
+	migrationsTable := "actor_migrations"
+	m, err := migrate_sqlite.NewMigrator(&migrate_sqlite.MigrateSQLiteV1Config{
+		Name:            "actors",
+		DataSource:      dataSource,
+		SourceURL:       &sourceURL,
+		MigrationsTable: &migrationsTable,
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.Up(logr.Discard(), "actors"))
+	require.NoError(t, m.Close())
+
+	db, err := sqlx.Connect("sqlite", dataSource)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLStorePlacement(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	store, err := actors.NewSQLStore(db, "actor_state")
+	require.NoError(t, err)
+
+	a := actors.New(logr.Discard(), store, actors.Config{Owner: "a"})
+	b := actors.New(logr.Discard(), store, actors.Config{Owner: "b"})
+	defer b.Close()
+
+	result, err := a.Invoke(ctx, "test.Counter", "1", increment(a))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result)
+
+	// The entity is active on replica a.
+	_, err = b.Invoke(ctx, "test.Counter", "1", increment(b))
+	var errz *errorz.Error
+	require.ErrorAs(t, err, &errz)
+	assert.Equal(t, errorz.Unavailable, errz.Code)
+
+	// Other entities are placed on replica b.
+	result, err = b.Invoke(ctx, "test.Counter", "2", increment(b))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result)
+
+	// Deactivating releases the lease and b loads the saved state.
+	require.NoError(t, a.Close())
+	result, err = b.Invoke(ctx, "test.Counter", "1", increment(b))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result)
+}
+
+func TestIdleDeactivation(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	store, err := actors.NewSQLStore(db, "actor_state")
+	require.NoError(t, err)
+
+	a := actors.New(logr.Discard(), store, actors.Config{
+		Owner:        "a",
+		IdleTimeout:  10 * time.Millisecond,
+		ScanInterval: 10 * time.Millisecond,
+	})
+	go a.Run()
+	defer a.Close()
+
+	_, err = a.Invoke(ctx, "test.Counter", "1", increment(a))
+	require.NoError(t, err)
+
+	// The lease is released once the entity is idle.
+	require.Eventually(t, func() bool {
+		acquired, err := store.Acquire(ctx, "test.Counter", "1", "b", time.Minute)
+		require.NoError(t, err)
+		return acquired
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRedisStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	defer client.Close()
+	require.NoError(t, client.Del(ctx, "test||test.Counter||1", "test||test.Counter||1||lease").Err())
+	store := actors.NewRedisStore(client, "test")
+
+	a := actors.New(logr.Discard(), store, actors.Config{Owner: "a"})
+	b := actors.New(logr.Discard(), store, actors.Config{Owner: "b"})
+	defer b.Close()
+
+	for i := 1; i <= 2; i++ {
+		result, err := a.Invoke(ctx, "test.Counter", "1", increment(a))
+		require.NoError(t, err)
+		assert.EqualValues(t, i, result)
+	}
+
+	_, err := b.Invoke(ctx, "test.Counter", "1", increment(b))
+	var errz *errorz.Error
+	require.ErrorAs(t, err, &errz)
+	assert.Equal(t, errorz.Unavailable, errz.Code)
+
+	require.NoError(t, a.Close())
+	result, err := b.Invoke(ctx, "test.Counter", "1", increment(b))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result)
+}
+
+// blockingLeaser holds leases in memory and blocks releases until unblock is
+// closed.
+type blockingLeaser struct {
+	actors.Store
+	releasing chan struct{}
+	unblock   chan struct{}
+
+	mu     sync.Mutex
+	owners map[string]string
+	events []string
+}
+
+func (l *blockingLeaser) Acquire(ctx context.Context, actorType, id, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current, ok := l.owners[id]; ok && current != owner {
+		return false, nil
+	}
+	l.owners[id] = owner
+	l.events = append(l.events, "acquire "+id)
+	return true, nil
+}
+
+func (l *blockingLeaser) Release(ctx context.Context, actorType, id, owner string) error {
+	select {
+	case l.releasing <- struct{}{}:
+	default:
+	}
+	<-l.unblock
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owners[id] == owner {
+		delete(l.owners, id)
+	}
+	l.events = append(l.events, "release "+id)
+	return nil
+}
+
+func TestDeactivationReleasesLeasesWithoutLock(t *testing.T) {
+	ctx := context.Background()
+	leaser := &blockingLeaser{
+		Store:     actors.NewMemoryStore(),
+		releasing: make(chan struct{}, 1),
+		unblock:   make(chan struct{}),
+		owners:    make(map[string]string),
+	}
+	rt := actors.New(logr.Discard(), leaser, actors.Config{
+		Owner:        "a",
+		IdleTimeout:  50 * time.Millisecond,
+		ScanInterval: 10 * time.Millisecond,
+	})
+	go rt.Run()
+	defer rt.Close()
+
+	_, err := rt.Invoke(ctx, "test.Counter", "1", increment(rt))
+	require.NoError(t, err)
+
+	select {
+	case <-leaser.releasing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("entity was not deactivated")
+	}
+
+	// Other entities are not held up by the release.
+	callCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = rt.Invoke(callCtx, "test.Counter", "2", increment(rt))
+	require.NoError(t, err)
+
+	// The entity is reactivated once its lease is released.
+	done := make(chan error, 1)
+	go func() {
+		_, err := rt.Invoke(ctx, "test.Counter", "1", increment(rt))
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("entity was reactivated before its lease was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(leaser.unblock)
+	require.NoError(t, <-done)
+
+	leaser.mu.Lock()
+	defer leaser.mu.Unlock()
+	var events []string
+	for _, e := range leaser.events {
+		if e == "acquire 1" || e == "release 1" {
+			events = append(events, e)
+		}
+	}
+	assert.Equal(t, []string{"acquire 1", "release 1", "acquire 1"}, events[:3])
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"embed"
+
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
+)
+
+// SourceScheme is the migration source URL scheme for the actor state table
+// migrations. The host is the database: actors://postgres or actors://sqlite.
+const SourceScheme = "actors"
+
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	migration.RegisterEmbedded(SourceScheme, migrations)
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS actor_state (
+  actor_type text NOT NULL,
+  actor_id text NOT NULL,
+  state_key text NOT NULL,
+  data bytea NOT NULL,
+  PRIMARY KEY (actor_type, actor_id, state_key)
+);
+
+CREATE TABLE IF NOT EXISTS actor_state_leases (
+  actor_type text NOT NULL,
+  actor_id text NOT NULL,
+  owner text NOT NULL,
+  expires_at bigint NOT NULL,
+  PRIMARY KEY (actor_type, actor_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS actor_state_reminders (
+  actor_type text NOT NULL,
+  actor_id text NOT NULL,
+  name text NOT NULL,
+  operation text NOT NULL,
+  input bytea,
+  due_at bigint NOT NULL,
+  period bigint NOT NULL,
+  PRIMARY KEY (actor_type, actor_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS actor_state_reminders_due_at ON actor_state_reminders (due_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS actor_state_leases;
+DROP TABLE IF EXISTS actor_state;
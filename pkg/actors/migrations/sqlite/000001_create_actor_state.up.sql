@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS actor_state (
+  actor_type TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  state_key TEXT NOT NULL,
+  data BLOB NOT NULL,
+  PRIMARY KEY (actor_type, actor_id, state_key)
+);
+
+CREATE TABLE IF NOT EXISTS actor_state_leases (
+  actor_type TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  owner TEXT NOT NULL,
+  expires_at INTEGER NOT NULL,
+  PRIMARY KEY (actor_type, actor_id)
+);
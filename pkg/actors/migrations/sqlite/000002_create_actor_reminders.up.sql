@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS actor_state_reminders (
+  actor_type TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  operation TEXT NOT NULL,
+  input BLOB,
+  due_at INTEGER NOT NULL,
+  period INTEGER NOT NULL,
+  PRIMARY KEY (actor_type, actor_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS actor_state_reminders_due_at ON actor_state_reminders (due_at);
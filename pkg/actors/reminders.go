@@ -0,0 +1,224 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+// Reminder is a durable callback to an operation of an entity. Reminders are
+// persisted in the store and fire on any replica, even after restarts.
+type Reminder struct {
+	Type      string        `json:"type" msgpack:"type"`
+	ID        string        `json:"id" msgpack:"id"`
+	Name      string        `json:"name" msgpack:"name"`
+	Operation string        `json:"operation" msgpack:"operation"`
+	Input     interface{}   `json:"input,omitempty" msgpack:"input,omitempty"`
+	DueTime   time.Time     `json:"dueTime" msgpack:"dueTime"`
+	Period    time.Duration `json:"period,omitempty" msgpack:"period,omitempty"`
+}
+
+// Timer is an in-memory callback to an operation of an active entity. Timers
+// are cleared when the entity is deactivated.
+type Timer struct {
+	Name      string
+	Operation string
+	Input     interface{}
+	DueTime   time.Duration
+	Period    time.Duration
+}
+
+// dueReminderBatch is the maximum number of reminders fired per poll.
+const dueReminderBatch = 100
+
+// SetInvoker sets the invoker used to call the operations of timers and
+// reminders. It is the transport invoker so that calls are processed like
+// any other call to the entity.
+func (r *Runtime) SetInvoker(invoker transport.Invoker) {
+	r.mu.Lock()
+	r.invoker = invoker
+	r.mu.Unlock()
+}
+
+// RegisterReminder creates or replaces the reminder with the same name.
+func (r *Runtime) RegisterReminder(ctx context.Context, reminder *Reminder) error {
+	if reminder.Type == "" || reminder.ID == "" || reminder.Name == "" || reminder.Operation == "" {
+		return errorz.New(errorz.InvalidArgument, "reminders require a type, id, name and operation")
+	}
+	if reminder.Period < 0 {
+		return errorz.New(errorz.InvalidArgument, "reminder period must not be negative")
+	}
+
+	return r.store.SetReminder(ctx, reminder)
+}
+
+// UnregisterReminder removes a reminder. It returns false if there was none.
+func (r *Runtime) UnregisterReminder(ctx context.Context, actorType, id, name string) (bool, error) {
+	return r.store.RemoveReminder(ctx, actorType, id, name)
+}
+
+// RegisterTimer creates or replaces the timer with the same name on an active
+// entity, typically the entity processing the call.
+func (r *Runtime) RegisterTimer(actorType, id string, t Timer) error {
+	if t.Name == "" || t.Operation == "" {
+		return errorz.New(errorz.InvalidArgument, "timers require a name and operation")
+	}
+	if t.DueTime < 0 || t.Period < 0 {
+		return errorz.New(errorz.InvalidArgument, "timer due time and period must not be negative")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := entityKey{actorType, id}
+	a, ok := r.active[key]
+	if !ok {
+		return errorz.New(errorz.FailedPrecondition,
+			fmt.Sprintf("%s/%s is not active", actorType, id))
+	}
+	if existing, ok := a.timers[t.Name]; ok {
+		existing.Stop()
+	}
+
+	var tm *time.Timer
+	tm = time.AfterFunc(t.DueTime, func() {
+		err := r.call(context.Background(), key, t.Operation, t.Input)
+		if err != nil {
+			r.log.Error(err, "timer call failed", "type", actorType, "id", id, "timer", t.Name)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// The timer is only rescheduled if it was not replaced, unregistered
+		// or cleared by deactivation while the call was processed.
+		if a.timers[t.Name] != tm {
+			return
+		}
+		if t.Period > 0 {
+			tm.Reset(t.Period)
+		} else {
+			delete(a.timers, t.Name)
+		}
+	})
+	a.timers[t.Name] = tm
+
+	return nil
+}
+
+// UnregisterTimer stops and removes a timer. It returns false if there was
+// none.
+func (r *Runtime) UnregisterTimer(actorType, id, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.active[entityKey{actorType, id}]
+	if !ok {
+		return false
+	}
+	t, ok := a.timers[name]
+	if !ok {
+		return false
+	}
+	t.Stop()
+	delete(a.timers, name)
+
+	return true
+}
+
+func (r *Runtime) call(ctx context.Context, key entityKey, operation string, input interface{}) error {
+	r.mu.Lock()
+	invoker := r.invoker
+	r.mu.Unlock()
+	if invoker == nil {
+		return errors.New("no invoker is set")
+	}
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+
+	_, err := invoker(ctx, handler.Handler{
+		Interface: key.actorType,
+		Operation: operation,
+	}, key.id, input, transport.BypassAuthorization)
+
+	return err
+}
+
+// fireReminders claims and calls the reminders that are due. Reminders that
+// fail because the entity is active on another replica are restored so that
+// the owning replica fires them.
+func (r *Runtime) fireReminders(ctx context.Context, now time.Time) {
+	due, err := r.store.DueReminders(ctx, now, dueReminderBatch)
+	if err != nil {
+		r.log.Error(err, "could not load due reminders")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, reminder := range due {
+		var next time.Time
+		if reminder.Period > 0 {
+			next = reminder.DueTime.Add(reminder.Period)
+			// Skip the periods that were missed while no replica was running.
+			if !next.After(now) {
+				next = now.Add(reminder.Period)
+			}
+		}
+
+		claimed, err := r.store.ClaimReminder(ctx, reminder, next)
+		if err != nil {
+			r.log.Error(err, "could not claim reminder", "type", reminder.Type, "id", reminder.ID, "reminder", reminder.Name)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		go func(reminder *Reminder, next time.Time) {
+			defer wg.Done()
+			err := r.call(ctx, entityKey{reminder.Type, reminder.ID}, reminder.Operation, reminder.Input)
+			if err == nil {
+				return
+			}
+
+			var errz *errorz.Error
+			if errors.As(err, &errz) && errz.Code == errorz.Unavailable {
+				r.restoreReminder(ctx, reminder, next)
+				return
+			}
+			r.log.Error(err, "reminder call failed", "type", reminder.Type, "id", reminder.ID, "reminder", reminder.Name)
+		}(reminder, next)
+	}
+	wg.Wait()
+}
+
+func (r *Runtime) restoreReminder(ctx context.Context, reminder *Reminder, next time.Time) {
+	var err error
+	if next.IsZero() {
+		err = r.store.SetReminder(ctx, reminder)
+	} else {
+		// Only restore the due time if the reminder was not changed since it
+		// was claimed.
+		claimed := *reminder
+		claimed.DueTime = next
+		_, err = r.store.ClaimReminder(ctx, &claimed, reminder.DueTime)
+	}
+	if err != nil {
+		r.log.Error(err, "could not restore reminder", "type", reminder.Type, "id", reminder.ID, "reminder", reminder.Name)
+	}
+}
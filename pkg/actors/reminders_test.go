@@ -0,0 +1,261 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actors"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+type call struct {
+	handler handler.Handler
+	id      string
+	input   interface{}
+}
+
+// recordCalls sets an invoker on rt that records its calls and returns err.
+func recordCalls(rt *actors.Runtime, err error) chan call {
+	calls := make(chan call, 100)
+	rt.SetInvoker(func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		calls <- call{h, id, input}
+		return nil, err
+	})
+	return calls
+}
+
+func receive(t *testing.T, calls chan call) call {
+	t.Helper()
+	select {
+	case c := <-calls:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("operation was not called")
+	}
+	return call{}
+}
+
+func TestReminders(t *testing.T) {
+	ctx := context.Background()
+	store := actors.NewMemoryStore()
+	rt := actors.New(logr.Discard(), store, actors.Config{
+		ReminderInterval: 5 * time.Millisecond,
+	})
+	calls := recordCalls(rt, nil)
+	go rt.Run()
+	defer rt.Close()
+
+	require.NoError(t, rt.RegisterReminder(ctx, &actors.Reminder{
+		Type:      "test.Subscription",
+		ID:        "1",
+		Name:      "expire",
+		Operation: "expire",
+		Input:     map[string]interface{}{"id": "1"},
+		DueTime:   time.Now(),
+	}))
+	require.NoError(t, rt.RegisterReminder(ctx, &actors.Reminder{
+		Type:      "test.Subscription",
+		ID:        "2",
+		Name:      "renew",
+		Operation: "renew",
+		DueTime:   time.Now(),
+		Period:    10 * time.Millisecond,
+	}))
+
+	seen := map[string]int{}
+	for seen["renew"] < 3 {
+		c := receive(t, calls)
+		seen[c.handler.Operation]++
+		switch c.handler.Operation {
+		case "expire":
+			assert.Equal(t, "1", c.id)
+			assert.Equal(t, map[string]interface{}{"id": "1"}, c.input)
+		case "renew":
+			assert.Equal(t, "2", c.id)
+			assert.Equal(t, map[string]interface{}{}, c.input)
+		}
+	}
+	// Reminders without a period are removed after they fire.
+	assert.Equal(t, 1, seen["expire"])
+
+	removed, err := rt.UnregisterReminder(ctx, "test.Subscription", "1", "expire")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	removed, err = rt.UnregisterReminder(ctx, "test.Subscription", "2", "renew")
+	require.NoError(t, err)
+	assert.True(t, removed)
+}
+
+func TestReminderOnOtherReplica(t *testing.T) {
+	ctx := context.Background()
+	store := actors.NewMemoryStore()
+	rt := actors.New(logr.Discard(), store, actors.Config{
+		ReminderInterval: 5 * time.Millisecond,
+	})
+	calls := recordCalls(rt, errorz.New(errorz.Unavailable))
+	go rt.Run()
+	defer rt.Close()
+
+	dueTime := time.Now()
+	require.NoError(t, rt.RegisterReminder(ctx, &actors.Reminder{
+		Type:      "test.Subscription",
+		ID:        "1",
+		Name:      "expire",
+		Operation: "expire",
+		DueTime:   dueTime,
+	}))
+	receive(t, calls)
+	require.NoError(t, rt.Close())
+
+	// The reminder is restored for the replica that owns the entity.
+	require.Eventually(t, func() bool {
+		due, err := store.DueReminders(ctx, time.Now(), 10)
+		require.NoError(t, err)
+		return len(due) == 1 && dueTime.Equal(due[0].DueTime)
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestTimers(t *testing.T) {
+	ctx := context.Background()
+	rt := actors.New(logr.Discard(), actors.NewMemoryStore(), actors.Config{})
+	calls := recordCalls(rt, nil)
+
+	err := rt.RegisterTimer("test.Job", "1", actors.Timer{Name: "poll", Operation: "poll"})
+	var errz *errorz.Error
+	require.ErrorAs(t, err, &errz)
+	assert.Equal(t, errorz.FailedPrecondition, errz.Code)
+
+	_, err = rt.Invoke(ctx, "test.Job", "1", func(ctx context.Context) (interface{}, error) {
+		return nil, rt.RegisterTimer("test.Job", "1", actors.Timer{
+			Name:      "poll",
+			Operation: "poll",
+			Input:     map[string]interface{}{"id": "1"},
+			DueTime:   time.Millisecond,
+			Period:    5 * time.Millisecond,
+		})
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		c := receive(t, calls)
+		assert.Equal(t, handler.Handler{Interface: "test.Job", Operation: "poll"}, c.handler)
+		assert.Equal(t, "1", c.id)
+		assert.Equal(t, map[string]interface{}{"id": "1"}, c.input)
+	}
+
+	// Timers are cleared when the entity is deactivated.
+	require.NoError(t, rt.Close())
+	time.Sleep(20 * time.Millisecond)
+	for len(calls) > 0 {
+		<-calls
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, calls)
+	assert.False(t, rt.UnregisterTimer("test.Job", "1", "poll"))
+}
+
+func TestMemoryStoreReminders(t *testing.T) {
+	testStoreReminders(t, actors.NewMemoryStore())
+}
+
+func TestSQLStoreReminders(t *testing.T) {
+	store, err := actors.NewSQLStore(newDB(t), "actor_state")
+	require.NoError(t, err)
+	testStoreReminders(t, store)
+}
+
+func TestRedisStoreReminders(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	defer client.Close()
+	require.NoError(t, client.Del(context.Background(), "{test}||reminders", "{test}||reminders||due").Err())
+	testStoreReminders(t, actors.NewRedisStore(client, "test"))
+}
+
+func testStoreReminders(t *testing.T, store actors.Store) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+
+	renew := &actors.Reminder{
+		Type:      "test.Subscription",
+		ID:        "1",
+		Name:      "renew",
+		Operation: "renew",
+		Input:     map[string]interface{}{"id": "1"},
+		DueTime:   now.Add(-time.Second),
+		Period:    time.Hour,
+	}
+	require.NoError(t, store.SetReminder(ctx, renew))
+	require.NoError(t, store.SetReminder(ctx, &actors.Reminder{
+		Type:      "test.Subscription",
+		ID:        "1",
+		Name:      "expire",
+		Operation: "expire",
+		DueTime:   now.Add(time.Hour),
+	}))
+
+	due, err := store.DueReminders(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "renew", due[0].Name)
+	assert.Equal(t, "renew", due[0].Operation)
+	assert.Equal(t, map[string]interface{}{"id": "1"}, due[0].Input)
+	assert.Equal(t, time.Hour, due[0].Period)
+	assert.True(t, renew.DueTime.Equal(due[0].DueTime))
+
+	// Only one replica claims a due reminder.
+	claims := 0
+	for i := 0; i < 2; i++ {
+		claimed, err := store.ClaimReminder(ctx, due[0], now.Add(time.Hour))
+		require.NoError(t, err)
+		if claimed {
+			claims++
+		}
+	}
+	assert.Equal(t, 1, claims)
+
+	due, err = store.DueReminders(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = store.DueReminders(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	for _, r := range due {
+		claimed, err := store.ClaimReminder(ctx, r, time.Time{})
+		require.NoError(t, err)
+		assert.True(t, claimed)
+	}
+
+	due, err = store.DueReminders(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	require.NoError(t, store.SetReminder(ctx, renew))
+	removed, err := store.RemoveReminder(ctx, "test.Subscription", "1", "renew")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = store.RemoveReminder(ctx, "test.Subscription", "1", "renew")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jmoiron/sqlx"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+)
+
+// Store persists the state and reminders of entities. Items are stored per
+// key so that entities only load the keys they read.
+type Store interface {
+	// Get returns the item stored under key or nil if there is none.
+	Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error)
+	// Apply atomically sets and removes the items of a mutation.
+	Apply(ctx context.Context, actorType, id string, m *stateful.Mutation) error
+
+	// SetReminder creates or replaces a reminder.
+	SetReminder(ctx context.Context, r *Reminder) error
+	// RemoveReminder removes a reminder. It returns false if there was none.
+	RemoveReminder(ctx context.Context, actorType, id, name string) (bool, error)
+	// DueReminders returns up to limit reminders that are due at now.
+	DueReminders(ctx context.Context, now time.Time, limit int) ([]*Reminder, error)
+	// ClaimReminder moves a reminder that is still due at r.DueTime to next,
+	// or removes it if next is zero. It returns false if the reminder was
+	// changed or claimed by another replica.
+	ClaimReminder(ctx context.Context, r *Reminder, next time.Time) (bool, error)
+}
+
+// Leaser is implemented by stores that are shared by multiple replicas. An
+// entity is only activated by the replica that holds its lease.
+type Leaser interface {
+	// Acquire acquires or renews the lease of an entity for owner. It returns
+	// false if another owner holds an unexpired lease.
+	Acquire(ctx context.Context, actorType, id, owner string, ttl time.Duration) (bool, error)
+	// Release releases the lease of an entity if it is held by owner.
+	Release(ctx context.Context, actorType, id, owner string) error
+}
+
+// encodeItem serializes an item for stores that persist bytes. Items set
+// with DataBase64 are stored with the decoded data.
+func encodeItem(item stateful.RawItem) ([]byte, error) {
+	if len(item.Data) == 0 && item.DataBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(item.DataBase64)
+		if err != nil {
+			return nil, err
+		}
+		item.Data = data
+		item.DataBase64 = ""
+	}
+	return msgpack.Marshal(&item)
+}
+
+func decodeItem(data []byte) (*stateful.RawItem, error) {
+	var item stateful.RawItem
+	if err := msgpack.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// encodeInput serializes the input of a reminder.
+func encodeInput(input interface{}) ([]byte, error) {
+	if input == nil {
+		return nil, nil
+	}
+	return msgpack.Marshal(input)
+}
+
+func decodeInput(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var input interface{}
+	if err := msgpack.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// NewStore returns the store for a state store resource: a redis connection,
+// a Postgres connection or a SQL database.
+func NewStore(r interface{}, table string) (Store, error) {
+	switch v := r.(type) {
+	case redis.UniversalClient:
+		return NewRedisStore(v, table), nil
+	case *pgxpool.Pool:
+		return NewPostgresStore(v, table), nil
+	case *sqlx.DB:
+		return NewSQLStore(v, table)
+	}
+
+	return nil, fmt.Errorf("%T cannot be used as an actor state store", r)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+)
+
+// MemoryStore keeps entity state in memory. It is used when no state store
+// resource is configured and does not survive restarts.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	entities  map[entityKey]map[string][]byte
+	reminders map[reminderKey]Reminder
+}
+
+type reminderKey struct {
+	entityKey
+	name string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entities:  make(map[entityKey]map[string][]byte),
+		reminders: make(map[reminderKey]Reminder),
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error) {
+	s.mu.RLock()
+	data, ok := s.entities[entityKey{actorType, id}][key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	return decodeItem(data)
+}
+
+func (s *MemoryStore) Apply(ctx context.Context, actorType, id string, m *stateful.Mutation) error {
+	encoded := make(map[string][]byte, len(m.Set))
+	for key, item := range m.Set {
+		data, err := encodeItem(item)
+		if err != nil {
+			return err
+		}
+		encoded[key] = data
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := entityKey{actorType, id}
+	items, ok := s.entities[k]
+	if !ok {
+		items = make(map[string][]byte, len(encoded))
+		s.entities[k] = items
+	}
+	for key, data := range encoded {
+		items[key] = data
+	}
+	for _, key := range m.Remove {
+		delete(items, key)
+	}
+	if len(items) == 0 {
+		delete(s.entities, k)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) SetReminder(ctx context.Context, r *Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reminders[reminderKey{entityKey{r.Type, r.ID}, r.Name}] = *r
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveReminder(ctx context.Context, actorType, id, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := reminderKey{entityKey{actorType, id}, name}
+	_, ok := s.reminders[k]
+	delete(s.reminders, k)
+
+	return ok, nil
+}
+
+func (s *MemoryStore) DueReminders(ctx context.Context, now time.Time, limit int) ([]*Reminder, error) {
+	s.mu.RLock()
+	var due []*Reminder
+	for _, r := range s.reminders {
+		if !r.DueTime.After(now) {
+			r := r
+			due = append(due, &r)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].DueTime.Before(due[j].DueTime)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+func (s *MemoryStore) ClaimReminder(ctx context.Context, r *Reminder, next time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := reminderKey{entityKey{r.Type, r.ID}, r.Name}
+	existing, ok := s.reminders[k]
+	if !ok || !existing.DueTime.Equal(r.DueTime) {
+		return false, nil
+	}
+	if next.IsZero() {
+		delete(s.reminders, k)
+	} else {
+		existing.DueTime = next
+		s.reminders[k] = existing
+	}
+
+	return true, nil
+}
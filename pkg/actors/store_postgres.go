@@ -0,0 +1,147 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+)
+
+type PostgresStore struct {
+	pool    *pgxpool.Pool
+	queries queries
+}
+
+// NewPostgresStore returns a Store for the actor state tables in a Postgres
+// database.
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	parts := strings.Split(table, ".")
+	suffixed := func(suffix string) string {
+		names := append([]string{}, parts...)
+		names[len(names)-1] += suffix
+		return pgx.Identifier(names).Sanitize()
+	}
+	q := newQueries(pgx.Identifier(parts).Sanitize(), suffixed("_leases"), suffixed("_reminders"))
+	return &PostgresStore{
+		pool: pool,
+		queries: q.rebind(func(query string) string {
+			return sqlx.Rebind(sqlx.DOLLAR, query)
+		}),
+	}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error) {
+	var data []byte
+	if err := s.pool.QueryRow(ctx, s.queries.get, actorType, id, key).Scan(&data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return decodeItem(data)
+}
+
+func (s *PostgresStore) Apply(ctx context.Context, actorType, id string, m *stateful.Mutation) error {
+	return s.pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for key, item := range m.Set {
+			data, err := encodeItem(item)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, s.queries.set, actorType, id, key, data); err != nil {
+				return err
+			}
+		}
+		for _, key := range m.Remove {
+			if _, err := tx.Exec(ctx, s.queries.remove, actorType, id, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) Acquire(ctx context.Context, actorType, id, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	tag, err := s.pool.Exec(ctx, s.queries.acquire, actorType, id, owner, millis(now.Add(ttl)), millis(now))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, actorType, id, owner string) error {
+	_, err := s.pool.Exec(ctx, s.queries.release, actorType, id, owner)
+	return err
+}
+
+func (s *PostgresStore) SetReminder(ctx context.Context, r *Reminder) error {
+	row, err := newReminderRow(r)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, s.queries.setReminder, row.args()...)
+	return err
+}
+
+func (s *PostgresStore) RemoveReminder(ctx context.Context, actorType, id, name string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, s.queries.removeReminder, actorType, id, name)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) DueReminders(ctx context.Context, now time.Time, limit int) ([]*Reminder, error) {
+	rows, err := s.pool.Query(ctx, s.queries.dueReminders, millis(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*Reminder
+	for rows.Next() {
+		var row reminderRow
+		if err := rows.Scan(&row.Type, &row.ID, &row.Name, &row.Operation,
+			&row.Input, &row.DueAt, &row.Period); err != nil {
+			return nil, err
+		}
+		r, err := row.toReminder()
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, r)
+	}
+
+	return due, rows.Err()
+}
+
+func (s *PostgresStore) ClaimReminder(ctx context.Context, r *Reminder, next time.Time) (bool, error) {
+	query, args := s.queries.claimReminder, []interface{}{r.Type, r.ID, r.Name, millis(r.DueTime)}
+	if !next.IsZero() {
+		query, args = s.queries.moveReminder, append([]interface{}{millis(next)}, args...)
+	}
+	tag, err := s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
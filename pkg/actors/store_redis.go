@@ -0,0 +1,230 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+)
+
+// acquireScript renews the lease if it is held by the owner or sets it if
+// it is not held by anyone.
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+  return 1
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// claimReminderScript moves or removes a reminder if it is still due at the
+// expected time.
+var claimReminderScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) then
+  return 0
+end
+if ARGV[3] == "" then
+  redis.call("ZREM", KEYS[1], ARGV[1])
+  redis.call("HDEL", KEYS[2], ARGV[1])
+else
+  redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+  redis.call("HSET", KEYS[2], ARGV[1], ARGV[4])
+end
+return 1
+`)
+
+// RedisStore stores each entity's state in a hash named
+// `{prefix}||{type}||{id}` with a field per key. Leases are stored in
+// `{prefix}||{type}||{id}||lease`. Reminders are stored in the hash
+// `{{prefix}}||reminders` and indexed by due time in the sorted set
+// `{{prefix}}||reminders||due`.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) key(actorType, id string) string {
+	return s.prefix + "||" + actorType + "||" + id
+}
+
+func (s *RedisStore) Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error) {
+	data, err := s.client.HGet(ctx, s.key(actorType, id), key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return decodeItem(data)
+}
+
+func (s *RedisStore) Apply(ctx context.Context, actorType, id string, m *stateful.Mutation) error {
+	values := make([]interface{}, 0, len(m.Set)*2)
+	for key, item := range m.Set {
+		data, err := encodeItem(item)
+		if err != nil {
+			return err
+		}
+		values = append(values, key, data)
+	}
+
+	hash := s.key(actorType, id)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(values) > 0 {
+			pipe.HSet(ctx, hash, values...)
+		}
+		if len(m.Remove) > 0 {
+			pipe.HDel(ctx, hash, m.Remove...)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (s *RedisStore) Acquire(ctx context.Context, actorType, id, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, s.client,
+		[]string{s.key(actorType, id) + "||lease"}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return acquired == 1, nil
+}
+
+func (s *RedisStore) Release(ctx context.Context, actorType, id, owner string) error {
+	return releaseScript.Run(ctx, s.client,
+		[]string{s.key(actorType, id) + "||lease"}, owner).Err()
+}
+
+func (s *RedisStore) remindersKey() string {
+	return "{" + s.prefix + "}||reminders"
+}
+
+func (s *RedisStore) dueKey() string {
+	return "{" + s.prefix + "}||reminders||due"
+}
+
+func reminderField(actorType, id, name string) string {
+	return actorType + "||" + id + "||" + name
+}
+
+func (s *RedisStore) SetReminder(ctx context.Context, r *Reminder) error {
+	data, err := msgpack.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	field := reminderField(r.Type, r.ID, r.Name)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.remindersKey(), field, data)
+		pipe.ZAdd(ctx, s.dueKey(), &redis.Z{
+			Score:  float64(millis(r.DueTime)),
+			Member: field,
+		})
+		return nil
+	})
+
+	return err
+}
+
+func (s *RedisStore) RemoveReminder(ctx context.Context, actorType, id, name string) (bool, error) {
+	field := reminderField(actorType, id, name)
+	var removed *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		removed = pipe.HDel(ctx, s.remindersKey(), field)
+		pipe.ZRem(ctx, s.dueKey(), field)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return removed.Val() > 0, nil
+}
+
+func (s *RedisStore) DueReminders(ctx context.Context, now time.Time, limit int) ([]*Reminder, error) {
+	fields, err := s.client.ZRangeByScore(ctx, s.dueKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(millis(now), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	values, err := s.client.HMGet(ctx, s.remindersKey(), fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]*Reminder, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Removed since the due reminders were listed.
+			continue
+		}
+		var r Reminder
+		if err := msgpack.Unmarshal([]byte(data), &r); err != nil {
+			return nil, err
+		}
+		due = append(due, &r)
+	}
+
+	return due, nil
+}
+
+func (s *RedisStore) ClaimReminder(ctx context.Context, r *Reminder, next time.Time) (bool, error) {
+	var nextScore string
+	var data []byte
+	if !next.IsZero() {
+		moved := *r
+		moved.DueTime = next
+		var err error
+		if data, err = msgpack.Marshal(&moved); err != nil {
+			return false, err
+		}
+		nextScore = strconv.FormatInt(millis(next), 10)
+	}
+
+	claimed, err := claimReminderScript.Run(ctx, s.client,
+		[]string{s.dueKey(), s.remindersKey()},
+		reminderField(r.Type, r.ID, r.Name), millis(r.DueTime), nextScore, data).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return claimed == 1, nil
+}
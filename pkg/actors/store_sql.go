@@ -0,0 +1,268 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package actors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/nanobus/nanobus/pkg/channel/stateful"
+)
+
+// queries are the statements used by the SQL stores with `?` placeholders.
+// Leases and reminders are stored in tables named after the state table with
+// `_leases` and `_reminders` suffixes.
+type queries struct {
+	get            string
+	set            string
+	remove         string
+	acquire        string
+	release        string
+	setReminder    string
+	removeReminder string
+	dueReminders   string
+	moveReminder   string
+	claimReminder  string
+}
+
+func newQueries(table, leases, reminders string) queries {
+	return queries{
+		get: fmt.Sprintf(`SELECT data FROM %s WHERE actor_type = ? AND actor_id = ? AND state_key = ?`, table),
+		set: fmt.Sprintf(`INSERT INTO %s (actor_type, actor_id, state_key, data) VALUES (?, ?, ?, ?)
+ON CONFLICT (actor_type, actor_id, state_key) DO UPDATE SET data = excluded.data`, table),
+		remove: fmt.Sprintf(`DELETE FROM %s WHERE actor_type = ? AND actor_id = ? AND state_key = ?`, table),
+		acquire: fmt.Sprintf(`INSERT INTO %[1]s (actor_type, actor_id, owner, expires_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (actor_type, actor_id) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+WHERE %[1]s.owner = excluded.owner OR %[1]s.expires_at <= ?`, leases),
+		release: fmt.Sprintf(`DELETE FROM %s WHERE actor_type = ? AND actor_id = ? AND owner = ?`, leases),
+		setReminder: fmt.Sprintf(`INSERT INTO %s (actor_type, actor_id, name, operation, input, due_at, period) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (actor_type, actor_id, name) DO UPDATE SET operation = excluded.operation, input = excluded.input,
+due_at = excluded.due_at, period = excluded.period`, reminders),
+		removeReminder: fmt.Sprintf(`DELETE FROM %s WHERE actor_type = ? AND actor_id = ? AND name = ?`, reminders),
+		dueReminders: fmt.Sprintf(`SELECT actor_type, actor_id, name, operation, input, due_at, period FROM %s
+WHERE due_at <= ? ORDER BY due_at LIMIT ?`, reminders),
+		moveReminder:  fmt.Sprintf(`UPDATE %s SET due_at = ? WHERE actor_type = ? AND actor_id = ? AND name = ? AND due_at = ?`, reminders),
+		claimReminder: fmt.Sprintf(`DELETE FROM %s WHERE actor_type = ? AND actor_id = ? AND name = ? AND due_at = ?`, reminders),
+	}
+}
+
+func (q queries) rebind(bind func(query string) string) queries {
+	return queries{
+		get:            bind(q.get),
+		set:            bind(q.set),
+		remove:         bind(q.remove),
+		acquire:        bind(q.acquire),
+		release:        bind(q.release),
+		setReminder:    bind(q.setReminder),
+		removeReminder: bind(q.removeReminder),
+		dueReminders:   bind(q.dueReminders),
+		moveReminder:   bind(q.moveReminder),
+		claimReminder:  bind(q.claimReminder),
+	}
+}
+
+// reminderRow is a reminder as it is stored in the reminders table.
+type reminderRow struct {
+	Type      string `db:"actor_type"`
+	ID        string `db:"actor_id"`
+	Name      string `db:"name"`
+	Operation string `db:"operation"`
+	Input     []byte `db:"input"`
+	DueAt     int64  `db:"due_at"`
+	Period    int64  `db:"period"`
+}
+
+func newReminderRow(r *Reminder) (*reminderRow, error) {
+	input, err := encodeInput(r.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reminderRow{
+		Type:      r.Type,
+		ID:        r.ID,
+		Name:      r.Name,
+		Operation: r.Operation,
+		Input:     input,
+		DueAt:     millis(r.DueTime),
+		Period:    r.Period.Milliseconds(),
+	}, nil
+}
+
+func (row *reminderRow) args() []interface{} {
+	return []interface{}{row.Type, row.ID, row.Name, row.Operation, row.Input, row.DueAt, row.Period}
+}
+
+func (row *reminderRow) toReminder() (*Reminder, error) {
+	input, err := decodeInput(row.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reminder{
+		Type:      row.Type,
+		ID:        row.ID,
+		Name:      row.Name,
+		Operation: row.Operation,
+		Input:     input,
+		DueTime:   fromMillis(row.DueAt),
+		Period:    time.Duration(row.Period) * time.Millisecond,
+	}, nil
+}
+
+type SQLStore struct {
+	db      *sqlx.DB
+	queries queries
+}
+
+// NewSQLStore returns a Store for the actor state tables in a Postgres or
+// SQLite database opened by the sql resource.
+func NewSQLStore(db *sqlx.DB, table string) (*SQLStore, error) {
+	switch db.DriverName() {
+	case "postgres", "pgx", "sqlite", "sqlite3":
+	default:
+		return nil, fmt.Errorf("actor state is not supported for %q databases", db.DriverName())
+	}
+
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	suffixed := func(suffix string) string {
+		names := append([]string{}, parts...)
+		last := len(names) - 1
+		names[last] = strings.TrimSuffix(names[last], `"`) + suffix + `"`
+		return strings.Join(names, ".")
+	}
+
+	q := newQueries(strings.Join(parts, "."), suffixed("_leases"), suffixed("_reminders"))
+	return &SQLStore{
+		db:      db,
+		queries: q.rebind(db.Rebind),
+	}, nil
+}
+
+func (s *SQLStore) Get(ctx context.Context, actorType, id, key string) (*stateful.RawItem, error) {
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, s.queries.get, actorType, id, key).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return decodeItem(data)
+}
+
+func (s *SQLStore) Apply(ctx context.Context, actorType, id string, m *stateful.Mutation) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for key, item := range m.Set {
+		data, err := encodeItem(item)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, s.queries.set, actorType, id, key, data); err != nil {
+			return err
+		}
+	}
+	for _, key := range m.Remove {
+		if _, err := tx.ExecContext(ctx, s.queries.remove, actorType, id, key); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) Acquire(ctx context.Context, actorType, id, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, s.queries.acquire, actorType, id, owner, millis(now.Add(ttl)), millis(now))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (s *SQLStore) Release(ctx context.Context, actorType, id, owner string) error {
+	_, err := s.db.ExecContext(ctx, s.queries.release, actorType, id, owner)
+	return err
+}
+
+func (s *SQLStore) SetReminder(ctx context.Context, r *Reminder) error {
+	row, err := newReminderRow(r)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.queries.setReminder, row.args()...)
+	return err
+}
+
+func (s *SQLStore) RemoveReminder(ctx context.Context, actorType, id, name string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, s.queries.removeReminder, actorType, id, name)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (s *SQLStore) DueReminders(ctx context.Context, now time.Time, limit int) ([]*Reminder, error) {
+	var rows []reminderRow
+	if err := s.db.SelectContext(ctx, &rows, s.queries.dueReminders, millis(now), limit); err != nil {
+		return nil, err
+	}
+
+	due := make([]*Reminder, len(rows))
+	for i := range rows {
+		r, err := rows[i].toReminder()
+		if err != nil {
+			return nil, err
+		}
+		due[i] = r
+	}
+
+	return due, nil
+}
+
+func (s *SQLStore) ClaimReminder(ctx context.Context, r *Reminder, next time.Time) (bool, error) {
+	query, args := s.queries.claimReminder, []interface{}{r.Type, r.ID, r.Name, millis(r.DueTime)}
+	if !next.IsZero() {
+		query, args = s.queries.moveReminder, append([]interface{}{millis(next)}, args...)
+	}
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
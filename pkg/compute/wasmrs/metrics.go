@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+// observe reports the stats of the pool each time meter collects until the
+// registration is unregistered. The attributes identify the module.
+func (p *Pool) observe(meter metric.Meter, attrs ...attribute.KeyValue) (metric.Registration, error) {
+	instances, err := meter.Int64ObservableGauge("nanobus.compute.wasmrs.instances",
+		instrument.WithDescription("Number of live instances of the module"))
+	if err != nil {
+		return nil, err
+	}
+	requests, err := meter.Int64ObservableGauge("nanobus.compute.wasmrs.requests",
+		instrument.WithDescription("Number of requests in progress"))
+	if err != nil {
+		return nil, err
+	}
+	created, err := meter.Int64ObservableCounter("nanobus.compute.wasmrs.instances.created",
+		instrument.WithDescription("Number of instances created"))
+	if err != nil {
+		return nil, err
+	}
+	recycled, err := meter.Int64ObservableCounter("nanobus.compute.wasmrs.instances.recycled",
+		instrument.WithDescription("Number of instances retired after a request was interrupted or crashed"))
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := p.Stats()
+		o.ObserveInt64(instances, s.Instances, attrs...)
+		o.ObserveInt64(requests, s.Requests, attrs...)
+		o.ObserveInt64(created, s.Created, attrs...)
+		o.ObserveInt64(recycled, s.Recycled, attrs...)
+		return nil
+	}, instances, requests, created, recycled)
+}
@@ -0,0 +1,402 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nanobus/iota/go/invoke"
+	"github.com/nanobus/iota/go/operations"
+	"github.com/nanobus/iota/go/payload"
+	"github.com/nanobus/iota/go/rx/flux"
+	"github.com/nanobus/iota/go/rx/mono"
+
+	"github.com/nanobus/nanobus/pkg/compute"
+)
+
+// ErrPoolClosed is returned for requests made after the pool is closed.
+var ErrPoolClosed = errors.New("wasmrs: instance pool is closed")
+
+// Factory creates an instance of a module. The instance is discarded when ctx
+// is canceled.
+type Factory func(ctx context.Context) (compute.Invoker, error)
+
+// Pool is a compute.Invoker that spreads requests across instances of a
+// module. Each instance processes any number of requests concurrently, so a
+// guest can call the host and the host can call the same module again. A
+// request goes to the instance with the fewest requests in progress, and a
+// new instance is created when they all have some, up to the size of the
+// pool.
+//
+// An instance is retired when the context of one of its requests is done
+// before the request completes, for example when a resiliency timeout
+// expires, or when a call into it panics. A retired instance takes no new
+// requests and is closed once its other requests end, which stops any guest
+// code that is still executing. A crashed guest stops responding, so the
+// deadlines of its requests are what recycle it.
+type Pool struct {
+	ctx        context.Context
+	factory    Factory
+	operations operations.Table
+	size       int
+
+	mu        sync.Mutex
+	serving   []*instance
+	instances map[*instance]struct{}
+	pending   int
+	closed    bool
+	requests  int64
+	created   int64
+	recycled  int64
+
+	requestResponse map[uint32]invoke.RequestResponseHandler
+	fireAndForget   map[uint32]invoke.FireAndForgetHandler
+	requestStream   map[uint32]invoke.RequestStreamHandler
+	requestChannel  map[uint32]invoke.RequestChannelHandler
+}
+
+// Stats is a snapshot of the instances of a pool.
+type Stats struct {
+	// Instances is the number of live instances, including retired ones
+	// that still process requests.
+	Instances int64
+	// Requests is the number of requests in progress.
+	Requests int64
+	// Created is the number of instances created since the pool started.
+	Created int64
+	// Recycled is the number of instances retired because one of their
+	// requests was interrupted or crashed.
+	Recycled int64
+}
+
+type instance struct {
+	compute.Invoker
+	cancel   context.CancelFunc
+	requests int
+	retired  bool
+}
+
+// lease is the use of an instance by a request.
+type lease struct {
+	p    *Pool
+	inst *instance
+	once sync.Once
+	done chan struct{}
+}
+
+// NewPool creates the first instance of the module to read its operations.
+// At most size instances take requests at a time. Instances are closed when
+// ctx is done.
+func NewPool(ctx context.Context, size uint64, factory Factory) (*Pool, error) {
+	if size == 0 {
+		return nil, errors.New("wasmrs: pool size must be at least 1")
+	}
+
+	p := &Pool{
+		ctx:             ctx,
+		factory:         factory,
+		size:            int(size),
+		instances:       make(map[*instance]struct{}),
+		pending:         1,
+		requestResponse: make(map[uint32]invoke.RequestResponseHandler),
+		fireAndForget:   make(map[uint32]invoke.FireAndForgetHandler),
+		requestStream:   make(map[uint32]invoke.RequestStreamHandler),
+		requestChannel:  make(map[uint32]invoke.RequestChannelHandler),
+	}
+
+	inst, err := p.create()
+	if err != nil {
+		return nil, err
+	}
+	p.operations = inst.Operations()
+
+	return p, nil
+}
+
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return Stats{
+		Instances: int64(len(p.instances)),
+		Requests:  p.requests,
+		Created:   p.created,
+		Recycled:  p.recycled,
+	}
+}
+
+func (p *Pool) Operations() operations.Table {
+	return p.operations
+}
+
+// create instantiates the module, sets the handlers of its imports and adds
+// it to the instances that take requests. The caller counts it as pending.
+func (p *Pool) create() (*instance, error) {
+	ctx, cancel := context.WithCancel(p.ctx)
+	invoker, err := p.factory(ctx)
+	if err != nil {
+		cancel()
+		p.mu.Lock()
+		p.pending--
+		p.mu.Unlock()
+		return nil, fmt.Errorf("could not instantiate module: %w", err)
+	}
+	inst := &instance{
+		Invoker: invoker,
+		cancel:  cancel,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending--
+	p.created++
+	if p.closed {
+		p.discard(inst)
+		return nil, ErrPoolClosed
+	}
+	for index, handler := range p.requestResponse {
+		inst.SetRequestResponseHandler(index, handler)
+	}
+	for index, handler := range p.fireAndForget {
+		inst.SetFireAndForgetHandler(index, handler)
+	}
+	for index, handler := range p.requestStream {
+		inst.SetRequestStreamHandler(index, handler)
+	}
+	for index, handler := range p.requestChannel {
+		inst.SetRequestChannelHandler(index, handler)
+	}
+	p.instances[inst] = struct{}{}
+	p.serving = append(p.serving, inst)
+
+	return inst, nil
+}
+
+// acquire leases the least busy instance for the request, creating one if
+// they are all busy and the pool is not full.
+func (p *Pool) acquire() (*lease, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		var inst *instance
+		for _, candidate := range p.serving {
+			if inst == nil || candidate.requests < inst.requests {
+				inst = candidate
+			}
+		}
+		if inst != nil && (inst.requests == 0 || len(p.serving)+p.pending >= p.size) {
+			inst.requests++
+			p.requests++
+			p.mu.Unlock()
+			return &lease{
+				p:    p,
+				inst: inst,
+				done: make(chan struct{}),
+			}, nil
+		}
+		p.pending++
+		p.mu.Unlock()
+
+		// The new instance is leased on the next pass unless another
+		// request takes it first.
+		if _, err := p.create(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// release ends a request to an instance. The instance is retired when the
+// request was interrupted or crashed, and closed when it is retired or the
+// pool is closed and it has no requests left.
+func (p *Pool) release(inst *instance, retire bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inst.requests--
+	p.requests--
+	if retire && !inst.retired {
+		p.retire(inst)
+		p.recycled++
+	}
+	if inst.requests == 0 && (inst.retired || p.closed) {
+		p.discard(inst)
+	}
+}
+
+// retire stops sending requests to an instance. It must be called with the
+// lock held.
+func (p *Pool) retire(inst *instance) {
+	inst.retired = true
+	for i, serving := range p.serving {
+		if serving == inst {
+			p.serving = append(p.serving[:i], p.serving[i+1:]...)
+			break
+		}
+	}
+}
+
+// discard closes an instance. It must be called with the lock held.
+func (p *Pool) discard(inst *instance) {
+	delete(p.instances, inst)
+	inst.cancel()
+	// Closing waits for calls the guest made to other modules to complete,
+	// so it must not hold up the request.
+	go inst.Close()
+}
+
+func (l *lease) watch(ctx context.Context, interrupt func(error)) {
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			if l.end(true) {
+				interrupt(ctx.Err())
+			}
+		case <-l.done:
+		}
+	}()
+}
+
+// end releases the instance and retires it if the request was interrupted.
+// Only the first call returns true.
+func (l *lease) end(interrupted bool) (ended bool) {
+	l.once.Do(func() {
+		ended = true
+		close(l.done)
+		l.p.release(l.inst, interrupted)
+	})
+	return ended
+}
+
+// crashed retires the instance after a call into it panicked.
+func (l *lease) crashed(r interface{}) error {
+	l.end(true)
+	return fmt.Errorf("wasmrs instance panicked: %v", r)
+}
+
+func (p *Pool) FireAndForget(ctx context.Context, pl payload.Payload) {
+	l, err := p.acquire()
+	if err != nil {
+		return
+	}
+	// There is no completion signal, so the request ends as soon as it is
+	// queued.
+	defer func() {
+		if r := recover(); r != nil {
+			_ = l.crashed(r)
+		}
+	}()
+	l.inst.FireAndForget(ctx, pl)
+	l.end(false)
+}
+
+func (p *Pool) RequestResponse(ctx context.Context, pl payload.Payload) (m mono.Mono[payload.Payload]) {
+	l, err := p.acquire()
+	if err != nil {
+		return mono.Error[payload.Payload](err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			m = mono.Error[payload.Payload](l.crashed(r))
+		}
+	}()
+
+	return newMonoResponse(ctx, l, l.inst.RequestResponse(ctx, pl))
+}
+
+func (p *Pool) RequestStream(ctx context.Context, pl payload.Payload) (f flux.Flux[payload.Payload]) {
+	l, err := p.acquire()
+	if err != nil {
+		return flux.Error[payload.Payload](err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			f = flux.Error[payload.Payload](l.crashed(r))
+		}
+	}()
+
+	return newFluxResponse(ctx, l, l.inst.RequestStream(ctx, pl))
+}
+
+func (p *Pool) RequestChannel(ctx context.Context, pl payload.Payload, in flux.Flux[payload.Payload]) (f flux.Flux[payload.Payload]) {
+	l, err := p.acquire()
+	if err != nil {
+		return flux.Error[payload.Payload](err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			f = flux.Error[payload.Payload](l.crashed(r))
+		}
+	}()
+
+	return newFluxResponse(ctx, l, l.inst.RequestChannel(ctx, pl, in))
+}
+
+func (p *Pool) SetRequestResponseHandler(index uint32, handler invoke.RequestResponseHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requestResponse[index] = handler
+	for inst := range p.instances {
+		inst.SetRequestResponseHandler(index, handler)
+	}
+}
+
+func (p *Pool) SetFireAndForgetHandler(index uint32, handler invoke.FireAndForgetHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fireAndForget[index] = handler
+	for inst := range p.instances {
+		inst.SetFireAndForgetHandler(index, handler)
+	}
+}
+
+func (p *Pool) SetRequestStreamHandler(index uint32, handler invoke.RequestStreamHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requestStream[index] = handler
+	for inst := range p.instances {
+		inst.SetRequestStreamHandler(index, handler)
+	}
+}
+
+func (p *Pool) SetRequestChannelHandler(index uint32, handler invoke.RequestChannelHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requestChannel[index] = handler
+	for inst := range p.instances {
+		inst.SetRequestChannelHandler(index, handler)
+	}
+}
+
+// Close closes the instances without requests. The others are closed when
+// their requests end.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	p.serving = nil
+	for inst := range p.instances {
+		if inst.requests == 0 {
+			p.discard(inst)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanobus/iota/go/invoke"
+	"github.com/nanobus/iota/go/operations"
+	"github.com/nanobus/iota/go/payload"
+	"github.com/nanobus/iota/go/rx/flux"
+	"github.com/nanobus/iota/go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/compute"
+	"github.com/nanobus/nanobus/pkg/compute/wasmrs"
+)
+
+// fakeInstance echoes requests unless the data is "hang", which never
+// responds, "wait", which responds when the factory is released, "reenter",
+// which calls the module through the host, or "panic".
+type fakeInstance struct {
+	ctx     context.Context
+	closed  atomic.Bool
+	release chan struct{}
+
+	mu       sync.Mutex
+	handlers map[uint32]invoke.RequestResponseHandler
+}
+
+type fakeFactory struct {
+	release chan struct{}
+
+	mu        sync.Mutex
+	instances []*fakeInstance
+}
+
+func (f *fakeFactory) create(ctx context.Context) (compute.Invoker, error) {
+	inst := &fakeInstance{
+		ctx:      ctx,
+		release:  f.release,
+		handlers: map[uint32]invoke.RequestResponseHandler{},
+	}
+	f.mu.Lock()
+	f.instances = append(f.instances, inst)
+	f.mu.Unlock()
+	return inst, nil
+}
+
+func (f *fakeFactory) get(i int) *fakeInstance {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.instances[i]
+}
+
+func (i *fakeInstance) Operations() operations.Table {
+	return operations.Table{{
+		Index:     0,
+		Type:      operations.RequestResponse,
+		Direction: operations.Export,
+		Namespace: "test",
+		Operation: "echo",
+	}}
+}
+
+func (i *fakeInstance) FireAndForget(ctx context.Context, p payload.Payload) {}
+
+func (i *fakeInstance) RequestResponse(ctx context.Context, p payload.Payload) mono.Mono[payload.Payload] {
+	switch string(p.Data()) {
+	case "hang":
+		return mono.NewProcessor[payload.Payload]()
+	case "wait":
+		return mono.Create(func(sink mono.Sink[payload.Payload]) {
+			go func() {
+				<-i.release
+				sink.Success(p)
+			}()
+		})
+	case "reenter":
+		return i.handler(reenterIndex)(ctx, payload.New([]byte("hello")))
+	case "panic":
+		panic("guest crashed")
+	}
+	return mono.Just(p)
+}
+
+func (i *fakeInstance) RequestStream(ctx context.Context, p payload.Payload) flux.Flux[payload.Payload] {
+	switch string(p.Data()) {
+	case "hang":
+		return flux.NewProcessor[payload.Payload]()
+	case "panic":
+		panic("guest crashed")
+	}
+	return flux.Create(func(sink flux.Sink[payload.Payload]) {
+		sink.Next(p)
+		sink.Complete()
+	})
+}
+
+func (i *fakeInstance) RequestChannel(ctx context.Context, p payload.Payload, in flux.Flux[payload.Payload]) flux.Flux[payload.Payload] {
+	return i.RequestStream(ctx, p)
+}
+
+func (i *fakeInstance) SetRequestResponseHandler(index uint32, handler invoke.RequestResponseHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers[index] = handler
+}
+
+func (i *fakeInstance) handler(index uint32) invoke.RequestResponseHandler {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.handlers[index]
+}
+
+func (i *fakeInstance) SetFireAndForgetHandler(uint32, invoke.FireAndForgetHandler)   {}
+func (i *fakeInstance) SetRequestStreamHandler(uint32, invoke.RequestStreamHandler)   {}
+func (i *fakeInstance) SetRequestChannelHandler(uint32, invoke.RequestChannelHandler) {}
+
+func (i *fakeInstance) Close() error {
+	i.closed.Store(true)
+	return nil
+}
+
+// reenterIndex is the import that "reenter" calls.
+const reenterIndex = 3
+
+func newPool(t *testing.T, size uint64) (*wasmrs.Pool, *fakeFactory) {
+	t.Helper()
+	f := &fakeFactory{release: make(chan struct{})}
+	p, err := wasmrs.NewPool(context.Background(), size, f.create)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Close() })
+	return p, f
+}
+
+func call(ctx context.Context, p *wasmrs.Pool, data string) (string, error) {
+	result, err := p.RequestResponse(ctx, payload.New([]byte(data))).Block()
+	if err != nil {
+		return "", err
+	}
+	return string(result.Data()), nil
+}
+
+func TestPoolReusesInstances(t *testing.T) {
+	p, _ := newPool(t, 4)
+	assert.Len(t, p.Operations(), 1)
+
+	for i := 0; i < 5; i++ {
+		result, err := call(context.Background(), p, "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result)
+	}
+
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 1}, p.Stats())
+}
+
+func TestPoolMultiplexesRequests(t *testing.T) {
+	p, f := newPool(t, 1)
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = call(context.Background(), p, "wait")
+		}(i)
+	}
+	assert.Eventually(t, func() bool {
+		return p.Stats() == wasmrs.Stats{Instances: 1, Requests: 5, Created: 1}
+	}, time.Second, 10*time.Millisecond)
+
+	close(f.release)
+	wg.Wait()
+	for i := range results {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "wait", results[i])
+	}
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 1}, p.Stats())
+}
+
+func TestPoolSpreadsRequests(t *testing.T) {
+	p, f := newPool(t, 2)
+
+	hanging, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		p.RequestResponse(hanging, payload.New([]byte("hang"))).Subscribe(mono.Subscribe[payload.Payload]{})
+	}
+	// The third request shares an instance once the pool is full.
+	assert.Equal(t, wasmrs.Stats{Instances: 2, Requests: 3, Created: 2}, p.Stats())
+
+	result, err := call(context.Background(), p, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Len(t, f.instances, 2)
+}
+
+func TestPoolReentrantCalls(t *testing.T) {
+	p, _ := newPool(t, 1)
+
+	// The host routes the call back to the module, as the guest calling one
+	// of its own operations through the mesh does.
+	p.SetRequestResponseHandler(reenterIndex, func(ctx context.Context, pl payload.Payload) mono.Mono[payload.Payload] {
+		return p.RequestResponse(ctx, pl)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := call(ctx, p, "reenter")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 1}, p.Stats())
+}
+
+func TestPoolInterruptsOnDeadline(t *testing.T) {
+	p, f := newPool(t, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := call(ctx, p, "hang")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	stuck := f.get(0)
+	assert.ErrorIs(t, stuck.ctx.Err(), context.Canceled)
+	assert.Eventually(t, stuck.closed.Load, time.Second, 10*time.Millisecond)
+	assert.Equal(t, wasmrs.Stats{Created: 1, Recycled: 1}, p.Stats())
+
+	// The next request gets a new instance.
+	result, err := call(context.Background(), p, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 2, Recycled: 1}, p.Stats())
+}
+
+func TestPoolInterruptsStreams(t *testing.T) {
+	p, f := newPool(t, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var streamErr error
+	done := make(chan struct{})
+	p.RequestStream(ctx, payload.New([]byte("hang"))).Subscribe(flux.Subscribe[payload.Payload]{
+		OnError: func(err error) {
+			streamErr = err
+			close(done)
+		},
+	})
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not interrupted")
+	}
+	assert.ErrorIs(t, streamErr, context.Canceled)
+	assert.Error(t, f.get(0).ctx.Err())
+	assert.Equal(t, wasmrs.Stats{Created: 1, Recycled: 1}, p.Stats())
+}
+
+func TestPoolDrainsRetiredInstances(t *testing.T) {
+	p, f := newPool(t, 1)
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := call(context.Background(), p, "wait")
+		waiting <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return p.Stats().Requests == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := call(ctx, p, "hang")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The retired instance keeps processing its other request while a new
+	// instance takes the next ones.
+	retired := f.get(0)
+	assert.NoError(t, retired.ctx.Err())
+	result, err := call(context.Background(), p, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Equal(t, wasmrs.Stats{Instances: 2, Requests: 1, Created: 2, Recycled: 1}, p.Stats())
+
+	close(f.release)
+	require.NoError(t, <-waiting)
+	assert.ErrorIs(t, retired.ctx.Err(), context.Canceled)
+	assert.Eventually(t, retired.closed.Load, time.Second, 10*time.Millisecond)
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 2, Recycled: 1}, p.Stats())
+	assert.NoError(t, f.get(1).ctx.Err())
+}
+
+func TestPoolRecyclesCrashedInstances(t *testing.T) {
+	p, f := newPool(t, 1)
+
+	_, err := call(context.Background(), p, "panic")
+	assert.ErrorContains(t, err, "guest crashed")
+	assert.Eventually(t, f.get(0).closed.Load, time.Second, 10*time.Millisecond)
+
+	result, err := call(context.Background(), p, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+	assert.Equal(t, wasmrs.Stats{Instances: 1, Created: 2, Recycled: 1}, p.Stats())
+}
+
+func TestPoolSetsHandlersOnNewInstances(t *testing.T) {
+	p, f := newPool(t, 2)
+
+	called := false
+	p.SetRequestResponseHandler(3, func(ctx context.Context, p payload.Payload) mono.Mono[payload.Payload] {
+		called = true
+		return mono.Just(p)
+	})
+
+	// Keep the first instance busy so the pool creates a second one.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.RequestResponse(ctx, payload.New([]byte("hang"))).Subscribe(mono.Subscribe[payload.Payload]{})
+	_, err := call(context.Background(), p, "hello")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		h := f.get(i).handler(3)
+		require.NotNil(t, h)
+		_, err := h(context.Background(), payload.New(nil)).Block()
+		require.NoError(t, err)
+	}
+	assert.True(t, called)
+}
+
+func TestPoolClose(t *testing.T) {
+	p, f := newPool(t, 1)
+	require.NoError(t, p.Close())
+
+	assert.Eventually(t, f.get(0).closed.Load, time.Second, 10*time.Millisecond)
+	_, err := call(context.Background(), p, "hello")
+	assert.True(t, errors.Is(err, wasmrs.ErrPoolClosed))
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nanobus/iota/go/payload"
+	"github.com/nanobus/iota/go/rx"
+	"github.com/nanobus/iota/go/rx/flux"
+	"github.com/nanobus/iota/go/rx/mono"
+)
+
+// The pool returns its own mono and flux for each request instead of those of
+// the guest. They forward the signals of the guest to the subscriber and end
+// the lease. When the request is interrupted, they deliver the context error
+// instead. Signals are delivered under a lock and only the first terminal
+// signal is, so a guest that responds while its request is interrupted cannot
+// signal the subscriber concurrently or twice.
+
+// terminal records the terminal signal of a response and the callbacks
+// registered with Notify.
+type terminal struct {
+	mu        sync.Mutex
+	ended     bool
+	signal    rx.SignalType
+	err       error
+	callbacks []rx.FnFinally
+}
+
+// end records the terminal signal and returns true if it is the first one.
+// It must be called with the lock held.
+func (t *terminal) end(signal rx.SignalType, err error) bool {
+	if t.ended {
+		return false
+	}
+	t.ended = true
+	t.signal = signal
+	t.err = err
+	for _, fn := range t.callbacks {
+		fn(signal)
+	}
+	return true
+}
+
+func (t *terminal) notify(fn rx.FnFinally) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ended {
+		fn(t.signal)
+		return
+	}
+	t.callbacks = append(t.callbacks, fn)
+}
+
+type monoResponse struct {
+	terminal
+	guest mono.Mono[payload.Payload]
+	lease *lease
+	sub   *mono.Subscribe[payload.Payload]
+	value payload.Payload
+}
+
+func newMonoResponse(ctx context.Context, l *lease, guest mono.Mono[payload.Payload]) *monoResponse {
+	r := &monoResponse{
+		guest: guest,
+		lease: l,
+	}
+	l.watch(ctx, func(err error) {
+		r.end(rx.SignalError, nil, err)
+	})
+	return r
+}
+
+func (r *monoResponse) end(signal rx.SignalType, value payload.Payload, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.terminal.end(signal, err) {
+		return
+	}
+	r.value = value
+	if r.sub != nil {
+		deliverMono(*r.sub, signal, value, err)
+	}
+}
+
+func deliverMono(sub mono.Subscribe[payload.Payload], signal rx.SignalType, value payload.Payload, err error) {
+	switch signal {
+	case rx.SignalComplete:
+		if sub.OnSuccess != nil {
+			sub.OnSuccess(value)
+		}
+	case rx.SignalError:
+		if sub.OnError != nil {
+			sub.OnError(err)
+		}
+	}
+	if sub.Finally != nil {
+		sub.Finally(signal)
+	}
+}
+
+func (r *monoResponse) Subscribe(sub mono.Subscribe[payload.Payload]) mono.Mono[payload.Payload] {
+	r.mu.Lock()
+	if r.ended {
+		deliverMono(sub, r.signal, r.value, r.err)
+		r.mu.Unlock()
+		return r
+	}
+	r.sub = &sub
+	r.mu.Unlock()
+
+	r.guest.Subscribe(mono.Subscribe[payload.Payload]{
+		OnSuccess: func(value payload.Payload) {
+			r.end(rx.SignalComplete, value, nil)
+			r.lease.end(false)
+		},
+		OnError: func(err error) {
+			r.end(rx.SignalError, nil, err)
+			r.lease.end(false)
+		},
+		OnRequest: sub.OnRequest,
+		Finally: func(signal rx.SignalType) {
+			r.end(signal, nil, nil)
+			r.lease.end(false)
+		},
+	})
+	return r
+}
+
+func (r *monoResponse) Block() (payload.Payload, error) {
+	done := make(chan struct{})
+	r.Subscribe(mono.Subscribe[payload.Payload]{
+		Finally: func(rx.SignalType) {
+			close(done)
+		},
+	})
+	<-done
+	return r.Get()
+}
+
+func (r *monoResponse) Get() (payload.Payload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.ended {
+		panic("wasmrs: Get called before completion")
+	}
+	return r.value, r.err
+}
+
+func (r *monoResponse) Async() {}
+
+func (r *monoResponse) Notify(fn rx.FnFinally) {
+	r.notify(fn)
+}
+
+type fluxResponse struct {
+	terminal
+	guest flux.Flux[payload.Payload]
+	lease *lease
+	sub   *flux.Subscribe[payload.Payload]
+}
+
+func newFluxResponse(ctx context.Context, l *lease, guest flux.Flux[payload.Payload]) *fluxResponse {
+	r := &fluxResponse{
+		guest: guest,
+		lease: l,
+	}
+	l.watch(ctx, func(err error) {
+		r.end(rx.SignalError, err)
+	})
+	return r
+}
+
+func (r *fluxResponse) next(value payload.Payload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.ended && r.sub != nil && r.sub.OnNext != nil {
+		r.sub.OnNext(value)
+	}
+}
+
+func (r *fluxResponse) end(signal rx.SignalType, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.terminal.end(signal, err) {
+		return
+	}
+	if r.sub != nil {
+		deliverFlux(*r.sub, signal, err)
+	}
+}
+
+func deliverFlux(sub flux.Subscribe[payload.Payload], signal rx.SignalType, err error) {
+	switch signal {
+	case rx.SignalComplete:
+		if sub.OnComplete != nil {
+			sub.OnComplete()
+		}
+	case rx.SignalError:
+		if sub.OnError != nil {
+			sub.OnError(err)
+		}
+	}
+	if sub.Finally != nil {
+		sub.Finally(signal)
+	}
+}
+
+func (r *fluxResponse) Subscribe(sub flux.Subscribe[payload.Payload]) flux.Flux[payload.Payload] {
+	r.mu.Lock()
+	if r.ended {
+		deliverFlux(sub, r.signal, r.err)
+		r.mu.Unlock()
+		return r
+	}
+	r.sub = &sub
+	r.mu.Unlock()
+
+	// Flow control is left to the subscriber and the guest.
+	r.guest.Subscribe(flux.Subscribe[payload.Payload]{
+		OnNext: r.next,
+		OnComplete: func() {
+			r.end(rx.SignalComplete, nil)
+			r.lease.end(false)
+		},
+		OnError: func(err error) {
+			r.end(rx.SignalError, err)
+			r.lease.end(false)
+		},
+		OnRequest: sub.OnRequest,
+		Finally: func(signal rx.SignalType) {
+			r.end(signal, nil)
+			r.lease.end(false)
+		},
+		NoRequest: sub.NoRequest,
+	})
+	return r
+}
+
+func (r *fluxResponse) Block(sub flux.Subscribe[payload.Payload]) error {
+	return flux.Block[payload.Payload](r, sub)
+}
+
+func (r *fluxResponse) Async() {}
+
+func (r *fluxResponse) Notify(fn rx.FnFinally) {
+	r.notify(fn)
+}
+
+func (r *fluxResponse) Subscription() rx.Subscription {
+	return r.guest.Subscription()
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs
+
+import (
+	"context"
+	"os"
+	_ "unsafe" // for go:linkname
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/assemblyscript"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// maxMemoryPages is the most memory a 32-bit module can address, in 64 KiB
+// pages.
+const maxMemoryPages = 65536
+
+const i32 = api.ValueTypeI32
+
+// The host module that WasmRS guests import calls into the host.Instance
+// running the guest. host.New instantiates it in a runtime that it
+// configures itself, so these bind its functions for use in a runtime that
+// limits memory and stops guest code when the context of the instance is
+// done.
+//
+// TODO: Remove once host.New accepts a wazero.RuntimeConfig.
+
+//go:linkname initBuffers github.com/nanobus/iota/go/transport/wasmrs/host.initBuffers
+func initBuffers(ctx context.Context, params []uint64)
+
+//go:linkname opList github.com/nanobus/iota/go/transport/wasmrs/host.opList
+func opList(ctx context.Context, params []uint64)
+
+//go:linkname send github.com/nanobus/iota/go/transport/wasmrs/host.send
+func send(ctx context.Context, params []uint64)
+
+// newRuntime creates a runtime with the modules WasmRS guests import. The
+// memory of each module is limited to memoryLimitPages and function calls
+// stop when their context is done, closing the module.
+func newRuntime(ctx context.Context, memoryLimitPages uint32) (wazero.Runtime, error) {
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCoreFeatures(api.CoreFeaturesV2).
+		WithMemoryLimitPages(memoryLimitPages).
+		WithCloseOnContextDone(true))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+
+	// This disables the abort message as no other engines write it.
+	envBuilder := r.NewHostModuleBuilder("env")
+	assemblyscript.NewFunctionExporter().WithAbortMessageDisabled().ExportFunctions(envBuilder)
+	if _, err := envBuilder.Instantiate(ctx); err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+
+	if _, err := r.NewHostModuleBuilder("wasmrs").
+		NewFunctionBuilder().
+		WithGoFunction(api.GoFunc(initBuffers), []api.ValueType{i32, i32}, []api.ValueType{}).
+		WithParameterNames("send_ptr", "recv_ptr").Export("__init_buffers").
+		NewFunctionBuilder().
+		WithGoFunction(api.GoFunc(opList), []api.ValueType{i32, i32}, []api.ValueType{}).
+		WithParameterNames("op_ptr", "op_size").Export("__op_list").
+		NewFunctionBuilder().
+		WithGoFunction(api.GoFunc(send), []api.ValueType{i32}, []api.ValueType{}).
+		WithParameterNames("recv_pos").Export("__send").
+		Instantiate(ctx); err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// moduleConfig calls the WASI and WasmRS start functions of a module when it
+// is instantiated, like host.Module.Instantiate.
+func moduleConfig(name string) wazero.ModuleConfig {
+	return wazero.NewModuleConfig().
+		WithName(name).
+		WithStartFunctions("_start", "wasmrs_init").
+		WithStdin(os.Stdin).
+		WithStdout(os.Stdout).
+		WithStderr(os.Stderr).
+		WithSysWalltime().
+		WithSysNanotime()
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package wasmrs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nanobus/iota/go/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// memoryModule declares a memory of 2 to 10 pages.
+	memoryModule = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x05, 0x04, 0x01, 0x01, 0x02, 0x0a,
+	}
+
+	// loopModule exports a function "loop" that never returns.
+	loopModule = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x03, 0x02, 0x01, 0x00,
+		0x07, 0x08, 0x01, 0x04, 'l', 'o', 'o', 'p', 0x00, 0x00,
+		0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b,
+	}
+
+	// guestModule is a WasmRS guest that registers its buffers and an
+	// operation table with the "test.echo" export through the wasmrs host
+	// module. It ignores the frames it is sent.
+	guestModule = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		// Types: (i32, i32), (i32, i32, i32), (), (i32).
+		0x01, 0x13, 0x04, 0x60, 0x02, 0x7f, 0x7f, 0x00, 0x60, 0x03, 0x7f, 0x7f,
+		0x7f, 0x00, 0x60, 0x00, 0x00, 0x60, 0x01, 0x7f, 0x00,
+		// Imports: wasmrs.__init_buffers and wasmrs.__op_list.
+		0x02, 0x2c, 0x02,
+		0x06, 'w', 'a', 's', 'm', 'r', 's',
+		0x0e, '_', '_', 'i', 'n', 'i', 't', '_', 'b', 'u', 'f', 'f', 'e', 'r', 's', 0x00, 0x00,
+		0x06, 'w', 'a', 's', 'm', 'r', 's',
+		0x09, '_', '_', 'o', 'p', '_', 'l', 'i', 's', 't', 0x00, 0x00,
+		// Functions.
+		0x03, 0x04, 0x03, 0x01, 0x02, 0x03,
+		// Memory of 1 page.
+		0x05, 0x03, 0x01, 0x00, 0x01,
+		// Exports: memory, __wasmrs_init, __wasmrs_op_list_request and
+		// __wasmrs_send.
+		0x07, 0x45, 0x04,
+		0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+		0x0d, '_', '_', 'w', 'a', 's', 'm', 'r', 's', '_', 'i', 'n', 'i', 't', 0x00, 0x02,
+		0x18, '_', '_', 'w', 'a', 's', 'm', 'r', 's', '_', 'o', 'p', '_', 'l', 'i', 's', 't',
+		'_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x03,
+		0x0d, '_', '_', 'w', 'a', 's', 'm', 'r', 's', '_', 's', 'e', 'n', 'd', 0x00, 0x04,
+		// Code: __init_buffers(1024, 2048), __op_list(0, 30) and nothing.
+		0x0a, 0x18, 0x03,
+		0x0a, 0x00, 0x41, 0x80, 0x08, 0x41, 0x80, 0x10, 0x10, 0x00, 0x0b,
+		0x08, 0x00, 0x41, 0x00, 0x41, 0x1e, 0x10, 0x01, 0x0b,
+		0x02, 0x00, 0x0b,
+		// Data: the operation table at 0.
+		0x0b, 0x24, 0x01, 0x00, 0x41, 0x00, 0x0b, 0x1e,
+		0x00, 'w', 'r', 's', 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+		0x01, 0x01, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x04, 't', 'e', 's', 't', 0x00, 0x04, 'e', 'c', 'h', 'o', 0x00, 0x00,
+	}
+)
+
+func TestRuntimeMemoryLimit(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := newRuntime(ctx, 4)
+	require.NoError(t, err)
+	defer r.Close(ctx)
+	m, err := r.Instantiate(ctx, memoryModule)
+	require.NoError(t, err)
+
+	// The declared maximum is lowered to the limit.
+	_, ok := m.Memory().Grow(3)
+	assert.False(t, ok)
+	_, ok = m.Memory().Grow(2)
+	assert.True(t, ok)
+
+	r, err = newRuntime(ctx, 1)
+	require.NoError(t, err)
+	defer r.Close(ctx)
+	_, err = r.CompileModule(ctx, memoryModule)
+	assert.ErrorContains(t, err, "over limit of 1 pages")
+}
+
+func TestRuntimeInterruptsOnDeadline(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := newRuntime(ctx, maxMemoryPages)
+	require.NoError(t, err)
+	defer r.Close(ctx)
+	m, err := r.Instantiate(ctx, loopModule)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = m.ExportedFunction("loop").Call(ctx)
+	assert.ErrorContains(t, err, "deadline exceeded")
+}
+
+func TestFactory(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := newRuntime(ctx, maxMemoryPages)
+	require.NoError(t, err)
+	defer r.Close(ctx)
+	module, err := r.CompileModule(ctx, guestModule)
+	require.NoError(t, err)
+	factory := newFactory(r, module)
+
+	// Each instance gets its own module name.
+	for i := 0; i < 2; i++ {
+		inst, err := factory(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, operations.Table{{
+			Index:     0,
+			Type:      operations.RequestResponse,
+			Direction: operations.Export,
+			Namespace: "test",
+			Operation: "echo",
+		}}, inst.Operations())
+		defer inst.Close()
+	}
+}
@@ -10,21 +10,43 @@ package wasmrs
 
 import (
 	"context"
-	"net/url"
-	"os"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/nanobus/iota/go/transport/wasmrs/host"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
 
 	"github.com/nanobus/nanobus/pkg/compute"
 	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/fetch"
 	"github.com/nanobus/nanobus/pkg/resolve"
 	"github.com/nanobus/nanobus/pkg/runtime"
 )
 
 type Config struct {
-	// Filename is the file name of the WasmRS module to load.
-	// TODO: Load from external location
+	// Filename is the location of the WasmRS module to load: a file path or a
+	// file://, https:// or oci:// URL, optionally pinned with @sha256:<hex>.
 	Filename runtime.FilePath `mapstructure:"filename" validate:"required"`
+	// PoolSize is the maximum number of instances of the module to create.
+	// Requests are spread across the instances and each instance processes
+	// any number of them concurrently. Instances do not share memory, so
+	// only modules that keep no state between requests should use more than
+	// one. Defaults to 1.
+	PoolSize uint64 `mapstructure:"poolSize"`
+	// MaxMemoryPages limits the memory of each instance, in 64 KiB pages.
+	// Defaults to 65536 (4 GiB), the most a module can address.
+	MaxMemoryPages uint32 `mapstructure:"maxMemoryPages" validate:"min=1,max=65536"`
+}
+
+// Compute is a pool of instances of a module and the runtime that runs them.
+type Compute struct {
+	*Pool
+	runtime      wazero.Runtime
+	registration metric.Registration
 }
 
 // WasmRS
@@ -33,29 +55,95 @@ func WasmRS() (string, compute.Loader) {
 }
 
 func Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (compute.Invoker, error) {
-	c := Config{}
+	c := Config{
+		PoolSize:       1,
+		MaxMemoryPages: maxMemoryPages,
+	}
 	if err := config.Decode(with, &c); err != nil {
 		return nil, err
 	}
 
-	targetUrl, err := url.Parse(string(c.Filename))
-	if err != nil {
+	var fetcher *fetch.Fetcher
+	if err := resolve.Resolve(resolver,
+		"system:fetcher", &fetcher); err != nil {
 		return nil, err
 	}
 
-	source, err := os.ReadFile(targetUrl.Path)
+	source, err := fetcher.Fetch(ctx, string(c.Filename))
 	if err != nil {
 		return nil, err
 	}
 
-	h, err := host.New(ctx)
+	r, err := newRuntime(ctx, c.MaxMemoryPages)
+	if err != nil {
+		return nil, err
+	}
+	module, err := r.CompileModule(ctx, source)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+	pool, err := NewPool(ctx, c.PoolSize, newFactory(r, module))
 	if err != nil {
+		_ = r.Close(ctx)
 		return nil, err
 	}
-	module, err := h.Compile(ctx, source)
+	registration, err := pool.observe(global.Meter("nanobus/compute/wasmrs"),
+		attribute.String("module", string(c.Filename)))
 	if err != nil {
+		pool.Close()
+		_ = r.Close(ctx)
 		return nil, err
 	}
 
-	return module.Instantiate(ctx)
+	return &Compute{
+		Pool:         pool,
+		runtime:      r,
+		registration: registration,
+	}, nil
+}
+
+// newFactory instantiates module in r. Calls into an instance stop when its
+// context is done.
+func newFactory(r wazero.Runtime, module wazero.CompiledModule) Factory {
+	var count atomic.Uint64
+	return func(ctx context.Context) (compute.Invoker, error) {
+		// Module names must be unique within the runtime.
+		name := fmt.Sprintf("wasmrs-%d", count.Add(1))
+		m, err := r.InstantiateModule(ctx, module, moduleConfig(name))
+		if err != nil {
+			return nil, err
+		}
+		inst, err := host.NewInstance(ctx, m)
+		if err != nil {
+			_ = m.Close(context.Background())
+			return nil, err
+		}
+		return &moduleInstance{
+			Instance: inst,
+			module:   m,
+		}, nil
+	}
+}
+
+// moduleInstance closes the module of the host instance with it.
+type moduleInstance struct {
+	*host.Instance
+	module api.Module
+}
+
+func (i *moduleInstance) Close() error {
+	err := i.Instance.Close()
+	if cerr := i.module.Close(context.Background()); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Close closes the pool and the runtime, which stops the instances that are
+// still processing requests.
+func (c *Compute) Close() error {
+	_ = c.registration.Unregister()
+	c.Pool.Close()
+	return c.runtime.Close(context.Background())
 }
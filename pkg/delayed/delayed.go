@@ -0,0 +1,92 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package delayed stores handler invocations that should run at a future time.
+package delayed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// Invocation is a handler invocation scheduled to run at RunAt.
+type Invocation struct {
+	// ID identifies the invocation and deduplicates scheduling.
+	ID string
+	// EntityID is the ID of the entity that handles the invocation when the
+	// handler is an actor.
+	EntityID string
+	Handler  handler.Handler
+	Input    interface{}
+	RunAt    time.Time
+	// Attempts is the number of times the invocation was claimed, including
+	// the current claim.
+	Attempts int
+}
+
+// Store persists delayed invocations. Due invocations are leased rather than
+// removed so they are delivered again if they are not completed before the
+// lease expires.
+type Store interface {
+	// Schedule stores inv. It returns false if an invocation with the same ID is
+	// already pending.
+	Schedule(ctx context.Context, inv Invocation) (bool, error)
+	// Cancel removes the pending invocation with the ID. It returns false if it
+	// does not exist.
+	Cancel(ctx context.Context, id string) (bool, error)
+	// Due claims up to limit invocations due at now for the lease duration.
+	Due(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Invocation, error)
+	// Complete removes a delivered invocation.
+	Complete(ctx context.Context, id string) error
+}
+
+const (
+	// PostgresTable is the table created by the delayed://postgres
+	// migrations.
+	PostgresTable    = "nanobus_delayed"
+	DefaultKeyPrefix = "nanobus:delayed:"
+)
+
+// Default is the in-memory store used when no resource is configured. It is
+// shared by all actions and transports in the process.
+var Default = NewMemoryStore()
+
+// NewStore returns a Store backed by the redis or Postgres resource named ref.
+// If ref is nil, Default is returned.
+func NewStore(ctx context.Context, resources resource.Resources, ref *resource.Ref, keyPrefix *string) (Store, error) {
+	if ref == nil {
+		return Default, nil
+	}
+
+	res, ok := resources[string(*ref)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", *ref)
+	}
+
+	switch r := res.(type) {
+	case Store:
+		return r, nil
+	case *pgxpool.Pool:
+		return NewPostgresStore(r, PostgresTable), nil
+	case redis.UniversalClient:
+		p := DefaultKeyPrefix
+		if keyPrefix != nil {
+			p = *keyPrefix
+		}
+		return NewRedisStore(r, p), nil
+	}
+
+	return nil, fmt.Errorf("resource %q is not a redis or postgres connection", *ref)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/delayed"
+	"github.com/nanobus/nanobus/pkg/handler"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, delayed.NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+		return
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	prefix := "test:" + uuid.New().String() + ":"
+	testStore(t, delayed.NewRedisStore(client, prefix))
+}
+
+func testStore(t *testing.T, store delayed.Store) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	h := handler.Handler{Interface: "orders", Operation: "expire"}
+
+	for i, key := range []string{"b", "a", "later"} {
+		runAt := now.Add(time.Duration(i) * time.Second)
+		if key == "later" {
+			runAt = now.Add(time.Hour)
+		}
+		scheduled, err := store.Schedule(ctx, delayed.Invocation{
+			ID:       key,
+			EntityID: "entity-" + key,
+			Handler:  h,
+			Input:    map[string]interface{}{"id": key},
+			RunAt:    runAt,
+		})
+		require.NoError(t, err)
+		assert.True(t, scheduled)
+	}
+
+	scheduled, err := store.Schedule(ctx, delayed.Invocation{ID: "a", Handler: h, RunAt: now})
+	require.NoError(t, err)
+	assert.False(t, scheduled, "duplicate keys are not scheduled")
+
+	due, err := store.Due(ctx, now.Add(time.Minute), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "b", due[0].ID)
+	assert.Equal(t, "a", due[1].ID)
+	assert.Equal(t, "entity-b", due[0].EntityID)
+	assert.Equal(t, h, due[0].Handler)
+	assert.Equal(t, map[string]interface{}{"id": "b"}, due[0].Input)
+	assert.Equal(t, now, due[0].RunAt.UTC())
+	assert.Equal(t, 1, due[0].Attempts)
+
+	// Leased invocations are not due again until the lease expires.
+	due, err = store.Due(ctx, now.Add(time.Minute), 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	require.NoError(t, store.Complete(ctx, "b"))
+	due, err = store.Due(ctx, now.Add(3*time.Minute), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "a", due[0].ID)
+	assert.Equal(t, 2, due[0].Attempts)
+
+	cancelled, err := store.Cancel(ctx, "later")
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+	cancelled, err = store.Cancel(ctx, "later")
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+
+	require.NoError(t, store.Complete(ctx, "a"))
+	due, err = store.Due(ctx, now.Add(2*time.Hour), 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func TestMigrations(t *testing.T) {
+	d, err := source.Open(delayed.SourceScheme + "://postgres")
+	require.NoError(t, err)
+	defer d.Close()
+	version, err := d.First()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), version)
+
+	_, err = source.Open(delayed.SourceScheme + "://mysql")
+	assert.EqualError(t, err, `delayed migrations are not available for "mysql"`)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store for a single process that does not survive restarts.
+type MemoryStore struct {
+	mu          sync.Mutex
+	invocations map[string]*Invocation
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		invocations: make(map[string]*Invocation),
+	}
+}
+
+func (s *MemoryStore) Schedule(ctx context.Context, inv Invocation) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.invocations[inv.ID]; exists {
+		return false, nil
+	}
+	inv.Attempts = 0
+	s.invocations[inv.ID] = &inv
+
+	return true, nil
+}
+
+func (s *MemoryStore) Cancel(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.invocations[id]; !exists {
+		return false, nil
+	}
+	delete(s.invocations, id)
+
+	return true, nil
+}
+
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Invocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*Invocation
+	for _, inv := range s.invocations {
+		if !inv.RunAt.After(now) {
+			due = append(due, inv)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].RunAt.Before(due[j].RunAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]Invocation, len(due))
+	for i, inv := range due {
+		inv.Attempts++
+		claimed[i] = *inv
+		inv.RunAt = now.Add(lease)
+	}
+
+	return claimed, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.invocations, id)
+
+	return nil
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS nanobus_delayed (
+  id text PRIMARY KEY,
+  entity_id text NOT NULL DEFAULT '',
+  handler text NOT NULL,
+  input jsonb,
+  run_at timestamptz NOT NULL,
+  attempts integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS nanobus_delayed_run_at_idx ON nanobus_delayed (run_at);
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// PostgresStore stores invocations in a table. Due invocations are claimed
+// with `FOR UPDATE SKIP LOCKED` so that concurrent pollers do not block each
+// other.
+type PostgresStore struct {
+	pool        *pgxpool.Pool
+	scheduleSQL string
+	cancelSQL   string
+	dueSQL      string
+}
+
+// NewPostgresStore returns a Store that uses table. The table is created by
+// the migrations with the source URL delayed://postgres.
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	ident := pgx.Identifier(strings.Split(table, ".")).Sanitize()
+
+	return &PostgresStore{
+		pool: pool,
+		scheduleSQL: fmt.Sprintf(`INSERT INTO %s (id, entity_id, handler, input, run_at) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO NOTHING`, ident),
+		cancelSQL: fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, ident),
+		dueSQL: fmt.Sprintf(`UPDATE %[1]s t SET run_at = $2, attempts = t.attempts + 1
+FROM (SELECT id, run_at FROM %[1]s WHERE run_at <= $1 ORDER BY run_at LIMIT $3 FOR UPDATE SKIP LOCKED) due
+WHERE t.id = due.id
+RETURNING t.id, t.entity_id, t.handler, t.input, due.run_at, t.attempts`, ident),
+	}
+}
+
+func (s *PostgresStore) Schedule(ctx context.Context, inv Invocation) (bool, error) {
+	input, err := json.Marshal(inv.Input)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := s.pool.Exec(ctx, s.scheduleSQL, inv.ID, inv.EntityID, inv.Handler.String(), input, inv.RunAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PostgresStore) Cancel(ctx context.Context, id string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, s.cancelSQL, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PostgresStore) Due(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Invocation, error) {
+	rows, err := s.pool.Query(ctx, s.dueSQL, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invocations []Invocation
+	for rows.Next() {
+		var inv Invocation
+		var h string
+		var input []byte
+		if err := rows.Scan(&inv.ID, &inv.EntityID, &h, &input, &inv.RunAt, &inv.Attempts); err != nil {
+			return nil, err
+		}
+		if err := inv.Handler.FromString(h); err != nil {
+			return nil, err
+		}
+		if len(input) > 0 {
+			if err := json.Unmarshal(input, &inv.Input); err != nil {
+				return nil, fmt.Errorf("invalid input for invocation %q: %w", inv.ID, err)
+			}
+		}
+		invocations = append(invocations, inv)
+	}
+
+	return invocations, rows.Err()
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, id string) error {
+	_, err := s.pool.Exec(ctx, s.cancelSQL, id)
+	return err
+}
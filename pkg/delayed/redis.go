@@ -0,0 +1,167 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/nanobus/nanobus/pkg/handler"
+)
+
+var (
+	// scheduleScript adds the invocation unless its ID is already pending.
+	// KEYS: queue, data; ARGV: id, run at (Unix ms), data.
+	scheduleScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[2], ARGV[1]) == 1 then
+  return 0
+end
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+	// dueScript leases due invocations by moving their score to the lease
+	// expiration. KEYS: queue, data, attempts; ARGV: now, lease expiration
+	// (Unix ms), limit.
+	dueScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[3])
+local result = {}
+for i = 1, #ids, 2 do
+  local id = ids[i]
+  redis.call('ZADD', KEYS[1], ARGV[2], id)
+  local attempts = redis.call('HINCRBY', KEYS[3], id, 1)
+  local data = redis.call('HGET', KEYS[2], id)
+  table.insert(result, id)
+  table.insert(result, ids[i + 1])
+  table.insert(result, tostring(attempts))
+  table.insert(result, data or '')
+end
+return result
+`)
+)
+
+// RedisStore stores invocations in a sorted set scored by run time, with their
+// handler and input in a hash.
+type RedisStore struct {
+	client      redis.UniversalClient
+	queueKey    string
+	dataKey     string
+	attemptsKey string
+}
+
+type redisData struct {
+	EntityID string      `json:"entityId,omitempty"`
+	Handler  string      `json:"handler"`
+	Input    interface{} `json:"input"`
+}
+
+// NewRedisStore returns a RedisStore with keys that start with keyPrefix. The
+// prefix is used as a hash tag so that the scripts, which access several keys,
+// work on Redis Cluster.
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	tag := "{" + keyPrefix + "}"
+	return &RedisStore{
+		client:      client,
+		queueKey:    tag + "queue",
+		dataKey:     tag + "data",
+		attemptsKey: tag + "attempts",
+	}
+}
+
+func (s *RedisStore) Schedule(ctx context.Context, inv Invocation) (bool, error) {
+	data, err := json.Marshal(redisData{
+		EntityID: inv.EntityID,
+		Handler:  inv.Handler.String(),
+		Input:    inv.Input,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	scheduled, err := scheduleScript.Run(ctx, s.client,
+		[]string{s.queueKey, s.dataKey},
+		inv.ID, inv.RunAt.UnixMilli(), data).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return scheduled == 1, nil
+}
+
+func (s *RedisStore) Cancel(ctx context.Context, id string) (bool, error) {
+	var removed *redis.IntCmd
+	if _, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		removed = p.ZRem(ctx, s.queueKey, id)
+		p.HDel(ctx, s.dataKey, id)
+		p.HDel(ctx, s.attemptsKey, id)
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return removed.Val() == 1, nil
+}
+
+func (s *RedisStore) Due(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Invocation, error) {
+	values, err := dueScript.Run(ctx, s.client,
+		[]string{s.queueKey, s.dataKey, s.attemptsKey},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	invocations := make([]Invocation, 0, len(values)/4)
+	for i := 0; i+3 < len(values); i += 4 {
+		id := values[i]
+		runAt, err := strconv.ParseFloat(values[i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		attempts, err := strconv.Atoi(values[i+2])
+		if err != nil {
+			return nil, err
+		}
+		var data redisData
+		if err := json.Unmarshal([]byte(values[i+3]), &data); err != nil {
+			return nil, fmt.Errorf("invalid data for invocation %q: %w", id, err)
+		}
+		var h handler.Handler
+		if err := h.FromString(data.Handler); err != nil {
+			return nil, err
+		}
+
+		invocations = append(invocations, Invocation{
+			ID:       id,
+			EntityID: data.EntityID,
+			Handler:  h,
+			Input:    data.Input,
+			RunAt:    time.UnixMilli(int64(runAt)).UTC(),
+			Attempts: attempts,
+		})
+	}
+
+	return invocations, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRem(ctx, s.queueKey, id)
+		p.HDel(ctx, s.dataKey, id)
+		p.HDel(ctx, s.attemptsKey, id)
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keySlot returns the Redis Cluster hash slot of key.
+func keySlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc % 16384
+}
+
+func TestRedisKeysShareSlot(t *testing.T) {
+	// Known slots from the Redis Cluster specification.
+	assert.Equal(t, uint16(12739), keySlot("123456789"))
+	assert.Equal(t, keySlot("user1000"), keySlot("{user1000}.following"))
+
+	s := NewRedisStore(nil, DefaultKeyPrefix)
+	slot := keySlot(s.queueKey)
+	assert.Equal(t, slot, keySlot(s.dataKey))
+	assert.Equal(t, slot, keySlot(s.attemptsKey))
+	assert.NotEqual(t, keySlot(DefaultKeyPrefix+"queue"), keySlot(DefaultKeyPrefix+"data"))
+}
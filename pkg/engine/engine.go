@@ -45,6 +45,7 @@ import (
 	"github.com/nanobus/nanobus/pkg/coalesce"
 	"github.com/nanobus/nanobus/pkg/config"
 	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/fetch"
 	"github.com/nanobus/nanobus/pkg/handler"
 	"github.com/nanobus/nanobus/pkg/initialize"
 	"github.com/nanobus/nanobus/pkg/logger"
@@ -67,17 +68,25 @@ import (
 
 	// COMPONENTS
 	"github.com/nanobus/iota/go/payload"
+	"github.com/nanobus/nanobus/pkg/actors"
 	"github.com/nanobus/nanobus/pkg/compute"
 	compute_wasmrs "github.com/nanobus/nanobus/pkg/compute/wasmrs"
+	"github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/state"
 
 	// ACTIONS
 	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/amqp"
 	"github.com/nanobus/nanobus/pkg/actions/blob"
 	"github.com/nanobus/nanobus/pkg/actions/core"
 	"github.com/nanobus/nanobus/pkg/actions/dapr"
+	"github.com/nanobus/nanobus/pkg/actions/kafka"
+	"github.com/nanobus/nanobus/pkg/actions/mqtt"
 	"github.com/nanobus/nanobus/pkg/actions/postgres"
+	actions_pubsub "github.com/nanobus/nanobus/pkg/actions/pubsub"
 	"github.com/nanobus/nanobus/pkg/actions/redis"
 	"github.com/nanobus/nanobus/pkg/actions/sql"
+	actions_state "github.com/nanobus/nanobus/pkg/actions/state"
 
 	// CODECS
 	"github.com/nanobus/nanobus/pkg/codec"
@@ -90,8 +99,12 @@ import (
 	codec_text "github.com/nanobus/nanobus/pkg/codec/text"
 
 	// INITIALIZERS / DB MIGRATION
+	topology_amqp "github.com/nanobus/nanobus/pkg/initialize/amqp"
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
 	migrate_mssql "github.com/nanobus/nanobus/pkg/initialize/mssql"
+	migrate_mysql "github.com/nanobus/nanobus/pkg/initialize/mysql"
 	migrate_postgres "github.com/nanobus/nanobus/pkg/initialize/postgres"
+	migrate_sqlite "github.com/nanobus/nanobus/pkg/initialize/sqlite"
 
 	// TELEMETRY / TRACING
 	otel_tracing "github.com/nanobus/nanobus/pkg/telemetry/tracing"
@@ -101,10 +114,18 @@ import (
 
 	// TRANSPORTS
 	"github.com/nanobus/nanobus/pkg/transport"
+	transport_amqp "github.com/nanobus/nanobus/pkg/transport/amqp"
 	transport_dapr "github.com/nanobus/nanobus/pkg/transport/dapr"
+	transport_delayed "github.com/nanobus/nanobus/pkg/transport/delayed"
 	transport_http "github.com/nanobus/nanobus/pkg/transport/http"
 	transport_httprpc "github.com/nanobus/nanobus/pkg/transport/httprpc"
+	transport_kafka "github.com/nanobus/nanobus/pkg/transport/kafka"
+	transport_mqtt "github.com/nanobus/nanobus/pkg/transport/mqtt"
 	transport_nats "github.com/nanobus/nanobus/pkg/transport/nats"
+	transport_outbox "github.com/nanobus/nanobus/pkg/transport/outbox"
+	transport_postgres "github.com/nanobus/nanobus/pkg/transport/postgres"
+	transport_pubsub "github.com/nanobus/nanobus/pkg/transport/pubsub"
+	transport_redis "github.com/nanobus/nanobus/pkg/transport/redis"
 	transport_time "github.com/nanobus/nanobus/pkg/transport/time"
 
 	// TRANSPORT - FILTERS
@@ -149,6 +170,11 @@ type Info struct {
 	ResourcesFile string
 	DeveloperMode bool
 	LogLevel      zapcore.Level
+	// SkipMigrations disables migration initializers so that migrations can
+	// be run separately with `nanobus migrate`.
+	SkipMigrations bool
+	// Offline loads remote modules only from the cache.
+	Offline bool
 
 	// Service mode
 	Process []string
@@ -169,8 +195,11 @@ type Engine struct {
 	allNamespaces  runtime.Namespaces
 	codec          channel.Codec
 
+	computeRegistry  compute.Registry
 	transportInvoker transport.Invoker
 	resources        resource.Resources
+	actors           *actors.Runtime
+	entityID         string
 
 	once sync.Once
 }
@@ -210,6 +239,7 @@ func (e *Engine) LoadConfig(busConfig *runtime.BusConfig) error {
 		}
 	}
 
+	var modules []mesh.Module
 	if busConfig.Main != nil {
 		file, err := config.NormalizeUrl(*busConfig.Main, *busConfig.BaseURL)
 		if err != nil {
@@ -217,7 +247,16 @@ func (e *Engine) LoadConfig(busConfig *runtime.BusConfig) error {
 			return err
 		}
 		var computeInvoker compute.Invoker
-		mainExt := filepath.Ext(file)
+		ref, _, err := fetch.SplitDigest(file)
+		if err != nil {
+			e.log.Error(err, "Error parsing main program location", "input", busConfig.Main)
+			return err
+		}
+		mainExt := filepath.Ext(ref)
+		if strings.HasPrefix(ref, "oci://") {
+			// OCI artifacts contain a WebAssembly module.
+			mainExt = ".wasm"
+		}
 		e.log.Info("Loading main program", "filename", file)
 		switch mainExt {
 		case ".wasm":
@@ -232,7 +271,44 @@ func (e *Engine) LoadConfig(busConfig *runtime.BusConfig) error {
 			e.log.Error(err, "Error loading program", "filename", file)
 			return err
 		}
-		e.m.Link(computeInvoker)
+		modules = append(modules, mesh.Module{
+			Name:    moduleName(busConfig, "main"),
+			Invoker: computeInvoker,
+		})
+	}
+
+	for i, component := range busConfig.Compute {
+		name := moduleName(busConfig, fmt.Sprintf("compute[%d]", i))
+		e.log.Info("Loading compute module", "name", name, "type", component.Uses)
+
+		loader, ok := e.computeRegistry[component.Uses]
+		if !ok {
+			err := fmt.Errorf("compute type %q for %s is not registered", component.Uses, name)
+			e.log.Error(err, "Could not find compute type", "type", component.Uses)
+			return err
+		}
+		with, err := normalizeFilename(component.With, *busConfig.BaseURL)
+		if err != nil {
+			e.log.Error(err, "Error parsing compute module location", "name", name)
+			return err
+		}
+		computeInvoker, err := loader(e.ctx, with, e.resolveAs)
+		if err != nil {
+			e.log.Error(err, "Error loading compute module", "name", name, "type", component.Uses)
+			return err
+		}
+		modules = append(modules, mesh.Module{
+			Name:    name,
+			Invoker: computeInvoker,
+		})
+	}
+
+	// Modules are linked after the modules they import operations from.
+	for _, module := range mesh.Order(modules) {
+		if err := e.m.LinkModule(module.Name, module.Invoker); err != nil {
+			e.log.Error(err, "Could not link compute module", "name", module.Name)
+			return err
+		}
 	}
 
 	if err = processor.Initialize(busConfig); err != nil {
@@ -255,6 +331,38 @@ func (e *Engine) LoadConfig(busConfig *runtime.BusConfig) error {
 	return nil
 }
 
+// moduleName qualifies the name of a compute module with the ID of an
+// imported configuration.
+func moduleName(busConfig *runtime.BusConfig, name string) string {
+	if busConfig.ID == "" {
+		return name
+	}
+	return busConfig.ID + "/" + name
+}
+
+// normalizeFilename resolves a relative filename in a compute module's
+// configuration against the base URL of the bus configuration.
+func normalizeFilename(with interface{}, baseURL string) (interface{}, error) {
+	m, ok := with.(map[string]interface{})
+	if !ok {
+		return with, nil
+	}
+	filename, ok := m["filename"].(string)
+	if !ok || strings.Contains(filename, "://") {
+		return with, nil
+	}
+	file, err := config.NormalizeUrl(filename, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		normalized[k] = v
+	}
+	normalized["filename"] = file
+	return normalized, nil
+}
+
 func Start(ctx context.Context, info *Info) (*Engine, error) {
 	// If there is a `.env` file, load its environment variables.
 	if err := godotenv.Load(); err != nil {
@@ -320,10 +428,18 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	// Transport registration
 	transportRegistry := transport.Registry{}
 	transportRegistry.Register(
+		transport_amqp.AMQPV1,
 		transport_dapr.DaprServerV1,
+		transport_delayed.DelayedV1,
 		transport_http.HttpServerV1,
 		transport_httprpc.Load,
+		transport_kafka.KafkaV1,
+		transport_mqtt.MQTTV1,
 		transport_nats.Load,
+		transport_outbox.OutboxV1,
+		transport_postgres.NotifyV1,
+		transport_pubsub.PubSubV1,
+		transport_redis.StreamsV1,
 		transport_time.SchedulerV1,
 	)
 
@@ -383,6 +499,9 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 		postgres.Connection,
 		sql.Connection,
 		redis.Connection,
+		mqtt.Connection,
+		amqp.Connection,
+		kafka.Connection,
 
 		dapr.Client,
 		blob.URLBlob,
@@ -391,6 +510,11 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 		blob.GCSBlob,
 		blob.MemBlob,
 		blob.S3Blob,
+
+		pubsub.MemPubSub,
+		pubsub.RedisPubSub,
+		state.MemState,
+		state.RedisState,
 	)
 
 	tracingRegistry := otel_tracing.Registry{}
@@ -407,13 +531,28 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	actionRegistry.Register(postgres.All...)
 	actionRegistry.Register(sql.All...)
 	actionRegistry.Register(redis.All...)
+	actionRegistry.Register(mqtt.All...)
+	actionRegistry.Register(amqp.All...)
+	actionRegistry.Register(kafka.All...)
+	actionRegistry.Register(actions_pubsub.All...)
+	actionRegistry.Register(actions_state.All...)
 
 	actionRegistry.Register(dapr.All...)
 
 	initializerRegistry := initialize.Registry{}
 	initializerRegistry.Register(
 		migrate_mssql.MigrateMSSQLV1,
-		migrate_postgres.MigratePostgresV1)
+		migrate_mysql.MigrateMySQLV1,
+		migrate_postgres.MigratePostgresV1,
+		migrate_sqlite.MigrateSQLiteV1,
+		topology_amqp.AMQPTopologyV1)
+
+	migrationRegistry := migration.Registry{}
+	migrationRegistry.Register(
+		migrate_mssql.MigrateMSSQLV1Migration,
+		migrate_mysql.MigrateMySQLV1Migration,
+		migrate_postgres.MigratePostgresV1Migration,
+		migrate_sqlite.MigrateSQLiteV1Migration)
 
 	// Codecs
 	jsoncodec := json_codec.New()
@@ -436,6 +575,7 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 		"codec:bytes":         bytescodec,
 		"spec:namespaces":     namespaces,
 		"os:env":              env,
+		"registry:actions":    actionRegistry,
 		"registry:routers":    routerRegistry,
 		"registry:middleware": middlewareRegistry,
 		"developerMode":       info.DeveloperMode,
@@ -484,6 +624,8 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	tracer := otel.Tracer("NanoBus")
 	dependencies["system:tracer"] = tracer
 
+	dependencies["system:fetcher"] = fetch.New(info.Offline)
+
 	if busConfig.Codecs == nil {
 		busConfig.Codecs = map[string]runtime.Component{}
 	}
@@ -534,6 +676,10 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	}
 
 	for name, spec := range busConfig.Initializers {
+		if _, ok := migrationRegistry[spec.Uses]; ok && info.SkipMigrations {
+			log.Info("Skipping migration", "name", name, "type", spec.Uses)
+			continue
+		}
 		log.Info("Initializer running", "name", name, "type", spec.Uses)
 		loader, ok := initializerRegistry[spec.Uses]
 		if !ok {
@@ -575,13 +721,38 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	}
 	dependencies["resource:lookup"] = resources
 
-	dependencies["state:invoker"] = func(ctx context.Context, namespace, id, key string) ([]byte, error) {
-		// TODO: Retrieve state
-		return []byte{}, nil
+	actorsConfig := runtime.DefaultActors()
+	if busConfig.Actors != nil {
+		actorsConfig = *busConfig.Actors
 	}
+	var actorStore actors.Store = actors.NewMemoryStore()
+	if actorsConfig.Resource != nil {
+		r, ok := resources[*actorsConfig.Resource]
+		if !ok {
+			log.Error(nil, "Could not find actor state store", "resource", *actorsConfig.Resource)
+			return nil, fmt.Errorf("resource %q is not registered", *actorsConfig.Resource)
+		}
+		if actorStore, err = actors.NewStore(r, actorsConfig.Table); err != nil {
+			log.Error(err, "Could not create actor state store", "resource", *actorsConfig.Resource)
+			return nil, err
+		}
+	}
+	actorRuntime := actors.New(log, actorStore, actors.Config{
+		IdleTimeout:      time.Duration(actorsConfig.IdleTimeout),
+		ScanInterval:     time.Duration(actorsConfig.ScanInterval),
+		LeaseTimeout:     time.Duration(actorsConfig.LeaseTimeout),
+		ReminderInterval: time.Duration(actorsConfig.ReminderInterval),
+	})
+	dependencies["actors:runtime"] = actorRuntime
+	dependencies["state:invoker"] = compute.StateInvoker(actorRuntime.GetState)
 
 	m := mesh.New(tracer)
 	dependencies["compute:mesh"] = m
+	m.Link(runtime.NewInvoker(log, runtime.Namespaces{
+		actors.StateInterface: runtime.Functions{
+			"get": actorRuntime.GetStateAction,
+		},
+	}, msgpackcodec))
 
 	allNamespaces := make(runtime.Namespaces)
 	dependencies["system:interfaces"] = allNamespaces
@@ -610,6 +781,10 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 		allNamespaces:  allNamespaces,
 		codec:          msgpackcodec,
 		resources:      resources,
+		actors:         actorRuntime,
+		entityID:       info.EntityID,
+
+		computeRegistry: computeRegistry,
 	}
 
 	if err := e.LoadConfig(busConfig); err != nil {
@@ -639,6 +814,7 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	}
 
 	interfaces := namespaces.ToInterfaces()
+	actorTypes := statefulServices(namespaces)
 
 	// Check for unsatified imports
 	ops := m.Unsatisfied()
@@ -725,6 +901,46 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 	// 	w.Write([]byte("OK"))
 	// }
 
+	// invoke runs the operation's pipeline or calls the compute module that
+	// exports it.
+	invoke := func(ctx context.Context, h handler.Handler, input interface{}, data actions.Data) (interface{}, error) {
+		// TODO: Use merged map of interfaces here
+		response, ok, err := allNamespaces.Invoke(ctx, h, data)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		// No pipeline exits for the operation so invoke directly.
+		if !ok {
+			payloadData, err := msgpack.Marshal(input)
+			if err != nil {
+				return nil, translateError(err)
+			}
+
+			metadata := make([]byte, 8)
+			p := payload.New(payloadData, metadata)
+
+			future := m.RequestResponse(ctx, h, p)
+			if future == nil {
+				return nil, errorz.New(errorz.Unimplemented, fmt.Sprintf("%s is not implemented", h.String()))
+			}
+			result, err := future.Block()
+			if err != nil {
+				return nil, translateError(err)
+			}
+
+			if len(result.Data()) > 0 {
+				var resultDecoded interface{}
+				if err := msgpack.Unmarshal(result.Data(), &resultDecoded); err != nil {
+					return nil, translateError(err)
+				}
+				response = resultDecoded
+			}
+		}
+
+		return response, err
+	}
+
 	transportInvoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
 		if err := coalesceInput(interfaces, h, input); err != nil {
 			return nil, err
@@ -761,46 +977,35 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 
 		data["env"] = env
 
-		ctx = handler.ToContext(ctx, h)
-
-		// TODO: Use merged map of interfaces here
-		response, ok, err := allNamespaces.Invoke(ctx, h, data)
-		if err != nil {
-			return nil, translateError(err)
-		}
-
-		// No pipeline exits for the operation so invoke directly.
-		if !ok {
-			payloadData, err := msgpack.Marshal(input)
-			if err != nil {
-				return nil, translateError(err)
+		// Transports can expose additional variables but not replace the
+		// built-in ones.
+		for name, value := range transport.VariablesFromContext(ctx) {
+			if _, exists := data[name]; !exists {
+				data[name] = value
 			}
+		}
 
-			metadata := make([]byte, 8)
-			p := payload.New(payloadData, metadata)
+		ctx = handler.ToContext(ctx, h)
 
-			future := m.RequestResponse(ctx, h, p)
-			if future == nil {
-				return nil, errorz.New(errorz.Unimplemented, fmt.Sprintf("%s is not implemented", h.String()))
+		if _, isActor := actorTypes[h.Interface]; isActor && id != "" {
+			data["entity"] = map[string]interface{}{
+				"type": h.Interface,
+				"id":   id,
 			}
-			result, err := future.Block()
+			response, err := actorRuntime.Invoke(ctx, h.Interface, id, func(ctx context.Context) (interface{}, error) {
+				return invoke(ctx, h, input, data)
+			})
 			if err != nil {
 				return nil, translateError(err)
 			}
-
-			if len(result.Data()) > 0 {
-				var resultDecoded interface{}
-				if err := msgpack.Unmarshal(result.Data(), &resultDecoded); err != nil {
-					return nil, translateError(err)
-				}
-				response = resultDecoded
-			}
+			return response, nil
 		}
 
-		return response, err
+		return invoke(ctx, h, input, data)
 	}
 	e.transportInvoker = transportInvoker
 	dependencies["transport:invoker"] = transport.Invoker(transportInvoker)
+	actorRuntime.SetInvoker(transportInvoker)
 
 	if info.Mode == ModeService {
 		if len(busConfig.Transports) == 0 {
@@ -839,6 +1044,11 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 				m.Close()
 			})
 		}
+		{
+			g.Add(actorRuntime.Run, func(error) {
+				actorRuntime.Close()
+			})
+		}
 
 		for name, comp := range busConfig.Transports {
 			name := name // Make copy
@@ -878,11 +1088,11 @@ func Start(ctx context.Context, info *Info) (*Engine, error) {
 }
 
 func (e *Engine) InvokeUnsafe(handler handler.Handler, input any) (any, error) {
-	return e.transportInvoker(e.ctx, handler, "", input, transport.BypassAuthorization)
+	return e.transportInvoker(e.ctx, handler, e.entityID, input, transport.BypassAuthorization)
 }
 
 func (e *Engine) Invoke(handler handler.Handler, input any) (any, error) {
-	return e.transportInvoker(e.ctx, handler, "", input, transport.PerformAuthorization)
+	return e.transportInvoker(e.ctx, handler, e.entityID, input, transport.PerformAuthorization)
 }
 
 func (e *Engine) Shutdown() {
@@ -892,6 +1102,9 @@ func (e *Engine) Shutdown() {
 		// Shutdown mesh
 		e.m.Close()
 
+		// Deactivate entities
+		e.actors.Close()
+
 		// Shutdown resources
 		for name, r := range e.resources {
 			switch c := r.(type) {
@@ -1008,6 +1221,23 @@ func environmentToMap(environment []string, getkeyval func(item string) (key, va
 	return items
 }
 
+// statefulServices returns the interfaces of services annotated with
+// `@actor`, `@stateful` or `@workflow`.
+func statefulServices(namespaces spec.Namespaces) map[string]struct{} {
+	services := make(map[string]struct{})
+	for _, namespace := range namespaces {
+		for _, service := range namespace.Services {
+			_, isActor := service.Annotation("actor")
+			_, isStateful := service.Annotation("stateful")
+			_, isWorkflow := service.Annotation("workflow")
+			if isActor || isStateful || isWorkflow {
+				services[namespace.Name+"."+service.Name] = struct{}{}
+			}
+		}
+	}
+	return services
+}
+
 func coalesceInput(interfaces spec.Interfaces, h handler.Handler, input interface{}) error {
 	if oper, ok := interfaces.Operation(h); ok {
 		if oper.Parameters != nil {
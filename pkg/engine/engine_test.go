@@ -42,3 +42,22 @@ func TestInvoke(t *testing.T) {
 	assert.Equal(t, "Hello, World", response)
 	fmt.Println(response)
 }
+
+func TestNormalizeFilename(t *testing.T) {
+	with := map[string]interface{}{
+		"filename": "build/app.wasm",
+		"poolSize": 2,
+	}
+	normalized, err := normalizeFilename(with, "/apps/greeter")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"filename": "file:///apps/greeter/build/app.wasm",
+		"poolSize": 2,
+	}, normalized)
+	assert.Equal(t, "build/app.wasm", with["filename"])
+
+	with = map[string]interface{}{"filename": "oci://registry.example.com/app:1.0.0"}
+	normalized, err = normalizeFilename(with, "/apps/greeter")
+	assert.NoError(t, err)
+	assert.Equal(t, with, normalized)
+}
@@ -0,0 +1,469 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package find builds the parts of an entity find that are decided per
+// request: the filters, the sort order and the cursor position. It is shared
+// by the postgres and sql actions, which differ in how they write
+// placeholders.
+package find
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/spf13/cast"
+
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/spec"
+)
+
+// Placeholder is the style of the bind parameters in generated SQL.
+type Placeholder int
+
+const (
+	// Question writes every parameter as ?.
+	Question Placeholder = iota
+	// Dollar numbers the parameters as $1, $2 and so on.
+	Dollar
+)
+
+// Rebind replaces the ? placeholders in sql with the style of p.
+func (p Placeholder) Rebind(sql string) string {
+	if p != Dollar {
+		return sql
+	}
+
+	var buf strings.Builder
+	n := 0
+	for _, r := range sql {
+		if r == '?' {
+			n++
+			buf.WriteString("$" + strconv.Itoa(n))
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// Query holds the filters, sort order and cursor position of a find. Values
+// are always bound as arguments and columns always come from the entity's
+// fields.
+type Query struct {
+	Offset int64
+	Limit  int64
+
+	placeholder Placeholder
+	conditions  []string
+	args        []interface{}
+	orderBy     []sortColumn
+	// after is the position decoded from the cursor, if any.
+	after []interface{}
+}
+
+type sortColumn struct {
+	field      string
+	column     string
+	descending bool
+}
+
+// New returns an empty Query that writes placeholders in the style of p.
+func New(p Placeholder) *Query {
+	return &Query{
+		placeholder: p,
+	}
+}
+
+// Columns maps the fields of t that are stored in columns to the column
+// names, optionally restricted to allowed.
+func Columns(t *spec.Type, allowed []string) (map[string]string, error) {
+	columns := make(map[string]string, len(t.Fields))
+	for _, f := range t.Fields {
+		if _, ok := f.Annotation("hasMany"); ok {
+			continue
+		}
+		column := ColumnName(f)
+		if _, ok := f.Annotation("key"); ok {
+			column = KeyColumn(t)
+		}
+		columns[f.Name] = column
+	}
+	if len(allowed) == 0 {
+		return columns, nil
+	}
+
+	restricted := make(map[string]string, len(allowed))
+	for _, name := range allowed {
+		column, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a field of %s", name, t.Name)
+		}
+		restricted[name] = column
+	}
+	return restricted, nil
+}
+
+// ColumnName returns the column f is stored in.
+func ColumnName(f *spec.Field) string {
+	column := annotationValue(f, "column", "name", "")
+	if column == "" {
+		column = annotationValue(f, "hasOne", "foreignKey", "")
+	}
+	if column == "" {
+		column = strcase.ToSnake(f.Name)
+	}
+	return column
+}
+
+// KeyField returns the name of the key field of t or an empty string if it
+// does not have one.
+func KeyField(t *spec.Type) string {
+	for _, f := range t.Fields {
+		if _, ok := f.Annotation("key"); ok {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// KeyColumn returns the primary key column of t. Without @column the key
+// field is stored in its snake case column like every other field, matching
+// the tables created by the schema migrations.
+func KeyColumn(t *spec.Type) string {
+	if _, ok := t.Annotation("primaryKey"); ok {
+		return annotationValue(t, "primaryKey", "name", "")
+	}
+	for _, f := range t.Fields {
+		if _, ok := f.Annotation("key"); ok {
+			return annotationValue(f, "column", "name", strcase.ToSnake(f.Name))
+		}
+	}
+	return ""
+}
+
+// SetOrderBy parses a comma-separated string or list of fields, each
+// optionally prefixed with - for descending order.
+func (q *Query) SetOrderBy(value interface{}, columns map[string]string) error {
+	var fields []string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		fields = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			fields = append(fields, cast.ToString(item))
+		}
+	case []string:
+		fields = v
+	default:
+		return invalidArgument("orderBy must be a string or a list of strings")
+	}
+
+	orderBy := make([]sortColumn, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		s := sortColumn{field: field}
+		if strings.HasPrefix(field, "-") {
+			s.field = field[1:]
+			s.descending = true
+		} else {
+			s.field = strings.TrimPrefix(field, "+")
+		}
+		column, ok := columns[s.field]
+		if !ok {
+			return invalidArgument("cannot sort by %q", s.field)
+		}
+		s.column = column
+		orderBy = append(orderBy, s)
+	}
+	q.orderBy = orderBy
+
+	return nil
+}
+
+// Ordered reports whether the query has a sort order.
+func (q *Query) Ordered() bool {
+	return len(q.orderBy) > 0
+}
+
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+	"in":   "IN",
+}
+
+// AddFilter adds conditions for a filter map. Keys are field names, optionally
+// suffixed with an operator in brackets as in `total[gte]`. Values are
+// compared for equality unless they are lists, which use `in`, or maps of
+// operators to values. Empty values are skipped so that optional query
+// parameters can be mapped directly.
+func (q *Query) AddFilter(value interface{}, columns map[string]string) error {
+	if value == nil {
+		return nil
+	}
+	filter, ok := value.(map[string]interface{})
+	if !ok {
+		return invalidArgument("filter must be a map")
+	}
+
+	// Sort the keys so that the generated SQL is stable.
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, op := key, ""
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			field, op = key[:i], key[i+1:len(key)-1]
+		}
+		column, ok := columns[field]
+		if !ok {
+			return invalidArgument("cannot filter by %q", field)
+		}
+
+		v := filter[key]
+		if ops, ok := v.(map[string]interface{}); ok && op == "" {
+			names := make([]string, 0, len(ops))
+			for name := range ops {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if err := q.addCondition(column, name, ops[name]); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if op == "" {
+			op = "eq"
+			if _, ok := v.([]interface{}); ok {
+				op = "in"
+			}
+		}
+		if err := q.addCondition(column, op, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Query) addCondition(column, op string, value interface{}) error {
+	operator, ok := filterOperators[op]
+	if !ok {
+		return invalidArgument("unknown filter operator %q", op)
+	}
+	if isNil(value) || value == "" {
+		return nil
+	}
+
+	if op != "in" {
+		q.conditions = append(q.conditions, column+" "+operator+" ?")
+		q.args = append(q.args, value)
+		return nil
+	}
+
+	var values []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		values = v
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			values = append(values, s)
+		}
+	default:
+		values = []interface{}{v}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	q.conditions = append(q.conditions,
+		column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")")
+	q.args = append(q.args, values...)
+
+	return nil
+}
+
+// cursor is the decoded form of an opaque cursor token.
+type cursor struct {
+	// OrderBy is the sort order the cursor was created with.
+	OrderBy string        `json:"o"`
+	Values  []interface{} `json:"v"`
+}
+
+func (q *Query) orderKey() string {
+	parts := make([]string, len(q.orderBy))
+	for i, s := range q.orderBy {
+		if s.descending {
+			parts[i] = "-" + s.field
+		} else {
+			parts[i] = s.field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// SetCursor appends the key of t to the sort order, if missing, so that the
+// order is total as keyset pagination requires. It then decodes token, if
+// any, and positions the query after it.
+func (q *Query) SetCursor(t *spec.Type, token string) error {
+	q.withKey(t)
+	if token == "" {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return invalidArgument("invalid cursor")
+	}
+	var c cursor
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || len(c.Values) != len(q.orderBy) {
+		return invalidArgument("invalid cursor")
+	}
+	if c.OrderBy != q.orderKey() {
+		return invalidArgument("cursor does not match the sort order")
+	}
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			if c.Values[i], err = n.Int64(); err != nil {
+				c.Values[i], _ = n.Float64()
+			}
+		}
+	}
+	q.after = c.Values
+	return nil
+}
+
+func (q *Query) withKey(t *spec.Type) {
+	field := KeyField(t)
+	for _, s := range q.orderBy {
+		if s.field == field {
+			return
+		}
+	}
+	q.orderBy = append(q.orderBy, sortColumn{
+		field:  field,
+		column: KeyColumn(t),
+	})
+}
+
+// NextCursor returns the token for the page after record.
+func (q *Query) NextCursor(record map[string]interface{}) (string, error) {
+	c := cursor{
+		OrderBy: q.orderKey(),
+		Values:  make([]interface{}, len(q.orderBy)),
+	}
+	for i, s := range q.orderBy {
+		c.Values[i] = record[s.field]
+	}
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// afterCondition returns the keyset condition for the cursor position. It is
+// expanded into ORs so that mixed sort directions work on all databases.
+func (q *Query) afterCondition() (string, []interface{}) {
+	if len(q.after) == 0 {
+		return "", nil
+	}
+
+	var ors []string
+	var args []interface{}
+	for i, s := range q.orderBy {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, q.orderBy[j].column+" = ?")
+			args = append(args, q.after[j])
+		}
+		op := " > ?"
+		if s.descending {
+			op = " < ?"
+		}
+		ands = append(ands, s.column+op)
+		args = append(args, q.after[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// WhereSQL returns the WHERE clause, if any, and its arguments. conditions
+// are written with ? placeholders and come before the filters. The condition
+// for the cursor position is added when after is set, which counts leave out.
+func (q *Query) WhereSQL(conditions []string, args []interface{}, after bool) (string, []interface{}) {
+	conditions = append(conditions[:len(conditions):len(conditions)], q.conditions...)
+	args = append(args[:len(args):len(args)], q.args...)
+	if after {
+		if c, afterArgs := q.afterCondition(); c != "" {
+			conditions = append(conditions, c)
+			args = append(args, afterArgs...)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return q.placeholder.Rebind(" WHERE " + strings.Join(conditions, " AND ")), args
+}
+
+// OrderBySQL returns the ORDER BY clause, if any.
+func (q *Query) OrderBySQL() string {
+	if len(q.orderBy) == 0 {
+		return ""
+	}
+	parts := make([]string, len(q.orderBy))
+	for i, s := range q.orderBy {
+		parts[i] = s.column
+		if s.descending {
+			parts[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+func annotationValue(a spec.Annotator, annotation, argument, defaultValue string) string {
+	if av, ok := a.Annotation(annotation); ok {
+		if arg, ok := av.Argument(argument); ok {
+			return fmt.Sprintf("%v", arg.Value)
+		}
+	}
+	return defaultValue
+}
+
+func isNil(val interface{}) bool {
+	return val == nil ||
+		(reflect.ValueOf(val).Kind() == reflect.Ptr &&
+			reflect.ValueOf(val).IsNil())
+}
+
+func invalidArgument(format string, args ...interface{}) error {
+	return errorz.New(errorz.InvalidArgument, fmt.Sprintf(format, args...))
+}
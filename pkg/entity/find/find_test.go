@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package find_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/entity/find"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/spec/apex"
+)
+
+const schema = `
+namespace "shop"
+
+type Order @entity(table: "orders") {
+  id: string @key @column(name: "order_id")
+  customerId: string
+  total: f64
+  items: [LineItem] @hasMany(key: "order_id")
+}
+
+type LineItem @entity(table: "line_items") {
+  id: i64 @key
+  sku: string
+}
+`
+
+func orderType(t *testing.T) *spec.Type {
+	ns, err := apex.Parse([]byte(schema))
+	require.NoError(t, err)
+	typ, ok := ns.Type("Order")
+	require.True(t, ok)
+	return typ
+}
+
+func TestColumns(t *testing.T) {
+	typ := orderType(t)
+
+	columns, err := find.Columns(typ, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"id":         "order_id",
+		"customerId": "customer_id",
+		"total":      "total",
+	}, columns)
+
+	columns, err = find.Columns(typ, []string{"total"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"total": "total"}, columns)
+
+	_, err = find.Columns(typ, []string{"items"})
+	assert.EqualError(t, err, "items is not a field of Order")
+}
+
+func TestDollarPlaceholders(t *testing.T) {
+	typ := orderType(t)
+	columns, err := find.Columns(typ, nil)
+	require.NoError(t, err)
+
+	q := find.New(find.Dollar)
+	require.NoError(t, q.SetOrderBy("-total", columns))
+	require.NoError(t, q.AddFilter(map[string]interface{}{
+		"customerId": []interface{}{"c1", "c2"},
+		"total":      map[string]interface{}{"gte": 10, "lt": 100},
+		"id[like]":   "",
+	}, columns))
+	require.NoError(t, q.SetCursor(typ, ""))
+
+	where, args := q.WhereSQL([]string{"tenant_id = ?"}, []interface{}{"t1"}, true)
+	assert.Equal(t, " WHERE tenant_id = $1 AND customer_id IN ($2, $3) AND total >= $4 AND total < $5", where)
+	assert.Equal(t, []interface{}{"t1", "c1", "c2", 10, 100}, args)
+	assert.Equal(t, " ORDER BY total DESC, order_id", q.OrderBySQL())
+
+	next, err := q.NextCursor(map[string]interface{}{"id": "o2", "total": 50})
+	require.NoError(t, err)
+
+	q2 := find.New(find.Dollar)
+	require.NoError(t, q2.SetOrderBy("-total", columns))
+	require.NoError(t, q2.AddFilter(map[string]interface{}{"customerId": "c1"}, columns))
+	require.NoError(t, q2.SetCursor(typ, next))
+
+	where, args = q2.WhereSQL(nil, nil, true)
+	assert.Equal(t, " WHERE customer_id = $1 AND ((total < $2) OR (total = $3 AND order_id > $4))", where)
+	assert.Equal(t, []interface{}{"c1", int64(50), int64(50), "o2"}, args)
+
+	// Counts leave out the cursor position.
+	where, args = q2.WhereSQL(nil, nil, false)
+	assert.Equal(t, " WHERE customer_id = $1", where)
+	assert.Equal(t, []interface{}{"c1"}, args)
+}
+
+func TestQuestionPlaceholders(t *testing.T) {
+	columns, err := find.Columns(orderType(t), nil)
+	require.NoError(t, err)
+
+	q := find.New(find.Question)
+	require.NoError(t, q.AddFilter(map[string]interface{}{"customerId[in]": "c1,c2"}, columns))
+
+	where, args := q.WhereSQL([]string{"tenant_id = ?"}, []interface{}{"t1"}, true)
+	assert.Equal(t, " WHERE tenant_id = ? AND customer_id IN (?, ?)", where)
+	assert.Equal(t, []interface{}{"t1", "c1", "c2"}, args)
+
+	where, args = find.New(find.Question).WhereSQL(nil, nil, true)
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+}
+
+func TestInvalidArguments(t *testing.T) {
+	typ := orderType(t)
+	columns, err := find.Columns(typ, []string{"total"})
+	require.NoError(t, err)
+
+	tests := []func(q *find.Query) error{
+		func(q *find.Query) error { return q.SetOrderBy("customerId", columns) },
+		func(q *find.Query) error { return q.SetOrderBy(42, columns) },
+		func(q *find.Query) error { return q.AddFilter("total", columns) },
+		func(q *find.Query) error {
+			return q.AddFilter(map[string]interface{}{"total[regex]": "x"}, columns)
+		},
+		func(q *find.Query) error { return q.SetCursor(typ, "not a cursor") },
+	}
+	for i, test := range tests {
+		err := test(find.New(find.Dollar))
+		var errz *errorz.Error
+		require.ErrorAs(t, err, &errz, "test %d", i)
+		assert.Equal(t, errorz.InvalidArgument, errz.Code)
+	}
+
+	// A cursor only applies to the sort order it was created with.
+	q := find.New(find.Dollar)
+	require.NoError(t, q.SetOrderBy("total", columns))
+	require.NoError(t, q.SetCursor(typ, ""))
+	next, err := q.NextCursor(map[string]interface{}{"id": "o1", "total": 5})
+	require.NoError(t, err)
+	q = find.New(find.Dollar)
+	require.NoError(t, q.SetOrderBy("-total", columns))
+	err = q.SetCursor(typ, next)
+	assert.EqualError(t, err, "cursor does not match the sort order")
+}
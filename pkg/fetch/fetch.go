@@ -0,0 +1,287 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package fetch reads modules from files, HTTP servers and OCI registries.
+// Locations may end with @sha256:<hex> to pin their content. Remote modules
+// are cached on disk by digest so they can be loaded without network access.
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/nanobus/nanobus/pkg/oci"
+)
+
+var (
+	// ErrNotCached is returned in offline mode for modules that are not cached.
+	ErrNotCached = errors.New("module is not cached")
+	// ErrInsecure is returned for http:// locations without a digest.
+	ErrInsecure = errors.New("modules must be downloaded over https or pinned with a digest")
+)
+
+// Fetcher reads modules and caches the remote ones.
+type Fetcher struct {
+	// CacheDir is the directory of cached modules. If empty, the nanobus
+	// directory of the user cache directory is used.
+	CacheDir string
+	// Offline only loads remote modules from the cache.
+	Offline bool
+	// Client downloads modules from HTTP servers.
+	Client *http.Client
+	// PullModule downloads an OCI artifact to a directory and returns the
+	// path of its module.
+	PullModule func(ctx context.Context, reference, target string) (string, error)
+
+	cacheOnce sync.Once
+	cacheErr  error
+}
+
+// New returns a Fetcher that caches modules in the nanobus directory of the
+// user cache directory. The directory is only determined once a remote module
+// is fetched, so loading files works without one.
+func New(offline bool) *Fetcher {
+	return &Fetcher{
+		Offline:    offline,
+		Client:     http.DefaultClient,
+		PullModule: oci.PullModule,
+	}
+}
+
+// initCache sets CacheDir to the default directory if it is empty.
+func (f *Fetcher) initCache() error {
+	f.cacheOnce.Do(func() {
+		if f.CacheDir != "" {
+			return
+		}
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			f.cacheErr = fmt.Errorf("could not determine the cache directory: %w", err)
+			return
+		}
+		f.CacheDir = filepath.Join(dir, "nanobus", "modules")
+	})
+	return f.cacheErr
+}
+
+// SplitDigest separates the digest from a location ending with
+// @sha256:<hex>.
+func SplitDigest(location string) (string, digest.Digest, error) {
+	i := strings.LastIndex(location, "@sha256:")
+	if i < 0 {
+		return location, "", nil
+	}
+	d, err := digest.Parse(location[i+1:])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid digest in %q: %w", location, err)
+	}
+	return location[:i], d, nil
+}
+
+// Fetch returns the contents of the module at location, which is a file path
+// or a file://, http://, https:// or oci:// URL. http:// URLs must be pinned
+// with a digest since their content could be altered in transit.
+//
+// For files and HTTP, the digest is that of the module. For OCI, it is the
+// digest of the manifest and the registry content is verified as it is
+// pulled.
+func (f *Fetcher) Fetch(ctx context.Context, location string) ([]byte, error) {
+	ref, want, err := SplitDigest(location)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		path := ref
+		if u.Scheme == "file" {
+			path = u.Path
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return data, verify(location, data, want)
+	case "http":
+		if want == "" {
+			return nil, fmt.Errorf("could not load %q: %w", location, ErrInsecure)
+		}
+	case "https", "oci":
+	default:
+		return nil, fmt.Errorf("unsupported module location %q", location)
+	}
+
+	if err := f.initCache(); err != nil {
+		return nil, err
+	}
+
+	// Pinned HTTP modules are found by their digest.
+	if want != "" && u.Scheme != "oci" {
+		if data, err := f.readBlob(want); err == nil {
+			return data, nil
+		}
+	}
+	if f.Offline || (want != "" && u.Scheme == "oci") {
+		data, err := f.readRef(location)
+		if err == nil {
+			return data, nil
+		}
+		if f.Offline {
+			return nil, fmt.Errorf("could not load %q in offline mode: %w", location, ErrNotCached)
+		}
+	}
+
+	var data []byte
+	if u.Scheme == "oci" {
+		data, err = f.pull(ctx, strings.TrimPrefix(location, "oci://"))
+	} else {
+		data, err = f.download(ctx, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "oci" {
+		if err := verify(location, data, want); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.store(location, data); err != nil {
+		return nil, fmt.Errorf("could not cache %q: %w", location, err)
+	}
+
+	return data, nil
+}
+
+func verify(location string, data []byte, want digest.Digest) error {
+	if want == "" {
+		return nil
+	}
+	if got := want.Algorithm().FromBytes(data); got != want {
+		return fmt.Errorf("digest of %q is %s, expected %s", location, got, want)
+	}
+	return nil
+}
+
+func (f *Fetcher) download(ctx context.Context, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %q: %s", location, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (f *Fetcher) pull(ctx context.Context, reference string) ([]byte, error) {
+	if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(f.CacheDir, "pull-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path, err := f.PullModule(ctx, reference, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+// The cache stores modules in blobs/sha256/<hex> and the digest of the module
+// last fetched from each location in refs/<sha256 of location>.
+
+func (f *Fetcher) blobPath(d digest.Digest) string {
+	return filepath.Join(f.CacheDir, "blobs", d.Algorithm().String(), d.Encoded())
+}
+
+func (f *Fetcher) refPath(location string) string {
+	sum := sha256.Sum256([]byte(location))
+	return filepath.Join(f.CacheDir, "refs", hex.EncodeToString(sum[:]))
+}
+
+// readBlob returns a cached module if its contents match the digest.
+func (f *Fetcher) readBlob(d digest.Digest) ([]byte, error) {
+	data, err := os.ReadFile(f.blobPath(d))
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(d.String(), data, d); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (f *Fetcher) readRef(location string) ([]byte, error) {
+	ref, err := os.ReadFile(f.refPath(location))
+	if err != nil {
+		return nil, err
+	}
+	d, err := digest.Parse(string(ref))
+	if err != nil {
+		return nil, err
+	}
+	return f.readBlob(d)
+}
+
+func (f *Fetcher) store(location string, data []byte) error {
+	d := digest.FromBytes(data)
+	if err := writeFile(f.blobPath(d), data); err != nil {
+		return err
+	}
+	return writeFile(f.refPath(location), []byte(d.String()))
+}
+
+// writeFile writes the file atomically so concurrent readers never see
+// partial contents.
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package fetch_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/fetch"
+)
+
+var module = []byte("\x00asm\x01\x00\x00\x00")
+
+func newFetcher(t *testing.T) *fetch.Fetcher {
+	return &fetch.Fetcher{
+		CacheDir: t.TempDir(),
+		Client:   http.DefaultClient,
+		PullModule: func(ctx context.Context, reference, target string) (string, error) {
+			t.Fatalf("unexpected pull of %s", reference)
+			return "", nil
+		},
+	}
+}
+
+func serve(t *testing.T, tls bool) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/app.wasm" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(module)
+	})
+	s := httptest.NewUnstartedServer(h)
+	if tls {
+		s.StartTLS()
+	} else {
+		s.Start()
+	}
+	t.Cleanup(s.Close)
+	return s, &requests
+}
+
+func TestSplitDigest(t *testing.T) {
+	d := digest.FromBytes(module)
+
+	ref, got, err := fetch.SplitDigest("oci://registry/app:1.0.0@" + d.String())
+	require.NoError(t, err)
+	assert.Equal(t, "oci://registry/app:1.0.0", ref)
+	assert.Equal(t, d, got)
+
+	ref, got, err = fetch.SplitDigest("app.wasm")
+	require.NoError(t, err)
+	assert.Equal(t, "app.wasm", ref)
+	assert.Empty(t, got)
+
+	_, _, err = fetch.SplitDigest("app.wasm@sha256:abc")
+	assert.Error(t, err)
+}
+
+func TestFile(t *testing.T) {
+	f := newFetcher(t)
+	path := filepath.Join(t.TempDir(), "app.wasm")
+	require.NoError(t, os.WriteFile(path, module, 0o644))
+
+	data, err := f.Fetch(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+
+	data, err = f.Fetch(context.Background(), "file://"+path+"@"+digest.FromBytes(module).String())
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+
+	_, err = f.Fetch(context.Background(), path+"@"+digest.FromString("other").String())
+	assert.ErrorContains(t, err, "expected sha256:")
+}
+
+func TestHTTP(t *testing.T) {
+	s, requests := serve(t, false)
+	f := newFetcher(t)
+	location := s.URL + "/app.wasm@" + digest.FromBytes(module).String()
+
+	data, err := f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+
+	// Pinned modules are loaded from the cache.
+	data, err = f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+	assert.EqualValues(t, 1, requests.Load())
+
+	_, err = f.Fetch(context.Background(), s.URL+"/app.wasm@"+digest.FromString("other").String())
+	assert.ErrorContains(t, err, "expected sha256:")
+
+	_, err = f.Fetch(context.Background(), s.URL+"/missing.wasm@"+digest.FromString("missing").String())
+	assert.ErrorContains(t, err, "404")
+
+	// Modules downloaded over plain HTTP must be pinned.
+	_, err = f.Fetch(context.Background(), s.URL+"/app.wasm")
+	assert.ErrorIs(t, err, fetch.ErrInsecure)
+	assert.EqualValues(t, 3, requests.Load())
+}
+
+func TestOffline(t *testing.T) {
+	s, requests := serve(t, true)
+	f := newFetcher(t)
+	f.Client = s.Client()
+	location := s.URL + "/app.wasm"
+
+	f.Offline = true
+	_, err := f.Fetch(context.Background(), location)
+	assert.ErrorIs(t, err, fetch.ErrNotCached)
+	assert.EqualValues(t, 0, requests.Load())
+
+	f.Offline = false
+	_, err = f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+
+	f.Offline = true
+	data, err := f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+	assert.EqualValues(t, 1, requests.Load())
+}
+
+func TestOCI(t *testing.T) {
+	f := newFetcher(t)
+	var pulled []string
+	f.PullModule = func(ctx context.Context, reference, target string) (string, error) {
+		pulled = append(pulled, reference)
+		path := filepath.Join(target, "app.wasm")
+		return path, os.WriteFile(path, module, 0o644)
+	}
+	location := "oci://registry.example.com/apps/app:1.0.0@" + digest.FromString("manifest").String()
+
+	data, err := f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+
+	// Pinned references are loaded from the cache.
+	data, err = f.Fetch(context.Background(), location)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+	assert.Equal(t, []string{"registry.example.com/apps/app:1.0.0@" + digest.FromString("manifest").String()}, pulled)
+
+	// The pull directory is removed.
+	entries, err := os.ReadDir(f.CacheDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.Contains(t, []string{"blobs", "refs"}, e.Name())
+	}
+}
+
+func TestNewWithoutCacheDir(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	f := fetch.New(false)
+
+	// Files do not need the cache.
+	path := filepath.Join(t.TempDir(), "app.wasm")
+	require.NoError(t, os.WriteFile(path, module, 0o644))
+	data, err := f.Fetch(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, module, data)
+
+	_, err = f.Fetch(context.Background(), "oci://registry/app:1.0.0")
+	assert.ErrorContains(t, err, "could not determine the cache directory")
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+//go:generate apex generate
+package amqp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/initialize"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+// Declarer is the subset of an AMQP channel used to declare topology.
+type Declarer interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+}
+
+func AMQPTopologyV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (initialize.Initializer, error) {
+	var c AMQPTopologyV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var logger logr.Logger
+	if err := resolve.Resolve(resolver,
+		"system:logger", &logger); err != nil {
+		return nil, err
+	}
+
+	return Topology(logger, &c), nil
+}
+
+func Topology(log logr.Logger, c *AMQPTopologyV1Config) initialize.Initializer {
+	return func(ctx context.Context) error {
+		conn, err := amqp.Dial(c.URL)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ch, err := conn.Channel()
+		if err != nil {
+			return err
+		}
+		defer ch.Close()
+
+		if err := Declare(ch, c); err != nil {
+			return err
+		}
+
+		log.Info("AMQP topology declared",
+			"exchanges", len(c.Exchanges),
+			"queues", len(c.Queues),
+			"bindings", len(c.Bindings))
+
+		return nil
+	}
+}
+
+// Declare applies the exchanges, queues and bindings in c, in that order.
+func Declare(ch Declarer, c *AMQPTopologyV1Config) error {
+	for _, e := range c.Exchanges {
+		if err := ch.ExchangeDeclare(e.Name, e.Kind, e.Durable, e.AutoDelete, e.Internal, false, amqp.Table(e.Arguments)); err != nil {
+			return fmt.Errorf("could not declare exchange %q: %w", e.Name, err)
+		}
+	}
+
+	for _, q := range c.Queues {
+		if _, err := ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, q.Exclusive, false, queueArguments(&q)); err != nil {
+			return fmt.Errorf("could not declare queue %q: %w", q.Name, err)
+		}
+	}
+
+	for _, b := range c.Bindings {
+		if err := ch.QueueBind(b.Queue, b.RoutingKey, b.Exchange, false, amqp.Table(b.Arguments)); err != nil {
+			return fmt.Errorf("could not bind queue %q to exchange %q: %w", b.Queue, b.Exchange, err)
+		}
+	}
+
+	return nil
+}
+
+func queueArguments(q *Queue) amqp.Table {
+	args := amqp.Table{}
+	for k, v := range q.Arguments {
+		args[k] = v
+	}
+	if q.DeadLetterExchange != nil {
+		args["x-dead-letter-exchange"] = *q.DeadLetterExchange
+	}
+	if q.DeadLetterRoutingKey != nil {
+		args["x-dead-letter-routing-key"] = *q.DeadLetterRoutingKey
+	}
+	if q.MessageTTL != nil {
+		args["x-message-ttl"] = q.MessageTTL.Milliseconds()
+	}
+	if q.MaxLength != nil {
+		args["x-max-length"] = int64(*q.MaxLength)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	return args
+}
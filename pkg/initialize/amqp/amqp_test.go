@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package amqp_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/initialize/amqp"
+)
+
+type mockDeclarer struct {
+	calls []string
+	args  map[string]amqp091.Table
+	err   error
+}
+
+func (m *mockDeclarer) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error {
+	m.calls = append(m.calls, "exchange:"+name+":"+kind)
+	return m.err
+}
+
+func (m *mockDeclarer) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error) {
+	m.calls = append(m.calls, "queue:"+name)
+	m.args[name] = args
+	return amqp091.Queue{Name: name}, m.err
+}
+
+func (m *mockDeclarer) QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error {
+	m.calls = append(m.calls, "bind:"+name+":"+key+":"+exchange)
+	return m.err
+}
+
+func TestDeclare(t *testing.T) {
+	var c amqp.AMQPTopologyV1Config
+	require.NoError(t, config.Decode(map[string]interface{}{
+		"url": "amqp://localhost",
+		"exchanges": []interface{}{
+			map[string]interface{}{"name": "orders", "kind": "topic", "durable": true},
+			map[string]interface{}{"name": "orders.dlx", "kind": "fanout"},
+		},
+		"queues": []interface{}{
+			map[string]interface{}{
+				"name":               "orders.created",
+				"deadLetterExchange": "orders.dlx",
+				"messageTtl":         "30s",
+				"maxLength":          1000,
+			},
+			map[string]interface{}{"name": "orders.dead"},
+		},
+		"bindings": []interface{}{
+			map[string]interface{}{"queue": "orders.created", "exchange": "orders", "routingKey": "order.created"},
+			map[string]interface{}{"queue": "orders.dead", "exchange": "orders.dlx"},
+		},
+	}, &c))
+
+	m := mockDeclarer{args: map[string]amqp091.Table{}}
+	require.NoError(t, amqp.Declare(&m, &c))
+
+	assert.Equal(t, []string{
+		"exchange:orders:topic",
+		"exchange:orders.dlx:fanout",
+		"queue:orders.created",
+		"queue:orders.dead",
+		"bind:orders.created:order.created:orders",
+		"bind:orders.dead::orders.dlx",
+	}, m.calls)
+	assert.Equal(t, amqp091.Table{
+		"x-dead-letter-exchange": "orders.dlx",
+		"x-message-ttl":          (30 * time.Second).Milliseconds(),
+		"x-max-length":           int64(1000),
+	}, m.args["orders.created"])
+	assert.Nil(t, m.args["orders.dead"])
+}
+
+func TestDeclareInvalidKind(t *testing.T) {
+	var c amqp.AMQPTopologyV1Config
+	err := config.Decode(map[string]interface{}{
+		"url": "amqp://localhost",
+		"exchanges": []interface{}{
+			map[string]interface{}{"name": "orders", "kind": "invalid"},
+		},
+	}, &c)
+	assert.Error(t, err)
+}
+
+func TestDeclareError(t *testing.T) {
+	c := amqp.AMQPTopologyV1Config{
+		Exchanges: []amqp.Exchange{{Name: "orders", Kind: "topic"}},
+	}
+	m := mockDeclarer{err: errors.New("access refused")}
+	assert.EqualError(t, amqp.Declare(&m, &c), `could not declare exchange "orders": access refused`)
+}
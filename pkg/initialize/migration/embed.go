@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// RegisterEmbedded registers a migration source for scheme:// URLs that
+// reads the migrations directory of fsys, which has a directory per
+// database. The URL host is the database, e.g. scheme://postgres reads
+// migrations/postgres.
+func RegisterEmbedded(scheme string, fsys fs.FS) {
+	source.Register(scheme, &embeddedSource{scheme: scheme, fsys: fsys})
+}
+
+// embeddedSource opens the migrations of a database from an embedded file
+// system.
+type embeddedSource struct {
+	source.Driver
+	scheme string
+	fsys   fs.FS
+}
+
+func (s *embeddedSource) Open(sourceURL string) (source.Driver, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Join("migrations", u.Host)
+	if _, err := fs.Stat(s.fsys, dir); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s migrations are not available for %q", s.scheme, u.Host)
+	}
+	d, err := iofs.New(s.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &embeddedSource{Driver: d, scheme: s.scheme, fsys: s.fsys}, nil
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package migration runs database migrations step by step for the migration
+// initializers and the `nanobus migrate` command.
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+
+	"github.com/nanobus/nanobus/pkg/registry"
+)
+
+type (
+	// NamedLoader returns the initializer name (e.g.
+	// "nanobus.migrate.postgres/v1") with a loader that creates a Migrator from
+	// the same configuration as the initializer.
+	NamedLoader = registry.NamedLoader[*Migrator]
+	Loader      = registry.Loader[*Migrator]
+	Registry    = registry.Registry[*Migrator]
+)
+
+type Direction int
+
+const (
+	Up Direction = iota
+	Down
+)
+
+func (d Direction) String() string {
+	if d == Down {
+		return "down"
+	}
+	return "up"
+}
+
+// Migration is a version from the migration source.
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// Step applies or reverts a single migration.
+type Step struct {
+	Migration
+	Direction Direction
+}
+
+// Migrator wraps golang-migrate so that migrations can be listed, planned
+// and applied one at a time.
+type Migrator struct {
+	m   *migrate.Migrate
+	src source.Driver
+}
+
+// SourceURL returns the migration source URL from the sourceUrl or directory
+// configuration of an initializer.
+func SourceURL(sourceURL, directory *string) string {
+	if sourceURL != nil {
+		return *sourceURL
+	}
+	if directory != nil {
+		return "file://" + *directory
+	}
+	return ""
+}
+
+// New creates a Migrator for the migrations at sourceURL and an open
+// database driver.
+func New(sourceURL, databaseName string, driver database.Driver) (*Migrator, error) {
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	return NewWithInstance(src, databaseName, driver)
+}
+
+// NewWithInstance creates a Migrator from an open source and database
+// driver. Both are closed by Close.
+func NewWithInstance(src source.Driver, databaseName string, driver database.Driver) (*Migrator, error) {
+	m, err := migrate.NewWithInstance("source", src, databaseName, driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, err
+	}
+
+	return &Migrator{
+		m:   m,
+		src: src,
+	}, nil
+}
+
+// Close closes the source and database.
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
+
+// Version returns the current version of the database. Zero means that no
+// migrations have been applied.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Migrations lists the migrations in the source in version order.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	var migrations []Migration
+	version, err := m.src.First()
+	for {
+		if errors.Is(err, os.ErrNotExist) {
+			return migrations, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name, nameErr := m.name(version)
+		if nameErr != nil {
+			return nil, nameErr
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+		})
+		version, err = m.src.Next(version)
+	}
+}
+
+func (m *Migrator) name(version uint) (string, error) {
+	r, identifier, err := m.src.ReadUp(version)
+	if errors.Is(err, os.ErrNotExist) {
+		r, identifier, err = m.src.ReadDown(version)
+	}
+	if err != nil {
+		return "", err
+	}
+	r.Close()
+	return identifier, nil
+}
+
+// PlanUp returns the steps that apply the next n pending migrations or all
+// of them if n is not positive.
+func (m *Migrator) PlanUp(n int) ([]Step, error) {
+	current, migrations, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	var steps []Step
+	for _, migration := range migrations {
+		if migration.Version > current && (n <= 0 || len(steps) < n) {
+			steps = append(steps, Step{migration, Up})
+		}
+	}
+	return steps, nil
+}
+
+// PlanDown returns the steps that revert the last n applied migrations or
+// all of them if n is not positive.
+func (m *Migrator) PlanDown(n int) ([]Step, error) {
+	current, migrations, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	var steps []Step
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version <= current && (n <= 0 || len(steps) < n) {
+			steps = append(steps, Step{migrations[i], Down})
+		}
+	}
+	return steps, nil
+}
+
+// PlanGoto returns the steps that migrate up or down to version. Version
+// zero reverts all migrations.
+func (m *Migrator) PlanGoto(version uint) ([]Step, error) {
+	current, migrations, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	found := version == 0
+	for _, migration := range migrations {
+		found = found || migration.Version == version
+	}
+	if !found {
+		return nil, fmt.Errorf("migration version %d does not exist", version)
+	}
+
+	var steps []Step
+	if version > current {
+		for _, migration := range migrations {
+			if migration.Version > current && migration.Version <= version {
+				steps = append(steps, Step{migration, Up})
+			}
+		}
+	} else {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if migrations[i].Version > version && migrations[i].Version <= current {
+				steps = append(steps, Step{migrations[i], Down})
+			}
+		}
+	}
+	return steps, nil
+}
+
+func (m *Migrator) state() (uint, []Migration, error) {
+	current, dirty, err := m.Version()
+	if err != nil {
+		return 0, nil, err
+	}
+	if dirty {
+		return 0, nil, fmt.Errorf("database is dirty at version %d: fix the database and force a version", current)
+	}
+	migrations, err := m.Migrations()
+	return current, migrations, err
+}
+
+// Apply runs steps in order, calling applied after each one with the time
+// it took.
+func (m *Migrator) Apply(steps []Step, applied func(step Step, duration time.Duration)) error {
+	for _, step := range steps {
+		n := 1
+		if step.Direction == Down {
+			n = -1
+		}
+		start := time.Now()
+		if err := m.m.Steps(n); err != nil {
+			return fmt.Errorf("migration %d_%s %s: %w", step.Version, step.Name, step.Direction, err)
+		}
+		if applied != nil {
+			applied(step, time.Since(start))
+		}
+	}
+	return nil
+}
+
+// Force sets the version without running migrations and clears the dirty
+// flag. Version -1 means that no migrations have been applied.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Up applies all pending migrations. It is used by the migration
+// initializers at startup.
+func (m *Migrator) Up(log logr.Logger, name string) error {
+	steps, err := m.PlanUp(0)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		log.Info("Migration has no changes", "name", name)
+		return nil
+	}
+	if err := m.Apply(steps, func(step Step, duration time.Duration) {
+		log.Info("Applied migration", "name", name, "version", step.Version,
+			"migration", step.Name, "duration", duration)
+	}); err != nil {
+		return err
+	}
+	log.Info("Migration successful", "name", name)
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package migration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
+)
+
+func newMigrator(t *testing.T) (*migration.Migrator, *stub.Stub) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"000001_create_users.up.sql", "000001_create_users.down.sql",
+		"000002_add_email.up.sql", "000002_add_email.down.sql",
+		"000005_create_orders.up.sql", "000005_create_orders.down.sql",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("-- "+name), 0o644))
+	}
+
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	require.NoError(t, err)
+	m, err := migration.New("file://"+dir, "stub", driver)
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+	return m, driver.(*stub.Stub)
+}
+
+func TestMigrations(t *testing.T) {
+	m, _ := newMigrator(t)
+	migrations, err := m.Migrations()
+	require.NoError(t, err)
+	assert.Equal(t, []migration.Migration{
+		{Version: 1, Name: "create_users"},
+		{Version: 2, Name: "add_email"},
+		{Version: 5, Name: "create_orders"},
+	}, migrations)
+}
+
+func TestRegisterEmbedded(t *testing.T) {
+	migration.RegisterEmbedded("embedtest", fstest.MapFS{
+		"migrations/postgres/000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+		"migrations/postgres/000001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	})
+
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	require.NoError(t, err)
+	m, err := migration.New("embedtest://postgres", "stub", driver)
+	require.NoError(t, err)
+	defer m.Close()
+	migrations, err := m.Migrations()
+	require.NoError(t, err)
+	assert.Equal(t, []migration.Migration{{Version: 1, Name: "create_users"}}, migrations)
+
+	_, err = migration.New("embedtest://mysql", "stub", driver)
+	assert.ErrorContains(t, err, `embedtest migrations are not available for "mysql"`)
+}
+
+func TestApply(t *testing.T) {
+	m, db := newMigrator(t)
+
+	steps, err := m.PlanUp(2)
+	require.NoError(t, err)
+	var applied []string
+	require.NoError(t, m.Apply(steps, func(step migration.Step, duration time.Duration) {
+		applied = append(applied, step.Direction.String()+" "+step.Name)
+	}))
+	assert.Equal(t, []string{"up create_users", "up add_email"}, applied)
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.False(t, dirty)
+
+	steps, err = m.PlanGoto(5)
+	require.NoError(t, err)
+	assert.Equal(t, []migration.Step{{Migration: migration.Migration{Version: 5, Name: "create_orders"}, Direction: migration.Up}}, steps)
+
+	steps, err = m.PlanDown(0)
+	require.NoError(t, err)
+	assert.Len(t, steps, 2)
+	require.NoError(t, m.Apply(steps, nil))
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), version)
+	assert.Equal(t, []string{
+		"-- 000001_create_users.up.sql",
+		"-- 000002_add_email.up.sql",
+		"-- 000002_add_email.down.sql",
+		"-- 000001_create_users.down.sql",
+	}, db.MigrationSequence)
+
+	_, err = m.PlanGoto(3)
+	assert.Error(t, err)
+}
+
+func TestDirty(t *testing.T) {
+	m, db := newMigrator(t)
+	db.CurrentVersion = 2
+	db.IsDirty = true
+
+	_, err := m.PlanUp(0)
+	assert.Error(t, err)
+
+	require.NoError(t, m.Force(1))
+	steps, err := m.PlanUp(0)
+	require.NoError(t, err)
+	assert.Len(t, steps, 2)
+}
@@ -14,13 +14,13 @@ import (
 	"database/sql"
 
 	"github.com/go-logr/logr"
-	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlserver"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/microsoft/go-mssqldb"
 
 	"github.com/nanobus/nanobus/pkg/config"
 	"github.com/nanobus/nanobus/pkg/initialize"
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
 	"github.com/nanobus/nanobus/pkg/resolve"
 )
 
@@ -41,46 +41,48 @@ func MigrateMSSQLV1Loader(ctx context.Context, with interface{}, resolver resolv
 
 func Migrate(log logr.Logger, c *MigrateMSSQLV1Config) initialize.Initializer {
 	return func(ctx context.Context) error {
-		db, err := sql.Open("sqlserver", c.DataSource)
+		m, err := NewMigrator(c)
 		if err != nil {
 			return err
 		}
+		defer m.Close()
 
-		driver, err := sqlserver.WithInstance(db, &sqlserver.Config{
-			MigrationsTable: orEmptyValue(c.MigrationsTable),
-			DatabaseName:    orEmptyValue(c.DatabaseName),
-			SchemaName:      orEmptyValue(c.SchemaName),
-		})
-		if err != nil {
-			return err
-		}
+		return m.Up(log, c.Name)
+	}
+}
 
-		sourceURL := ""
-		if c.SourceURL != nil {
-			sourceURL = *c.SourceURL
-		} else if c.Directory != nil {
-			sourceURL = "file://" + *c.Directory
-		}
+// MigrateMSSQLV1Migration registers the initializer's configuration with the
+// `nanobus migrate` command.
+func MigrateMSSQLV1Migration() (string, migration.Loader) {
+	return "nanobus.migrate.mssql/v1", MigratorLoader
+}
 
-		m, err := migrate.NewWithDatabaseInstance(
-			sourceURL,
-			"sqlserver", driver)
-		if err != nil {
-			return err
-		}
+func MigratorLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (*migration.Migrator, error) {
+	var c MigrateMSSQLV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
 
-		if err := m.Up(); err != nil {
-			if err != migrate.ErrNoChange {
-				return err
-			}
+	return NewMigrator(&c)
+}
 
-			log.Info("Migration has no changes", "name", c.Name)
-		} else {
-			log.Info("Migration successful", "name", c.Name)
-		}
+func NewMigrator(c *MigrateMSSQLV1Config) (*migration.Migrator, error) {
+	db, err := sql.Open("sqlserver", c.DataSource)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
+	driver, err := sqlserver.WithInstance(db, &sqlserver.Config{
+		MigrationsTable: orEmptyValue(c.MigrationsTable),
+		DatabaseName:    orEmptyValue(c.DatabaseName),
+		SchemaName:      orEmptyValue(c.SchemaName),
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
+
+	return migration.New(migration.SourceURL(c.SourceURL, c.Directory), "sqlserver", driver)
 }
 
 type Ptr[T any] interface {
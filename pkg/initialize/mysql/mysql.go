@@ -0,0 +1,98 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+//go:generate apex generate
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-logr/logr"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/initialize"
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func MigrateMySQLV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (initialize.Initializer, error) {
+	var c MigrateMySQLV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var logger logr.Logger
+	if err := resolve.Resolve(resolver,
+		"system:logger", &logger); err != nil {
+		return nil, err
+	}
+
+	return Migrate(logger, &c), nil
+}
+
+func Migrate(log logr.Logger, c *MigrateMySQLV1Config) initialize.Initializer {
+	return func(ctx context.Context) error {
+		m, err := NewMigrator(c)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return m.Up(log, c.Name)
+	}
+}
+
+// MigrateMySQLV1Migration registers the initializer's configuration with the
+// `nanobus migrate` command.
+func MigrateMySQLV1Migration() (string, migration.Loader) {
+	return "nanobus.migrate.mysql/v1", MigratorLoader
+}
+
+func MigratorLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (*migration.Migrator, error) {
+	var c MigrateMySQLV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(&c)
+}
+
+func NewMigrator(c *MigrateMySQLV1Config) (*migration.Migrator, error) {
+	db, err := sql.Open("mysql", c.DataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := mysql.WithInstance(db, &mysql.Config{
+		MigrationsTable:  orEmptyValue(c.MigrationsTable),
+		DatabaseName:     orEmptyValue(c.DatabaseName),
+		NoLock:           c.NoLock,
+		StatementTimeout: orEmptyValue(c.StatementTimeout),
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return migration.New(migration.SourceURL(c.SourceURL, c.Directory), "mysql", driver)
+}
+
+type Ptr[T any] interface {
+	*T
+}
+
+func orEmptyValue[T any, P Ptr[T]](val P) (ret T) {
+	if val != nil {
+		ret = *val
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+//go:generate apex generate
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "modernc.org/sqlite"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/initialize"
+	"github.com/nanobus/nanobus/pkg/initialize/migration"
+	"github.com/nanobus/nanobus/pkg/resolve"
+)
+
+func MigrateSQLiteV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (initialize.Initializer, error) {
+	var c MigrateSQLiteV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var logger logr.Logger
+	if err := resolve.Resolve(resolver,
+		"system:logger", &logger); err != nil {
+		return nil, err
+	}
+
+	return Migrate(logger, &c), nil
+}
+
+func Migrate(log logr.Logger, c *MigrateSQLiteV1Config) initialize.Initializer {
+	return func(ctx context.Context) error {
+		m, err := NewMigrator(c)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return m.Up(log, c.Name)
+	}
+}
+
+// MigrateSQLiteV1Migration registers the initializer's configuration with the
+// `nanobus migrate` command.
+func MigrateSQLiteV1Migration() (string, migration.Loader) {
+	return "nanobus.migrate.sqlite/v1", MigratorLoader
+}
+
+func MigratorLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (*migration.Migrator, error) {
+	var c MigrateSQLiteV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(&c)
+}
+
+func NewMigrator(c *MigrateSQLiteV1Config) (*migration.Migrator, error) {
+	db, err := sql.Open("sqlite", c.DataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{
+		MigrationsTable: orEmptyValue(c.MigrationsTable),
+		DatabaseName:    orEmptyValue(c.DatabaseName),
+		NoTxWrap:        c.NoTxWrap,
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return migration.New(migration.SourceURL(c.SourceURL, c.Directory), "sqlite", driver)
+}
+
+type Ptr[T any] interface {
+	*T
+}
+
+func orEmptyValue[T any, P Ptr[T]](val P) (ret T) {
+	if val != nil {
+		ret = *val
+	}
+	return ret
+}
@@ -46,6 +46,7 @@ type (
 		m        *Mesh
 		tracer   trace.Tracer
 		instance compute.Invoker
+		module   string
 		index    uint32
 		name     string
 	}
@@ -161,6 +162,31 @@ func (m *Mesh) WaitUntilShutdown() error {
 }
 
 func (m *Mesh) Link(inst compute.Invoker) {
+	m.link("", inst)
+}
+
+// LinkModule links a named compute module. Unlike Link, it returns an error
+// instead of replacing operations that are already exported.
+func (m *Mesh) LinkModule(name string, inst compute.Invoker) error {
+	for _, op := range inst.Operations() {
+		if op.Direction != operations.Export {
+			continue
+		}
+		ptr, ok := m.exports[op.Namespace][op.Operation]
+		if !ok {
+			continue
+		}
+		if owner := ptr.Load().module; owner != "" {
+			return fmt.Errorf("operation %q of compute module %q is already exported by %q", op.Namespace+"::"+op.Operation, name, owner)
+		}
+		return fmt.Errorf("operation %q of compute module %q is already exported", op.Namespace+"::"+op.Operation, name)
+	}
+
+	m.link(name, inst)
+	return nil
+}
+
+func (m *Mesh) link(module string, inst compute.Invoker) {
 	m.instances = append(m.instances, inst)
 	opers := inst.Operations()
 
@@ -183,6 +209,7 @@ func (m *Mesh) Link(inst compute.Invoker) {
 				m:        m,
 				tracer:   m.tracer,
 				instance: inst,
+				module:   module,
 				index:    op.Index,
 				name:     fmt.Sprintf("%s/%s", op.Namespace, op.Operation),
 			})
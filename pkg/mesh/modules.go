@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mesh
+
+import (
+	"github.com/nanobus/iota/go/operations"
+
+	"github.com/nanobus/nanobus/pkg/compute"
+)
+
+// Module is a compute instance and the name used to refer to it in errors.
+type Module struct {
+	Name    string
+	Invoker compute.Invoker
+}
+
+// Order sorts modules so that each one comes after the modules exporting the
+// operations it imports, which makes the link order deterministic. Modules
+// without dependencies between them keep their relative order. Modules that
+// import from each other in a cycle are ordered by their position in modules,
+// which is fine since Link satisfies imports from modules linked later.
+func Order(modules []Module) []Module {
+	type key struct{ namespace, operation string }
+
+	exporters := make(map[key]int)
+	for i, mod := range modules {
+		for _, op := range mod.Invoker.Operations() {
+			if op.Direction != operations.Export {
+				continue
+			}
+			k := key{op.Namespace, op.Operation}
+			if _, ok := exporters[k]; !ok {
+				exporters[k] = i
+			}
+		}
+	}
+
+	deps := make([][]int, len(modules))
+	for i, mod := range modules {
+		seen := make(map[int]bool)
+		for _, op := range mod.Invoker.Operations() {
+			if op.Direction != operations.Import {
+				continue
+			}
+			j, ok := exporters[key{op.Namespace, op.Operation}]
+			if !ok || j == i || seen[j] {
+				continue
+			}
+			seen[j] = true
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	visited := make([]bool, len(modules))
+	sorted := make([]Module, 0, len(modules))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, j := range deps[i] {
+			visit(j)
+		}
+		sorted = append(sorted, modules[i])
+	}
+
+	for i := range modules {
+		visit(i)
+	}
+
+	return sorted
+}
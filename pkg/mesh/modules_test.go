@@ -0,0 +1,139 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mesh_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanobus/iota/go/invoke"
+	"github.com/nanobus/iota/go/operations"
+	"github.com/nanobus/iota/go/payload"
+	"github.com/nanobus/iota/go/rx/flux"
+	"github.com/nanobus/iota/go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/mesh"
+)
+
+// fakeModule exports operations that echo requests and records the handlers
+// set for its imports.
+type fakeModule struct {
+	ops      operations.Table
+	handlers map[uint32]invoke.RequestResponseHandler
+}
+
+func newModule(exports []string, imports ...string) *fakeModule {
+	m := &fakeModule{handlers: map[uint32]invoke.RequestResponseHandler{}}
+	for _, name := range exports {
+		m.ops = append(m.ops, operations.Operation{
+			Index:     uint32(len(m.ops)),
+			Type:      operations.RequestResponse,
+			Direction: operations.Export,
+			Namespace: "test",
+			Operation: name,
+		})
+	}
+	for _, name := range imports {
+		m.ops = append(m.ops, operations.Operation{
+			Index:     uint32(len(m.ops)),
+			Type:      operations.RequestResponse,
+			Direction: operations.Import,
+			Namespace: "test",
+			Operation: name,
+		})
+	}
+	return m
+}
+
+func (m *fakeModule) Operations() operations.Table { return m.ops }
+
+func (m *fakeModule) FireAndForget(ctx context.Context, p payload.Payload) {}
+
+func (m *fakeModule) RequestResponse(ctx context.Context, p payload.Payload) mono.Mono[payload.Payload] {
+	return mono.Just(p)
+}
+
+func (m *fakeModule) RequestStream(ctx context.Context, p payload.Payload) flux.Flux[payload.Payload] {
+	return flux.Error[payload.Payload](assert.AnError)
+}
+
+func (m *fakeModule) RequestChannel(ctx context.Context, p payload.Payload, in flux.Flux[payload.Payload]) flux.Flux[payload.Payload] {
+	return flux.Error[payload.Payload](assert.AnError)
+}
+
+func (m *fakeModule) SetRequestResponseHandler(index uint32, handler invoke.RequestResponseHandler) {
+	m.handlers[index] = handler
+}
+
+func (m *fakeModule) SetFireAndForgetHandler(uint32, invoke.FireAndForgetHandler)   {}
+func (m *fakeModule) SetRequestStreamHandler(uint32, invoke.RequestStreamHandler)   {}
+func (m *fakeModule) SetRequestChannelHandler(uint32, invoke.RequestChannelHandler) {}
+
+func (m *fakeModule) Close() error { return nil }
+
+func names(modules []mesh.Module) []string {
+	n := make([]string, len(modules))
+	for i, m := range modules {
+		n[i] = m.Name
+	}
+	return n
+}
+
+func TestOrder(t *testing.T) {
+	modules := []mesh.Module{
+		{Name: "app", Invoker: newModule([]string{"run"}, "lookup", "store")},
+		{Name: "other", Invoker: newModule([]string{"other"})},
+		{Name: "store", Invoker: newModule([]string{"store"}, "lookup")},
+		{Name: "lookup", Invoker: newModule([]string{"lookup"}, "missing")},
+	}
+
+	assert.Equal(t, []string{"lookup", "store", "app", "other"}, names(mesh.Order(modules)))
+}
+
+func TestOrderMutualImports(t *testing.T) {
+	modules := []mesh.Module{
+		{Name: "a", Invoker: newModule([]string{"a"}, "c")},
+		{Name: "b", Invoker: newModule([]string{"b"}, "a")},
+		{Name: "c", Invoker: newModule([]string{"c"}, "b")},
+	}
+
+	assert.Equal(t, []string{"b", "c", "a"}, names(mesh.Order(modules)))
+}
+
+func TestLinkModule(t *testing.T) {
+	m := mesh.New(trace.NewNoopTracerProvider().Tracer("test"))
+	app := newModule([]string{"run"}, "store")
+	store := newModule([]string{"store"})
+
+	require.NoError(t, m.LinkModule("store", store))
+	require.NoError(t, m.LinkModule("app", app))
+	assert.Empty(t, m.Unsatisfied())
+
+	// The import of app is routed to store.
+	h := app.handlers[1]
+	require.NotNil(t, h)
+	result, err := h(context.Background(), payload.New([]byte("hello"))).Block()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(result.Data()))
+
+	result, err = m.RequestResponse(context.Background(), handler.Handler{Interface: "test", Operation: "run"}, payload.New([]byte("hi"))).Block()
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(result.Data()))
+
+	err = m.LinkModule("copy", newModule([]string{"other", "store"}))
+	assert.EqualError(t, err, `operation "test::store" of compute module "copy" is already exported by "store"`)
+
+	m.Link(newModule([]string{"builtin"}))
+	err = m.LinkModule("copy", newModule([]string{"builtin"}))
+	assert.EqualError(t, err, `operation "test::builtin" of compute module "copy" is already exported`)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sync"
 
 	"github.com/opencontainers/go-digest"
@@ -13,15 +14,40 @@ import (
 	"oras.land/oras-go/v2/content/file"
 )
 
+// Pull downloads the files of an application to the target directory and
+// returns the name of its bus configuration.
 func Pull(reference, target string) (string, error) {
+	files, err := pull(context.Background(), reference, target)
+	if err != nil {
+		return "", err
+	}
+	return files[AppMediaType], nil
+}
+
+// PullModule downloads the files of an artifact to the target directory and
+// returns the path of its WebAssembly module.
+func PullModule(ctx context.Context, reference, target string) (string, error) {
+	files, err := pull(ctx, reference, target)
+	if err != nil {
+		return "", err
+	}
+	name, ok := files[WasmMediaType]
+	if !ok {
+		return "", fmt.Errorf("%s does not contain a WebAssembly module", reference)
+	}
+	return filepath.Join(target, name), nil
+}
+
+// pull downloads the named files of an artifact to the target directory and
+// returns the first file name of each media type.
+func pull(ctx context.Context, reference, target string) (map[string]string, error) {
 	verbose := false
 	var printed sync.Map
 	var targetPlatform *ocispec.Platform
-	ctx := context.Background()
 
 	repo, err := getRepository(reference)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Copy Options
@@ -95,11 +121,12 @@ func Pull(reference, target string) (string, error) {
 		return ret, nil
 	}
 
-	appFile := ""
+	files := make(map[string]string)
 	dst, err := file.New(target)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer dst.Close()
 
 	pulledEmpty := true
 	copyOptions.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
@@ -131,9 +158,8 @@ func Pull(reference, target string) (string, error) {
 			// named content downloaded
 			pulledEmpty = false
 
-			// Found the application media type.
-			if desc.MediaType == AppMediaType {
-				appFile = name
+			if _, ok := files[desc.MediaType]; !ok {
+				files[desc.MediaType] = name
 			}
 		}
 		printed.Store(generateContentKey(desc), true)
@@ -143,14 +169,14 @@ func Pull(reference, target string) (string, error) {
 	// Copy
 	desc, err := oras.Copy(ctx, repo, repo.Reference.Reference, dst, repo.Reference.Reference, copyOptions)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if pulledEmpty {
 		fmt.Println("Downloaded empty artifact")
 	}
 	fmt.Println("Pulled", reference)
 	fmt.Println("Digest:", desc.Digest)
-	return appFile, nil
+	return files, nil
 }
 
 // generateContentKey generates a unique key for each content descriptor, using
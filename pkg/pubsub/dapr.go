@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+
+	dapr "github.com/dapr/go-sdk/client"
+)
+
+// Dapr adapts a Dapr pub/sub component. Subscriptions are configured with
+// the Dapr transport because Dapr delivers messages to the app callback
+// server.
+type Dapr struct {
+	client dapr.Client
+	pubsub string
+}
+
+func NewDapr(client dapr.Client, pubsub string) *Dapr {
+	return &Dapr{
+		client: client,
+		pubsub: pubsub,
+	}
+}
+
+func (d *Dapr) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	md := copyMetadata(metadata, 1)
+	md["rawPayload"] = "true"
+
+	return d.client.PublishEvent(ctx, d.pubsub, topic, data,
+		dapr.PublishEventWithMetadata(md))
+}
+
+func (d *Dapr) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	return errors.New("subscriptions to Dapr pub/sub components are configured with the nanobus.transport.dapr/v1 transport")
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// memoryBufferSize is the number of messages buffered per subscriber before
+// publishers wait.
+const memoryBufferSize = 1024
+
+// Memory is an in-process pub/sub for local development and tests. Every
+// subscriber of a topic receives each message published while it is
+// subscribed. Failed messages are retried with exponential backoff.
+type Memory struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*memorySubscriber]struct{}
+	lastID      uint64
+}
+
+type memorySubscriber struct {
+	messages chan *Message
+	done     chan struct{}
+}
+
+// MemPubSub is the NamedLoader for an in-memory pub/sub.
+func MemPubSub() (string, resource.Loader) {
+	return "nanobus.resource.mempubsub/v1", MemPubSubLoader
+}
+
+func MemPubSubLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	return NewMemory(), nil
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		subscribers: make(map[string]map[*memorySubscriber]struct{}),
+	}
+}
+
+func (m *Memory) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	id := strconv.FormatUint(atomic.AddUint64(&m.lastID, 1), 10)
+
+	m.mu.RLock()
+	subscribers := make([]*memorySubscriber, 0, len(m.subscribers[topic]))
+	for s := range m.subscribers[topic] {
+		subscribers = append(subscribers, s)
+	}
+	m.mu.RUnlock()
+
+	for _, s := range subscribers {
+		msg := &Message{
+			ID:       id,
+			Topic:    topic,
+			Data:     append([]byte(nil), data...),
+			Metadata: copyMetadata(metadata, 0),
+		}
+		select {
+		case s.messages <- msg:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	s := &memorySubscriber{
+		messages: make(chan *Message, memoryBufferSize),
+		done:     make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	subscribers, ok := m.subscribers[topic]
+	if !ok {
+		subscribers = make(map[*memorySubscriber]struct{})
+		m.subscribers[topic] = subscribers
+	}
+	subscribers[s] = struct{}{}
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(subscribers, s)
+		if len(subscribers) == 0 {
+			delete(m.subscribers, topic)
+		}
+		m.mu.Unlock()
+		close(s.done)
+	}()
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-s.messages:
+			b.Reset()
+			for handler(ctx, msg) != nil {
+				select {
+				case <-time.After(b.NextBackOff()):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+}
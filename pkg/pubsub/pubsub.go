@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package pubsub defines the publish/subscribe resources used by the
+// `@pubsub/publish` action and the pubsub transport.
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// Message is a message delivered to a subscriber. Handlers must not modify
+// its fields.
+type Message struct {
+	ID       string
+	Topic    string
+	Data     []byte
+	Metadata map[string]string
+}
+
+// Handler processes a message. Messages are delivered again while the
+// handler returns an error.
+type Handler func(ctx context.Context, msg *Message) error
+
+// PubSub publishes messages to topics and delivers them to subscribers.
+type PubSub interface {
+	// Publish publishes data with metadata to topic.
+	Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error
+	// Subscribe delivers the messages published to topic to handler. It
+	// blocks until ctx is done, returning nil, or until the subscription
+	// fails.
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+}
+
+// Get returns the pub/sub resource named name. Dapr client resources are
+// adapted to publish to the Dapr pub/sub component named daprPubSub.
+func Get(resources resource.Resources, name resource.Ref, daprPubSub string) (PubSub, error) {
+	r, ok := resources[string(name)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", name)
+	}
+
+	switch r := r.(type) {
+	case PubSub:
+		return r, nil
+	case dapr.Client:
+		if daprPubSub == "" {
+			return nil, fmt.Errorf("resource %q is a Dapr client and requires the name of a pub/sub component", name)
+		}
+		return NewDapr(r, daprPubSub), nil
+	}
+
+	return nil, fmt.Errorf("resource %q is not a pub/sub", name)
+}
+
+func copyMetadata(metadata map[string]string, extra int) map[string]string {
+	md := make(map[string]string, len(metadata)+extra)
+	for k, v := range metadata {
+		md[k] = v
+	}
+	return md
+}
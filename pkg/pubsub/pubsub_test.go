@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+func TestMemory(t *testing.T) {
+	testPubSub(t, pubsub.NewMemory(), "orders")
+}
+
+func TestRedis(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	defer client.Close()
+	require.NoError(t, client.Del(context.Background(), "test.orders").Err())
+
+	ps, err := pubsub.NewRedis(client, &pubsub.RedisConfig{
+		ConsumerGroup:  "test",
+		Consumer:       "test",
+		RedeliverAfter: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	testPubSub(t, ps, "test.orders")
+}
+
+func TestGet(t *testing.T) {
+	resources := resource.Resources{
+		"pubsub": pubsub.NewMemory(),
+		"other":  "other",
+	}
+
+	ps, err := pubsub.Get(resources, "pubsub", "")
+	require.NoError(t, err)
+	assert.Equal(t, resources["pubsub"], ps)
+
+	_, err = pubsub.Get(resources, "other", "")
+	assert.EqualError(t, err, `resource "other" is not a pub/sub`)
+	_, err = pubsub.Get(resources, "missing", "")
+	assert.EqualError(t, err, `resource "missing" is not registered`)
+}
+
+func testPubSub(t *testing.T, ps pubsub.PubSub, topic string) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	messages := make(chan *pubsub.Message, 10)
+	var failed bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, ps.Subscribe(ctx, topic, func(ctx context.Context, msg *pubsub.Message) error {
+			// The first delivery of the first message fails.
+			if !failed {
+				failed = true
+				return errors.New("test")
+			}
+			messages <- msg
+			return nil
+		}))
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Wait for the subscription before publishing.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, ps.Publish(ctx, topic, []byte(`{"id":1}`), map[string]string{"type": "order.created"}))
+	require.NoError(t, ps.Publish(ctx, topic, []byte(`{"id":2}`), nil))
+
+	var received []*pubsub.Message
+	for len(received) < 2 {
+		select {
+		case msg := <-messages:
+			received = append(received, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatal("message was not delivered")
+		}
+	}
+
+	data := map[string]bool{}
+	for _, msg := range received {
+		assert.Equal(t, topic, msg.Topic)
+		assert.NotEmpty(t, msg.ID)
+		data[string(msg.Data)] = true
+		if string(msg.Data) == `{"id":1}` {
+			assert.Equal(t, map[string]string{"type": "order.created"}, msg.Metadata)
+		}
+	}
+	assert.Equal(t, map[string]bool{`{"id":1}`: true, `{"id":2}`: true}, data)
+}
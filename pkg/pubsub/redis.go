@@ -0,0 +1,238 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	nanoredis "github.com/nanobus/nanobus/pkg/actions/redis"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// Fields of the stream entries of published messages.
+const (
+	fieldData     = "data"
+	fieldMetadata = "metadata"
+)
+
+// redisBlock is how long a subscriber waits for new entries before checking
+// for entries to redeliver.
+const redisBlock = time.Second
+
+type RedisConfig struct {
+	nanoredis.ConnectionConfig `mapstructure:",squash"`
+	// ConsumerGroup is the consumer group that subscribers read as. Replicas
+	// in the same group share the messages of a topic.
+	ConsumerGroup string `mapstructure:"consumerGroup" validate:"required"`
+	// Consumer is the name of this replica in the group. Defaults to the
+	// host name.
+	Consumer string `mapstructure:"consumer"`
+	// MaxLen approximately caps the number of entries of each topic stream.
+	// Streams are not trimmed by default.
+	MaxLen int64 `mapstructure:"maxLen"`
+	// RedeliverAfter is how long a message stays unacknowledged before it is
+	// delivered again. Defaults to 1 minute.
+	RedeliverAfter time.Duration `mapstructure:"redeliverAfter"`
+}
+
+// Redis publishes messages to a stream per topic. Subscribers read the
+// streams as members of a consumer group and acknowledge messages after
+// their handler succeeds. Unacknowledged messages are claimed and delivered
+// again after RedeliverAfter.
+type Redis struct {
+	client         redis.UniversalClient
+	group          string
+	consumer       string
+	maxLen         int64
+	redeliverAfter time.Duration
+}
+
+// RedisPubSub is the NamedLoader for a Redis Streams pub/sub.
+func RedisPubSub() (string, resource.Loader) {
+	return "nanobus.resource.redispubsub/v1", RedisPubSubLoader
+}
+
+func RedisPubSubLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	var c RedisConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, err := nanoredis.NewClient(&c.ConnectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedis(client, &c)
+}
+
+func NewRedis(client redis.UniversalClient, c *RedisConfig) (*Redis, error) {
+	consumer := c.Consumer
+	if consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine consumer name: %w", err)
+		}
+		consumer = hostname
+	}
+	redeliverAfter := c.RedeliverAfter
+	if redeliverAfter <= 0 {
+		redeliverAfter = time.Minute
+	}
+
+	return &Redis{
+		client:         client,
+		group:          c.ConsumerGroup,
+		consumer:       consumer,
+		maxLen:         c.MaxLen,
+		redeliverAfter: redeliverAfter,
+	}, nil
+}
+
+func (r *Redis) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	values := map[string]interface{}{
+		fieldData: data,
+	}
+	if len(metadata) > 0 {
+		md, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		values[fieldMetadata] = md
+	}
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic,
+		MaxLen: r.maxLen,
+		Approx: r.maxLen > 0,
+		Values: values,
+	}).Err()
+}
+
+func (r *Redis) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	err := r.client.XGroupCreateMkStream(ctx, topic, r.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("could not create consumer group for topic %q: %w", topic, err)
+	}
+
+	args := redis.XReadGroupArgs{
+		Group:    r.group,
+		Consumer: r.consumer,
+		Streams:  []string{topic, ">"},
+		Count:    10,
+		Block:    redisBlock,
+	}
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= r.redeliverAfter {
+			if err := r.claim(ctx, topic, handler); err != nil {
+				return err
+			}
+			lastClaim = time.Now()
+		}
+
+		streams, err := r.client.XReadGroup(ctx, &args).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			return err
+		}
+
+		for _, xs := range streams {
+			for _, msg := range xs.Messages {
+				r.process(ctx, topic, msg, handler)
+			}
+		}
+	}
+
+	return nil
+}
+
+// claim takes ownership of the entries that were not acknowledged within
+// redeliverAfter and handles them again.
+func (r *Redis) claim(ctx context.Context, topic string, handler Handler) error {
+	start := "0-0"
+	for ctx.Err() == nil {
+		reply, err := r.client.Do(ctx, "xautoclaim", topic, r.group, r.consumer,
+			r.redeliverAfter.Milliseconds(), start, "count", 10).Slice()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("could not claim entries of topic %q: %w", topic, err)
+		}
+		if len(reply) < 2 {
+			return fmt.Errorf("unexpected XAUTOCLAIM reply with %d elements", len(reply))
+		}
+		next, _ := reply[0].(string)
+		entries, _ := reply[1].([]interface{})
+
+		for _, entry := range entries {
+			// Entries deleted while pending are nil in Redis 6.2.
+			pair, ok := entry.([]interface{})
+			if !ok || len(pair) != 2 {
+				continue
+			}
+			id, _ := pair[0].(string)
+			kvs, _ := pair[1].([]interface{})
+			values := make(map[string]interface{}, len(kvs)/2)
+			for i := 0; i+1 < len(kvs); i += 2 {
+				if k, ok := kvs[i].(string); ok {
+					values[k] = kvs[i+1]
+				}
+			}
+			r.process(ctx, topic, redis.XMessage{ID: id, Values: values}, handler)
+		}
+
+		if next == "0-0" || len(entries) == 0 {
+			break
+		}
+		start = next
+	}
+
+	return nil
+}
+
+// process handles an entry and acknowledges it if the handler succeeds.
+func (r *Redis) process(ctx context.Context, topic string, entry redis.XMessage, handler Handler) {
+	msg := Message{
+		ID:    entry.ID,
+		Topic: topic,
+	}
+	if data, ok := entry.Values[fieldData].(string); ok {
+		msg.Data = []byte(data)
+	}
+	if md, ok := entry.Values[fieldMetadata].(string); ok {
+		// Entries added by other producers may not have valid metadata.
+		_ = json.Unmarshal([]byte(md), &msg.Metadata)
+	}
+
+	if err := handler(ctx, &msg); err != nil {
+		return
+	}
+
+	// Acknowledge even while shutting down so handled entries are not
+	// delivered again. Entries that could not be acknowledged are claimed
+	// and delivered again.
+	ackCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = r.client.XAck(ackCtx, topic, r.group, entry.ID).Err()
+}
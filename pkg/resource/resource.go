@@ -33,11 +33,9 @@ func Get[T any](r Resources, name Ref) (res T, err error) {
 	}
 	res, ok = iface.(T)
 	if !ok {
-		t := reflect.TypeOf(res)
-		if t == nil {
-			return res, fmt.Errorf("unknown target type trying to resolve resource %q", name)
-		}
-		return res, fmt.Errorf("resource %q is not a %s", name, t.Name())
+		// Use the pointer's element type so that interface types have names.
+		t := reflect.TypeOf((*T)(nil)).Elem()
+		return res, fmt.Errorf("resource %q is not a %s", name, t.String())
 	}
 
 	return res, nil
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -262,6 +263,11 @@ func (f *FilePath) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 func (f *FilePath) FromString(value string) error {
+	// URLs such as file:// and oci:// references are not relative paths.
+	if strings.Contains(value, "://") {
+		*f = FilePath(value)
+		return nil
+	}
 	*f = FilePath(filepath.Join(configBaseDir, value))
 
 	return nil
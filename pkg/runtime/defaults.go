@@ -112,6 +112,29 @@ func (h *Component) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Returns a Actors instance with default fields populated
+
+func DefaultActors() Actors {
+	obj := Actors{}
+	obj.Table = "actor_state"
+	obj.IdleTimeout = (func(value string) Duration { d, _ := time.ParseDuration(value); return Duration(d) })("1m")
+	obj.ScanInterval = (func(value string) Duration { d, _ := time.ParseDuration(value); return Duration(d) })("10s")
+	obj.LeaseTimeout = (func(value string) Duration { d, _ := time.ParseDuration(value); return Duration(d) })("30s")
+	obj.ReminderInterval = (func(value string) Duration { d, _ := time.ParseDuration(value); return Duration(d) })("1s")
+
+	return obj
+}
+
+func (h *Actors) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias Actors
+	raw := alias(DefaultActors())
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*h = Actors(raw)
+	return nil
+}
+
 // Returns a Resiliency instance with default fields populated
 
 func DefaultResiliency() Resiliency {
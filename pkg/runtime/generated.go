@@ -52,6 +52,8 @@ type BusConfig struct {
 	// Tracing configures an Open Telemetry span exporter.
 	Tracing *Component  `json:"tracing,omitempty" yaml:"tracing,omitempty" msgpack:"tracing,omitempty" mapstructure:"tracing"`
 	Specs   []Component `json:"specs,omitempty" yaml:"specs,omitempty" msgpack:"specs,omitempty" mapstructure:"specs" validate:"dive"`
+	// Compute modules linked with the main program. Each module may import operations
+	// exported by the others.
 	Compute []Component `json:"compute,omitempty" yaml:"compute,omitempty" msgpack:"compute,omitempty" mapstructure:"compute" validate:"dive"`
 	// Resiliency defines policies for fault tolerance.
 	Resiliency *Resiliency `json:"resiliency,omitempty" yaml:"resiliency,omitempty" msgpack:"resiliency,omitempty" mapstructure:"resiliency"`
@@ -75,8 +77,11 @@ type BusConfig struct {
 	Interfaces Interfaces `json:"interfaces,omitempty" yaml:"interfaces,omitempty" msgpack:"interfaces,omitempty" mapstructure:"interfaces"`
 	// Pipelines that preform data access (typically using resources) on behalf of the
 	// application.
-	Providers Interfaces               `json:"providers,omitempty" yaml:"providers,omitempty" msgpack:"providers,omitempty" mapstructure:"providers"`
-	Errors    map[string]ErrorTemplate `json:"errors,omitempty" yaml:"errors,omitempty" msgpack:"errors,omitempty" mapstructure:"errors" validate:"dive"`
+	Providers Interfaces `json:"providers,omitempty" yaml:"providers,omitempty" msgpack:"providers,omitempty" mapstructure:"providers"`
+	// Actors configures the runtime for services annotated with `@actor`,
+	// `@stateful` or `@workflow`.
+	Actors *Actors                  `json:"actors,omitempty" yaml:"actors,omitempty" msgpack:"actors,omitempty" mapstructure:"actors"`
+	Errors map[string]ErrorTemplate `json:"errors,omitempty" yaml:"errors,omitempty" msgpack:"errors,omitempty" mapstructure:"errors" validate:"dive"`
 	// If set, the base path or URL with which to resolve relative dependencies
 	BaseURL *string `json:"baseUrl,omitempty" yaml:"baseUrl,omitempty" msgpack:"baseUrl,omitempty" mapstructure:"baseUrl"`
 }
@@ -133,6 +138,26 @@ type Component struct {
 	With interface{} `json:"with,omitempty" yaml:"with,omitempty" msgpack:"with,omitempty" mapstructure:"with"`
 }
 
+// Actors configures the runtime for stateful services. Calls to the same entity
+// are processed one at a time and its state is loaded lazily from the store.
+type Actors struct {
+	// The resource that stores entity state (redis, postgres or sql). State is kept
+	// in memory when not set.
+	Resource *string `json:"resource,omitempty" yaml:"resource,omitempty" msgpack:"resource,omitempty" mapstructure:"resource"`
+	// The table name or, for redis, the key prefix used to store entity state.
+	Table string `json:"table" yaml:"table" msgpack:"table" mapstructure:"table" validate:"required"`
+	// How long an entity stays active without receiving calls.
+	IdleTimeout Duration `json:"idleTimeout" yaml:"idleTimeout" msgpack:"idleTimeout" mapstructure:"idleTimeout"`
+	// How often idle entities are deactivated.
+	ScanInterval Duration `json:"scanInterval" yaml:"scanInterval" msgpack:"scanInterval" mapstructure:"scanInterval"`
+	// How long a replica holds an entity before renewing its lease. Stores shared by
+	// multiple replicas use leases so that an entity is only active on one replica
+	// at a time.
+	LeaseTimeout Duration `json:"leaseTimeout" yaml:"leaseTimeout" msgpack:"leaseTimeout" mapstructure:"leaseTimeout"`
+	// How often the store is polled for due reminders.
+	ReminderInterval Duration `json:"reminderInterval" yaml:"reminderInterval" msgpack:"reminderInterval" mapstructure:"reminderInterval"`
+}
+
 // A section where resiliency policies are configured and given reusable reference
 // names.
 type Resiliency struct {
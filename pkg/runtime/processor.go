@@ -302,6 +302,7 @@ func (p *Processor) loadStep(s *Step) (*step, error) {
 }
 
 func (r *runnable) Run(ctx context.Context, data actions.Data) (interface{}, error) {
+	ctx, onStop := actions.ObserveStop(ctx)
 	var runOutput interface{}
 	var err error
 	for _, s := range r.steps {
@@ -323,6 +324,7 @@ func (r *runnable) Run(ctx context.Context, data actions.Data) (interface{}, err
 				err = pe.Err
 			}
 			if errors.Is(err, actions.ErrStop) {
+				onStop()
 				return nil, nil
 			}
 			return nil, err
@@ -330,6 +332,7 @@ func (r *runnable) Run(ctx context.Context, data actions.Data) (interface{}, err
 		if err != nil && s.onError != nil {
 			if output, err = s.onError(ctx, data); err != nil {
 				if errors.Is(err, actions.ErrStop) {
+					onStop()
 					return nil, nil
 				}
 				return nil, err
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dialect renders schema changes as DDL statements for a specific database.
+type Dialect interface {
+	// Name is the name used to select the dialect (e.g. "postgres").
+	Name() string
+	CreateTable(t *Table) []string
+	DropTable(t *Table) []string
+	// AlterTable returns the statements that change from into to. Both
+	// tables have the same name.
+	AlterTable(from, to *Table) []string
+}
+
+var dialects = map[string]Dialect{}
+
+// RegisterDialect makes a dialect available to DialectByName.
+func RegisterDialect(d Dialect) {
+	dialects[d.Name()] = d
+}
+
+// DialectByName returns the dialect registered with name.
+func DialectByName(name string) (Dialect, bool) {
+	d, ok := dialects[name]
+	return d, ok
+}
+
+// DialectNames returns the names of the registered dialects.
+func DialectNames() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterDialect(Postgres{})
+	RegisterDialect(MSSQL{})
+	RegisterDialect(MySQL{})
+	RegisterDialect(SQLite{})
+}
+
+// tableChanges is the column level difference between two versions of a
+// table that dialects with ALTER TABLE support translate into statements.
+type tableChanges struct {
+	added   []*Column
+	dropped []*Column
+	// changed holds the old and new definition of columns.
+	changed [][2]*Column
+	// foreign keys are dropped and recreated when they change.
+	droppedFKs       []*ForeignKey
+	addedFKs         []*ForeignKey
+	primaryKeyChange bool
+}
+
+func compareTables(from, to *Table) tableChanges {
+	var c tableChanges
+	for _, col := range to.Columns {
+		if old, ok := from.Column(col.Name); !ok {
+			c.added = append(c.added, col)
+		} else if !old.Equal(col) {
+			c.changed = append(c.changed, [2]*Column{old, col})
+		}
+	}
+	for _, col := range from.Columns {
+		if _, ok := to.Column(col.Name); !ok {
+			c.dropped = append(c.dropped, col)
+		}
+	}
+	for _, fk := range from.ForeignKeys {
+		if n, ok := to.ForeignKey(fk.Column); !ok || *n != *fk {
+			c.droppedFKs = append(c.droppedFKs, fk)
+		}
+	}
+	for _, fk := range to.ForeignKeys {
+		if o, ok := from.ForeignKey(fk.Column); !ok || *o != *fk {
+			c.addedFKs = append(c.addedFKs, fk)
+		}
+	}
+	c.primaryKeyChange = from.PrimaryKey != to.PrimaryKey
+	return c
+}
+
+func (c *tableChanges) empty() bool {
+	return len(c.added) == 0 && len(c.dropped) == 0 && len(c.changed) == 0 &&
+		len(c.droppedFKs) == 0 && len(c.addedFKs) == 0 && !c.primaryKeyChange
+}
+
+func primaryKeyName(t *Table) string {
+	return "pk_" + t.Name
+}
+
+func foreignKeyName(t *Table, fk *ForeignKey) string {
+	return "fk_" + t.Name + "_" + fk.Column
+}
+
+func uniqueName(t *Table, c *Column) string {
+	return "uq_" + t.Name + "_" + c.Name
+}
+
+func quoteWith(open, close, name string) string {
+	return open + strings.ReplaceAll(name, close, close+close) + close
+}
+
+// commonColumns returns the quoted names of the columns in both tables.
+func commonColumns(from, to *Table, quote func(string) string) string {
+	var names []string
+	for _, c := range to.Columns {
+		if _, ok := from.Column(c.Name); ok {
+			names = append(names, quote(c.Name))
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import "strings"
+
+// Migration holds the statements that apply (Up) and revert (Down) a change
+// from one schema to another.
+type Migration struct {
+	Up   []string
+	Down []string
+}
+
+// Empty reports whether there is nothing to migrate.
+func (m *Migration) Empty() bool {
+	return len(m.Up) == 0 && len(m.Down) == 0
+}
+
+// UpSQL returns the contents of the up migration file.
+func (m *Migration) UpSQL() string {
+	return joinStatements(m.Up)
+}
+
+// DownSQL returns the contents of the down migration file.
+func (m *Migration) DownSQL() string {
+	return joinStatements(m.Down)
+}
+
+func joinStatements(stmts []string) string {
+	if len(stmts) == 0 {
+		return ""
+	}
+	return strings.Join(stmts, "\n\n") + "\n"
+}
+
+// Diff returns the migration from one schema to another. New tables are
+// created before the tables that reference them and dropped tables are
+// removed after them. from may be nil for an empty database.
+func Diff(d Dialect, from, to *Schema) *Migration {
+	if from == nil {
+		from = &Schema{}
+	}
+	if to == nil {
+		to = &Schema{}
+	}
+
+	var created, dropped []*Table
+	var altered [][2]*Table
+	for _, t := range to.Tables {
+		if old, ok := from.Table(t.Name); ok {
+			altered = append(altered, [2]*Table{old, t})
+		} else {
+			created = append(created, t)
+		}
+	}
+	for _, t := range from.Tables {
+		if _, ok := to.Table(t.Name); !ok {
+			dropped = append(dropped, t)
+		}
+	}
+	created = sortTables(created)
+	dropped = sortTables(dropped)
+
+	var m Migration
+	for _, t := range created {
+		m.Up = append(m.Up, d.CreateTable(t)...)
+	}
+	for _, pair := range altered {
+		m.Up = append(m.Up, d.AlterTable(pair[0], pair[1])...)
+	}
+	for i := len(dropped) - 1; i >= 0; i-- {
+		m.Up = append(m.Up, d.DropTable(dropped[i])...)
+	}
+
+	for _, t := range dropped {
+		m.Down = append(m.Down, d.CreateTable(t)...)
+	}
+	for _, pair := range altered {
+		m.Down = append(m.Down, d.AlterTable(pair[1], pair[0])...)
+	}
+	for i := len(created) - 1; i >= 0; i-- {
+		m.Down = append(m.Down, d.DropTable(created[i])...)
+	}
+
+	return &m
+}
+
+// sortTables orders tables so that referenced tables come first while
+// otherwise keeping the order of the definition. Cycles are broken in
+// definition order.
+func sortTables(tables []*Table) []*Table {
+	byName := make(map[string]*Table, len(tables))
+	for _, t := range tables {
+		byName[t.Name] = t
+	}
+
+	sorted := make([]*Table, 0, len(tables))
+	visited := make(map[string]bool, len(tables))
+	var visit func(t *Table)
+	visit = func(t *Table) {
+		if visited[t.Name] {
+			return
+		}
+		visited[t.Name] = true
+		for _, fk := range t.ForeignKeys {
+			if ref, ok := byName[fk.References]; ok {
+				visit(ref)
+			}
+		}
+		sorted = append(sorted, t)
+	}
+	for _, t := range tables {
+		visit(t)
+	}
+
+	return sorted
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SnapshotFile is the file in a migrations directory that records the
+// schema the existing migrations produce. New migrations are the difference
+// between it and the current interface definition. The file does not match
+// the migration file pattern so it is ignored when migrating.
+const SnapshotFile = "nanobus.schema.json"
+
+// ErrNoSnapshot is returned by LoadSnapshot for a directory that has
+// migrations but no snapshot. The schema those migrations produce is not
+// known, so a baseline snapshot must be created with SaveSnapshot once they
+// match the interface definition.
+var ErrNoSnapshot = errors.New("the migrations directory does not have a " + SnapshotFile + " snapshot")
+
+// defaultVersionWidth is the number of digits used for versions when the
+// directory does not have migrations yet.
+const defaultVersionWidth = 6
+
+var migrationFile = regexp.MustCompile(`^([0-9]+)_(.*)\.(down|up)\.sql$`)
+
+// LoadSnapshot reads the schema snapshot in dir. An empty schema is returned
+// if the directory does not have migrations yet and ErrNoSnapshot if it has
+// migrations but no snapshot.
+func LoadSnapshot(dir string) (*Schema, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SnapshotFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		has, err := hasMigrations(dir)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			return nil, ErrNoSnapshot
+		}
+		return &Schema{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", SnapshotFile, err)
+	}
+	return &s, nil
+}
+
+func hasMigrations(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && migrationFile.MatchString(entry.Name()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveSnapshot writes the schema snapshot to dir.
+func SaveSnapshot(dir string, s *Schema) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, SnapshotFile), append(data, '\n'), 0o644)
+}
+
+// NextVersion returns the version number for the next migration in dir,
+// keeping the zero padding of the existing migrations.
+func NextVersion(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	var last uint64
+	width := defaultVersionWidth
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid migration version %q", entry.Name())
+		}
+		if version >= last {
+			last = version
+			width = len(match[1])
+		}
+	}
+
+	return fmt.Sprintf("%0*d", width, last+1), nil
+}
+
+// Filenames returns the up and down migration file names for version.
+func Filenames(version, name string) (up, down string) {
+	base := version + "_" + migrationName(name)
+	return base + ".up.sql", base + ".down.sql"
+}
+
+// WriteMigration writes m as the next numbered migration pair in dir and
+// records s as the new snapshot. It returns the paths of the files written.
+func WriteMigration(dir, name string, m *Migration, s *Schema) ([]string, error) {
+	version, err := NextVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	up, down := Filenames(version, name)
+	files := []string{filepath.Join(dir, up), filepath.Join(dir, down)}
+	if err := os.WriteFile(files[0], []byte(m.UpSQL()), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(files[1], []byte(m.DownSQL()), 0o644); err != nil {
+		return nil, err
+	}
+	if err := SaveSnapshot(dir, s); err != nil {
+		return nil, err
+	}
+
+	return append(files, filepath.Join(dir, SnapshotFile)), nil
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+func migrationName(name string) string {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "schema"
+	}
+	return name
+}
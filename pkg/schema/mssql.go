@@ -0,0 +1,205 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MSSQL generates DDL for Microsoft SQL Server and Azure SQL.
+type MSSQL struct{}
+
+func (MSSQL) Name() string {
+	return "mssql"
+}
+
+func (d MSSQL) quote(name string) string {
+	return quoteWith("[", "]", name)
+}
+
+// columnType maps c to a SQL Server type. Strings that are part of a key or
+// constraint are limited to 450 characters, the most an index key allows.
+func (d MSSQL) columnType(t *Table, c *Column) string {
+	if c.SQLType != "" {
+		return c.SQLType
+	}
+	switch c.Type {
+	case TypeString:
+		if c.Size > 0 {
+			return fmt.Sprintf("nvarchar(%d)", c.Size)
+		}
+		if _, fk := t.ForeignKey(c.Name); fk || c.Unique || c.Name == t.PrimaryKey {
+			return "nvarchar(450)"
+		}
+		return "nvarchar(max)"
+	case TypeBool:
+		return "bit"
+	case TypeInt16:
+		return "smallint"
+	case TypeInt32:
+		return "int"
+	case TypeInt64:
+		return "bigint"
+	case TypeFloat32:
+		return "real"
+	case TypeFloat64:
+		return "float"
+	case TypeDecimal:
+		return numeric("decimal", c)
+	case TypeBytes:
+		return "varbinary(max)"
+	case TypeDateTime:
+		return "datetimeoffset"
+	}
+	return "nvarchar(max)"
+}
+
+func (d MSSQL) defaultValue(c *Column) string {
+	if c.Type == TypeBool {
+		switch *c.Default {
+		case "true":
+			return "1"
+		case "false":
+			return "0"
+		}
+	}
+	return *c.Default
+}
+
+func (d MSSQL) columnDefinition(t *Table, c *Column) string {
+	var b strings.Builder
+	b.WriteString(d.quote(c.Name))
+	b.WriteString(" ")
+	b.WriteString(d.columnType(t, c))
+	if c.AutoIncrement {
+		b.WriteString(" IDENTITY(1,1)")
+	}
+	if c.Nullable {
+		b.WriteString(" NULL")
+	} else {
+		b.WriteString(" NOT NULL")
+	}
+	if c.Default != nil {
+		fmt.Fprintf(&b, " CONSTRAINT %s DEFAULT %s", d.quote(defaultName(t, c)), d.defaultValue(c))
+	}
+	return b.String()
+}
+
+func (d MSSQL) foreignKey(t *Table, fk *ForeignKey) string {
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.quote(foreignKeyName(t, fk)), d.quote(fk.Column), d.quote(fk.References), d.quote(fk.ReferencedColumn))
+}
+
+func (d MSSQL) unique(t *Table, c *Column) string {
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", d.quote(uniqueName(t, c)), d.quote(c.Name))
+}
+
+func (d MSSQL) primaryKey(t *Table) string {
+	return fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)", d.quote(primaryKeyName(t)), d.quote(t.PrimaryKey))
+}
+
+func (d MSSQL) CreateTable(t *Table) []string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, d.columnDefinition(t, c))
+	}
+	lines = append(lines, d.primaryKey(t))
+	for _, c := range t.Columns {
+		if c.Unique {
+			lines = append(lines, d.unique(t, c))
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		lines = append(lines, d.foreignKey(t, fk))
+	}
+	return []string{createTable(d.quote(t.Name), lines)}
+}
+
+func (d MSSQL) DropTable(t *Table) []string {
+	return []string{"DROP TABLE " + d.quote(t.Name) + ";"}
+}
+
+// AlterTable changes columns in place. SQL Server cannot add or remove the
+// IDENTITY property of an existing column so that change is left as a
+// comment for the author of the migration.
+func (d MSSQL) AlterTable(from, to *Table) []string {
+	changes := compareTables(from, to)
+	if changes.empty() {
+		return nil
+	}
+
+	table := d.quote(to.Name)
+	var stmts []string
+	alter := func(format string, args ...interface{}) {
+		stmts = append(stmts, "ALTER TABLE "+table+" "+fmt.Sprintf(format, args...)+";")
+	}
+
+	for _, fk := range changes.droppedFKs {
+		alter("DROP CONSTRAINT %s", d.quote(foreignKeyName(from, fk)))
+	}
+	if changes.primaryKeyChange {
+		alter("DROP CONSTRAINT %s", d.quote(primaryKeyName(from)))
+	}
+	for _, c := range changes.dropped {
+		if c.Unique {
+			alter("DROP CONSTRAINT %s", d.quote(uniqueName(from, c)))
+		}
+		if c.Default != nil {
+			alter("DROP CONSTRAINT %s", d.quote(defaultName(from, c)))
+		}
+		alter("DROP COLUMN %s", d.quote(c.Name))
+	}
+	for _, c := range changes.added {
+		alter("ADD %s", d.columnDefinition(to, c))
+		if c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	for _, pair := range changes.changed {
+		old, c := pair[0], pair[1]
+		column := d.quote(c.Name)
+		if old.AutoIncrement != c.AutoIncrement {
+			stmts = append(stmts, fmt.Sprintf("-- The IDENTITY property of %s.%s must be changed manually.", table, column))
+		}
+		// Constraints that depend on the column are recreated around the change.
+		if old.Unique {
+			alter("DROP CONSTRAINT %s", d.quote(uniqueName(from, old)))
+		}
+		if old.Default != nil {
+			alter("DROP CONSTRAINT %s", d.quote(defaultName(from, old)))
+		}
+		oldType, newType := d.columnType(from, old), d.columnType(to, c)
+		if oldType != newType || old.Nullable != c.Nullable {
+			null := "NOT NULL"
+			if c.Nullable {
+				null = "NULL"
+			}
+			alter("ALTER COLUMN %s %s %s", column, newType, null)
+		}
+		if c.Default != nil {
+			alter("ADD CONSTRAINT %s DEFAULT %s FOR %s", d.quote(defaultName(to, c)), d.defaultValue(c), column)
+		}
+		if c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	if changes.primaryKeyChange {
+		alter("ADD %s", d.primaryKey(to))
+	}
+	for _, fk := range changes.addedFKs {
+		alter("ADD %s", d.foreignKey(to, fk))
+	}
+
+	return stmts
+}
+
+func defaultName(t *Table, c *Column) string {
+	return "df_" + t.Name + "_" + c.Name
+}
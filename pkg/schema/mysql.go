@@ -0,0 +1,170 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MySQL generates DDL for MySQL and MariaDB.
+type MySQL struct{}
+
+func (MySQL) Name() string {
+	return "mysql"
+}
+
+func (d MySQL) quote(name string) string {
+	return quoteWith("`", "`", name)
+}
+
+// columnType maps c to a MySQL type. Strings that are part of a key or
+// constraint are limited to 255 characters since TEXT columns cannot be
+// indexed without a prefix length.
+func (d MySQL) columnType(t *Table, c *Column) string {
+	if c.SQLType != "" {
+		return c.SQLType
+	}
+	switch c.Type {
+	case TypeString:
+		if c.Size > 0 {
+			return fmt.Sprintf("varchar(%d)", c.Size)
+		}
+		if _, fk := t.ForeignKey(c.Name); fk || c.Unique || c.Name == t.PrimaryKey {
+			return "varchar(255)"
+		}
+		return "text"
+	case TypeBool:
+		return "boolean"
+	case TypeInt16:
+		return "smallint"
+	case TypeInt32:
+		return "int"
+	case TypeInt64:
+		return "bigint"
+	case TypeFloat32:
+		return "float"
+	case TypeFloat64:
+		return "double"
+	case TypeDecimal:
+		return numeric("decimal", c)
+	case TypeBytes:
+		return "longblob"
+	case TypeDateTime:
+		return "datetime(6)"
+	}
+	return "json"
+}
+
+func (d MySQL) columnDefinition(t *Table, c *Column) string {
+	var b strings.Builder
+	b.WriteString(d.quote(c.Name))
+	b.WriteString(" ")
+	b.WriteString(d.columnType(t, c))
+	if c.Nullable {
+		b.WriteString(" NULL")
+	} else {
+		b.WriteString(" NOT NULL")
+	}
+	if c.AutoIncrement {
+		b.WriteString(" AUTO_INCREMENT")
+	}
+	if c.Default != nil {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(*c.Default)
+	}
+	return b.String()
+}
+
+func (d MySQL) foreignKey(t *Table, fk *ForeignKey) string {
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.quote(foreignKeyName(t, fk)), d.quote(fk.Column), d.quote(fk.References), d.quote(fk.ReferencedColumn))
+}
+
+func (d MySQL) unique(t *Table, c *Column) string {
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", d.quote(uniqueName(t, c)), d.quote(c.Name))
+}
+
+// primaryKey returns the primary key constraint. MySQL always names it
+// PRIMARY.
+func (d MySQL) primaryKey(t *Table) string {
+	return fmt.Sprintf("PRIMARY KEY (%s)", d.quote(t.PrimaryKey))
+}
+
+func (d MySQL) CreateTable(t *Table) []string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, d.columnDefinition(t, c))
+	}
+	lines = append(lines, d.primaryKey(t))
+	for _, c := range t.Columns {
+		if c.Unique {
+			lines = append(lines, d.unique(t, c))
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		lines = append(lines, d.foreignKey(t, fk))
+	}
+	return []string{createTable(d.quote(t.Name), lines)}
+}
+
+func (d MySQL) DropTable(t *Table) []string {
+	return []string{"DROP TABLE " + d.quote(t.Name) + ";"}
+}
+
+// AlterTable redefines changed columns with MODIFY COLUMN, which sets the
+// type, nullability, default and AUTO_INCREMENT at once.
+func (d MySQL) AlterTable(from, to *Table) []string {
+	changes := compareTables(from, to)
+	if changes.empty() {
+		return nil
+	}
+
+	table := d.quote(to.Name)
+	var stmts []string
+	alter := func(format string, args ...interface{}) {
+		stmts = append(stmts, "ALTER TABLE "+table+" "+fmt.Sprintf(format, args...)+";")
+	}
+
+	for _, fk := range changes.droppedFKs {
+		alter("DROP FOREIGN KEY %s", d.quote(foreignKeyName(from, fk)))
+	}
+	if changes.primaryKeyChange {
+		alter("DROP PRIMARY KEY")
+	}
+	for _, c := range changes.dropped {
+		alter("DROP COLUMN %s", d.quote(c.Name))
+	}
+	for _, c := range changes.added {
+		alter("ADD COLUMN %s", d.columnDefinition(to, c))
+		if c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	for _, pair := range changes.changed {
+		old, c := pair[0], pair[1]
+		if old.Unique && !c.Unique {
+			alter("DROP INDEX %s", d.quote(uniqueName(from, old)))
+		}
+		if d.columnDefinition(from, old) != d.columnDefinition(to, c) {
+			alter("MODIFY COLUMN %s", d.columnDefinition(to, c))
+		}
+		if !old.Unique && c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	if changes.primaryKeyChange {
+		alter("ADD %s", d.primaryKey(to))
+	}
+	for _, fk := range changes.addedFKs {
+		alter("ADD %s", d.foreignKey(to, fk))
+	}
+
+	return stmts
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Postgres generates DDL for PostgreSQL.
+type Postgres struct{}
+
+func (Postgres) Name() string {
+	return "postgres"
+}
+
+func (d Postgres) quote(name string) string {
+	return quoteWith(`"`, `"`, name)
+}
+
+func (d Postgres) columnType(c *Column) string {
+	if c.SQLType != "" {
+		return c.SQLType
+	}
+	switch c.Type {
+	case TypeString:
+		if c.Size > 0 {
+			return fmt.Sprintf("varchar(%d)", c.Size)
+		}
+		return "text"
+	case TypeBool:
+		return "boolean"
+	case TypeInt16:
+		return "smallint"
+	case TypeInt32:
+		return "integer"
+	case TypeInt64:
+		return "bigint"
+	case TypeFloat32:
+		return "real"
+	case TypeFloat64:
+		return "double precision"
+	case TypeDecimal:
+		return numeric("numeric", c)
+	case TypeBytes:
+		return "bytea"
+	case TypeDateTime:
+		return "timestamptz"
+	}
+	return "jsonb"
+}
+
+func (d Postgres) columnDefinition(c *Column) string {
+	var b strings.Builder
+	b.WriteString(d.quote(c.Name))
+	b.WriteString(" ")
+	b.WriteString(d.columnType(c))
+	if c.AutoIncrement {
+		b.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+	}
+	if !c.Nullable {
+		b.WriteString(" NOT NULL")
+	}
+	if c.Default != nil {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(*c.Default)
+	}
+	return b.String()
+}
+
+func (d Postgres) foreignKey(t *Table, fk *ForeignKey) string {
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.quote(foreignKeyName(t, fk)), d.quote(fk.Column), d.quote(fk.References), d.quote(fk.ReferencedColumn))
+}
+
+func (d Postgres) unique(t *Table, c *Column) string {
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", d.quote(uniqueName(t, c)), d.quote(c.Name))
+}
+
+func (d Postgres) primaryKey(t *Table) string {
+	return fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)", d.quote(primaryKeyName(t)), d.quote(t.PrimaryKey))
+}
+
+func (d Postgres) CreateTable(t *Table) []string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, d.columnDefinition(c))
+	}
+	lines = append(lines, d.primaryKey(t))
+	for _, c := range t.Columns {
+		if c.Unique {
+			lines = append(lines, d.unique(t, c))
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		lines = append(lines, d.foreignKey(t, fk))
+	}
+	return []string{createTable(d.quote(t.Name), lines)}
+}
+
+func (d Postgres) DropTable(t *Table) []string {
+	return []string{"DROP TABLE " + d.quote(t.Name) + ";"}
+}
+
+func (d Postgres) AlterTable(from, to *Table) []string {
+	changes := compareTables(from, to)
+	if changes.empty() {
+		return nil
+	}
+
+	table := d.quote(to.Name)
+	var stmts []string
+	alter := func(format string, args ...interface{}) {
+		stmts = append(stmts, "ALTER TABLE "+table+" "+fmt.Sprintf(format, args...)+";")
+	}
+
+	for _, fk := range changes.droppedFKs {
+		alter("DROP CONSTRAINT %s", d.quote(foreignKeyName(from, fk)))
+	}
+	if changes.primaryKeyChange {
+		alter("DROP CONSTRAINT %s", d.quote(primaryKeyName(from)))
+	}
+	for _, c := range changes.dropped {
+		alter("DROP COLUMN %s", d.quote(c.Name))
+	}
+	for _, c := range changes.added {
+		alter("ADD COLUMN %s", d.columnDefinition(c))
+		if c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	for _, pair := range changes.changed {
+		old, c := pair[0], pair[1]
+		column := d.quote(c.Name)
+		if old.Unique && !c.Unique {
+			alter("DROP CONSTRAINT %s", d.quote(uniqueName(from, old)))
+		}
+		if old.AutoIncrement && !c.AutoIncrement {
+			alter("ALTER COLUMN %s DROP IDENTITY", column)
+		}
+		if d.columnType(old) != d.columnType(c) {
+			alter("ALTER COLUMN %s TYPE %s USING %s::%s", column, d.columnType(c), column, d.columnType(c))
+		}
+		if old.Nullable != c.Nullable {
+			if c.Nullable {
+				alter("ALTER COLUMN %s DROP NOT NULL", column)
+			} else {
+				alter("ALTER COLUMN %s SET NOT NULL", column)
+			}
+		}
+		if stringValue(old.Default) != stringValue(c.Default) {
+			if c.Default == nil {
+				alter("ALTER COLUMN %s DROP DEFAULT", column)
+			} else {
+				alter("ALTER COLUMN %s SET DEFAULT %s", column, *c.Default)
+			}
+		}
+		if !old.AutoIncrement && c.AutoIncrement {
+			alter("ALTER COLUMN %s ADD GENERATED BY DEFAULT AS IDENTITY", column)
+		}
+		if !old.Unique && c.Unique {
+			alter("ADD %s", d.unique(to, c))
+		}
+	}
+	if changes.primaryKeyChange {
+		alter("ADD %s", d.primaryKey(to))
+	}
+	for _, fk := range changes.addedFKs {
+		alter("ADD %s", d.foreignKey(to, fk))
+	}
+
+	return stmts
+}
+
+func createTable(name string, lines []string) string {
+	return "CREATE TABLE " + name + " (\n  " + strings.Join(lines, ",\n  ") + "\n);"
+}
+
+func numeric(name string, c *Column) string {
+	switch {
+	case c.Precision > 0 && c.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", name, c.Precision, c.Scale)
+	case c.Precision > 0:
+		return fmt.Sprintf("%s(%d)", name, c.Precision)
+	}
+	return name
+}
@@ -0,0 +1,431 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package schema derives relational database schemas from the `@entity`
+// types of an interface definition and generates the DDL migrations needed
+// to move a database from one version of the schema to the next.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	gormschema "gorm.io/gorm/schema"
+
+	"github.com/nanobus/nanobus/pkg/spec"
+)
+
+// Column types are database independent and mapped to concrete SQL types by
+// each Dialect.
+const (
+	TypeString   = "string"
+	TypeBool     = "bool"
+	TypeInt16    = "int16"
+	TypeInt32    = "int32"
+	TypeInt64    = "int64"
+	TypeFloat32  = "float32"
+	TypeFloat64  = "float64"
+	TypeDecimal  = "decimal"
+	TypeBytes    = "bytes"
+	TypeDateTime = "datetime"
+	TypeJSON     = "json"
+)
+
+type (
+	// Schema is the set of tables for the entities of an interface definition.
+	Schema struct {
+		Tables []*Table `json:"tables"`
+	}
+
+	Table struct {
+		Name        string        `json:"name"`
+		Columns     []*Column     `json:"columns"`
+		PrimaryKey  string        `json:"primaryKey"`
+		ForeignKeys []*ForeignKey `json:"foreignKeys,omitempty"`
+	}
+
+	Column struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		// SQLType overrides the dialect's type mapping (e.g. `@gorm("type:citext")`).
+		SQLType       string  `json:"sqlType,omitempty"`
+		Size          int     `json:"size,omitempty"`
+		Precision     int     `json:"precision,omitempty"`
+		Scale         int     `json:"scale,omitempty"`
+		Nullable      bool    `json:"nullable,omitempty"`
+		AutoIncrement bool    `json:"autoIncrement,omitempty"`
+		Unique        bool    `json:"unique,omitempty"`
+		Default       *string `json:"default,omitempty"`
+	}
+
+	ForeignKey struct {
+		Column     string `json:"column"`
+		References string `json:"references"`
+		// ReferencedColumn is the primary key column of the referenced table.
+		ReferencedColumn string `json:"referencedColumn"`
+	}
+)
+
+// Table returns the table with the given name.
+func (s *Schema) Table(name string) (*Table, bool) {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Column returns the column with the given name.
+func (t *Table) Column(name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// ForeignKey returns the foreign key defined on column.
+func (t *Table) ForeignKey(column string) (*ForeignKey, bool) {
+	for _, fk := range t.ForeignKeys {
+		if fk.Column == column {
+			return fk, true
+		}
+	}
+	return nil, false
+}
+
+// Equal reports whether two columns have the same definition.
+func (c *Column) Equal(other *Column) bool {
+	return c.Name == other.Name &&
+		c.Type == other.Type &&
+		c.SQLType == other.SQLType &&
+		c.Size == other.Size &&
+		c.Precision == other.Precision &&
+		c.Scale == other.Scale &&
+		c.Nullable == other.Nullable &&
+		c.AutoIncrement == other.AutoIncrement &&
+		c.Unique == other.Unique &&
+		stringValue(c.Default) == stringValue(other.Default)
+}
+
+// FromSpec returns the schema for the types annotated with `@entity` in
+// namespaces. Column names follow the same rules as the SQL actions so that
+// generated tables can be queried without further configuration.
+func FromSpec(namespaces spec.Namespaces) (*Schema, error) {
+	names := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var types []*spec.Type
+	for _, name := range names {
+		for _, t := range namespaces[name].Types {
+			if _, ok := t.Annotation("entity"); ok {
+				types = append(types, t)
+			}
+		}
+	}
+
+	s := Schema{}
+	tables := make(map[*spec.Type]*Table, len(types))
+	for _, t := range types {
+		table, err := tableOf(t)
+		if err != nil {
+			return nil, err
+		}
+		tables[t] = table
+		s.Tables = append(s.Tables, table)
+	}
+
+	// Relationships are resolved once all tables are known since they
+	// depend on the key columns of the related tables.
+	for _, t := range types {
+		table := tables[t]
+		for _, f := range t.Fields {
+			if hasMany, ok := f.Annotation("hasMany"); ok {
+				if err := addHasMany(tables, table, t, f, hasMany); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if _, ok := f.Annotation("hasOne"); ok {
+				if err := addHasOne(tables, table, t, f); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return &s, nil
+}
+
+func tableOf(t *spec.Type) (*Table, error) {
+	table := Table{
+		Name: annotationValue(t, "entity", "table", t.Name),
+	}
+
+	for _, f := range t.Fields {
+		if _, ok := f.Annotation("hasMany"); ok {
+			continue
+		}
+		if _, ok := f.Annotation("hasOne"); ok {
+			// Added once the related table is known.
+			continue
+		}
+
+		c, err := columnOf(t, f)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := f.Annotation("key"); ok {
+			c.Name = keyColumn(t, f)
+			c.Nullable = false
+			if isInteger(c.Type) && c.Default == nil {
+				c.AutoIncrement = true
+			}
+			table.PrimaryKey = c.Name
+		}
+		if _, ok := f.Annotation("version"); ok && c.Default == nil {
+			c.Default = stringPtr("1")
+		}
+		if err := applyTagSettings(c, f); err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", f.Name, t.Name, err)
+		}
+		table.Columns = append(table.Columns, c)
+	}
+
+	if table.PrimaryKey == "" {
+		return nil, fmt.Errorf("entity %s does not have a key field", t.Name)
+	}
+
+	return &table, nil
+}
+
+func columnOf(t *spec.Type, f *spec.Field) (*Column, error) {
+	c := Column{
+		Name: columnName(f),
+	}
+
+	ref := f.Type
+	if ref.Kind == spec.KindOptional {
+		c.Nullable = true
+		ref = ref.OptionalType
+	}
+
+	switch ref.Kind {
+	case spec.KindString, spec.KindEnum:
+		c.Type = TypeString
+	case spec.KindBool:
+		c.Type = TypeBool
+	case spec.KindI8, spec.KindI16, spec.KindU8:
+		c.Type = TypeInt16
+	case spec.KindI32, spec.KindU16:
+		c.Type = TypeInt32
+	case spec.KindI64, spec.KindU32, spec.KindU64:
+		c.Type = TypeInt64
+	case spec.KindF32:
+		c.Type = TypeFloat32
+	case spec.KindF64:
+		c.Type = TypeFloat64
+	case spec.KindBytes:
+		c.Type = TypeBytes
+	case spec.KindDateTime:
+		c.Type = TypeDateTime
+	case spec.KindType, spec.KindList, spec.KindMap, spec.KindUnion, spec.KindRaw:
+		// Nested structures that are not relationships are stored as documents.
+		c.Type = TypeJSON
+	default:
+		return nil, fmt.Errorf("field %s of %s has an unsupported type", f.Name, t.Name)
+	}
+
+	if f.DefaultValue != nil {
+		c.Default = stringPtr(literal(f.DefaultValue))
+	}
+
+	return &c, nil
+}
+
+// applyTagSettings applies GORM style tag settings from a `@gorm` annotation,
+// e.g. `@gorm("size:64;not null;unique")`.
+func applyTagSettings(c *Column, f *spec.Field) error {
+	a, ok := f.Annotation("gorm")
+	if !ok || len(a.Arguments) == 0 {
+		return nil
+	}
+	settings := gormschema.ParseTagSetting(a.Arguments[0].ValueString(), ";")
+
+	if v, ok := settings["COLUMN"]; ok {
+		c.Name = v
+	}
+	if v, ok := settings["TYPE"]; ok {
+		c.SQLType = v
+	}
+	for key, dst := range map[string]*int{
+		"SIZE":      &c.Size,
+		"PRECISION": &c.Precision,
+		"SCALE":     &c.Scale,
+	} {
+		if v, ok := settings[key]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", strings.ToLower(key), v)
+			}
+			*dst = n
+		}
+	}
+	if c.Precision > 0 && c.Type == TypeFloat64 {
+		c.Type = TypeDecimal
+	}
+	if _, ok := settings["NOT NULL"]; ok {
+		c.Nullable = false
+	}
+	if _, ok := settings["UNIQUE"]; ok {
+		c.Unique = true
+	}
+	if v, ok := settings["DEFAULT"]; ok {
+		c.Default = &v
+	}
+	if v, ok := settings["AUTOINCREMENT"]; ok {
+		c.AutoIncrement = !strings.EqualFold(v, "false")
+	}
+
+	return nil
+}
+
+func addHasOne(tables map[*spec.Type]*Table, table *Table, t *spec.Type, f *spec.Field) error {
+	ref := f.Type
+	nullable := false
+	if ref.Kind == spec.KindOptional {
+		nullable = true
+		ref = ref.OptionalType
+	}
+	related, ok := tables[ref.Type]
+	if ref.Kind != spec.KindType || !ok {
+		return fmt.Errorf("hasOne field %s of %s is not an entity", f.Name, t.Name)
+	}
+	key, _ := related.Column(related.PrimaryKey)
+
+	c := foreignKeyColumn(columnName(f), key)
+	c.Nullable = nullable
+	if err := applyTagSettings(c, f); err != nil {
+		return fmt.Errorf("field %s of %s: %w", f.Name, t.Name, err)
+	}
+	table.Columns = append(table.Columns, c)
+	table.ForeignKeys = append(table.ForeignKeys, &ForeignKey{
+		Column:           c.Name,
+		References:       related.Name,
+		ReferencedColumn: related.PrimaryKey,
+	})
+
+	return nil
+}
+
+func addHasMany(tables map[*spec.Type]*Table, table *Table, t *spec.Type, f *spec.Field, hasMany *spec.Annotation) error {
+	arg, ok := hasMany.Argument("key")
+	if !ok {
+		return nil
+	}
+	ref := f.Type
+	if ref.Kind == spec.KindOptional {
+		ref = ref.OptionalType
+	}
+	if ref.Kind != spec.KindList || ref.ItemType.Kind != spec.KindType {
+		return fmt.Errorf("hasMany field %s of %s is not a list of entities", f.Name, t.Name)
+	}
+	child, ok := tables[ref.ItemType.Type]
+	if !ok {
+		return fmt.Errorf("hasMany field %s of %s is not a list of entities", f.Name, t.Name)
+	}
+
+	column := arg.ValueString()
+	if _, ok := child.Column(column); !ok {
+		key, _ := table.Column(table.PrimaryKey)
+		child.Columns = append(child.Columns, foreignKeyColumn(column, key))
+	}
+	if _, ok := child.ForeignKey(column); !ok {
+		child.ForeignKeys = append(child.ForeignKeys, &ForeignKey{
+			Column:           column,
+			References:       table.Name,
+			ReferencedColumn: table.PrimaryKey,
+		})
+	}
+
+	return nil
+}
+
+// foreignKeyColumn returns a column that stores values of key.
+func foreignKeyColumn(name string, key *Column) *Column {
+	return &Column{
+		Name:      name,
+		Type:      key.Type,
+		SQLType:   key.SQLType,
+		Size:      key.Size,
+		Precision: key.Precision,
+		Scale:     key.Scale,
+	}
+}
+
+func columnName(f *spec.Field) string {
+	column := annotationValue(f, "column", "name", "")
+	if column == "" {
+		column = annotationValue(f, "hasOne", "foreignKey", "")
+	}
+	if column == "" {
+		column = strcase.ToSnake(f.Name)
+	}
+	return column
+}
+
+func keyColumn(t *spec.Type, f *spec.Field) string {
+	if _, ok := t.Annotation("primaryKey"); ok {
+		return annotationValue(t, "primaryKey", "name", "")
+	}
+	return annotationValue(f, "column", "name", strcase.ToSnake(f.Name))
+}
+
+func annotationValue(a spec.Annotator, annotation, argument, defaultValue string) string {
+	if av, ok := a.Annotation(annotation); ok {
+		if arg, ok := av.Argument(argument); ok {
+			return fmt.Sprintf("%v", arg.Value)
+		}
+	}
+	return defaultValue
+}
+
+func isInteger(typ string) bool {
+	return typ == TypeInt16 || typ == TypeInt32 || typ == TypeInt64
+}
+
+// literal renders a default value from the interface definition as SQL.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
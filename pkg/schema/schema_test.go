@@ -0,0 +1,207 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/schema"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/spec/apex"
+)
+
+const v1 = `
+namespace "shop"
+
+type Customer @entity(table: "customers") {
+  id: i64 @key
+  email: string @gorm("size:128;unique")
+}
+
+type Order @entity(table: "orders") {
+  id: string @key
+  customer: Customer @hasOne(foreignKey: "customer_id")
+  version: i64 @version
+  items: [LineItem] @hasMany(key: "order_id")
+}
+
+type LineItem @entity(table: "line_items") {
+  id: i64 @key
+  sku: string
+}
+`
+
+const v2 = `
+namespace "shop"
+
+type Customer @entity(table: "customers") {
+  id: i64 @key
+  email: string @gorm("size:256;unique")
+  name: string?
+}
+
+type Order @entity(table: "orders") {
+  id: string @key
+  customer: Customer @hasOne(foreignKey: "customer_id")
+  version: i64 @version
+  items: [LineItem] @hasMany(key: "order_id")
+  notes: [string]?
+}
+
+type LineItem @entity(table: "line_items") {
+  id: i64 @key
+  sku: string
+}
+`
+
+func fromSpec(t *testing.T, source string) *schema.Schema {
+	ns, err := apex.Parse([]byte(source))
+	require.NoError(t, err)
+	s, err := schema.FromSpec(spec.Namespaces{ns.Name: ns})
+	require.NoError(t, err)
+	return s
+}
+
+func TestCreatePostgres(t *testing.T) {
+	m := schema.Diff(schema.Postgres{}, nil, fromSpec(t, v1))
+	assert.Equal(t, []string{
+		`CREATE TABLE "customers" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+  "email" varchar(128) NOT NULL,
+  CONSTRAINT "pk_customers" PRIMARY KEY ("id"),
+  CONSTRAINT "uq_customers_email" UNIQUE ("email")
+);`,
+		`CREATE TABLE "orders" (
+  "id" text NOT NULL,
+  "version" bigint NOT NULL DEFAULT 1,
+  "customer_id" bigint NOT NULL,
+  CONSTRAINT "pk_orders" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_orders_customer_id" FOREIGN KEY ("customer_id") REFERENCES "customers" ("id")
+);`,
+		`CREATE TABLE "line_items" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+  "sku" text NOT NULL,
+  "order_id" text NOT NULL,
+  CONSTRAINT "pk_line_items" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_line_items_order_id" FOREIGN KEY ("order_id") REFERENCES "orders" ("id")
+);`,
+	}, m.Up)
+	assert.Equal(t, []string{
+		`DROP TABLE "line_items";`,
+		`DROP TABLE "orders";`,
+		`DROP TABLE "customers";`,
+	}, m.Down)
+}
+
+func TestAlterPostgres(t *testing.T) {
+	m := schema.Diff(schema.Postgres{}, fromSpec(t, v1), fromSpec(t, v2))
+	assert.Equal(t, []string{
+		`ALTER TABLE "customers" ADD COLUMN "name" text;`,
+		`ALTER TABLE "customers" ALTER COLUMN "email" TYPE varchar(256) USING "email"::varchar(256);`,
+		`ALTER TABLE "orders" ADD COLUMN "notes" jsonb;`,
+	}, m.Up)
+	assert.Equal(t, []string{
+		`ALTER TABLE "customers" DROP COLUMN "name";`,
+		`ALTER TABLE "customers" ALTER COLUMN "email" TYPE varchar(128) USING "email"::varchar(128);`,
+		`ALTER TABLE "orders" DROP COLUMN "notes";`,
+	}, m.Down)
+}
+
+func TestAlterMSSQL(t *testing.T) {
+	m := schema.Diff(schema.MSSQL{}, fromSpec(t, v1), fromSpec(t, v2))
+	assert.Equal(t, []string{
+		`ALTER TABLE [customers] ADD [name] nvarchar(max) NULL;`,
+		`ALTER TABLE [customers] DROP CONSTRAINT [uq_customers_email];`,
+		`ALTER TABLE [customers] ALTER COLUMN [email] nvarchar(256) NOT NULL;`,
+		`ALTER TABLE [customers] ADD CONSTRAINT [uq_customers_email] UNIQUE ([email]);`,
+		`ALTER TABLE [orders] ADD [notes] nvarchar(max) NULL;`,
+	}, m.Up)
+}
+
+func TestAlterSQLite(t *testing.T) {
+	m := schema.Diff(schema.SQLite{}, fromSpec(t, v1), fromSpec(t, v2))
+	assert.Equal(t, []string{
+		`PRAGMA defer_foreign_keys = ON;`,
+		`CREATE TABLE "customers__new" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  "email" TEXT NOT NULL,
+  "name" TEXT,
+  CONSTRAINT "uq_customers_email" UNIQUE ("email")
+);`,
+		`INSERT INTO "customers__new" ("id", "email") SELECT "id", "email" FROM "customers";`,
+		`DROP TABLE "customers";`,
+		`ALTER TABLE "customers__new" RENAME TO "customers";`,
+		`ALTER TABLE "orders" ADD COLUMN "notes" TEXT;`,
+	}, m.Up)
+}
+
+func TestWriteMigration(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0007_init.up.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0007_init.down.sql"), nil, 0o644))
+
+	s := fromSpec(t, v1)
+	files, err := schema.WriteMigration(dir, "Add customers!", schema.Diff(schema.Postgres{}, nil, s), s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0008_add_customers.up.sql"),
+		filepath.Join(dir, "0008_add_customers.down.sql"),
+		filepath.Join(dir, schema.SnapshotFile),
+	}, files)
+
+	snapshot, err := schema.LoadSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, s, snapshot)
+	assert.True(t, schema.Diff(schema.Postgres{}, snapshot, s).Empty())
+
+	next, err := schema.NextVersion(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "0009", next)
+}
+
+func TestAlterMySQL(t *testing.T) {
+	m := schema.Diff(schema.MySQL{}, nil, fromSpec(t, v1))
+	assert.Equal(t, "CREATE TABLE `orders` (\n"+
+		"  `id` varchar(255) NOT NULL,\n"+
+		"  `version` bigint NOT NULL DEFAULT 1,\n"+
+		"  `customer_id` bigint NOT NULL,\n"+
+		"  PRIMARY KEY (`id`),\n"+
+		"  CONSTRAINT `fk_orders_customer_id` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)\n"+
+		");", m.Up[1])
+
+	m = schema.Diff(schema.MySQL{}, fromSpec(t, v1), fromSpec(t, v2))
+	assert.Equal(t, []string{
+		"ALTER TABLE `customers` ADD COLUMN `name` text NULL;",
+		"ALTER TABLE `customers` MODIFY COLUMN `email` varchar(256) NOT NULL;",
+		"ALTER TABLE `orders` ADD COLUMN `notes` json NULL;",
+	}, m.Up)
+}
+
+func TestLoadSnapshotWithoutBaseline(t *testing.T) {
+	dir := t.TempDir()
+	s, err := schema.LoadSnapshot(dir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Tables)
+
+	// The schema of existing migrations is not known without a snapshot.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_init.up.sql"), nil, 0o644))
+	_, err = schema.LoadSnapshot(dir)
+	assert.ErrorIs(t, err, schema.ErrNoSnapshot)
+
+	baseline := fromSpec(t, v1)
+	require.NoError(t, schema.SaveSnapshot(dir, baseline))
+	s, err = schema.LoadSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, s)
+}
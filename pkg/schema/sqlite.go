@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLite generates DDL for SQLite.
+type SQLite struct{}
+
+func (SQLite) Name() string {
+	return "sqlite"
+}
+
+func (d SQLite) quote(name string) string {
+	return quoteWith(`"`, `"`, name)
+}
+
+func (d SQLite) columnType(c *Column) string {
+	if c.SQLType != "" {
+		return c.SQLType
+	}
+	switch c.Type {
+	case TypeString, TypeJSON:
+		return "TEXT"
+	case TypeBool:
+		return "BOOLEAN"
+	case TypeInt16, TypeInt32, TypeInt64:
+		return "INTEGER"
+	case TypeFloat32, TypeFloat64:
+		return "REAL"
+	case TypeDecimal:
+		return numeric("NUMERIC", c)
+	case TypeBytes:
+		return "BLOB"
+	case TypeDateTime:
+		return "DATETIME"
+	}
+	return "TEXT"
+}
+
+// rowID reports whether the primary key is an alias for the rowid, which is
+// how SQLite supports auto incrementing keys.
+func (d SQLite) rowID(t *Table) bool {
+	key, ok := t.Column(t.PrimaryKey)
+	return ok && key.AutoIncrement && isInteger(key.Type) && key.SQLType == ""
+}
+
+func (d SQLite) columnDefinition(t *Table, c *Column) string {
+	var b strings.Builder
+	b.WriteString(d.quote(c.Name))
+	b.WriteString(" ")
+	b.WriteString(d.columnType(c))
+	if c.Name == t.PrimaryKey && d.rowID(t) {
+		b.WriteString(" PRIMARY KEY AUTOINCREMENT")
+	}
+	if !c.Nullable {
+		b.WriteString(" NOT NULL")
+	}
+	if c.Default != nil {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(*c.Default)
+	}
+	return b.String()
+}
+
+func (d SQLite) foreignKey(t *Table, fk *ForeignKey) string {
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.quote(foreignKeyName(t, fk)), d.quote(fk.Column), d.quote(fk.References), d.quote(fk.ReferencedColumn))
+}
+
+func (d SQLite) createTable(name string, t *Table) string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, d.columnDefinition(t, c))
+	}
+	if !d.rowID(t) {
+		lines = append(lines, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)",
+			d.quote(primaryKeyName(t)), d.quote(t.PrimaryKey)))
+	}
+	for _, c := range t.Columns {
+		if c.Unique {
+			lines = append(lines, fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)",
+				d.quote(uniqueName(t, c)), d.quote(c.Name)))
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		lines = append(lines, d.foreignKey(t, fk))
+	}
+	return createTable(d.quote(name), lines)
+}
+
+func (d SQLite) CreateTable(t *Table) []string {
+	return []string{d.createTable(t.Name, t)}
+}
+
+func (d SQLite) DropTable(t *Table) []string {
+	return []string{"DROP TABLE " + d.quote(t.Name) + ";"}
+}
+
+// AlterTable adds columns in place when possible. Any other change rebuilds
+// the table as described in https://www.sqlite.org/lang_altertable.html
+// since SQLite cannot alter columns or constraints.
+func (d SQLite) AlterTable(from, to *Table) []string {
+	changes := compareTables(from, to)
+	if changes.empty() {
+		return nil
+	}
+
+	if d.canAddColumns(&changes) {
+		stmts := make([]string, len(changes.added))
+		for i, c := range changes.added {
+			stmts[i] = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", d.quote(to.Name), d.columnDefinition(to, c))
+		}
+		return stmts
+	}
+
+	temp := to.Name + "__new"
+	columns := commonColumns(from, to, d.quote)
+	return []string{
+		"PRAGMA defer_foreign_keys = ON;",
+		d.createTable(temp, to),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", d.quote(temp), columns, columns, d.quote(from.Name)),
+		"DROP TABLE " + d.quote(from.Name) + ";",
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", d.quote(temp), d.quote(to.Name)),
+	}
+}
+
+// canAddColumns reports whether the changes only add columns that SQLite's
+// ADD COLUMN supports.
+func (d SQLite) canAddColumns(c *tableChanges) bool {
+	if len(c.dropped) > 0 || len(c.changed) > 0 || len(c.droppedFKs) > 0 ||
+		len(c.addedFKs) > 0 || c.primaryKeyChange {
+		return false
+	}
+	for _, col := range c.added {
+		if col.Unique || (!col.Nullable && col.Default == nil) {
+			return false
+		}
+	}
+	return true
+}
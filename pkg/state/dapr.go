@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+
+	dapr "github.com/dapr/go-sdk/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Dapr adapts a Dapr state store component. Items with an etag are written
+// with first-write concurrency.
+type Dapr struct {
+	client dapr.Client
+	store  string
+}
+
+func NewDapr(client dapr.Client, store string) *Dapr {
+	return &Dapr{
+		client: client,
+		store:  store,
+	}
+}
+
+func (d *Dapr) Get(ctx context.Context, key string) (*Item, error) {
+	resp, err := d.client.GetState(ctx, d.store, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Value) == 0 {
+		return nil, nil
+	}
+
+	return &Item{
+		Key:  key,
+		Data: resp.Value,
+		Etag: resp.Etag,
+	}, nil
+}
+
+func (d *Dapr) Set(ctx context.Context, items ...*SetItem) error {
+	r := make([]*dapr.SetStateItem, len(items))
+	for i, item := range items {
+		r[i] = &dapr.SetStateItem{
+			Key:   item.Key,
+			Value: item.Data,
+		}
+		if item.Etag != nil {
+			r[i].Etag = &dapr.ETag{Value: *item.Etag}
+			r[i].Options = &dapr.StateOptions{
+				Concurrency: dapr.StateConcurrencyFirstWrite,
+			}
+		}
+	}
+
+	return daprError(d.client.SaveBulkState(ctx, d.store, r...))
+}
+
+func (d *Dapr) Delete(ctx context.Context, key string, etag *string) error {
+	if etag == nil {
+		return daprError(d.client.DeleteState(ctx, d.store, key, nil))
+	}
+
+	return daprError(d.client.DeleteStateWithETag(ctx, d.store, key,
+		&dapr.ETag{Value: *etag}, nil, &dapr.StateOptions{
+			Concurrency: dapr.StateConcurrencyFirstWrite,
+		}))
+}
+
+// daprError converts the status Dapr returns for etag mismatches.
+func daprError(err error) error {
+	if status.Code(err) == codes.Aborted {
+		return ErrEtagMismatch
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// Memory is an in-process state store for local development and tests.
+// Etags are version numbers that increase with each write.
+type Memory struct {
+	mu          sync.RWMutex
+	items       map[string]memoryItem
+	lastVersion uint64
+}
+
+type memoryItem struct {
+	data []byte
+	etag string
+}
+
+// MemState is the NamedLoader for an in-memory state store.
+func MemState() (string, resource.Loader) {
+	return "nanobus.resource.memstate/v1", MemStateLoader
+}
+
+func MemStateLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	return NewMemory(), nil
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		items: make(map[string]memoryItem),
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) (*Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return &Item{
+		Key:  key,
+		Data: append([]byte(nil), item.data...),
+		Etag: item.etag,
+	}, nil
+}
+
+// Set stores the items atomically.
+func (m *Memory) Set(ctx context.Context, items ...*SetItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, item := range items {
+		if !m.matches(item.Key, item.Etag) {
+			return ErrEtagMismatch
+		}
+	}
+	for _, item := range items {
+		m.lastVersion++
+		m.items[item.Key] = memoryItem{
+			data: append([]byte(nil), item.Data...),
+			etag: strconv.FormatUint(m.lastVersion, 10),
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string, etag *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.matches(key, etag) {
+		return ErrEtagMismatch
+	}
+	delete(m.items, key)
+
+	return nil
+}
+
+// matches returns true if etag is nil or the etag of the stored item.
+func (m *Memory) matches(key string, etag *string) bool {
+	if etag == nil {
+		return true
+	}
+	item, ok := m.items[key]
+	return ok && item.etag == *etag
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	nanoredis "github.com/nanobus/nanobus/pkg/actions/redis"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+const errEtagMismatch = "ETAG_MISMATCH"
+
+// setScript checks the etags of all items before setting any of them. The
+// arguments are the data, whether an etag is set and the etag of each item.
+var setScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+  if ARGV[i * 3 - 1] == "1" and redis.call("HGET", key, "version") ~= ARGV[i * 3] then
+    return redis.error_reply("` + errEtagMismatch + `")
+  end
+end
+for i, key in ipairs(KEYS) do
+  redis.call("HINCRBY", key, "version", 1)
+  redis.call("HSET", key, "data", ARGV[i * 3 - 2])
+end
+return 1
+`)
+
+var deleteScript = redis.NewScript(`
+if ARGV[1] == "1" and redis.call("HGET", KEYS[1], "version") ~= ARGV[2] then
+  return redis.error_reply("` + errEtagMismatch + `")
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+type RedisConfig struct {
+	nanoredis.ConnectionConfig `mapstructure:",squash"`
+	// KeyPrefix is prepended to the keys of items, for example to share a
+	// database between applications.
+	KeyPrefix string `mapstructure:"keyPrefix"`
+}
+
+// Redis stores each item in a hash with its data and a version that is the
+// etag. Items set together are written atomically and must hash to the same
+// slot in a cluster.
+type Redis struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// RedisState is the NamedLoader for a Redis state store.
+func RedisState() (string, resource.Loader) {
+	return "nanobus.resource.redisstate/v1", RedisStateLoader
+}
+
+func RedisStateLoader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (interface{}, error) {
+	var c RedisConfig
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	client, err := nanoredis.NewClient(&c.ConnectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedis(client, c.KeyPrefix), nil
+}
+
+func NewRedis(client redis.UniversalClient, keyPrefix string) *Redis {
+	return &Redis{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) (*Item, error) {
+	values, err := r.client.HMGet(ctx, r.keyPrefix+key, "data", "version").Result()
+	if err != nil {
+		return nil, err
+	}
+	version, ok := values[1].(string)
+	if !ok {
+		return nil, nil
+	}
+	data, _ := values[0].(string)
+
+	return &Item{
+		Key:  key,
+		Data: []byte(data),
+		Etag: version,
+	}, nil
+}
+
+func (r *Redis) Set(ctx context.Context, items ...*SetItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(items))
+	args := make([]interface{}, 0, len(items)*3)
+	for i, item := range items {
+		keys[i] = r.keyPrefix + item.Key
+		args = append(args, item.Data)
+		args = appendEtag(args, item.Etag)
+	}
+
+	return etagError(setScript.Run(ctx, r.client, keys, args...).Err())
+}
+
+func (r *Redis) Delete(ctx context.Context, key string, etag *string) error {
+	args := appendEtag(make([]interface{}, 0, 2), etag)
+	return etagError(deleteScript.Run(ctx, r.client, []string{r.keyPrefix + key}, args...).Err())
+}
+
+func appendEtag(args []interface{}, etag *string) []interface{} {
+	if etag == nil {
+		return append(args, "0", "")
+	}
+	return append(args, "1", *etag)
+}
+
+func etagError(err error) error {
+	// Redis versions and proxies may prefix the error with a code.
+	if err != nil && strings.Contains(err.Error(), errEtagMismatch) {
+		return ErrEtagMismatch
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package state defines the key/value state stores used by the `@state`
+// actions.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dapr "github.com/dapr/go-sdk/client"
+
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// ErrEtagMismatch is returned when the etag of a write does not match the
+// etag of the stored item.
+var ErrEtagMismatch = errors.New("etag mismatch")
+
+// Item is a stored value and the etag of its current version.
+type Item struct {
+	Key  string
+	Data []byte
+	Etag string
+}
+
+// SetItem is a value to store. If Etag is set, the item is only written if
+// it matches the etag of the stored item (first-write-wins concurrency).
+type SetItem struct {
+	Key  string
+	Data []byte
+	Etag *string
+}
+
+// Store stores values by key with etag concurrency.
+type Store interface {
+	// Get returns the item stored under key or nil if there is none.
+	Get(ctx context.Context, key string) (*Item, error)
+	// Set stores items. It returns ErrEtagMismatch if the etag of an item
+	// does not match.
+	Set(ctx context.Context, items ...*SetItem) error
+	// Delete removes the item stored under key. If etag is not nil, it
+	// returns ErrEtagMismatch unless it matches the etag of the stored item.
+	Delete(ctx context.Context, key string, etag *string) error
+}
+
+// Get returns the state store resource named name. Dapr client resources are
+// adapted to the Dapr state store component named daprStore.
+func Get(resources resource.Resources, name resource.Ref, daprStore string) (Store, error) {
+	r, ok := resources[string(name)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", name)
+	}
+
+	switch r := r.(type) {
+	case Store:
+		return r, nil
+	case dapr.Client:
+		if daprStore == "" {
+			return nil, fmt.Errorf("resource %q is a Dapr client and requires the name of a state store component", name)
+		}
+		return NewDapr(r, daprStore), nil
+	}
+
+	return nil, fmt.Errorf("resource %q is not a state store", name)
+}
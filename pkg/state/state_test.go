@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package state_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/state"
+)
+
+func TestMemory(t *testing.T) {
+	testStore(t, state.NewMemory())
+}
+
+func TestRedis(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	defer client.Close()
+	require.NoError(t, client.Del(context.Background(), "{test}1", "{test}2").Err())
+	testStore(t, state.NewRedis(client, "{test}"))
+}
+
+func testStore(t *testing.T, store state.Store) {
+	ctx := context.Background()
+
+	item, err := store.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	require.NoError(t, store.Set(ctx,
+		&state.SetItem{Key: "1", Data: []byte("one")},
+		&state.SetItem{Key: "2", Data: []byte("two")}))
+	item, err = store.Get(ctx, "1")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "1", item.Key)
+	assert.Equal(t, []byte("one"), item.Data)
+	etag := item.Etag
+	assert.NotEmpty(t, etag)
+
+	// Items are only written if their etag matches.
+	stale := "stale"
+	err = store.Set(ctx,
+		&state.SetItem{Key: "1", Data: []byte("uno"), Etag: &etag},
+		&state.SetItem{Key: "2", Data: []byte("dos"), Etag: &stale})
+	assert.ErrorIs(t, err, state.ErrEtagMismatch)
+	item, err = store.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("one"), item.Data)
+
+	require.NoError(t, store.Set(ctx, &state.SetItem{Key: "1", Data: []byte("uno"), Etag: &etag}))
+	item, err = store.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("uno"), item.Data)
+	assert.NotEqual(t, etag, item.Etag)
+
+	assert.ErrorIs(t, store.Set(ctx, &state.SetItem{Key: "1", Data: []byte("one"), Etag: &etag}), state.ErrEtagMismatch)
+	assert.ErrorIs(t, store.Delete(ctx, "1", &etag), state.ErrEtagMismatch)
+	require.NoError(t, store.Delete(ctx, "1", &item.Etag))
+	require.NoError(t, store.Delete(ctx, "2", nil))
+	require.NoError(t, store.Delete(ctx, "2", nil))
+
+	item, err = store.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	// Items that do not exist do not match any etag.
+	assert.ErrorIs(t, store.Set(ctx, &state.SetItem{Key: "1", Data: []byte("one"), Etag: &etag}), state.ErrEtagMismatch)
+}
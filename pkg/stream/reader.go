@@ -0,0 +1,81 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package stream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/nanobus/nanobus/pkg/channel/metadata"
+)
+
+// DefaultChunkSize is the chunk size used by SourceFromReader when none is
+// given.
+const DefaultChunkSize = 32 * 1024
+
+type readerSource struct {
+	r         io.Reader
+	chunkSize int
+	md        metadata.MD
+	canceled  atomic.Bool
+}
+
+// SourceFromReader returns a Source that reads `r` in chunks of up to
+// `chunkSize` bytes. Next sets `data`, which must be a `*any` or `*[]byte`,
+// to the next chunk and `md` to a copy of `md`.
+func SourceFromReader(r io.Reader, chunkSize int, md metadata.MD) Source {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &readerSource{
+		r:         r,
+		chunkSize: chunkSize,
+		md:        md,
+	}
+}
+
+func (s *readerSource) Next(data any, md *metadata.MD) error {
+	if s.canceled.Load() {
+		return context.Canceled
+	}
+
+	// Each chunk gets its own buffer because the caller may hold onto it.
+	buf := make([]byte, s.chunkSize)
+	n, err := io.ReadFull(s.r, buf)
+	if n == 0 {
+		if err == nil || err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return err
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	chunk := buf[:n]
+
+	switch v := data.(type) {
+	case *any:
+		*v = chunk
+	case *[]byte:
+		*v = chunk
+	default:
+		return fmt.Errorf("unsupported stream target %T", data)
+	}
+	if md != nil && s.md != nil {
+		*md = s.md.Copy()
+	}
+
+	return nil
+}
+
+func (s *readerSource) Cancel() {
+	s.canceled.Store(true)
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+// Package transaction stores database transactions in the context so that
+// actions run inside a transaction block share them.
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jmoiron/sqlx"
+)
+
+// Isolation levels.
+const (
+	ReadUncommitted = "read_uncommitted"
+	ReadCommitted   = "read_committed"
+	RepeatableRead  = "repeatable_read"
+	Serializable    = "serializable"
+)
+
+var (
+	ErrNotSupported        = errors.New("resource does not support transactions")
+	ErrNestingNotSupported = errors.New("database does not support nested transactions")
+)
+
+type Options struct {
+	// Isolation is one of the isolation level constants or empty for the
+	// database default.
+	Isolation string
+	ReadOnly  bool
+}
+
+// Tx is a transaction or, when nested inside another transaction on the same
+// resource, a savepoint.
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+type txKey struct {
+	resource interface{}
+}
+
+type sqlTx struct {
+	tx    *sqlx.Tx
+	depth int
+}
+
+// Begin starts a transaction on resource, which must be a *pgxpool.Pool or a
+// *sqlx.DB, and returns a context carrying it. If ctx already carries a
+// transaction for the resource, a savepoint is created instead and opts are
+// ignored. ErrNestingNotSupported is returned for databases without
+// savepoints.
+func Begin(ctx context.Context, resource interface{}, opts Options) (context.Context, Tx, error) {
+	key := txKey{resource}
+
+	switch r := resource.(type) {
+	case *pgxpool.Pool:
+		var tx pgx.Tx
+		var err error
+		if parent, ok := Pgx(ctx, r); ok {
+			// pgx implements nested transactions with savepoints.
+			tx, err = parent.Begin(ctx)
+		} else {
+			var txOptions pgx.TxOptions
+			if txOptions.IsoLevel, err = pgxIsolation(opts.Isolation); err != nil {
+				return ctx, nil, err
+			}
+			if opts.ReadOnly {
+				txOptions.AccessMode = pgx.ReadOnly
+			}
+			tx, err = r.BeginTx(ctx, txOptions)
+		}
+		if err != nil {
+			return ctx, nil, err
+		}
+
+		return context.WithValue(ctx, key, tx), pgxTx{tx}, nil
+
+	case *sqlx.DB:
+		if parent, ok := ctx.Value(key).(*sqlTx); ok {
+			statements, ok := savepointsOf(r.DriverName())
+			if !ok {
+				return ctx, nil, fmt.Errorf("%w: driver %q", ErrNestingNotSupported, r.DriverName())
+			}
+			nested := sqlTx{
+				tx:    parent.tx,
+				depth: parent.depth + 1,
+			}
+			sp := savepoint{
+				tx:         parent.tx,
+				name:       fmt.Sprintf("nanobus_sp_%d", nested.depth),
+				statements: statements,
+			}
+			if _, err := parent.tx.ExecContext(ctx, statements.create+sp.name); err != nil {
+				return ctx, nil, err
+			}
+
+			return context.WithValue(ctx, key, &nested), &sp, nil
+		}
+
+		isolation, err := sqlIsolation(opts.Isolation)
+		if err != nil {
+			return ctx, nil, err
+		}
+		tx, err := r.BeginTxx(ctx, &sql.TxOptions{
+			Isolation: isolation,
+			ReadOnly:  opts.ReadOnly,
+		})
+		if err != nil {
+			return ctx, nil, err
+		}
+
+		return context.WithValue(ctx, key, &sqlTx{tx: tx}), sqlxTx{tx}, nil
+	}
+
+	return ctx, nil, ErrNotSupported
+}
+
+// Pgx returns the transaction in ctx for pool, if any.
+func Pgx(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{pool}).(pgx.Tx)
+	return tx, ok
+}
+
+// SQL returns the transaction in ctx for db, if any.
+func SQL(ctx context.Context, db *sqlx.DB) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{db}).(*sqlTx)
+	if !ok {
+		return nil, false
+	}
+	return tx.tx, true
+}
+
+func pgxIsolation(isolation string) (pgx.TxIsoLevel, error) {
+	switch isolation {
+	case "":
+		return "", nil
+	case ReadUncommitted:
+		return pgx.ReadUncommitted, nil
+	case ReadCommitted:
+		return pgx.ReadCommitted, nil
+	case RepeatableRead:
+		return pgx.RepeatableRead, nil
+	case Serializable:
+		return pgx.Serializable, nil
+	}
+	return "", fmt.Errorf("unknown isolation level %q", isolation)
+}
+
+func sqlIsolation(isolation string) (sql.IsolationLevel, error) {
+	switch isolation {
+	case "":
+		return sql.LevelDefault, nil
+	case ReadUncommitted:
+		return sql.LevelReadUncommitted, nil
+	case ReadCommitted:
+		return sql.LevelReadCommitted, nil
+	case RepeatableRead:
+		return sql.LevelRepeatableRead, nil
+	case Serializable:
+		return sql.LevelSerializable, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", isolation)
+}
+
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (t pgxTx) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t pgxTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
+
+type sqlxTx struct {
+	tx *sqlx.Tx
+}
+
+func (t sqlxTx) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t sqlxTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
+// savepointStatements are the statement prefixes that create, release and
+// roll back to a savepoint. release is empty for databases that only release
+// savepoints with the transaction.
+type savepointStatements struct {
+	create   string
+	release  string
+	rollback string
+}
+
+// savepointsOf returns the savepoint statements for the database of a
+// driver and false if it does not support savepoints.
+func savepointsOf(driverName string) (savepointStatements, bool) {
+	switch driverName {
+	case "postgres", "pgx", "sqlite", "sqlite3", "mysql":
+		return savepointStatements{
+			create:   "SAVEPOINT ",
+			release:  "RELEASE SAVEPOINT ",
+			rollback: "ROLLBACK TO SAVEPOINT ",
+		}, true
+	case "sqlserver", "mssql", "azuresql":
+		return savepointStatements{
+			create:   "SAVE TRANSACTION ",
+			rollback: "ROLLBACK TRANSACTION ",
+		}, true
+	case "oracle":
+		return savepointStatements{
+			create:   "SAVEPOINT ",
+			rollback: "ROLLBACK TO SAVEPOINT ",
+		}, true
+	}
+	return savepointStatements{}, false
+}
+
+type savepoint struct {
+	tx         *sqlx.Tx
+	name       string
+	statements savepointStatements
+}
+
+func (s *savepoint) Commit(ctx context.Context) error {
+	if s.statements.release == "" {
+		return nil
+	}
+	_, err := s.tx.ExecContext(ctx, s.statements.release+s.name)
+	return err
+}
+
+func (s *savepoint) Rollback(ctx context.Context) error {
+	_, err := s.tx.ExecContext(ctx, s.statements.rollback+s.name)
+	return err
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package transaction_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/transaction"
+)
+
+func newDB(t *testing.T, driverName string) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return sqlx.NewDb(db, driverName), mock
+}
+
+func TestSQLSavepoints(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newDB(t, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT nanobus_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT nanobus_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT nanobus_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT nanobus_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, ok := transaction.SQL(ctx, db)
+	assert.False(t, ok)
+
+	txCtx, tx, err := transaction.Begin(ctx, db, transaction.Options{})
+	require.NoError(t, err)
+	outer, ok := transaction.SQL(txCtx, db)
+	require.True(t, ok)
+
+	nestedCtx, nested, err := transaction.Begin(txCtx, db, transaction.Options{})
+	require.NoError(t, err)
+	inner, ok := transaction.SQL(nestedCtx, db)
+	require.True(t, ok)
+	assert.Same(t, outer, inner)
+
+	deepCtx, deep, err := transaction.Begin(nestedCtx, db, transaction.Options{})
+	require.NoError(t, err)
+	require.NoError(t, deep.Rollback(deepCtx))
+	require.NoError(t, nested.Commit(nestedCtx))
+	require.NoError(t, tx.Commit(txCtx))
+}
+
+func TestSQLRollback(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newDB(t, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	_, tx, err := transaction.Begin(ctx, db, transaction.Options{
+		Isolation: transaction.Serializable,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+}
+
+func TestInvalidIsolation(t *testing.T) {
+	db, _ := newDB(t, "postgres")
+	_, _, err := transaction.Begin(context.Background(), db, transaction.Options{
+		Isolation: "snapshot",
+	})
+	assert.EqualError(t, err, `unknown isolation level "snapshot"`)
+}
+
+func TestNotSupported(t *testing.T) {
+	_, _, err := transaction.Begin(context.Background(), "not a database", transaction.Options{})
+	assert.ErrorIs(t, err, transaction.ErrNotSupported)
+}
+
+func TestMSSQLSavepoints(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newDB(t, "sqlserver")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVE TRANSACTION nanobus_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVE TRANSACTION nanobus_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TRANSACTION nanobus_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	txCtx, tx, err := transaction.Begin(ctx, db, transaction.Options{})
+	require.NoError(t, err)
+	nestedCtx, nested, err := transaction.Begin(txCtx, db, transaction.Options{})
+	require.NoError(t, err)
+	deepCtx, deep, err := transaction.Begin(nestedCtx, db, transaction.Options{})
+	require.NoError(t, err)
+	require.NoError(t, deep.Rollback(deepCtx))
+	// SQL Server does not release savepoints.
+	require.NoError(t, nested.Commit(nestedCtx))
+	require.NoError(t, tx.Commit(txCtx))
+}
+
+func TestNestingNotSupported(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newDB(t, "snowflake")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txCtx, tx, err := transaction.Begin(ctx, db, transaction.Options{})
+	require.NoError(t, err)
+	_, _, err = transaction.Begin(txCtx, db, transaction.Options{})
+	assert.ErrorIs(t, err, transaction.ErrNestingNotSupported)
+	assert.EqualError(t, err, `database does not support nested transactions: driver "snowflake"`)
+	require.NoError(t, tx.Rollback(txCtx))
+}
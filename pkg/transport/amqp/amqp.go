@@ -0,0 +1,354 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+var (
+	ErrConnectionClosed = errors.New("AMQP connection closed")
+	ErrChannelClosed    = errors.New("AMQP channel closed")
+)
+
+// DefaultPrefetch is the prefetch count used when the configuration does not
+// set one.
+const DefaultPrefetch = 10
+
+// Connection is the subset of an AMQP connection used by the transport.
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+// Channel is the subset of an AMQP channel used by the transport.
+type Channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+// Dialer opens a connection to an AMQP broker.
+type Dialer func(url string, config amqp.Config) (Connection, error)
+
+type AMQP struct {
+	log            logr.Logger
+	ctx            context.Context
+	cancel         context.CancelFunc
+	dial           Dialer
+	url            string
+	config         amqp.Config
+	prefetch       int
+	reconnectDelay time.Duration
+	invoker        transport.Invoker
+	errorResolver  errorz.Resolver
+	consumers      []*consumer
+}
+
+type consumer struct {
+	config *Consumer
+	codec  codec.Codec
+}
+
+func AMQPV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := AMQPV1Config{
+		Prefetch: DefaultPrefetch,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var errorResolver errorz.Resolver
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"errors:resolver", &errorResolver,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	return New(log, transportInvoker, codecs, errorResolver, &c, Dial)
+}
+
+// Dial connects to an AMQP broker using amqp091-go.
+func Dial(url string, config amqp.Config) (Connection, error) {
+	conn, err := amqp.DialConfig(url, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return connection{conn}, nil
+}
+
+type connection struct {
+	*amqp.Connection
+}
+
+func (c connection) Channel() (Channel, error) {
+	return c.Connection.Channel()
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, errorResolver errorz.Resolver, c *AMQPV1Config, dial Dialer) (*AMQP, error) {
+	consumers := make([]*consumer, len(c.Consumers))
+	for i := range c.Consumers {
+		cons := &c.Consumers[i]
+		codec, ok := codecs[string(cons.Codec)]
+		if !ok {
+			return nil, fmt.Errorf("could not find codec %q", cons.Codec)
+		}
+		consumers[i] = &consumer{
+			config: cons,
+			codec:  codec,
+		}
+	}
+
+	var cfg amqp.Config
+	if c.Heartbeat != nil {
+		cfg.Heartbeat = *c.Heartbeat
+	}
+	// Deliveries are handled concurrently up to the prefetch count, so it
+	// must be finite.
+	prefetch := int(c.Prefetch)
+	if prefetch == 0 {
+		prefetch = DefaultPrefetch
+	}
+	reconnectDelay := 5 * time.Second
+	if c.ReconnectDelay != nil {
+		reconnectDelay = *c.ReconnectDelay
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AMQP{
+		log:            log,
+		ctx:            ctx,
+		cancel:         cancel,
+		dial:           dial,
+		url:            c.URL,
+		config:         cfg,
+		prefetch:       prefetch,
+		reconnectDelay: reconnectDelay,
+		invoker:        invoker,
+		errorResolver:  errorResolver,
+		consumers:      consumers,
+	}, nil
+}
+
+func (t *AMQP) Listen() error {
+	for {
+		err := t.consume()
+		if t.ctx.Err() != nil {
+			return nil
+		}
+
+		t.log.Error(err, "AMQP consumer stopped, reconnecting", "delay", t.reconnectDelay)
+		select {
+		case <-time.After(t.reconnectDelay):
+		case <-t.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (t *AMQP) Close() error {
+	t.cancel()
+	return nil
+}
+
+// consume connects to the broker and dispatches deliveries until the
+// connection or channel is closed or the transport is shut down.
+func (t *AMQP) consume() error {
+	conn, err := t.dial(t.url, t.config)
+	if err != nil {
+		return err
+	}
+	// Closing the connection ends the delivery channels, so it must happen
+	// before waiting for the consumers to finish.
+	var wg sync.WaitGroup
+	defer func() {
+		conn.Close()
+		wg.Wait()
+	}()
+
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	// The broker closes the channel on errors such as a missing queue or an
+	// invalid acknowledgement while the connection stays open.
+	channelClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	if err := ch.Qos(t.prefetch, 0, false); err != nil {
+		return err
+	}
+
+	for _, c := range t.consumers {
+		tag := ""
+		if c.config.ConsumerTag != nil {
+			tag = *c.config.ConsumerTag
+		}
+		deliveries, err := ch.Consume(c.config.Queue, tag, false, c.config.Exclusive, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("could not consume from queue %q: %w", c.config.Queue, err)
+		}
+		t.log.Info("Consuming", "queue", c.config.Queue)
+
+		wg.Add(1)
+		go func(c *consumer) {
+			defer wg.Done()
+			// The broker limits in-flight deliveries to the prefetch count.
+			// The semaphore enforces the same limit should it not.
+			sem := make(chan struct{}, t.prefetch)
+			for d := range deliveries {
+				sem <- struct{}{}
+				wg.Add(1)
+				go func(d amqp.Delivery) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					t.handle(ch, c, d)
+				}(d)
+			}
+		}(c)
+	}
+
+	select {
+	case <-t.ctx.Done():
+		return nil
+	case err, ok := <-closed:
+		if !ok || err == nil {
+			return ErrConnectionClosed
+		}
+		return err
+	case err, ok := <-channelClosed:
+		if !ok || err == nil {
+			return ErrChannelClosed
+		}
+		return err
+	}
+}
+
+func (t *AMQP) handle(ch Channel, c *consumer, d amqp.Delivery) {
+	input, eventType, err := c.codec.Decode(d.Body, c.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode AMQP message", "queue", c.config.Queue)
+		t.nack(d, false)
+		return
+	}
+
+	if d.Type != "" {
+		eventType = d.Type
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if c.config.Handler != nil {
+		h = *c.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && c.config.Types != nil {
+		if handler, ok := c.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for AMQP message", "queue", c.config.Queue, "type", eventType)
+		t.nack(d, false)
+		return
+	}
+
+	output, err := t.invoker(t.ctx, h, "", input, transport.BypassAuthorization)
+	if err != nil {
+		t.log.Error(err, "error handling AMQP message", "queue", c.config.Queue, "handler", h.String())
+		// Only reply with the error once the message will not be redelivered.
+		if !c.config.Requeue {
+			t.replyError(ch, c, d, err)
+		}
+		t.nack(d, c.config.Requeue)
+		return
+	}
+
+	if d.ReplyTo != "" {
+		payload, err := c.codec.Encode(output, c.config.CodecArgs...)
+		if err != nil {
+			t.log.Error(err, "could not encode AMQP reply", "queue", c.config.Queue)
+			t.replyError(ch, c, d, err)
+		} else {
+			t.reply(ch, c, d, payload, 200)
+		}
+	}
+
+	if err := d.Ack(false); err != nil {
+		t.log.Error(err, "could not acknowledge AMQP message", "queue", c.config.Queue)
+	}
+}
+
+func (t *AMQP) nack(d amqp.Delivery, requeue bool) {
+	if err := d.Nack(false, requeue); err != nil {
+		t.log.Error(err, "could not negatively acknowledge AMQP message")
+	}
+}
+
+func (t *AMQP) replyError(ch Channel, c *consumer, d amqp.Delivery, err error) {
+	if d.ReplyTo == "" {
+		return
+	}
+
+	errz := t.errorResolver(err)
+	errz.Path = c.config.Queue
+
+	payload, err := c.codec.Encode(errz, c.config.CodecArgs...)
+	if err != nil {
+		payload = []byte(errz.Message)
+	}
+
+	t.reply(ch, c, d, payload, errz.Status)
+}
+
+func (t *AMQP) reply(ch Channel, c *consumer, d amqp.Delivery, payload []byte, status int) {
+	if err := ch.PublishWithContext(t.ctx, "", d.ReplyTo, false, false, amqp.Publishing{
+		Headers: amqp.Table{
+			"status": int32(status),
+		},
+		ContentType:   c.codec.ContentType(),
+		CorrelationId: d.CorrelationId,
+		Timestamp:     time.Now(),
+		Body:          payload,
+	}); err != nil {
+		t.log.Error(err, "could not publish AMQP reply", "replyTo", d.ReplyTo)
+	}
+}
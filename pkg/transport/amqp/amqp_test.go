@@ -0,0 +1,350 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package amqp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	transport_amqp "github.com/nanobus/nanobus/pkg/transport/amqp"
+)
+
+type ack struct {
+	tag     uint64
+	ack     bool
+	requeue bool
+}
+
+type mockAcknowledger struct {
+	acks chan ack
+}
+
+func (m *mockAcknowledger) Ack(tag uint64, multiple bool) error {
+	m.acks <- ack{tag: tag, ack: true}
+	return nil
+}
+
+func (m *mockAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	m.acks <- ack{tag: tag, requeue: requeue}
+	return nil
+}
+
+func (m *mockAcknowledger) Reject(tag uint64, requeue bool) error {
+	return m.Nack(tag, false, requeue)
+}
+
+type publishing struct {
+	key string
+	msg amqp.Publishing
+}
+
+type mockChannel struct {
+	mu         sync.Mutex
+	deliveries map[string]chan amqp.Delivery
+	published  chan publishing
+	prefetch   int
+	notifies   []chan *amqp.Error
+}
+
+func (m *mockChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prefetch = prefetchCount
+	return nil
+}
+
+func (m *mockChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch, ok := m.deliveries[queue]
+	if !ok {
+		return nil, errors.New("queue not found")
+	}
+	return ch, nil
+}
+
+func (m *mockChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	m.published <- publishing{key: key, msg: msg}
+	return nil
+}
+
+func (m *mockChannel) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifies = append(m.notifies, receiver)
+	return receiver
+}
+
+// closeWith notifies the consumer that the broker closed the channel.
+func (m *mockChannel) closeWith(err *amqp.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, n := range m.notifies {
+		n <- err
+	}
+	m.notifies = nil
+}
+
+func (m *mockChannel) Close() error { return nil }
+
+type mockConnection struct {
+	ch       *mockChannel
+	once     sync.Once
+	notifies []chan *amqp.Error
+}
+
+func (m *mockConnection) Channel() (transport_amqp.Channel, error) {
+	return m.ch, nil
+}
+
+func (m *mockConnection) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	m.notifies = append(m.notifies, receiver)
+	return receiver
+}
+
+func (m *mockConnection) Close() error {
+	m.once.Do(func() {
+		for _, d := range m.ch.deliveries {
+			close(d)
+		}
+	})
+	return nil
+}
+
+type mockInvoker struct {
+	mu     sync.Mutex
+	inputs map[handler.Handler]interface{}
+	output interface{}
+	err    error
+}
+
+func (m *mockInvoker) Invoke(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs[h] = input
+	return m.output, m.err
+}
+
+func nextAck(t *testing.T, acks chan ack) ack {
+	select {
+	case a := <-acks:
+		return a
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for acknowledgement")
+	}
+	return ack{}
+}
+
+func setup(t *testing.T, mi *mockInvoker, consumers ...transport_amqp.Consumer) (*mockChannel, *transport_amqp.AMQP) {
+	ch, _, tr := setupWithDials(t, mi, 5, consumers...)
+	return ch, tr
+}
+
+// setupWithDials returns a transport that connects to a new mock connection
+// each time it dials and sends the connections on the returned channel.
+func setupWithDials(t *testing.T, mi *mockInvoker, prefetch uint16, consumers ...transport_amqp.Consumer) (*mockChannel, chan *mockConnection, *transport_amqp.AMQP) {
+	ch := &mockChannel{
+		published: make(chan publishing, 10),
+	}
+	dials := make(chan *mockConnection, 10)
+	dial := func(url string, config amqp.Config) (transport_amqp.Connection, error) {
+		ch.mu.Lock()
+		ch.deliveries = map[string]chan amqp.Delivery{}
+		for _, c := range consumers {
+			ch.deliveries[c.Queue] = make(chan amqp.Delivery, 10)
+		}
+		ch.mu.Unlock()
+		conn := &mockConnection{ch: ch}
+		dials <- conn
+		return conn, nil
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	errorResolver := func(err error) *errorz.Error {
+		return errorz.From(err)
+	}
+
+	reconnectDelay := 10 * time.Millisecond
+	tr, err := transport_amqp.New(logr.Discard(), mi.Invoke, codecs, errorResolver, &transport_amqp.AMQPV1Config{
+		URL:            "amqp://localhost",
+		Prefetch:       prefetch,
+		ReconnectDelay: &reconnectDelay,
+		Consumers:      consumers,
+	}, dial)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tr.Listen())
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, tr.Close())
+		wg.Wait()
+	})
+
+	// Wait for the deliveries of the first connection.
+	<-dials
+	return ch, dials, tr
+}
+
+func TestAMQP(t *testing.T) {
+	handlerDefault := handler.Handler{
+		Interface: "Orders",
+		Operation: "onEvent",
+	}
+	handlerCreated := handler.Handler{
+		Interface: "Orders",
+		Operation: "onCreated",
+	}
+	mi := &mockInvoker{
+		inputs: map[handler.Handler]interface{}{},
+		output: map[string]interface{}{"ok": true},
+	}
+	ch, _ := setup(t, mi, transport_amqp.Consumer{
+		Queue:   "orders",
+		Codec:   transport_amqp.CodecRef("json"),
+		Handler: &handlerDefault,
+		Types: map[string]handler.Handler{
+			"order.created": handlerCreated,
+		},
+	})
+	acks := &mockAcknowledger{acks: make(chan ack, 10)}
+
+	ch.deliveries["orders"] <- amqp.Delivery{
+		Acknowledger: acks,
+		DeliveryTag:  1,
+		Body:         []byte(`{"id":"1"}`),
+	}
+	assert.Equal(t, ack{tag: 1, ack: true}, nextAck(t, acks.acks))
+
+	ch.deliveries["orders"] <- amqp.Delivery{
+		Acknowledger:  acks,
+		DeliveryTag:   2,
+		Type:          "order.created",
+		ReplyTo:       "replies",
+		CorrelationId: "abc",
+		Body:          []byte(`{"id":"2"}`),
+	}
+	assert.Equal(t, ack{tag: 2, ack: true}, nextAck(t, acks.acks))
+
+	mi.mu.Lock()
+	assert.Equal(t, map[string]interface{}{"id": "1"}, mi.inputs[handlerDefault])
+	assert.Equal(t, map[string]interface{}{"id": "2"}, mi.inputs[handlerCreated])
+	mi.mu.Unlock()
+
+	reply := <-ch.published
+	assert.Equal(t, "replies", reply.key)
+	assert.Equal(t, "abc", reply.msg.CorrelationId)
+	assert.Equal(t, int32(200), reply.msg.Headers["status"])
+	assert.Equal(t, `{"ok":true}`, string(reply.msg.Body))
+	assert.Equal(t, 5, ch.prefetch)
+}
+
+func TestAMQPError(t *testing.T) {
+	h := handler.Handler{
+		Interface: "Orders",
+		Operation: "onEvent",
+	}
+
+	tests := []struct {
+		name    string
+		requeue bool
+		body    string
+		replies bool
+	}{
+		{name: "reject", requeue: false, body: `{}`, replies: true},
+		{name: "requeue", requeue: true, body: `{}`, replies: false},
+		{name: "decode error", requeue: true, body: `{`, replies: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := &mockInvoker{
+				inputs: map[handler.Handler]interface{}{},
+				err:    errors.New("boom"),
+			}
+			ch, _ := setup(t, mi, transport_amqp.Consumer{
+				Queue:   "orders",
+				Codec:   transport_amqp.CodecRef("json"),
+				Handler: &h,
+				Requeue: tt.requeue,
+			})
+			acks := &mockAcknowledger{acks: make(chan ack, 10)}
+
+			ch.deliveries["orders"] <- amqp.Delivery{
+				Acknowledger: acks,
+				DeliveryTag:  1,
+				ReplyTo:      "replies",
+				Body:         []byte(tt.body),
+			}
+			a := nextAck(t, acks.acks)
+			assert.False(t, a.ack)
+			// Messages that cannot be decoded are never requeued.
+			assert.Equal(t, tt.requeue && tt.body == `{}`, a.requeue)
+
+			if tt.replies {
+				reply := <-ch.published
+				assert.Equal(t, int32(500), reply.msg.Headers["status"])
+			} else {
+				assert.Len(t, ch.published, 0)
+			}
+		})
+	}
+}
+
+func TestAMQPReconnectsWhenChannelCloses(t *testing.T) {
+	h := handler.Handler{
+		Interface: "Orders",
+		Operation: "onEvent",
+	}
+	mi := &mockInvoker{
+		inputs: map[handler.Handler]interface{}{},
+	}
+	ch, dials, _ := setupWithDials(t, mi, 0, transport_amqp.Consumer{
+		Queue:   "orders",
+		Codec:   transport_amqp.CodecRef("json"),
+		Handler: &h,
+	})
+
+	// Zero falls back to the default so that concurrency is bounded.
+	ch.mu.Lock()
+	assert.Equal(t, transport_amqp.DefaultPrefetch, ch.prefetch)
+	ch.mu.Unlock()
+
+	ch.closeWith(&amqp.Error{Code: amqp.PreconditionFailed, Reason: "PRECONDITION_FAILED"})
+	select {
+	case <-dials:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for reconnection")
+	}
+
+	acks := &mockAcknowledger{acks: make(chan ack, 10)}
+	ch.deliveries["orders"] <- amqp.Delivery{
+		Acknowledger: acks,
+		DeliveryTag:  1,
+		Body:         []byte(`{"id":"1"}`),
+	}
+	assert.Equal(t, ack{tag: 1, ack: true}, nextAck(t, acks.acks))
+}
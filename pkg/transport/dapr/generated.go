@@ -3,6 +3,8 @@
 package dapr
 
 import (
+	"time"
+
 	"github.com/nanobus/nanobus/pkg/handler"
 	"github.com/nanobus/nanobus/pkg/transport"
 )
@@ -37,6 +39,35 @@ type Subscription struct {
 	Handler                *handler.Handler           `json:"handler,omitempty" yaml:"handler,omitempty" msgpack:"handler,omitempty" mapstructure:"handler"`
 	Codec                  CodecRef                   `json:"codec" yaml:"codec" msgpack:"codec" mapstructure:"codec"`
 	DisableTopicValidation bool                       `json:"disableTopicValidation" yaml:"disableTopicValidation" msgpack:"disableTopicValidation" mapstructure:"disableTopicValidation"`
+	// CEL routing rules evaluated in order against `event`, `data` and `metadata`.
+	// The first matching rule selects the handler.
+	Routes []Route `json:"routes,omitempty" yaml:"routes,omitempty" msgpack:"routes,omitempty" mapstructure:"routes" validate:"dive"`
+	// Events that are dropped or exhaust their retries are published to this topic
+	// by Dapr.
+	DeadLetterTopic *string `json:"deadLetterTopic,omitempty" yaml:"deadLetterTopic,omitempty" msgpack:"deadLetterTopic,omitempty" mapstructure:"deadLetterTopic"`
+	// Receives events from Dapr in batches.
+	Bulk *BulkSubscribe `json:"bulk,omitempty" yaml:"bulk,omitempty" msgpack:"bulk,omitempty" mapstructure:"bulk"`
+	// Error codes that are redelivered by Dapr. Events failing with other codes
+	// are dropped. Defaults to `unknown`, `deadline_exceeded`,
+	// `resource_exhausted`, `aborted`, `internal` and `unavailable`.
+	RetryCodes []string `json:"retryCodes,omitempty" yaml:"retryCodes,omitempty" msgpack:"retryCodes,omitempty" mapstructure:"retryCodes" validate:"dive"`
+}
+
+// A CEL routing rule.
+type Route struct {
+	// The CEL expression to match (e.g. `event.type == 'order.created' &&
+	// data.total > 100`).
+	Match string `json:"match" yaml:"match" msgpack:"match" mapstructure:"match" validate:"required"`
+	// The handler of matching events.
+	Handler handler.Handler `json:"handler" yaml:"handler" msgpack:"handler" mapstructure:"handler" validate:"required"`
+}
+
+// Bulk subscription settings.
+type BulkSubscribe struct {
+	// The maximum number of events in a batch.
+	MaxMessages uint32 `json:"maxMessages" yaml:"maxMessages" msgpack:"maxMessages" mapstructure:"maxMessages"`
+	// The maximum time to wait for a batch to fill up.
+	MaxAwaitDuration time.Duration `json:"maxAwaitDuration" yaml:"maxAwaitDuration" msgpack:"maxAwaitDuration" mapstructure:"maxAwaitDuration"`
 }
 
 type Binding struct {
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package dapr
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/nanobus/nanobus/pkg/handler"
+)
+
+type route struct {
+	program cel.Program
+	handler handler.Handler
+}
+
+// compileRoutes compiles the CEL expressions of routing rules. Rules can
+// reference the CloudEvent attributes as `event`, the decoded event as `data`
+// and the event metadata as `metadata`.
+func compileRoutes(routes []Route) ([]route, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("event", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("data", cel.DynType),
+		cel.Variable("metadata", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]route, len(routes))
+	for i, r := range routes {
+		ast, iss := env.Compile(r.Match)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("could not compile %q: %w", r.Match, iss.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("%q does not return a bool", r.Match)
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = route{
+			program: program,
+			handler: r.Handler,
+		}
+	}
+
+	return compiled, nil
+}
+
+// matches returns true if the rule evaluates to true. Evaluation errors, such
+// as referencing a missing field, do not match.
+func (r *route) matches(variables map[string]interface{}) bool {
+	out, _, err := r.program.Eval(variables)
+	if err != nil {
+		return false
+	}
+	matched, ok := out.Value().(bool)
+	return ok && matched
+}
@@ -10,25 +10,72 @@ package dapr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/dapr/go-sdk/service/common"
-	daprd "github.com/dapr/go-sdk/service/grpc"
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
 	"github.com/nanobus/nanobus/pkg/codec"
 	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/errorz"
 	"github.com/nanobus/nanobus/pkg/handler"
 	"github.com/nanobus/nanobus/pkg/resolve"
 	"github.com/nanobus/nanobus/pkg/transport"
 )
 
+// Server implements the Dapr app callback services so that Dapr delivers
+// events and binding invocations to the configured handlers.
 type Server struct {
-	log     logr.Logger
-	address string
-	server  common.Service
-	invoker transport.Invoker
-	codecs  codec.Codecs
+	pb.UnimplementedAppCallbackServer
+	pb.UnimplementedAppCallbackAlphaServer
+	pb.UnimplementedAppCallbackHealthCheckServer
+
+	log           logr.Logger
+	address       string
+	invoker       transport.Invoker
+	codecs        codec.Codecs
+	subscriptions []*subscription
+	topics        map[string]*subscription
+	bindings      map[string]*binding
+
+	mu     sync.Mutex
+	server *grpc.Server
+	closed bool
+}
+
+type subscription struct {
+	config     *Subscription
+	codec      codec.Codec
+	routes     []route
+	retryCodes map[errorz.ErrCode]struct{}
+}
+
+type binding struct {
+	config *Binding
+	codec  codec.Codec
+}
+
+// defaultRetryCodes are the error codes of failures that are expected to be
+// transient.
+var defaultRetryCodes = []string{
+	"unknown",
+	"deadline_exceeded",
+	"resource_exhausted",
+	"aborted",
+	"internal",
+	"unavailable",
 }
 
 func DaprServerV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
@@ -50,31 +97,29 @@ func DaprServerV1Loader(ctx context.Context, with interface{}, resolver resolve.
 		return nil, err
 	}
 
-	server, err := daprd.NewService(c.Address)
-	if err != nil {
-		return nil, err
-	}
-
-	return NewServer(logger, server, transportInvoker, codecs, &c)
+	return NewServer(logger, transportInvoker, codecs, &c)
 }
 
-func NewServer(logger logr.Logger, server common.Service, transportInvoker transport.Invoker, codecs codec.Codecs, config *DaprServerV1Config) (*Server, error) {
+func NewServer(logger logr.Logger, transportInvoker transport.Invoker, codecs codec.Codecs, config *DaprServerV1Config) (*Server, error) {
 	s := Server{
-		log:     logger,
-		address: config.Address,
-		server:  server,
-		invoker: transportInvoker,
-		codecs:  codecs,
+		log:      logger,
+		address:  config.Address,
+		invoker:  transportInvoker,
+		codecs:   codecs,
+		topics:   make(map[string]*subscription, len(config.Subscriptions)),
+		bindings: make(map[string]*binding, len(config.Bindings)),
 	}
 
-	for _, subscription := range config.Subscriptions {
+	for i := range config.Subscriptions {
+		subscription := &config.Subscriptions[i]
 		logger.Info("Adding subscription", "subscription", subscription)
 		if err := s.addSubscription(subscription); err != nil {
 			return nil, err
 		}
 	}
 
-	for _, binding := range config.Bindings {
+	for i := range config.Bindings {
+		binding := &config.Bindings[i]
 		logger.Info("Adding binding", "binding", binding)
 		if err := s.addBinding(binding); err != nil {
 			return nil, err
@@ -84,86 +129,391 @@ func NewServer(logger logr.Logger, server common.Service, transportInvoker trans
 	return &s, nil
 }
 
-func (s *Server) addSubscription(sub Subscription) error {
+func (s *Server) addSubscription(sub *Subscription) error {
 	codec, ok := s.codecs[string(sub.Codec)]
 	if !ok {
 		return fmt.Errorf("could not find codec %q", sub.Codec)
 	}
 
-	metadata := make(map[string]string, len(sub.Metadata)+1)
-	for name, value := range sub.Metadata {
-		metadata[name] = value
+	routes, err := compileRoutes(sub.Routes)
+	if err != nil {
+		return fmt.Errorf("invalid route for topic %q: %w", sub.Topic, err)
+	}
+
+	retryCodes := sub.RetryCodes
+	if retryCodes == nil {
+		retryCodes = defaultRetryCodes
+	}
+	codes := make(map[errorz.ErrCode]struct{}, len(retryCodes))
+	for _, name := range retryCodes {
+		var code errorz.ErrCode
+		if err := code.Parse(name); err != nil {
+			return err
+		}
+		codes[code] = struct{}{}
 	}
-	metadata["rawPayload"] = "true"
 
-	return s.server.AddTopicEventHandler(&common.Subscription{
-		PubsubName:             sub.Pubsub,
-		Topic:                  sub.Topic,
-		Metadata:               metadata,
-		DisableTopicValidation: sub.DisableTopicValidation,
-	}, func(ctx context.Context, e *common.TopicEvent) (retry bool, err error) {
-		input, eventType, err := codec.Decode(e.RawData)
-		if err != nil {
-			return false, err
+	if sub.Bulk != nil {
+		if sub.Bulk.MaxMessages == 0 {
+			sub.Bulk.MaxMessages = 100
+		}
+		if sub.Bulk.MaxAwaitDuration == 0 {
+			sub.Bulk.MaxAwaitDuration = time.Second
 		}
+	}
 
-		var h handler.Handler
-		var handlerFound bool
+	key := topicKey(sub.Pubsub, sub.Topic)
+	if sub.DisableTopicValidation {
+		key = sub.Pubsub
+	}
+	if _, exists := s.topics[key]; exists {
+		return fmt.Errorf("topic %q of pubsub %q is already subscribed", sub.Topic, sub.Pubsub)
+	}
+
+	subscription := &subscription{
+		config:     sub,
+		codec:      codec,
+		routes:     routes,
+		retryCodes: codes,
+	}
+	s.subscriptions = append(s.subscriptions, subscription)
+	s.topics[key] = subscription
+
+	return nil
+}
 
-		// Default handler
-		if sub.Handler != nil {
-			h = *sub.Handler
-			handlerFound = true
+func (s *Server) addBinding(b *Binding) error {
+	codec, ok := s.codecs[string(b.Codec)]
+	if !ok {
+		return fmt.Errorf("could not find codec %q", b.Codec)
+	}
+
+	s.bindings[b.Name] = &binding{
+		config: b,
+		codec:  codec,
+	}
+
+	return nil
+}
+
+func (s *Server) Listen() error {
+	lis, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Dapr server listening", "address", s.address)
+	return s.Serve(lis)
+}
+
+// Serve accepts Dapr's connections on lis until the server is closed.
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return lis.Close()
+	}
+	s.server = grpc.NewServer()
+	pb.RegisterAppCallbackServer(s.server, s)
+	pb.RegisterAppCallbackAlphaServer(s.server, s)
+	pb.RegisterAppCallbackHealthCheckServer(s.server, s)
+	server := s.server
+	s.mu.Unlock()
+
+	return server.Serve(lis)
+}
+
+func (s *Server) Close() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.server != nil {
+		s.server.GracefulStop()
+	}
+
+	return nil
+}
+
+// ListTopicSubscriptions is called by Dapr to get the topics to deliver.
+func (s *Server) ListTopicSubscriptions(ctx context.Context, _ *emptypb.Empty) (*pb.ListTopicSubscriptionsResponse, error) {
+	subscriptions := make([]*pb.TopicSubscription, len(s.subscriptions))
+	for i, sub := range s.subscriptions {
+		metadata := make(map[string]string, len(sub.config.Metadata)+1)
+		for name, value := range sub.config.Metadata {
+			metadata[name] = value
 		}
+		metadata["rawPayload"] = "true"
 
-		// Handler for event type
-		if eventType != "" && sub.Types != nil {
-			if handler, ok := sub.Types[eventType]; ok {
-				h = handler
-				handlerFound = true
+		ts := pb.TopicSubscription{
+			PubsubName: sub.config.Pubsub,
+			Topic:      sub.config.Topic,
+			Metadata:   metadata,
+		}
+		if sub.config.DeadLetterTopic != nil {
+			ts.DeadLetterTopic = *sub.config.DeadLetterTopic
+		}
+		if bulk := sub.config.Bulk; bulk != nil {
+			ts.BulkSubscribe = &pb.BulkSubscribeConfig{
+				Enabled:            true,
+				MaxMessagesCount:   int32(bulk.MaxMessages),
+				MaxAwaitDurationMs: int32(bulk.MaxAwaitDuration / time.Millisecond),
 			}
 		}
+		subscriptions[i] = &ts
+	}
+
+	return &pb.ListTopicSubscriptionsResponse{
+		Subscriptions: subscriptions,
+	}, nil
+}
+
+// OnTopicEvent is called by Dapr for each event of a subscribed topic.
+func (s *Server) OnTopicEvent(ctx context.Context, in *pb.TopicEventRequest) (*pb.TopicEventResponse, error) {
+	sub, ok := s.subscription(in.PubsubName, in.Topic)
+	if !ok {
+		return &pb.TopicEventResponse{Status: pb.TopicEventResponse_RETRY},
+			fmt.Errorf("topic %q of pubsub %q is not subscribed", in.Topic, in.PubsubName)
+	}
+
+	event := cloudEvent(in.Id, in.Source, in.Type, in.SpecVersion, in.DataContentType, in.Extensions)
+	event["topic"] = in.Topic
+	event["pubsubname"] = in.PubsubName
+
+	status := s.handleEvent(ctx, sub, in.Data, event, incomingMetadata(ctx))
+
+	return &pb.TopicEventResponse{Status: status}, nil
+}
+
+// OnBulkTopicEventAlpha1 is called by Dapr with a batch of events of a topic
+// subscribed with `bulk`. Each event is acknowledged with its own status.
+func (s *Server) OnBulkTopicEventAlpha1(ctx context.Context, in *pb.TopicEventBulkRequest) (*pb.TopicEventBulkResponse, error) {
+	sub, ok := s.subscription(in.PubsubName, in.Topic)
+	if !ok {
+		return nil, fmt.Errorf("topic %q of pubsub %q is not subscribed", in.Topic, in.PubsubName)
+	}
+
+	requestMetadata := incomingMetadata(ctx)
+	for name, value := range in.Metadata {
+		requestMetadata[name] = value
+	}
+
+	statuses := make([]*pb.TopicEventBulkResponseEntry, len(in.Entries))
+	for i, entry := range in.Entries {
+		metadata := make(map[string]string, len(requestMetadata)+len(entry.Metadata))
+		for name, value := range requestMetadata {
+			metadata[name] = value
+		}
+		for name, value := range entry.Metadata {
+			metadata[name] = value
+		}
 
-		if handlerFound {
-			_, err = s.invoker(ctx, h, "", input, transport.BypassAuthorization)
+		var data []byte
+		var event map[string]interface{}
+		switch e := entry.Event.(type) {
+		case *pb.TopicEventBulkRequestEntry_CloudEvent:
+			data = e.CloudEvent.Data
+			event = cloudEvent(e.CloudEvent.Id, e.CloudEvent.Source, e.CloudEvent.Type,
+				e.CloudEvent.SpecVersion, e.CloudEvent.DataContentType, e.CloudEvent.Extensions)
+		case *pb.TopicEventBulkRequestEntry_Bytes:
+			data = e.Bytes
+			event = cloudEvent(entry.EntryId, "", in.Type, "", entry.ContentType, nil)
+		default:
+			event = cloudEvent(entry.EntryId, "", in.Type, "", entry.ContentType, nil)
 		}
+		event["topic"] = in.Topic
+		event["pubsubname"] = in.PubsubName
 
-		return false, err
-	})
+		statuses[i] = &pb.TopicEventBulkResponseEntry{
+			EntryId: entry.EntryId,
+			Status:  s.handleEvent(ctx, sub, data, event, metadata),
+		}
+	}
+
+	return &pb.TopicEventBulkResponse{
+		Statuses: statuses,
+	}, nil
 }
 
-func (s *Server) addBinding(binding Binding) error {
-	codec, ok := s.codecs[string(binding.Codec)]
+// handleEvent invokes the handler of an event and returns whether Dapr should
+// acknowledge, redeliver or drop it.
+func (s *Server) handleEvent(
+	ctx context.Context,
+	sub *subscription,
+	data []byte,
+	event map[string]interface{},
+	metadata map[string]string) pb.TopicEventResponse_TopicEventResponseStatus {
+	input, eventType, err := sub.codec.Decode(data)
+	if err != nil {
+		s.log.Error(err, "could not decode Dapr event", "pubsub", sub.config.Pubsub, "topic", sub.config.Topic)
+		return pb.TopicEventResponse_DROP
+	}
+
+	h, ok := sub.handler(input, eventType, event, metadata)
 	if !ok {
-		return fmt.Errorf("could not find codec %q", binding.Codec)
+		s.log.Info("No handler found for Dapr event", "pubsub", sub.config.Pubsub, "topic", sub.config.Topic, "type", eventType)
+		return pb.TopicEventResponse_SUCCESS
 	}
 
-	return s.server.AddBindingInvocationHandler(binding.Name, func(ctx context.Context, in *common.BindingEvent) (out []byte, err error) {
-		input, _, err := codec.Decode(in.Data)
-		if err != nil {
-			return nil, err
+	// Continue the trace of the publisher, if any.
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(metadata))
+	ctx = transport.WithVariables(ctx, transport.Variables{
+		"event":    event,
+		"metadata": metadata,
+	})
+
+	if _, err = s.invoker(ctx, h, "", input, transport.BypassAuthorization); err != nil {
+		s.log.Error(err, "error handling Dapr event", "pubsub", sub.config.Pubsub, "topic", sub.config.Topic, "handler", h.String())
+		if sub.retriable(err) {
+			return pb.TopicEventResponse_RETRY
 		}
+		return pb.TopicEventResponse_DROP
+	}
 
-		output, err := s.invoker(ctx, binding.Handler, "", input, transport.BypassAuthorization)
-		if err != nil {
-			return nil, err
+	return pb.TopicEventResponse_SUCCESS
+}
+
+// handler returns the handler selected by the first matching route, the
+// event type or the default handler, in that order.
+func (sub *subscription) handler(input interface{}, eventType string, event map[string]interface{}, metadata map[string]string) (handler.Handler, bool) {
+	if len(sub.routes) > 0 {
+		variables := map[string]interface{}{
+			"event":    event,
+			"data":     input,
+			"metadata": metadata,
 		}
+		for _, r := range sub.routes {
+			if r.matches(variables) {
+				return r.handler, true
+			}
+		}
+	}
 
-		if !isNil(output) {
-			out, err = codec.Encode(output)
+	if eventType != "" && sub.config.Types != nil {
+		if h, ok := sub.config.Types[eventType]; ok {
+			return h, true
 		}
+	}
 
-		return out, err
+	if sub.config.Handler != nil {
+		return *sub.config.Handler, true
+	}
+
+	return handler.Handler{}, false
+}
+
+// retriable returns true if the error code of err is one of the retry codes.
+// Errors without a code are considered unknown.
+func (sub *subscription) retriable(err error) bool {
+	code := errorz.Unknown
+	var errz *errorz.Error
+	if errors.As(err, &errz) {
+		code = errz.Code
+	}
+	_, ok := sub.retryCodes[code]
+	return ok
+}
+
+// OnBindingEvent is called by Dapr for each event of an input binding. The
+// binding metadata is available to the handler as `metadata`.
+func (s *Server) OnBindingEvent(ctx context.Context, in *pb.BindingEventRequest) (*pb.BindingEventResponse, error) {
+	b, ok := s.bindings[in.Name]
+	if !ok {
+		return nil, fmt.Errorf("binding %q is not configured", in.Name)
+	}
+
+	input, _, err := b.codec.Decode(in.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := in.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	ctx = transport.WithVariables(ctx, transport.Variables{
+		"metadata": metadata,
 	})
+
+	output, err := s.invoker(ctx, b.config.Handler, "", input, transport.BypassAuthorization)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.BindingEventResponse
+	if !isNil(output) {
+		if resp.Data, err = b.codec.Encode(output); err != nil {
+			return nil, err
+		}
+	}
+
+	return &resp, nil
 }
 
-func (s *Server) Listen() error {
-	s.log.Info("Dapr server listening", "address", s.address)
-	return s.server.Start()
+// ListInputBindings is called by Dapr to get the input bindings to deliver.
+func (s *Server) ListInputBindings(ctx context.Context, _ *emptypb.Empty) (*pb.ListInputBindingsResponse, error) {
+	names := make([]string, 0, len(s.bindings))
+	for name := range s.bindings {
+		names = append(names, name)
+	}
+
+	return &pb.ListInputBindingsResponse{
+		Bindings: names,
+	}, nil
 }
 
-func (s *Server) Close() (err error) {
-	return s.server.GracefulStop()
+func (s *Server) HealthCheck(ctx context.Context, _ *emptypb.Empty) (*pb.HealthCheckResponse, error) {
+	return &pb.HealthCheckResponse{}, nil
+}
+
+func (s *Server) subscription(pubsub, topic string) (*subscription, bool) {
+	sub, ok := s.topics[topicKey(pubsub, topic)]
+	if !ok {
+		// Subscriptions without topic validation receive all topics of the
+		// pubsub.
+		sub, ok = s.topics[pubsub]
+	}
+
+	return sub, ok
+}
+
+func topicKey(pubsub, topic string) string {
+	return pubsub + "||" + topic
+}
+
+// cloudEvent returns the CloudEvent attributes of an event, including its
+// extensions.
+func cloudEvent(id, source, eventType, specVersion, dataContentType string, extensions *structpb.Struct) map[string]interface{} {
+	event := make(map[string]interface{}, 7+len(extensions.GetFields()))
+	for name, value := range extensions.AsMap() {
+		event[name] = value
+	}
+	event["id"] = id
+	event["source"] = source
+	event["type"] = eventType
+	event["specversion"] = specVersion
+	event["datacontenttype"] = dataContentType
+
+	return event
+}
+
+// incomingMetadata returns the event metadata that Dapr sends as gRPC
+// metadata.
+func incomingMetadata(ctx context.Context) map[string]string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	result := make(map[string]string, len(md))
+	for name, values := range md {
+		if len(values) == 0 || strings.HasPrefix(name, ":") || strings.HasPrefix(name, "grpc-") {
+			continue
+		}
+		switch name {
+		case "content-type", "user-agent":
+			continue
+		}
+		result[name] = values[0]
+	}
+
+	return result
 }
 
 func isNil(val interface{}) bool {
@@ -3,56 +3,28 @@ package dapr_test
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"testing"
+	"time"
 
-	"github.com/dapr/go-sdk/service/common"
+	pb "github.com/dapr/go-sdk/dapr/proto/runtime/v1"
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/nanobus/nanobus/pkg/codec"
 	codec_json "github.com/nanobus/nanobus/pkg/codec/cloudevents/json"
+	"github.com/nanobus/nanobus/pkg/errorz"
 	"github.com/nanobus/nanobus/pkg/handler"
 	"github.com/nanobus/nanobus/pkg/transport"
 	"github.com/nanobus/nanobus/pkg/transport/dapr"
 )
 
-type mockService struct {
-	common.Service
-	topics   map[string]common.TopicEventHandler
-	bindings map[string]common.BindingInvocationHandler
-}
-
-func (m *mockService) AddTopicEventHandler(sub *common.Subscription, fn common.TopicEventHandler) error {
-	m.topics[sub.Topic] = fn
-	return nil
-}
-
-func (m *mockService) TriggerTopic(ctx context.Context, topic string, e *common.TopicEvent) {
-	if fn, ok := m.topics[topic]; ok {
-		if _, err := fn(ctx, e); err != nil {
-			panic(err)
-		}
-	}
-}
-
-func (m *mockService) AddBindingInvocationHandler(name string, fn common.BindingInvocationHandler) error {
-	m.bindings[name] = fn
-	return nil
-}
-
-func (m *mockService) TriggerBinding(ctx context.Context, name string, e *common.BindingEvent) {
-	if fn, ok := m.bindings[name]; ok {
-		if _, err := fn(ctx, e); err != nil {
-			panic(err)
-		}
-	}
-}
-
-func (m *mockService) Start() error        { return nil }
-func (m *mockService) Stop() error         { return nil }
-func (m *mockService) GracefulStop() error { return nil }
-
 type mockInvoker struct {
 	h      handler.Handler
 	input  any
@@ -76,10 +48,6 @@ func (m *mockInvoker) Invoke(ctx context.Context, h handler.Handler, id string,
 
 func TestServerCallbacks(t *testing.T) {
 	ctx := context.Background()
-	m := &mockService{
-		topics:   make(map[string]common.TopicEventHandler),
-		bindings: make(map[string]common.BindingInvocationHandler),
-	}
 	mi := &mockInvoker{
 		invocations: make(map[handler.Handler]int),
 	}
@@ -102,7 +70,7 @@ func TestServerCallbacks(t *testing.T) {
 		Operation: "test",
 	}
 
-	s, err := dapr.NewServer(logr.Discard(), m, mi.Invoke, codecs, &dapr.DaprServerV1Config{
+	s, err := dapr.NewServer(logr.Discard(), mi.Invoke, codecs, &dapr.DaprServerV1Config{
 		Subscriptions: []dapr.Subscription{
 			{
 				Pubsub: "test",
@@ -127,13 +95,18 @@ func TestServerCallbacks(t *testing.T) {
 	require.NoError(t, err)
 
 	sendTopic := func(data []byte) {
-		m.TriggerTopic(ctx, "test", &common.TopicEvent{
-			RawData: data,
+		resp, err := s.OnTopicEvent(ctx, &pb.TopicEventRequest{
+			PubsubName: "test",
+			Topic:      "test",
+			Data:       data,
 		})
+		require.NoError(t, err)
+		assert.Equal(t, pb.TopicEventResponse_SUCCESS, resp.Status)
 	}
 
 	sendBinding := func(data []byte) {
-		m.TriggerBinding(ctx, "test", &common.BindingEvent{Data: data})
+		_, err := s.OnBindingEvent(ctx, &pb.BindingEventRequest{Name: "test", Data: data})
+		require.NoError(t, err)
 	}
 
 	tests := []struct {
@@ -182,6 +155,290 @@ func TestServerCallbacks(t *testing.T) {
 	assert.Equal(t, 1, mi.invocations[handlerTopicV2])
 	assert.Equal(t, 1, mi.invocations[handlerBinding])
 
-	require.NoError(t, s.Listen())
 	require.NoError(t, s.Close())
 }
+
+type call struct {
+	h         handler.Handler
+	input     any
+	variables transport.Variables
+}
+
+// recordCalls returns an invoker that records its calls and returns the
+// error for the input's `fail` code, if any.
+func recordCalls(calls *[]call) transport.Invoker {
+	return func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		*calls = append(*calls, call{h, input, transport.VariablesFromContext(ctx)})
+		if m, ok := input.(map[string]any); ok {
+			if code, ok := m["fail"].(string); ok {
+				return nil, errorz.New(errorz.CodeLookup[code])
+			}
+		}
+		return nil, nil
+	}
+}
+
+func testCodecs() codec.Codecs {
+	return codec.Codecs{
+		"json": codec_json.NewCodec(&codec_json.Config{
+			SpecVersion: "1.0",
+		}),
+	}
+}
+
+func TestRetryCodes(t *testing.T) {
+	ctx := context.Background()
+	var calls []call
+	s, err := dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{
+				Pubsub:  "test",
+				Topic:   "default",
+				Codec:   "json",
+				Handler: &handler.Handler{Interface: "Events", Operation: "onDefault"},
+			},
+			{
+				Pubsub:     "test",
+				Topic:      "custom",
+				Codec:      "json",
+				Handler:    &handler.Handler{Interface: "Events", Operation: "onCustom"},
+				RetryCodes: []string{"not_found"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		topic  string
+		data   string
+		status pb.TopicEventResponse_TopicEventResponseStatus
+	}{
+		{"default", `{"id": "1"}`, pb.TopicEventResponse_SUCCESS},
+		{"default", `{"fail": "unavailable"}`, pb.TopicEventResponse_RETRY},
+		{"default", `{"fail": "internal"}`, pb.TopicEventResponse_RETRY},
+		{"default", `{"fail": "invalid_argument"}`, pb.TopicEventResponse_DROP},
+		{"default", `not json`, pb.TopicEventResponse_DROP},
+		{"custom", `{"fail": "not_found"}`, pb.TopicEventResponse_RETRY},
+		{"custom", `{"fail": "unavailable"}`, pb.TopicEventResponse_DROP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic+" "+tt.data, func(t *testing.T) {
+			resp, err := s.OnTopicEvent(ctx, &pb.TopicEventRequest{
+				PubsubName: "test",
+				Topic:      tt.topic,
+				Data:       []byte(tt.data),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.Status)
+		})
+	}
+
+	_, err = dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{Pubsub: "test", Topic: "test", Codec: "json", RetryCodes: []string{"sometimes"}},
+		},
+	})
+	assert.EqualError(t, err, `unknown error code "sometimes"`)
+}
+
+func TestRoutes(t *testing.T) {
+	ctx := context.Background()
+	var calls []call
+	large := handler.Handler{Interface: "Orders", Operation: "onLargeOrder"}
+	priority := handler.Handler{Interface: "Orders", Operation: "onPriorityOrder"}
+	fallback := handler.Handler{Interface: "Orders", Operation: "onOrder"}
+	s, err := dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{
+				Pubsub: "test",
+				Topic:  "orders",
+				Codec:  "json",
+				Routes: []dapr.Route{
+					{Match: `metadata.priority == "high"`, Handler: priority},
+					{Match: `data.total > 100`, Handler: large},
+				},
+				Handler: &fallback,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	send := func(ctx context.Context, data string) call {
+		calls = nil
+		resp, err := s.OnTopicEvent(ctx, &pb.TopicEventRequest{
+			Id:         "1234",
+			Source:     "checkout",
+			Type:       "order.created",
+			PubsubName: "test",
+			Topic:      "orders",
+			Data:       []byte(data),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, pb.TopicEventResponse_SUCCESS, resp.Status)
+		require.Len(t, calls, 1)
+		return calls[0]
+	}
+
+	c := send(ctx, `{"total": 250}`)
+	assert.Equal(t, large, c.h)
+	// Rules that cannot be evaluated, like a missing field, do not match.
+	assert.Equal(t, fallback, send(ctx, `{"items": 2}`).h)
+	assert.Equal(t, fallback, send(ctx, `{"total": 50}`).h)
+
+	// Event metadata is sent by Dapr as gRPC metadata.
+	mdCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("priority", "high"))
+	c = send(mdCtx, `{"total": 250}`)
+	assert.Equal(t, priority, c.h)
+	assert.Equal(t, transport.Variables{
+		"event": map[string]any{
+			"id":              "1234",
+			"source":          "checkout",
+			"type":            "order.created",
+			"specversion":     "",
+			"datacontenttype": "",
+			"topic":           "orders",
+			"pubsubname":      "test",
+		},
+		"metadata": map[string]string{"priority": "high"},
+	}, c.variables)
+
+	_, err = dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{Pubsub: "test", Topic: "test", Codec: "json", Routes: []dapr.Route{
+				{Match: `data.total`, Handler: large},
+			}},
+		},
+	})
+	assert.EqualError(t, err, `invalid route for topic "test": "data.total" does not return a bool`)
+}
+
+func TestBulkSubscribe(t *testing.T) {
+	ctx := context.Background()
+	var calls []call
+	h := handler.Handler{Interface: "Events", Operation: "onEvent"}
+	s, err := dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{
+				Pubsub:  "test",
+				Topic:   "events",
+				Codec:   "json",
+				Handler: &h,
+				Bulk:    &dapr.BulkSubscribe{},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := s.OnBulkTopicEventAlpha1(ctx, &pb.TopicEventBulkRequest{
+		PubsubName: "test",
+		Topic:      "events",
+		Metadata:   map[string]string{"batch": "1"},
+		Entries: []*pb.TopicEventBulkRequestEntry{
+			{
+				EntryId:  "a",
+				Event:    &pb.TopicEventBulkRequestEntry_Bytes{Bytes: []byte(`{"id": "a"}`)},
+				Metadata: map[string]string{"key": "a"},
+			},
+			{
+				EntryId: "b",
+				Event: &pb.TopicEventBulkRequestEntry_CloudEvent{CloudEvent: &pb.TopicEventCERequest{
+					Id:   "event-b",
+					Type: "test.b",
+					Data: []byte(`{"fail": "unavailable"}`),
+				}},
+			},
+			{
+				EntryId: "c",
+				Event:   &pb.TopicEventBulkRequestEntry_Bytes{Bytes: []byte(`{"fail": "invalid_argument"}`)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*pb.TopicEventBulkResponseEntry{
+		{EntryId: "a", Status: pb.TopicEventResponse_SUCCESS},
+		{EntryId: "b", Status: pb.TopicEventResponse_RETRY},
+		{EntryId: "c", Status: pb.TopicEventResponse_DROP},
+	}, resp.Statuses)
+
+	require.Len(t, calls, 3)
+	assert.Equal(t, map[string]string{"batch": "1", "key": "a"}, calls[0].variables["metadata"])
+	assert.Equal(t, "event-b", calls[1].variables["event"].(map[string]any)["id"])
+	assert.Equal(t, "test.b", calls[1].variables["event"].(map[string]any)["type"])
+}
+
+func TestServe(t *testing.T) {
+	ctx := context.Background()
+	var calls []call
+	deadLetterTopic := "orders-dead"
+	s, err := dapr.NewServer(logr.Discard(), recordCalls(&calls), testCodecs(), &dapr.DaprServerV1Config{
+		Subscriptions: []dapr.Subscription{
+			{
+				Pubsub:          "test",
+				Topic:           "orders",
+				Codec:           "json",
+				Metadata:        map[string]string{"queue": "orders"},
+				DeadLetterTopic: &deadLetterTopic,
+				Bulk: &dapr.BulkSubscribe{
+					MaxMessages:      50,
+					MaxAwaitDuration: 500 * time.Millisecond,
+				},
+			},
+		},
+		Bindings: []dapr.Binding{
+			{
+				Name:    "cron",
+				Codec:   "json",
+				Handler: handler.Handler{Interface: "Jobs", Operation: "run"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(lis) }()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewAppCallbackClient(conn)
+
+	subs, err := client.ListTopicSubscriptions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, subs.Subscriptions, 1)
+	sub := subs.Subscriptions[0]
+	assert.Equal(t, "test", sub.PubsubName)
+	assert.Equal(t, "orders", sub.Topic)
+	assert.Equal(t, map[string]string{"queue": "orders", "rawPayload": "true"}, sub.Metadata)
+	assert.Equal(t, "orders-dead", sub.DeadLetterTopic)
+	assert.True(t, sub.BulkSubscribe.Enabled)
+	assert.Equal(t, int32(50), sub.BulkSubscribe.MaxMessagesCount)
+	assert.Equal(t, int32(500), sub.BulkSubscribe.MaxAwaitDurationMs)
+
+	bindings, err := client.ListInputBindings(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cron"}, bindings.Bindings)
+
+	// Binding handlers receive the binding metadata.
+	_, err = client.OnBindingEvent(ctx, &pb.BindingEventRequest{
+		Name:     "cron",
+		Data:     []byte(`{"job": "cleanup"}`),
+		Metadata: map[string]string{"schedule": "@hourly"},
+	})
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, transport.Variables{
+		"metadata": map[string]string{"schedule": "@hourly"},
+	}, calls[0].variables)
+
+	_, err = pb.NewAppCallbackHealthCheckClient(conn).HealthCheck(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Close())
+	require.NoError(t, <-done)
+}
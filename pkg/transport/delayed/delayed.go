@@ -0,0 +1,149 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/delayed"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+type Delayed struct {
+	log     logr.Logger
+	ctx     context.Context
+	cancel  context.CancelFunc
+	store   delayed.Store
+	invoker transport.Invoker
+	config  *DelayedV1Config
+	wg      sync.WaitGroup
+}
+
+func DelayedV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := DelayedV1Config{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var resources resource.Resources
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"resource:lookup", &resources,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	store, err := delayed.NewStore(ctx, resources, c.Resource, c.KeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(ctx, log, transportInvoker, store, &c), nil
+}
+
+func New(ctx context.Context, log logr.Logger, invoker transport.Invoker, store delayed.Store, c *DelayedV1Config) *Delayed {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Delayed{
+		log:     log,
+		ctx:     ctx,
+		cancel:  cancel,
+		store:   store,
+		invoker: invoker,
+		config:  c,
+	}
+}
+
+func (t *Delayed) Listen() error {
+	t.log.Info("Polling for delayed invocations", "interval", t.config.PollInterval)
+
+	ticker := time.NewTicker(t.config.PollInterval)
+	defer ticker.Stop()
+	defer t.wg.Wait()
+
+	for {
+		// Keep claiming while full batches are returned so a backlog is
+		// drained without waiting for the next tick.
+		for t.poll() {
+		}
+
+		select {
+		case <-t.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Delayed) Close() error {
+	t.cancel()
+	return nil
+}
+
+// poll claims and delivers due invocations. It returns true if a full batch
+// was claimed.
+func (t *Delayed) poll() bool {
+	if t.ctx.Err() != nil {
+		return false
+	}
+
+	limit := int(t.config.BatchSize)
+	invocations, err := t.store.Due(t.ctx, time.Now(), limit, t.config.Lease)
+	if err != nil {
+		t.log.Error(err, "Could not claim delayed invocations")
+		return false
+	}
+
+	var wg sync.WaitGroup
+	for i := range invocations {
+		inv := &invocations[i]
+		wg.Add(1)
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer wg.Done()
+			t.deliver(inv)
+		}()
+	}
+	wg.Wait()
+
+	return len(invocations) == limit
+}
+
+func (t *Delayed) deliver(inv *delayed.Invocation) {
+	_, err := t.invoker(t.ctx, inv.Handler, inv.EntityID, inv.Input, transport.BypassAuthorization)
+	if err != nil {
+		if t.config.MaxAttempts == 0 || inv.Attempts < int(t.config.MaxAttempts) {
+			t.log.Error(err, "Delayed invocation failed and will be retried",
+				"key", inv.ID, "handler", inv.Handler.String(), "attempts", inv.Attempts)
+			return
+		}
+		t.log.Error(err, "Delayed invocation failed and is discarded",
+			"key", inv.ID, "handler", inv.Handler.String(), "attempts", inv.Attempts)
+	}
+
+	// Use a fresh context so completions are recorded during shutdown.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := t.store.Complete(ctx, inv.ID); err != nil {
+		t.log.Error(err, "Could not complete delayed invocation", "key", inv.ID)
+	}
+}
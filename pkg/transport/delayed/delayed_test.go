@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package delayed_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/delayed"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	transport_delayed "github.com/nanobus/nanobus/pkg/transport/delayed"
+)
+
+// recorder records calls by operation.
+type recorder struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	input    map[string]interface{}
+	entityID map[string]string
+	fail     map[string]bool
+}
+
+func (r *recorder) invoke(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[h.Operation]++
+	r.input[h.Operation] = input
+	r.entityID[h.Operation] = id
+	if r.fail[h.Operation] {
+		return nil, errors.New("boom")
+	}
+	return nil, nil
+}
+
+func (r *recorder) count(operation string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[operation]
+}
+
+func TestDelayed(t *testing.T) {
+	ctx := context.Background()
+	store := delayed.NewMemoryStore()
+	h := func(operation string) handler.Handler {
+		return handler.Handler{Interface: "orders", Operation: operation}
+	}
+	now := time.Now()
+	for _, inv := range []delayed.Invocation{
+		{ID: "expire-1", EntityID: "1", Handler: h("due"), Input: map[string]interface{}{"id": "1"}, RunAt: now},
+		{ID: "failing", Handler: h("failing"), RunAt: now},
+		{ID: "later", Handler: h("later"), RunAt: now.Add(time.Hour)},
+	} {
+		_, err := store.Schedule(ctx, inv)
+		require.NoError(t, err)
+	}
+
+	r := recorder{
+		calls:    map[string]int{},
+		input:    map[string]interface{}{},
+		entityID: map[string]string{},
+		fail:     map[string]bool{"failing": true},
+	}
+	d := transport_delayed.New(ctx, logr.Discard(), r.invoke, store, &transport_delayed.DelayedV1Config{
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    1,
+		Lease:        20 * time.Millisecond,
+		MaxAttempts:  3,
+	})
+	done := make(chan error)
+	go func() { done <- d.Listen() }()
+
+	require.Eventually(t, func() bool {
+		return r.count("due") == 1 && r.count("failing") == 3
+	}, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, d.Close())
+	require.NoError(t, <-done)
+
+	assert.Equal(t, 1, r.count("due"))
+	assert.Equal(t, 3, r.count("failing"), "discarded after max attempts")
+	assert.Equal(t, 0, r.count("later"))
+	assert.Equal(t, map[string]interface{}{"id": "1"}, r.input["due"])
+	assert.Equal(t, "1", r.entityID["due"])
+	assert.Empty(t, r.entityID["failing"], "the key is not the entity ID")
+
+	due, err := store.Due(ctx, now.Add(2*time.Hour), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "later", due[0].ID)
+}
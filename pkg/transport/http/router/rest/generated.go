@@ -8,6 +8,8 @@ import (
 
 type RestV1Config struct {
 	Documentation Documentation `json:"documentation" yaml:"documentation" msgpack:"documentation" mapstructure:"documentation"`
+	// Limits for operations with a parameter annotated with `@stream`.
+	Uploads Uploads `json:"uploads" yaml:"uploads" msgpack:"uploads" mapstructure:"uploads"`
 }
 
 func RestV1() (string, router.Loader) {
@@ -19,3 +21,21 @@ type Documentation struct {
 	Postman    *bool `json:"postman,omitempty" yaml:"postman,omitempty" msgpack:"postman,omitempty" mapstructure:"postman"`
 	RestClient *bool `json:"restClient,omitempty" yaml:"restClient,omitempty" msgpack:"restClient,omitempty" mapstructure:"restClient"`
 }
+
+// Uploads configures streamed request bodies. An operation parameter annotated
+// with `@stream` receives a `multipart/form-data` file part or the raw request
+// body through the pipeline's stream source instead of being read into memory.
+type Uploads struct {
+	// The maximum request body size in bytes. Zero disables the limit.
+	MaxBodySize int64 `json:"maxBodySize" yaml:"maxBodySize" msgpack:"maxBodySize" mapstructure:"maxBodySize"`
+	// The maximum size in bytes of a non-file form field.
+	MaxFieldSize int64 `json:"maxFieldSize" yaml:"maxFieldSize" msgpack:"maxFieldSize" mapstructure:"maxFieldSize"`
+	// The content types allowed for the file part or raw body. Entries ending in
+	// `/*` match any subtype. All content types are allowed when empty.
+	AllowedContentTypes []string `json:"allowedContentTypes,omitempty" yaml:"allowedContentTypes,omitempty" msgpack:"allowedContentTypes,omitempty" mapstructure:"allowedContentTypes"`
+	// The size in bytes of the chunks read from the body.
+	ChunkSize uint32 `json:"chunkSize" yaml:"chunkSize" msgpack:"chunkSize" mapstructure:"chunkSize"`
+	// Sets the file name, content type and form field name of the streamed part
+	// on the `@stream` parameter.
+	PartMetadata bool `json:"partMetadata" yaml:"partMetadata" msgpack:"partMetadata" mapstructure:"partMetadata"`
+}
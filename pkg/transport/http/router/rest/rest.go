@@ -31,6 +31,7 @@ import (
 	"github.com/nanobus/nanobus/pkg/logger"
 	"github.com/nanobus/nanobus/pkg/resolve"
 	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/stream"
 	"github.com/nanobus/nanobus/pkg/transport"
 	"github.com/nanobus/nanobus/pkg/transport/filter"
 	"github.com/nanobus/nanobus/pkg/transport/http/router"
@@ -45,6 +46,7 @@ type Rest struct {
 	errorResolver errorz.Resolver
 	codecs        map[string]channel.Codec
 	filters       []filter.Filter
+	uploads       Uploads
 }
 
 type queryParam struct {
@@ -104,7 +106,9 @@ func RestV1Loader(ctx context.Context, with interface{}, resolver resolve.Resolv
 	}
 
 	// Defaults
-	c := RestV1Config{}
+	c := RestV1Config{
+		Uploads: DefaultUploads(),
+	}
 	if err := config.Decode(with, &c); err != nil {
 		return nil, err
 	}
@@ -134,6 +138,7 @@ func NewV1(log logr.Logger, tracer trace.Tracer, config RestV1Config, namespaces
 			errorResolver: errorResolver,
 			codecs:        codecMap,
 			filters:       opts.filters,
+			uploads:       config.Uploads,
 		}
 
 		docsHost := address
@@ -222,6 +227,7 @@ func NewV1(log logr.Logger, tracer trace.Tracer, config RestV1Config, namespaces
 					}
 
 					bodyParamName := ""
+					streamParamName := ""
 					hasBody := false
 					queryParams := map[string]queryParam{}
 
@@ -274,6 +280,8 @@ func NewV1(log logr.Logger, tracer trace.Tracer, config RestV1Config, namespaces
 							} else if _, ok := param.Annotation("body"); ok {
 								bodyParamName = param.Name
 								hasBody = true
+							} else if _, ok := param.Annotation("stream"); ok {
+								streamParamName = param.Name
 							}
 						}
 					} else {
@@ -309,7 +317,7 @@ func NewV1(log logr.Logger, tracer trace.Tracer, config RestV1Config, namespaces
 							Interface: namespace.Name + "." + service.Name,
 							Operation: operation.Name,
 						}, isActor,
-						hasBody, bodyParamName, queryParams)).Methods(methods...)
+						hasBody, bodyParamName, streamParamName, queryParams)).Methods(methods...)
 				}
 			}
 		}
@@ -319,7 +327,7 @@ func NewV1(log logr.Logger, tracer trace.Tracer, config RestV1Config, namespaces
 }
 
 func (t *Rest) handler(h handler.Handler, isActor bool,
-	hasBody bool, bodyParamName string, queryParams map[string]queryParam) http.HandlerFunc {
+	hasBody bool, bodyParamName, streamParamName string, queryParams map[string]queryParam) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		defer r.Body.Close()
@@ -334,6 +342,17 @@ func (t *Rest) handler(h handler.Handler, isActor bool,
 			contentType = "application/json"
 		}
 
+		// Streamed bodies are not decoded so the codec is only used for the
+		// response.
+		if streamParamName != "" {
+			contentType = "application/json"
+			if accept := r.Header.Get("Accept"); accept != "" {
+				if _, ok := t.codecs[accept]; ok {
+					contentType = accept
+				}
+			}
+		}
+
 		codec, ok := t.codecs[contentType]
 		if !ok {
 			w.WriteHeader(http.StatusUnsupportedMediaType)
@@ -352,14 +371,21 @@ func (t *Rest) handler(h handler.Handler, isActor bool,
 			}
 		}
 
-		requestBytes, err := io.ReadAll(r.Body)
-		if err != nil {
+		var input map[string]interface{}
+		var err error
+		if streamParamName != "" {
+			var source stream.Source
+			input, source, err = t.openUpload(w, r, streamParamName)
+			if err != nil {
+				t.handleError(err, codec, r, w, http.StatusBadRequest)
+				return
+			}
+			defer source.Cancel()
+			ctx = stream.SourceNewContext(ctx, source)
+		} else if requestBytes, err := io.ReadAll(r.Body); err != nil {
 			t.handleError(err, codec, r, w, http.StatusInternalServerError)
 			return
-		}
-
-		var input map[string]interface{}
-		if len(requestBytes) > 0 {
+		} else if len(requestBytes) > 0 {
 			if bodyParamName == "" {
 				if err := codec.Decode(requestBytes, &input); err != nil {
 					t.handleError(err, codec, r, w, http.StatusInternalServerError)
@@ -448,8 +474,11 @@ func (t *Rest) handler(h handler.Handler, isActor bool,
 		response, err := t.invoker(ctx, h, id, input, transport.PerformAuthorization)
 		if err != nil {
 			code := http.StatusInternalServerError
+			var maxBytes *http.MaxBytesError
 			if errors.Is(err, transport.ErrBadInput) {
 				code = http.StatusBadRequest
+			} else if errors.As(err, &maxBytes) {
+				err = uploadReadError(err)
 			}
 			t.handleError(err, codec, r, w, code)
 			return
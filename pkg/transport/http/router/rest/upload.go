@@ -0,0 +1,189 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/nanobus/nanobus/pkg/channel/metadata"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/stream"
+)
+
+const defaultUploadContentType = "application/octet-stream"
+
+// DefaultUploads returns the upload limits used when none are configured.
+func DefaultUploads() Uploads {
+	return Uploads{
+		MaxBodySize:  32 << 20,
+		MaxFieldSize: 1 << 20,
+		ChunkSize:    stream.DefaultChunkSize,
+		PartMetadata: true,
+	}
+}
+
+// openUpload prepares a request for an operation with a `@stream` parameter.
+// Form fields that precede the first file part of a `multipart/form-data`
+// body are decoded into the returned input. The file part, or the whole body
+// for any other content type, is returned as a stream source. Parts after the
+// file part are not read.
+func (t *Rest) openUpload(w http.ResponseWriter, r *http.Request, streamParamName string) (map[string]interface{}, stream.Source, error) {
+	c := t.uploads
+	body := r.Body
+	if c.MaxBodySize > 0 {
+		body = http.MaxBytesReader(w, body, c.MaxBodySize)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = defaultUploadContentType
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, uploadError(errorz.InvalidArgument, http.StatusBadRequest,
+			fmt.Sprintf("invalid content type %q", contentType), err)
+	}
+
+	input := map[string]interface{}{}
+	if mediaType != "multipart/form-data" {
+		if !c.allowed(mediaType) {
+			return nil, nil, unsupportedContentType(mediaType)
+		}
+		if c.PartMetadata {
+			input[streamParamName] = map[string]interface{}{
+				"contentType": mediaType,
+			}
+		}
+		md := metadata.Pairs("content-type", mediaType)
+		return input, stream.SourceFromReader(body, int(c.ChunkSize), md), nil
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, nil, uploadError(errorz.InvalidArgument, http.StatusBadRequest,
+			"multipart boundary is missing", nil)
+	}
+
+	mr := multipart.NewReader(body, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil, uploadError(errorz.InvalidArgument, http.StatusBadRequest,
+					"multipart body does not contain a file part", nil)
+			}
+			return nil, nil, uploadReadError(err)
+		}
+
+		if part.FileName() == "" {
+			value, err := readField(part, c.MaxFieldSize)
+			if err != nil {
+				return nil, nil, err
+			}
+			addField(input, part.FormName(), value)
+			continue
+		}
+
+		partType := part.Header.Get("Content-Type")
+		if partType == "" {
+			partType = defaultUploadContentType
+		}
+		if mediaType, _, err := mime.ParseMediaType(partType); err == nil {
+			partType = mediaType
+		}
+		if !c.allowed(partType) {
+			return nil, nil, unsupportedContentType(partType)
+		}
+
+		if c.PartMetadata {
+			input[streamParamName] = map[string]interface{}{
+				"name":        part.FormName(),
+				"filename":    part.FileName(),
+				"contentType": partType,
+			}
+		}
+		md := metadata.Pairs(
+			"name", part.FormName(),
+			"filename", part.FileName(),
+			"content-type", partType)
+		return input, stream.SourceFromReader(part, int(c.ChunkSize), md), nil
+	}
+}
+
+func (c *Uploads) allowed(contentType string) bool {
+	if len(c.AllowedContentTypes) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedContentTypes {
+		if strings.HasSuffix(allowed, "/*") {
+			if strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+		} else if strings.EqualFold(allowed, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func readField(part *multipart.Part, maxSize int64) (string, error) {
+	r := io.Reader(part)
+	if maxSize > 0 {
+		r = io.LimitReader(part, maxSize+1)
+	}
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return "", uploadReadError(err)
+	}
+	if maxSize > 0 && int64(len(value)) > maxSize {
+		return "", uploadError(errorz.InvalidArgument, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("form field %q exceeds %d bytes", part.FormName(), maxSize), nil)
+	}
+	return string(value), nil
+}
+
+// addField sets a form field on the input. Repeated fields become arrays.
+func addField(input map[string]interface{}, name, value string) {
+	switch existing := input[name].(type) {
+	case nil:
+		input[name] = value
+	case []interface{}:
+		input[name] = append(existing, value)
+	default:
+		input[name] = []interface{}{existing, value}
+	}
+}
+
+func unsupportedContentType(contentType string) error {
+	return uploadError(errorz.InvalidArgument, http.StatusUnsupportedMediaType,
+		fmt.Sprintf("content type %q is not allowed", contentType), nil)
+}
+
+// uploadReadError translates errors reading the request body, which includes
+// exceeding the maximum body size while the pipeline consumes the stream.
+func uploadReadError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return uploadError(errorz.InvalidArgument, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit), err)
+	}
+	return uploadError(errorz.InvalidArgument, http.StatusBadRequest, err.Error(), err)
+}
+
+func uploadError(code errorz.ErrCode, status int, message string, err error) *errorz.Error {
+	errz := errorz.Wrap(err, code, message)
+	errz.Status = status
+	return errz
+}
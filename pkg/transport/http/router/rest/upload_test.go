@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package rest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	json_codec "github.com/nanobus/nanobus/pkg/channel/codecs/json"
+	"github.com/nanobus/nanobus/pkg/channel/metadata"
+	"github.com/nanobus/nanobus/pkg/errorz"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/spec"
+	"github.com/nanobus/nanobus/pkg/stream"
+	"github.com/nanobus/nanobus/pkg/transport"
+	"github.com/nanobus/nanobus/pkg/transport/http/router/rest"
+)
+
+type upload struct {
+	input  map[string]interface{}
+	chunks []string
+	md     metadata.MD
+}
+
+// newServer serves a `POST /files/{folder}` operation whose `file` parameter
+// is streamed.
+func newServer(t *testing.T, uploads rest.Uploads, uploaded *upload) *httptest.Server {
+	ns := spec.NewNamespace("test")
+	params := spec.NewType(ns, "UploadArgs", "").AddFields(
+		spec.NewField("folder", "", &spec.TypeRef{Kind: spec.KindString}, nil),
+		spec.NewField("owner", "", &spec.TypeRef{Kind: spec.KindString}, nil),
+		spec.NewField("file", "", &spec.TypeRef{
+			Kind:         spec.KindOptional,
+			OptionalType: &spec.TypeRef{Kind: spec.KindRaw},
+		}, nil).AddAnnotation(spec.NewAnnotation("stream")),
+	)
+	ns.AddService(spec.NewService("Files", "").
+		AddAnnotation(spec.NewAnnotation("service")).
+		AddAnnotation(spec.NewAnnotation("path").AddArgument(spec.NewArgument("value", "/files"))).
+		AddOperation(spec.NewOperation("upload", "", false, params, nil).
+			AddAnnotation(spec.NewAnnotation("POST")).
+			AddAnnotation(spec.NewAnnotation("path").AddArgument(spec.NewArgument("value", "/{folder}")))))
+
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		uploaded.input = input.(map[string]interface{})
+		s, ok := stream.SourceFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no stream source")
+		}
+		for {
+			var chunk any
+			if err := s.Next(&chunk, &uploaded.md); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			uploaded.chunks = append(uploaded.chunks, string(chunk.([]byte)))
+		}
+		return map[string]interface{}{"ok": true}, nil
+	}
+	errorResolver := func(err error) *errorz.Error {
+		return errorz.From(err)
+	}
+
+	route, err := rest.NewV1(logr.Discard(), trace.NewNoopTracerProvider().Tracer("test"),
+		rest.RestV1Config{Uploads: uploads}, spec.Namespaces{ns.Name: ns}, invoker, errorResolver,
+		rest.WithCodecs(json_codec.New()))
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	require.NoError(t, route(r, ":0"))
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func multipartBody(t *testing.T, fileType string, file string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("owner", "alice"))
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="file"; filename="notes.txt"`)
+	h.Set("Content-Type", fileType)
+	part, err := mw.CreatePart(h)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(file))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func TestMultipartUpload(t *testing.T) {
+	uploads := rest.DefaultUploads()
+	uploads.ChunkSize = 4
+	uploads.AllowedContentTypes = []string{"text/*"}
+	var uploaded upload
+	server := newServer(t, uploads, &uploaded)
+
+	body, contentType := multipartBody(t, "text/plain", "hello world")
+	resp, err := http.Post(server.URL+"/files/docs", contentType, body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, map[string]interface{}{
+		"folder": "docs",
+		"owner":  "alice",
+		"file": map[string]interface{}{
+			"name":        "file",
+			"filename":    "notes.txt",
+			"contentType": "text/plain",
+		},
+	}, uploaded.input)
+	assert.Equal(t, []string{"hell", "o wo", "rld"}, uploaded.chunks)
+	assert.Equal(t, []string{"notes.txt"}, uploaded.md.Get("filename"))
+	assert.Equal(t, []string{"text/plain"}, uploaded.md.Get("content-type"))
+
+	// Disallowed part content types are rejected before invoking the pipeline.
+	body, contentType = multipartBody(t, "image/png", "png")
+	resp, err = http.Post(server.URL+"/files/docs", contentType, body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestRawUpload(t *testing.T) {
+	uploads := rest.DefaultUploads()
+	uploads.MaxBodySize = 8
+	var uploaded upload
+	server := newServer(t, uploads, &uploaded)
+
+	resp, err := http.Post(server.URL+"/files/docs", "application/pdf", strings.NewReader("%PDF-1"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, map[string]interface{}{"contentType": "application/pdf"}, uploaded.input["file"])
+	assert.Equal(t, []string{"%PDF-1"}, uploaded.chunks)
+
+	resp, err = http.Post(server.URL+"/files/docs", "application/pdf", strings.NewReader("%PDF-1.7 too large"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
@@ -0,0 +1,442 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-logr/logr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	actions_kafka "github.com/nanobus/nanobus/pkg/actions/kafka"
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+// Headers added to records forwarded to retry and dead-letter topics.
+const (
+	HeaderRetryCount    = "x-retry-count"
+	HeaderOriginalTopic = "x-original-topic"
+	HeaderError         = "x-error"
+)
+
+const defaultMaxRetries = 3
+
+// Client is the subset of *kgo.Client used by the transport.
+type Client interface {
+	PollFetches(ctx context.Context) kgo.Fetches
+	CommitRecords(ctx context.Context, rs ...*kgo.Record) error
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+	AllowRebalance()
+	Close()
+}
+
+type Kafka struct {
+	log         logr.Logger
+	ctx         context.Context
+	cancel      context.CancelFunc
+	client      Client
+	invoker     transport.Invoker
+	concurrency int
+	topics      map[string]*subscription
+}
+
+type subscription struct {
+	config     *Subscription
+	codec      codec.Codec
+	maxRetries uint32
+}
+
+func KafkaV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := KafkaV1Config{
+		StartOffset: "latest",
+		Concurrency: 1,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	opts, err := clientOptions(&c)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := New(log, transportInvoker, codecs, &c, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// clientOptions returns the connection options shared with the Kafka
+// resource plus the consumer group options.
+func clientOptions(c *KafkaV1Config) ([]kgo.Opt, error) {
+	conn := actions_kafka.ConnectionConfig{
+		Brokers:    c.Brokers,
+		TLS:        c.TLS,
+		Idempotent: true,
+		Acks:       "all",
+	}
+	if c.ClientID != nil {
+		conn.ClientID = *c.ClientID
+	}
+	if c.SASL != nil {
+		conn.SASL = &actions_kafka.SASLConfig{
+			Mechanism: c.SASL.Mechanism,
+			Username:  c.SASL.Username,
+			Password:  c.SASL.Password,
+		}
+	}
+	opts, err := actions_kafka.ClientOptions(&conn)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]string, 0, len(c.Subscriptions))
+	for _, sub := range c.Subscriptions {
+		topics = append(topics, sub.Topic)
+		if sub.RetryTopic != nil {
+			topics = append(topics, *sub.RetryTopic)
+		}
+	}
+
+	offset := kgo.NewOffset().AtEnd()
+	if c.StartOffset == "earliest" {
+		offset = kgo.NewOffset().AtStart()
+	}
+
+	return append(opts,
+		kgo.ConsumerGroup(c.Group),
+		kgo.ConsumeTopics(topics...),
+		kgo.ConsumeResetOffset(offset),
+		// Offsets are committed after records are handled and rebalancing
+		// waits until those commits complete.
+		kgo.DisableAutoCommit(),
+		kgo.BlockRebalanceOnPoll(),
+	), nil
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, c *KafkaV1Config, client Client) (*Kafka, error) {
+	topics := make(map[string]*subscription, len(c.Subscriptions))
+	for i := range c.Subscriptions {
+		sub := &c.Subscriptions[i]
+		codec, ok := codecs[string(sub.Codec)]
+		if !ok {
+			return nil, fmt.Errorf("could not find codec %q", sub.Codec)
+		}
+
+		s := &subscription{
+			config:     sub,
+			codec:      codec,
+			maxRetries: defaultMaxRetries,
+		}
+		if sub.MaxRetries != nil {
+			s.maxRetries = *sub.MaxRetries
+		}
+
+		if _, exists := topics[sub.Topic]; exists {
+			return nil, fmt.Errorf("duplicate subscription for topic %q", sub.Topic)
+		}
+		topics[sub.Topic] = s
+		if sub.RetryTopic != nil {
+			if _, exists := topics[*sub.RetryTopic]; exists {
+				return nil, fmt.Errorf("duplicate subscription for topic %q", *sub.RetryTopic)
+			}
+			topics[*sub.RetryTopic] = s
+		}
+	}
+
+	concurrency := int(c.Concurrency)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Kafka{
+		log:         log,
+		ctx:         ctx,
+		cancel:      cancel,
+		client:      client,
+		invoker:     invoker,
+		concurrency: concurrency,
+		topics:      topics,
+	}, nil
+}
+
+func (t *Kafka) Listen() error {
+	defer t.client.Close()
+
+	for {
+		fetches := t.client.PollFetches(t.ctx)
+		if fetches.IsClientClosed() || t.ctx.Err() != nil {
+			return nil
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			t.log.Error(err, "could not fetch Kafka records", "topic", topic, "partition", partition)
+		})
+
+		t.poll(fetches)
+		t.client.AllowRebalance()
+	}
+}
+
+func (t *Kafka) Close() error {
+	t.cancel()
+	return nil
+}
+
+// poll processes each partition of fetches concurrently, up to the
+// configured concurrency, and commits the last handled record of each.
+func (t *Kafka) poll(fetches kgo.Fetches) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		commit []*kgo.Record
+		sem    = make(chan struct{}, t.concurrency)
+	)
+
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if len(p.Records) == 0 {
+			return
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if last := t.processPartition(p.Records); last != nil {
+				mu.Lock()
+				commit = append(commit, last)
+				mu.Unlock()
+			}
+		}()
+	})
+	wg.Wait()
+
+	if len(commit) == 0 {
+		return
+	}
+
+	// Commit even while shutting down so handled records are not redelivered.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := t.client.CommitRecords(ctx, commit...); err != nil {
+		t.log.Error(err, "could not commit Kafka offsets")
+	}
+}
+
+// processPartition handles records in order and returns the last record that
+// was handled, or nil if none were.
+func (t *Kafka) processPartition(records []*kgo.Record) *kgo.Record {
+	var last *kgo.Record
+	for _, r := range records {
+		if err := t.process(r); err != nil {
+			break
+		}
+		last = r
+	}
+	return last
+}
+
+// process handles a single record. An error is only returned when the record
+// was not handled and must be redelivered.
+func (t *Kafka) process(r *kgo.Record) error {
+	s, ok := t.topics[r.Topic]
+	if !ok {
+		t.log.Info("No subscription found for Kafka record", "topic", r.Topic)
+		return nil
+	}
+
+	if s.config.RetryTopic != nil && r.Topic == *s.config.RetryTopic && s.config.RetryDelay != nil {
+		if wait := time.Until(r.Timestamp.Add(*s.config.RetryDelay)); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-t.ctx.Done():
+				return t.ctx.Err()
+			}
+		}
+	}
+
+	headers := make(map[string]string, len(r.Headers))
+	for _, h := range r.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	input, eventType, err := s.codec.Decode(r.Value, s.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode Kafka record", "topic", r.Topic, "partition", r.Partition, "offset", r.Offset)
+		if s.config.DeadLetterTopic != nil {
+			return t.forward(r, *s.config.DeadLetterTopic, retryCount(headers), err)
+		}
+		return nil
+	}
+
+	if s.config.TypeHeader != nil {
+		eventType = headers[*s.config.TypeHeader]
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if s.config.Handler != nil {
+		h = *s.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && s.config.Types != nil {
+		if handler, ok := s.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for Kafka record", "topic", r.Topic, "type", eventType)
+		return nil
+	}
+
+	// Continue the trace of the producer, if any.
+	ctx := otel.GetTextMapPropagator().Extract(t.ctx, propagation.MapCarrier(headers))
+
+	invoke := func() error {
+		_, err := t.invoker(ctx, h, "", input, transport.BypassAuthorization)
+		if err != nil {
+			t.log.Error(err, "error handling Kafka record", "topic", r.Topic, "partition", r.Partition, "offset", r.Offset, "handler", h.String())
+		}
+		return err
+	}
+
+	if err = invoke(); err == nil {
+		return nil
+	}
+	if t.ctx.Err() != nil {
+		return t.ctx.Err()
+	}
+
+	attempts := retryCount(headers)
+	if s.config.RetryTopic != nil && attempts < s.maxRetries {
+		return t.forward(r, *s.config.RetryTopic, attempts+1, nil)
+	}
+	if s.config.DeadLetterTopic != nil {
+		return t.forward(r, *s.config.DeadLetterTopic, attempts, err)
+	}
+
+	// Without a retry or dead-letter topic the record is retried in place so
+	// that ordering is preserved. Rebalances of the whole group wait for the
+	// poll to finish, so the attempts are limited by maxRetries and the record
+	// is skipped afterwards.
+	b := backoff.WithMaxRetries(t.backoff(), uint64(s.maxRetries))
+	for {
+		next := b.NextBackOff()
+		if next == backoff.Stop {
+			break
+		}
+		select {
+		case <-time.After(next):
+		case <-t.ctx.Done():
+			return t.ctx.Err()
+		}
+		if err = invoke(); err == nil {
+			return nil
+		}
+	}
+	if t.ctx.Err() != nil {
+		return t.ctx.Err()
+	}
+	t.log.Error(err, "skipping Kafka record after retries", "topic", r.Topic, "partition", r.Partition, "offset", r.Offset)
+	return nil
+}
+
+// forward produces a copy of r to topic, retrying until it succeeds or the
+// transport is closed.
+func (t *Kafka) forward(r *kgo.Record, topic string, attempts uint32, cause error) error {
+	record := kgo.Record{
+		Topic: topic,
+		Key:   r.Key,
+		Value: r.Value,
+	}
+	hasOriginalTopic := false
+	for _, h := range r.Headers {
+		switch h.Key {
+		case HeaderRetryCount, HeaderError:
+			continue
+		case HeaderOriginalTopic:
+			hasOriginalTopic = true
+		}
+		record.Headers = append(record.Headers, h)
+	}
+	if !hasOriginalTopic {
+		record.Headers = append(record.Headers, kgo.RecordHeader{
+			Key:   HeaderOriginalTopic,
+			Value: []byte(r.Topic),
+		})
+	}
+	record.Headers = append(record.Headers, kgo.RecordHeader{
+		Key:   HeaderRetryCount,
+		Value: []byte(strconv.FormatUint(uint64(attempts), 10)),
+	})
+	if cause != nil {
+		record.Headers = append(record.Headers, kgo.RecordHeader{
+			Key:   HeaderError,
+			Value: []byte(cause.Error()),
+		})
+	}
+
+	return backoff.Retry(func() error {
+		err := t.client.ProduceSync(t.ctx, &record).FirstErr()
+		if err != nil {
+			t.log.Error(err, "could not forward Kafka record", "topic", topic)
+		}
+		return err
+	}, t.backoff())
+}
+
+func (t *Kafka) backoff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0
+	return backoff.WithContext(b, t.ctx)
+}
+
+func retryCount(headers map[string]string) uint32 {
+	count, _ := strconv.ParseUint(headers[HeaderRetryCount], 10, 32)
+	return uint32(count)
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	"github.com/nanobus/nanobus/pkg/transport/kafka"
+)
+
+type mockClient struct {
+	mu       sync.Mutex
+	fetches  chan kgo.Fetches
+	commits  chan []*kgo.Record
+	produced []*kgo.Record
+}
+
+func (m *mockClient) PollFetches(ctx context.Context) kgo.Fetches {
+	select {
+	case f := <-m.fetches:
+		return f
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (m *mockClient) CommitRecords(ctx context.Context, rs ...*kgo.Record) error {
+	m.commits <- rs
+	return nil
+}
+
+func (m *mockClient) ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make(kgo.ProduceResults, len(rs))
+	for i, r := range rs {
+		m.produced = append(m.produced, r)
+		results[i] = kgo.ProduceResult{Record: r}
+	}
+	return results
+}
+
+func (m *mockClient) AllowRebalance() {}
+func (m *mockClient) Close()          {}
+
+type mockInvoker struct {
+	mu      sync.Mutex
+	handled []handler.Handler
+	inputs  []interface{}
+	fail    map[string]bool
+}
+
+func (m *mockInvoker) Invoke(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handled = append(m.handled, h)
+	m.inputs = append(m.inputs, input)
+	if in, ok := input.(map[string]interface{}); ok {
+		if id, ok := in["id"].(string); ok && m.fail[id] {
+			return nil, errors.New("boom")
+		}
+	}
+	return nil, nil
+}
+
+func fetches(topic string, partitions ...[]*kgo.Record) kgo.Fetches {
+	ft := kgo.FetchTopic{Topic: topic}
+	for i, records := range partitions {
+		for j, r := range records {
+			r.Topic = topic
+			r.Partition = int32(i)
+			r.Offset = int64(j)
+		}
+		ft.Partitions = append(ft.Partitions, kgo.FetchPartition{
+			Partition: int32(i),
+			Records:   records,
+		})
+	}
+	return kgo.Fetches{{Topics: []kgo.FetchTopic{ft}}}
+}
+
+func record(value string, headers ...string) *kgo.Record {
+	r := kgo.Record{Value: []byte(value)}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Headers = append(r.Headers, kgo.RecordHeader{Key: headers[i], Value: []byte(headers[i+1])})
+	}
+	return &r
+}
+
+func header(r *kgo.Record, key string) string {
+	for _, h := range r.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func start(t *testing.T, mi *mockInvoker, subs ...kafka.Subscription) *mockClient {
+	client := &mockClient{
+		fetches: make(chan kgo.Fetches, 1),
+		commits: make(chan []*kgo.Record, 1),
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	k, err := kafka.New(logr.Discard(), mi.Invoke, codecs, &kafka.KafkaV1Config{
+		Brokers:       []string{"localhost:9092"},
+		Group:         "test",
+		Concurrency:   2,
+		Subscriptions: subs,
+	}, client)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, k.Listen())
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, k.Close())
+		wg.Wait()
+	})
+
+	return client
+}
+
+func nextCommit(t *testing.T, client *mockClient) []*kgo.Record {
+	select {
+	case c := <-client.commits:
+		return c
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for commit")
+	}
+	return nil
+}
+
+func TestKafka(t *testing.T) {
+	handlerDefault := handler.Handler{Interface: "Orders", Operation: "onEvent"}
+	handlerCreated := handler.Handler{Interface: "Orders", Operation: "onCreated"}
+	typeHeader := "ce_type"
+
+	mi := &mockInvoker{}
+	client := start(t, mi, kafka.Subscription{
+		Topic:      "orders",
+		Codec:      kafka.CodecRef("json"),
+		Handler:    &handlerDefault,
+		TypeHeader: &typeHeader,
+		Types: map[string]handler.Handler{
+			"order.created": handlerCreated,
+		},
+	})
+
+	client.fetches <- fetches("orders",
+		[]*kgo.Record{
+			record(`{"id":"1"}`, "ce_type", "order.created"),
+			record(`{"id":"2"}`),
+		},
+		[]*kgo.Record{
+			record(`{"id":"3"}`),
+		})
+
+	commit := nextCommit(t, client)
+	offsets := map[int32]int64{}
+	for _, r := range commit {
+		offsets[r.Partition] = r.Offset
+	}
+	assert.Equal(t, map[int32]int64{0: 1, 1: 0}, offsets)
+
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+	assert.Len(t, mi.handled, 3)
+	for i, in := range mi.inputs {
+		if in.(map[string]interface{})["id"] == "1" {
+			assert.Equal(t, handlerCreated, mi.handled[i])
+		} else {
+			assert.Equal(t, handlerDefault, mi.handled[i])
+		}
+	}
+}
+
+func TestKafkaRetryAndDeadLetter(t *testing.T) {
+	h := handler.Handler{Interface: "Orders", Operation: "onEvent"}
+	retryTopic := "orders.retry"
+	deadLetterTopic := "orders.dlq"
+	maxRetries := uint32(2)
+
+	mi := &mockInvoker{
+		fail: map[string]bool{"1": true, "2": true},
+	}
+	client := start(t, mi, kafka.Subscription{
+		Topic:           "orders",
+		Codec:           kafka.CodecRef("json"),
+		Handler:         &h,
+		RetryTopic:      &retryTopic,
+		MaxRetries:      &maxRetries,
+		DeadLetterTopic: &deadLetterTopic,
+	})
+
+	client.fetches <- fetches("orders",
+		[]*kgo.Record{
+			record(`{"id":"1"}`, "tenant", "acme"),
+			record(`{`),
+		})
+	assert.Len(t, nextCommit(t, client), 1)
+
+	client.fetches <- fetches("orders.retry",
+		[]*kgo.Record{
+			record(`{"id":"2"}`, kafka.HeaderRetryCount, "2", kafka.HeaderOriginalTopic, "orders"),
+		})
+	assert.Len(t, nextCommit(t, client), 1)
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	require.Len(t, client.produced, 3)
+
+	retried := client.produced[0]
+	assert.Equal(t, "orders.retry", retried.Topic)
+	assert.Equal(t, "1", header(retried, kafka.HeaderRetryCount))
+	assert.Equal(t, "orders", header(retried, kafka.HeaderOriginalTopic))
+	assert.Equal(t, "acme", header(retried, "tenant"))
+
+	undecodable := client.produced[1]
+	assert.Equal(t, "orders.dlq", undecodable.Topic)
+	assert.NotEmpty(t, header(undecodable, kafka.HeaderError))
+
+	exhausted := client.produced[2]
+	assert.Equal(t, "orders.dlq", exhausted.Topic)
+	assert.Equal(t, "2", header(exhausted, kafka.HeaderRetryCount))
+	assert.Equal(t, "orders", header(exhausted, kafka.HeaderOriginalTopic))
+	assert.Equal(t, "boom", header(exhausted, kafka.HeaderError))
+}
+
+func TestKafkaRetryInPlace(t *testing.T) {
+	h := handler.Handler{Interface: "Orders", Operation: "onEvent"}
+	maxRetries := uint32(1)
+
+	mi := &mockInvoker{
+		fail: map[string]bool{"1": true},
+	}
+	client := start(t, mi, kafka.Subscription{
+		Topic:      "orders",
+		Codec:      kafka.CodecRef("json"),
+		Handler:    &h,
+		MaxRetries: &maxRetries,
+	})
+
+	// A record that keeps failing does not block the partition forever.
+	client.fetches <- fetches("orders",
+		[]*kgo.Record{
+			record(`{"id":"1"}`),
+			record(`{"id":"2"}`),
+		})
+	commit := nextCommit(t, client)
+	require.Len(t, commit, 1)
+	assert.Equal(t, int64(1), commit[0].Offset)
+
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "1"},
+		map[string]interface{}{"id": "1"},
+		map[string]interface{}{"id": "2"},
+	}, mi.inputs)
+}
+
+func TestKafkaInvalidConfig(t *testing.T) {
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		return nil, nil
+	}
+
+	_, err := kafka.New(logr.Discard(), invoker, codecs, &kafka.KafkaV1Config{
+		Subscriptions: []kafka.Subscription{{Topic: "orders", Codec: "avro"}},
+	}, &mockClient{})
+	assert.EqualError(t, err, `could not find codec "avro"`)
+
+	_, err = kafka.New(logr.Discard(), invoker, codecs, &kafka.KafkaV1Config{
+		Subscriptions: []kafka.Subscription{
+			{Topic: "orders", Codec: "json"},
+			{Topic: "orders", Codec: "json"},
+		},
+	}, &mockClient{})
+	assert.EqualError(t, err, `duplicate subscription for topic "orders"`)
+}
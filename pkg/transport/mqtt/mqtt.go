@@ -0,0 +1,319 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-logr/logr"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+type MQTT struct {
+	log           logr.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	client        mqtt.Client
+	invoker       transport.Invoker
+	filters       map[string]byte
+	subscriptions []*subscription
+}
+
+type subscription struct {
+	config   *Subscription
+	codec    codec.Codec
+	filter   string
+	segments []segment
+}
+
+// segment is a named wildcard segment of a topic pattern.
+type segment struct {
+	index int
+	name  string
+	multi bool
+}
+
+func MQTTV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := MQTTV1Config{
+		CleanSession: true,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	return New(log, transportInvoker, codecs, &c)
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, c *MQTTV1Config) (*MQTT, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := MQTT{
+		log:           log,
+		ctx:           ctx,
+		cancel:        cancel,
+		invoker:       invoker,
+		filters:       make(map[string]byte, len(c.Subscriptions)),
+		subscriptions: make([]*subscription, len(c.Subscriptions)),
+	}
+
+	opts := mqtt.NewClientOptions()
+	for _, broker := range c.Brokers {
+		opts.AddBroker(broker)
+	}
+	if c.ClientID != nil {
+		opts.SetClientID(*c.ClientID)
+	}
+	if c.Username != nil {
+		opts.SetUsername(*c.Username)
+	}
+	if c.Password != nil {
+		opts.SetPassword(*c.Password)
+	}
+	if c.KeepAlive != nil {
+		opts.SetKeepAlive(*c.KeepAlive)
+	}
+	if c.ConnectTimeout != nil {
+		opts.SetConnectTimeout(*c.ConnectTimeout)
+	}
+	if c.Store != nil {
+		opts.SetStore(mqtt.NewFileStore(*c.Store))
+	}
+	opts.SetCleanSession(c.CleanSession)
+	opts.SetAutoReconnect(true)
+	opts.SetOnConnectHandler(t.onConnect)
+	opts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		log.Error(err, "MQTT connection lost")
+	})
+
+	client := mqtt.NewClient(opts)
+
+	for i := range c.Subscriptions {
+		sub := &c.Subscriptions[i]
+		codec, ok := codecs[string(sub.Codec)]
+		if !ok {
+			cancel()
+			return nil, fmt.Errorf("could not find codec %q", sub.Codec)
+		}
+
+		filter, segments, err := parseTopic(sub.Topic)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if sub.TypeSegment != nil && !hasSegment(segments, *sub.TypeSegment) {
+			cancel()
+			return nil, fmt.Errorf("type segment %q is not in topic %q", *sub.TypeSegment, sub.Topic)
+		}
+		if sub.Group != nil {
+			filter = "$share/" + *sub.Group + "/" + filter
+		}
+
+		s := &subscription{
+			config:   sub,
+			codec:    codec,
+			filter:   filter,
+			segments: segments,
+		}
+		t.subscriptions[i] = s
+		t.filters[filter] = sub.Qos
+
+		// Routes are added before connecting so that messages
+		// delivered from a persistent session are not dropped.
+		client.AddRoute(filter, func(_ mqtt.Client, m mqtt.Message) {
+			t.handler(s, m)
+		})
+	}
+
+	t.client = client
+
+	return &t, nil
+}
+
+func (t *MQTT) Listen() error {
+	token := t.client.Connect()
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return err
+		}
+	case <-t.ctx.Done():
+		return nil
+	}
+
+	<-t.ctx.Done()
+
+	return nil
+}
+
+func (t *MQTT) Close() error {
+	defer t.cancel()
+	if t.client.IsConnected() {
+		t.client.Disconnect(250)
+	}
+
+	return nil
+}
+
+// onConnect (re)subscribes to the configured topic filters. This is called
+// after the initial connection and whenever the client reconnects.
+func (t *MQTT) onConnect(client mqtt.Client) {
+	if len(t.filters) == 0 {
+		return
+	}
+
+	for filter := range t.filters {
+		t.log.Info("Subscribing", "topic", filter)
+	}
+	token := client.SubscribeMultiple(t.filters, nil)
+	go func() {
+		token.WaitTimeout(30 * time.Second)
+		if err := token.Error(); err != nil {
+			t.log.Error(err, "could not subscribe to MQTT topics")
+		}
+	}()
+}
+
+func (t *MQTT) handler(s *subscription, m mqtt.Message) {
+	input, eventType, err := s.codec.Decode(m.Payload(), s.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode MQTT message", "topic", m.Topic())
+		return
+	}
+
+	bound := s.bind(m.Topic())
+	if len(bound) > 0 {
+		inputMap, ok := input.(map[string]interface{})
+		if !ok {
+			inputMap = map[string]interface{}{
+				"data": input,
+			}
+		}
+		for name, value := range bound {
+			inputMap[name] = value
+		}
+		input = inputMap
+	}
+
+	if s.config.TypeSegment != nil {
+		eventType = bound[*s.config.TypeSegment]
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if s.config.Handler != nil {
+		h = *s.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && s.config.Types != nil {
+		if handler, ok := s.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for MQTT message", "topic", m.Topic(), "type", eventType)
+		return
+	}
+
+	if _, err = t.invoker(t.ctx, h, "", input, transport.BypassAuthorization); err != nil {
+		t.log.Error(err, "error handling MQTT message", "topic", m.Topic(), "handler", h.String())
+	}
+}
+
+// bind returns the values of the named wildcard segments in topic.
+func (s *subscription) bind(topic string) map[string]string {
+	if len(s.segments) == 0 {
+		return nil
+	}
+
+	parts := strings.Split(topic, "/")
+	bound := make(map[string]string, len(s.segments))
+	for _, seg := range s.segments {
+		if seg.index >= len(parts) {
+			continue
+		}
+		if seg.multi {
+			bound[seg.name] = strings.Join(parts[seg.index:], "/")
+		} else {
+			bound[seg.name] = parts[seg.index]
+		}
+	}
+
+	return bound
+}
+
+// parseTopic converts a topic pattern with named wildcard segments into an
+// MQTT topic filter.
+func parseTopic(pattern string) (string, []segment, error) {
+	parts := strings.Split(pattern, "/")
+	var segments []segment
+	for i, part := range parts {
+		if part == "#" && i != len(parts)-1 {
+			return "", nil, fmt.Errorf("invalid topic %q: # must be the last level", pattern)
+		}
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		multi := strings.HasSuffix(name, "...")
+		if multi {
+			if i != len(parts)-1 {
+				return "", nil, fmt.Errorf("invalid topic %q: {%s} must be the last level", pattern, name)
+			}
+			name = strings.TrimSuffix(name, "...")
+			parts[i] = "#"
+		} else {
+			parts[i] = "+"
+		}
+		if name == "" {
+			return "", nil, fmt.Errorf("invalid topic %q: wildcard name is empty", pattern)
+		}
+
+		segments = append(segments, segment{
+			index: i,
+			name:  name,
+			multi: multi,
+		})
+	}
+
+	return strings.Join(parts, "/"), segments, nil
+}
+
+func hasSegment(segments []segment, name string) bool {
+	for _, seg := range segments {
+		if seg.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package mqtt_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	server "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	"github.com/nanobus/nanobus/pkg/transport/mqtt"
+)
+
+type invocation struct {
+	h     handler.Handler
+	input interface{}
+}
+
+type mockInvoker struct {
+	invocations chan invocation
+}
+
+func (m *mockInvoker) Invoke(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+	m.invocations <- invocation{h: h, input: input}
+	return nil, nil
+}
+
+func (m *mockInvoker) Next(t *testing.T) invocation {
+	select {
+	case i := <-m.invocations:
+		return i
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for invocation")
+	}
+	return invocation{}
+}
+
+func startBroker(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	log := zerolog.Nop()
+	s := server.New(&server.Options{Logger: &log})
+	require.NoError(t, s.AddHook(new(auth.AllowHook), nil))
+	require.NoError(t, s.AddListener(listeners.NewTCP("t1", addr, nil)))
+	require.NoError(t, s.Serve())
+	t.Cleanup(func() { s.Close() })
+
+	// Retained messages are delivered when the transport subscribes.
+	require.NoError(t, s.Publish("devices/sensor-1/readings", []byte(`{"value":42}`), true, 1))
+	require.NoError(t, s.Publish("events/created/a/b", []byte(`"payload"`), true, 1))
+
+	return "tcp://" + addr
+}
+
+func TestMQTT(t *testing.T) {
+	broker := startBroker(t)
+	mi := &mockInvoker{
+		invocations: make(chan invocation, 10),
+	}
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+
+	handlerReadings := handler.Handler{
+		Interface: "Devices",
+		Operation: "readings",
+	}
+	handlerCreated := handler.Handler{
+		Interface: "Events",
+		Operation: "created",
+	}
+	typeSegment := "type"
+
+	m, err := mqtt.New(logr.Discard(), mi.Invoke, codecs, &mqtt.MQTTV1Config{
+		Brokers:      []string{broker},
+		CleanSession: true,
+		Subscriptions: []mqtt.Subscription{
+			{
+				Topic:   "devices/{id}/readings",
+				Qos:     1,
+				Codec:   mqtt.CodecRef("json"),
+				Handler: &handlerReadings,
+			},
+			{
+				Topic:       "events/{type}/{path...}",
+				Qos:         1,
+				Codec:       mqtt.CodecRef("json"),
+				TypeSegment: &typeSegment,
+				Types: map[string]handler.Handler{
+					"created": handlerCreated,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, m.Listen())
+	}()
+
+	received := map[handler.Handler]interface{}{}
+	for i := 0; i < 2; i++ {
+		inv := mi.Next(t)
+		received[inv.h] = inv.input
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"id":    "sensor-1",
+		"value": int64(42),
+	}, received[handlerReadings])
+	assert.Equal(t, map[string]interface{}{
+		"data": "payload",
+		"type": "created",
+		"path": "a/b",
+	}, received[handlerCreated])
+
+	require.NoError(t, m.Close())
+	wg.Wait()
+}
+
+func TestMQTTInvalidTopic(t *testing.T) {
+	codecs := codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name        string
+		topic       string
+		typeSegment string
+	}{
+		{name: "multi-level not last", topic: "a/{rest...}/b"},
+		{name: "hash not last", topic: "a/#/b"},
+		{name: "empty name", topic: "a/{}"},
+		{name: "unknown type segment", topic: "a/{id}", typeSegment: "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := mqtt.Subscription{
+				Topic: tt.topic,
+				Codec: mqtt.CodecRef("json"),
+			}
+			if tt.typeSegment != "" {
+				sub.TypeSegment = &tt.typeSegment
+			}
+			_, err := mqtt.New(logr.Discard(), invoker, codecs, &mqtt.MQTTV1Config{
+				Brokers:       []string{"tcp://127.0.0.1:1883"},
+				Subscriptions: []mqtt.Subscription{sub},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
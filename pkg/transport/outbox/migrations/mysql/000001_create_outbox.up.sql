@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  topic VARCHAR(255) NOT NULL,
+  event_key VARCHAR(255) NULL,
+  event_type VARCHAR(255) NULL,
+  payload JSON NOT NULL,
+  metadata JSON NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at BIGINT NOT NULL DEFAULT 0,
+  locked_until BIGINT NOT NULL DEFAULT 0,
+  last_error TEXT NULL,
+  delivered_at TIMESTAMP(6) NULL,
+  failed_at TIMESTAMP(6) NULL,
+  INDEX outbox_event_key (event_key, id)
+);
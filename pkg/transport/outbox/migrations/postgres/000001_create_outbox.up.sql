@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  topic text NOT NULL,
+  event_key text,
+  event_type text,
+  payload jsonb NOT NULL,
+  metadata jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at bigint NOT NULL DEFAULT 0,
+  locked_until bigint NOT NULL DEFAULT 0,
+  last_error text,
+  delivered_at timestamptz,
+  failed_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox (id)
+  WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS outbox_event_key ON outbox (event_key, id)
+  WHERE delivered_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  topic TEXT NOT NULL,
+  event_key TEXT,
+  event_type TEXT,
+  payload TEXT NOT NULL,
+  metadata TEXT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at INTEGER NOT NULL DEFAULT 0,
+  locked_until INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  delivered_at DATETIME,
+  failed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox (id)
+  WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS outbox_event_key ON outbox (event_key, id)
+  WHERE delivered_at IS NULL AND failed_at IS NULL;
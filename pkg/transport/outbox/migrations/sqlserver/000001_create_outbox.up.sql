@@ -0,0 +1,16 @@
+CREATE TABLE outbox (
+  id BIGINT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+  topic NVARCHAR(255) NOT NULL,
+  event_key NVARCHAR(255) NULL,
+  event_type NVARCHAR(255) NULL,
+  payload NVARCHAR(MAX) NOT NULL,
+  metadata NVARCHAR(MAX) NULL,
+  created_at DATETIMEOFFSET NOT NULL DEFAULT SYSDATETIMEOFFSET(),
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at BIGINT NOT NULL DEFAULT 0,
+  locked_until BIGINT NOT NULL DEFAULT 0,
+  last_error NVARCHAR(MAX) NULL,
+  delivered_at DATETIMEOFFSET NULL,
+  failed_at DATETIMEOFFSET NULL,
+  INDEX outbox_event_key (event_key, id)
+);
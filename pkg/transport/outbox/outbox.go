@@ -0,0 +1,346 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/runtime"
+	"github.com/nanobus/nanobus/pkg/transport"
+	"github.com/nanobus/nanobus/pkg/transport/postgres"
+)
+
+type Outbox struct {
+	log           logr.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	store         Store
+	publish       actions.Action
+	topics        map[string]actions.Action
+	connect       postgres.Connector
+	channel       string
+	wake          chan struct{}
+	pollInterval  time.Duration
+	lockTimeout   time.Duration
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	batchSize     int
+	maxAttempts   int
+}
+
+func OutboxV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := OutboxV1Config{
+		Table:     "outbox",
+		BatchSize: 100,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var resources resource.Resources
+	var actionRegistry actions.Registry
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"resource:lookup", &resources,
+		"registry:actions", &actionRegistry,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	r, ok := resources[string(c.Resource)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", c.Resource)
+	}
+	var store Store
+	var connect postgres.Connector
+	switch r := r.(type) {
+	case *pgxpool.Pool:
+		store = NewPostgresStore(r, c.Table)
+		if c.Channel != nil {
+			connect = postgres.PoolConnector(r)
+		}
+	case *sqlx.DB:
+		if c.Channel != nil {
+			return nil, fmt.Errorf("channel requires a Postgres resource but %q is a SQL resource", c.Resource)
+		}
+		store = NewSQLStore(r, c.Table)
+	default:
+		return nil, fmt.Errorf("resource %q is not a Postgres or SQL resource", c.Resource)
+	}
+
+	load := func(component *runtime.Component) (actions.Action, error) {
+		loader, ok := actionRegistry[component.Uses]
+		if !ok {
+			return nil, fmt.Errorf("unregistered action %q", component.Uses)
+		}
+		return loader(ctx, component.With, resolver)
+	}
+	var publish actions.Action
+	if c.Publish != nil {
+		var err error
+		if publish, err = load(c.Publish); err != nil {
+			return nil, err
+		}
+	}
+	topics := make(map[string]actions.Action, len(c.Topics))
+	for topic, component := range c.Topics {
+		component := component
+		action, err := load(&component)
+		if err != nil {
+			return nil, fmt.Errorf("could not load action for topic %q: %w", topic, err)
+		}
+		topics[topic] = action
+	}
+
+	return New(log, store, publish, topics, &c, connect)
+}
+
+// New creates an outbox relay. connect is only used to listen on c.Channel
+// and may be nil.
+func New(log logr.Logger, store Store, publish actions.Action, topics map[string]actions.Action, c *OutboxV1Config, connect postgres.Connector) (*Outbox, error) {
+	if publish == nil && len(topics) == 0 {
+		return nil, errors.New("outbox relay requires publish or topics")
+	}
+
+	pollInterval := time.Second
+	if c.PollInterval != nil {
+		pollInterval = *c.PollInterval
+	}
+	lockTimeout := 30 * time.Second
+	if c.LockTimeout != nil {
+		lockTimeout = *c.LockTimeout
+	}
+	retryDelay := time.Second
+	if c.RetryDelay != nil {
+		retryDelay = *c.RetryDelay
+	}
+	maxRetryDelay := 5 * time.Minute
+	if c.MaxRetryDelay != nil {
+		maxRetryDelay = *c.MaxRetryDelay
+	}
+	batchSize := int(c.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	var channel string
+	if c.Channel != nil {
+		channel = *c.Channel
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Outbox{
+		log:           log,
+		ctx:           ctx,
+		cancel:        cancel,
+		store:         store,
+		publish:       publish,
+		topics:        topics,
+		connect:       connect,
+		channel:       channel,
+		wake:          make(chan struct{}, 1),
+		pollInterval:  pollInterval,
+		lockTimeout:   lockTimeout,
+		retryDelay:    retryDelay,
+		maxRetryDelay: maxRetryDelay,
+		batchSize:     batchSize,
+		maxAttempts:   int(c.MaxAttempts),
+	}, nil
+}
+
+func (t *Outbox) Listen() error {
+	if t.connect != nil && t.channel != "" {
+		go t.listenNotifications()
+	}
+
+	for {
+		t.relay()
+
+		select {
+		case <-t.ctx.Done():
+			return nil
+		case <-t.wake:
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+func (t *Outbox) Close() error {
+	t.cancel()
+	return nil
+}
+
+// relay publishes pending events until there are none left that can make
+// progress. Reading again after events are delivered publishes the next
+// event of each key without waiting for the poll interval.
+func (t *Outbox) relay() {
+	for t.ctx.Err() == nil {
+		events, err := t.store.Pending(t.ctx, time.Now(), t.batchSize)
+		if err != nil {
+			if t.ctx.Err() == nil {
+				t.log.Error(err, "could not read outbox events")
+			}
+			return
+		}
+
+		progressed := false
+		for _, e := range events {
+			if t.ctx.Err() != nil {
+				return
+			}
+			if t.relayEvent(e) {
+				progressed = true
+			}
+		}
+		if !progressed {
+			return
+		}
+	}
+}
+
+// relayEvent locks and publishes an event. It returns true if the event is
+// finished, either delivered or marked as failed.
+func (t *Outbox) relayEvent(e *Event) bool {
+	now := time.Now()
+	locked, err := t.store.Lock(t.ctx, e.ID, now, now.Add(t.lockTimeout))
+	if err != nil {
+		t.log.Error(err, "could not lock outbox event", "id", e.ID)
+		return false
+	}
+	if !locked {
+		return false
+	}
+
+	if err := t.publishEvent(e); err != nil {
+		return t.failed(e, err)
+	}
+
+	if err := t.store.Delivered(t.ctx, e.ID, time.Now()); err != nil {
+		// The lock expires and the event is published again.
+		t.log.Error(err, "could not mark outbox event as delivered", "id", e.ID)
+		return false
+	}
+
+	return true
+}
+
+func (t *Outbox) publishEvent(e *Event) error {
+	action, ok := t.topics[e.Topic]
+	if !ok {
+		action = t.publish
+	}
+	if action == nil {
+		return fmt.Errorf("no action publishes topic %q", e.Topic)
+	}
+
+	var input interface{}
+	if err := json.Unmarshal(e.Payload, &input); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	event, err := e.Data()
+	if err != nil {
+		return err
+	}
+
+	// Publishing must finish before the lock expires so that another
+	// replica does not publish the event at the same time.
+	ctx, cancel := context.WithTimeout(t.ctx, t.lockTimeout)
+	defer cancel()
+	_, err = action(ctx, actions.Data{
+		"input": input,
+		"$":     input,
+		"event": event,
+	})
+	return err
+}
+
+func (t *Outbox) failed(e *Event, cause error) bool {
+	attempts := e.Attempts + 1
+	now := time.Now()
+
+	var failedAt *time.Time
+	if t.maxAttempts > 0 && attempts >= t.maxAttempts {
+		failedAt = &now
+		t.log.Error(cause, "outbox event failed, giving up", "id", e.ID, "topic", e.Topic, "attempts", attempts)
+	} else {
+		t.log.Error(cause, "could not publish outbox event", "id", e.ID, "topic", e.Topic, "attempts", attempts)
+	}
+
+	if err := t.store.Failed(t.ctx, e.ID, attempts, now.Add(t.backoff(attempts)), failedAt, cause.Error()); err != nil {
+		t.log.Error(err, "could not record failed outbox event", "id", e.ID)
+		return false
+	}
+
+	return failedAt != nil
+}
+
+// backoff returns the delay before retrying after a number of attempts.
+func (t *Outbox) backoff(attempts int) time.Duration {
+	delay := t.retryDelay
+	for i := 1; i < attempts && delay < t.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.maxRetryDelay {
+		delay = t.maxRetryDelay
+	}
+	return delay
+}
+
+// listenNotifications wakes the relay when the outbox action notifies the
+// channel. Polling continues while the connection is down.
+func (t *Outbox) listenNotifications() {
+	for {
+		err := t.waitForNotifications()
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		t.log.Error(err, "outbox listener stopped, reconnecting", "delay", t.pollInterval)
+		select {
+		case <-time.After(t.pollInterval):
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *Outbox) waitForNotifications() error {
+	conn, err := t.connect(t.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(t.ctx, "LISTEN "+pgx.Identifier{t.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(t.ctx); err != nil {
+			return err
+		}
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
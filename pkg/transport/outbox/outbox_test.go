@@ -0,0 +1,191 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/actions"
+	"github.com/nanobus/nanobus/pkg/actions/sql"
+	"github.com/nanobus/nanobus/pkg/expr"
+	migrate_sqlite "github.com/nanobus/nanobus/pkg/initialize/sqlite"
+	"github.com/nanobus/nanobus/pkg/transport/outbox"
+)
+
+type published struct {
+	input interface{}
+	event map[string]interface{}
+}
+
+// newDB returns a SQLite database with the outbox table created by its
+// migration.
+func newDB(t *testing.T) *sqlx.DB {
+	dataSource := "file:" + filepath.Join(t.TempDir(), "test.db")
+	sourceURL := outbox.SourceScheme + "://sqlite"
+	migrationsTable := "outbox_migrations"
+	m, err := migrate_sqlite.NewMigrator(&migrate_sqlite.MigrateSQLiteV1Config{
+		Name:            "outbox",
+		DataSource:      dataSource,
+		SourceURL:       &sourceURL,
+		MigrationsTable: &migrationsTable,
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.Up(logr.Discard(), "outbox"))
+	require.NoError(t, m.Close())
+
+	// The relay and the writes share the file, so the resource sets a busy
+	// timeout.
+	r, err := sql.ConnectionLoader(context.Background(), map[string]interface{}{
+		"driver":     "sqlite",
+		"dataSource": dataSource,
+	}, nil)
+	require.NoError(t, err)
+	db := r.(*sqlx.DB)
+	t.Cleanup(func() { db.Close() })
+
+	var timeout int
+	require.NoError(t, db.Get(&timeout, "PRAGMA busy_timeout"))
+	require.Equal(t, int(sql.DefaultBusyTimeout.Milliseconds()), timeout)
+	return db
+}
+
+func write(t *testing.T, db *sqlx.DB, topic string, key interface{}, input interface{}) {
+	var keyExpr expr.ValueExpr
+	require.NoError(t, keyExpr.FromString(`input.key`))
+	var topicExpr expr.ValueExpr
+	require.NoError(t, topicExpr.FromString(`"`+topic+`"`))
+	write := sql.OutboxAction(db, &sql.OutboxConfig{
+		Resource: "db",
+		Table:    "outbox",
+		Topic:    &topicExpr,
+		Key:      &keyExpr,
+	})
+	_, err := write(context.Background(), actions.Data{"input": map[string]interface{}{
+		"key":  key,
+		"data": input,
+	}})
+	require.NoError(t, err)
+}
+
+func relay(t *testing.T, db *sqlx.DB, publish actions.Action, c *outbox.OutboxV1Config) {
+	interval := 10 * time.Millisecond
+	c.Table = "outbox"
+	c.PollInterval = &interval
+	c.RetryDelay = &interval
+	tr, err := outbox.New(logr.Discard(), outbox.NewSQLStore(db, "outbox"), publish, nil, c, nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tr.Listen())
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, tr.Close())
+		wg.Wait()
+	})
+}
+
+func TestOutboxOrdersByKey(t *testing.T) {
+	db := newDB(t)
+	write(t, db, "orders", "order-1", "created")
+	write(t, db, "orders", "order-1", "shipped")
+	write(t, db, "audit", nil, "logged")
+
+	var mu sync.Mutex
+	var calls []published
+	done := make(chan struct{})
+	publish := func(ctx context.Context, data actions.Data) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		event := data["event"].(map[string]interface{})
+		calls = append(calls, published{data["input"], event})
+		// The first attempt of the first event fails.
+		if len(calls) == 1 {
+			return nil, errors.New("broker unavailable")
+		}
+		if len(calls) == 4 {
+			close(done)
+		}
+		return nil, nil
+	}
+	relay(t, db, publish, &outbox.OutboxV1Config{})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for events")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var order []string
+	for _, call := range calls {
+		order = append(order, call.input.(map[string]interface{})["data"].(string))
+	}
+	// "shipped" waits for "created" to be retried and delivered.
+	assert.Equal(t, []string{"created", "logged", "created", "shipped"}, order)
+	assert.Equal(t, map[string]interface{}{
+		"id":       int64(2),
+		"topic":    "orders",
+		"key":      "order-1",
+		"attempts": 0,
+	}, calls[3].event)
+
+	var pending int
+	require.Eventually(t, func() bool {
+		require.NoError(t, db.Get(&pending, `SELECT COUNT(*) FROM outbox WHERE delivered_at IS NULL`))
+		return pending == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestOutboxMaxAttempts(t *testing.T) {
+	db := newDB(t)
+	write(t, db, "orders", "order-1", "created")
+	write(t, db, "orders", "order-1", "shipped")
+
+	delivered := make(chan interface{}, 10)
+	publish := func(ctx context.Context, data actions.Data) (interface{}, error) {
+		input := data["input"].(map[string]interface{})["data"]
+		if input == "created" {
+			return nil, errors.New("rejected")
+		}
+		delivered <- input
+		return nil, nil
+	}
+	relay(t, db, publish, &outbox.OutboxV1Config{MaxAttempts: 2})
+
+	select {
+	case input := <-delivered:
+		assert.Equal(t, "shipped", input)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for events")
+	}
+
+	var row struct {
+		Attempts  int     `db:"attempts"`
+		LastError *string `db:"last_error"`
+		Failed    bool    `db:"failed"`
+	}
+	require.NoError(t, db.Get(&row, `SELECT attempts, last_error, failed_at IS NOT NULL AS failed FROM outbox WHERE id = 1`))
+	assert.Equal(t, 2, row.Attempts)
+	assert.Equal(t, "rejected", *row.LastError)
+	assert.True(t, row.Failed)
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event is an undelivered row of the outbox table.
+type Event struct {
+	ID       int64
+	Topic    string
+	Key      *string
+	Type     *string
+	Payload  []byte
+	Metadata []byte
+	Attempts int
+}
+
+// Data returns the event as the `event` variable passed to the publish
+// action.
+func (e *Event) Data() (map[string]interface{}, error) {
+	data := map[string]interface{}{
+		"id":       e.ID,
+		"topic":    e.Topic,
+		"attempts": e.Attempts,
+	}
+	if e.Key != nil {
+		data["key"] = *e.Key
+	}
+	if e.Type != nil {
+		data["type"] = *e.Type
+	}
+	if len(e.Metadata) > 0 {
+		var metadata interface{}
+		if err := json.Unmarshal(e.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata for outbox event %d: %w", e.ID, err)
+		}
+		data["metadata"] = metadata
+	}
+	return data, nil
+}
+
+// Store reads and updates the outbox table. Times that are compared by the
+// queries (next_attempt_at and locked_until) are stored as Unix milliseconds
+// so that they behave the same in every database.
+type Store interface {
+	// Pending returns up to limit events that are due and not locked in ID
+	// order. An event is skipped while an earlier event with the same key
+	// has not been delivered or marked as failed.
+	Pending(ctx context.Context, now time.Time, limit int) ([]*Event, error)
+	// Lock locks an event until the given time. It returns false if the
+	// event was locked or delivered by another replica.
+	Lock(ctx context.Context, id int64, now, until time.Time) (bool, error)
+	// Delivered marks an event as delivered.
+	Delivered(ctx context.Context, id int64, at time.Time) error
+	// Failed records a failed attempt to publish an event. The event is
+	// retried at next unless failedAt is set.
+	Failed(ctx context.Context, id int64, attempts int, next time.Time, failedAt *time.Time, cause string) error
+}
+
+// queries are the statements used by the stores with `?` placeholders.
+type queries struct {
+	pending   string
+	lock      string
+	delivered string
+	failed    string
+}
+
+func newQueries(table, limit string) queries {
+	return queries{
+		pending: fmt.Sprintf(`SELECT o.id, o.topic, o.event_key, o.event_type, o.payload, o.metadata, o.attempts FROM %[1]s o
+WHERE o.delivered_at IS NULL AND o.failed_at IS NULL AND o.next_attempt_at <= ? AND o.locked_until <= ?
+AND (o.event_key IS NULL OR NOT EXISTS (
+  SELECT 1 FROM %[1]s p WHERE p.event_key = o.event_key AND p.id < o.id AND p.delivered_at IS NULL AND p.failed_at IS NULL
+))
+ORDER BY o.id%[2]s`, table, limit),
+		lock:      fmt.Sprintf(`UPDATE %s SET locked_until = ? WHERE id = ? AND delivered_at IS NULL AND locked_until <= ?`, table),
+		delivered: fmt.Sprintf(`UPDATE %s SET delivered_at = ?, locked_until = 0 WHERE id = ?`, table),
+		failed:    fmt.Sprintf(`UPDATE %s SET attempts = ?, next_attempt_at = ?, failed_at = ?, last_error = ?, locked_until = 0 WHERE id = ?`, table),
+	}
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
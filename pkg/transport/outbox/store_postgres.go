@@ -0,0 +1,77 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package outbox
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresStore struct {
+	pool    *pgxpool.Pool
+	queries queries
+}
+
+// NewPostgresStore returns a Store for the outbox table in a Postgres
+// database.
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	q := newQueries(pgx.Identifier(strings.Split(table, ".")).Sanitize(), " LIMIT ?")
+	return &PostgresStore{
+		pool: pool,
+		queries: queries{
+			pending:   sqlx.Rebind(sqlx.DOLLAR, q.pending),
+			lock:      sqlx.Rebind(sqlx.DOLLAR, q.lock),
+			delivered: sqlx.Rebind(sqlx.DOLLAR, q.delivered),
+			failed:    sqlx.Rebind(sqlx.DOLLAR, q.failed),
+		},
+	}
+}
+
+func (s *PostgresStore) Pending(ctx context.Context, now time.Time, limit int) ([]*Event, error) {
+	rows, err := s.pool.Query(ctx, s.queries.pending, millis(now), millis(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Topic, &e.Key, &e.Type, &e.Payload, &e.Metadata, &e.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, id int64, now, until time.Time) (bool, error) {
+	tag, err := s.pool.Exec(ctx, s.queries.lock, millis(until), id, millis(now))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PostgresStore) Delivered(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.pool.Exec(ctx, s.queries.delivered, at, id)
+	return err
+}
+
+func (s *PostgresStore) Failed(ctx context.Context, id int64, attempts int, next time.Time, failedAt *time.Time, cause string) error {
+	_, err := s.pool.Exec(ctx, s.queries.failed, attempts, millis(next), failedAt, cause, id)
+	return err
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package outbox
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLStore struct {
+	db      *sqlx.DB
+	queries queries
+}
+
+// NewSQLStore returns a Store for the outbox table in a database opened by
+// the sql resource.
+func NewSQLStore(db *sqlx.DB, table string) *SQLStore {
+	quote, limit := `"`, " LIMIT ?"
+	switch db.DriverName() {
+	case "mysql":
+		quote = "`"
+	case "sqlserver", "mssql", "azuresql":
+		limit = " OFFSET 0 ROWS FETCH NEXT ? ROWS ONLY"
+	}
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+	}
+
+	q := newQueries(strings.Join(parts, "."), limit)
+	return &SQLStore{
+		db: db,
+		queries: queries{
+			pending:   db.Rebind(q.pending),
+			lock:      db.Rebind(q.lock),
+			delivered: db.Rebind(q.delivered),
+			failed:    db.Rebind(q.failed),
+		},
+	}
+}
+
+func (s *SQLStore) Pending(ctx context.Context, now time.Time, limit int) ([]*Event, error) {
+	rows, err := s.db.QueryContext(ctx, s.queries.pending, millis(now), millis(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Topic, &e.Key, &e.Type, &e.Payload, &e.Metadata, &e.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *SQLStore) Lock(ctx context.Context, id int64, now, until time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, s.queries.lock, millis(until), id, millis(now))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (s *SQLStore) Delivered(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, s.queries.delivered, at.UTC(), id)
+	return err
+}
+
+func (s *SQLStore) Failed(ctx context.Context, id int64, attempts int, next time.Time, failedAt *time.Time, cause string) error {
+	if failedAt != nil {
+		utc := failedAt.UTC()
+		failedAt = &utc
+	}
+	_, err := s.db.ExecContext(ctx, s.queries.failed, attempts, millis(next), failedAt, cause, id)
+	return err
+}
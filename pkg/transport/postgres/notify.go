@@ -0,0 +1,227 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-logr/logr"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+// Conn is the subset of a Postgres connection used to listen for
+// notifications.
+type Conn interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// Connector opens a dedicated connection for listening.
+type Connector func(ctx context.Context) (Conn, error)
+
+type Notify struct {
+	log               logr.Logger
+	ctx               context.Context
+	cancel            context.CancelFunc
+	connect           Connector
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	invoker           transport.Invoker
+	channels          map[string]*channel
+}
+
+type channel struct {
+	config *Channel
+	codec  codec.Codec
+}
+
+func NotifyV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	var c NotifyV1Config
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var resources resource.Resources
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"resource:lookup", &resources,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	pool, err := resource.Get[*pgxpool.Pool](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(log, transportInvoker, codecs, &c, PoolConnector(pool))
+}
+
+// PoolConnector returns a Connector that opens connections outside of pool
+// using its connection configuration so that listening does not hold one of
+// the pool's connections.
+func PoolConnector(pool *pgxpool.Pool) Connector {
+	return func(ctx context.Context) (Conn, error) {
+		conn, err := pgx.ConnectConfig(ctx, pool.Config().ConnConfig.Copy())
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, c *NotifyV1Config, connect Connector) (*Notify, error) {
+	channels := make(map[string]*channel, len(c.Channels))
+	for i := range c.Channels {
+		ch := &c.Channels[i]
+		codec, ok := codecs[string(ch.Codec)]
+		if !ok {
+			return nil, fmt.Errorf("could not find codec %q", ch.Codec)
+		}
+		if _, exists := channels[ch.Channel]; exists {
+			return nil, fmt.Errorf("channel %q is configured more than once", ch.Channel)
+		}
+		channels[ch.Channel] = &channel{
+			config: ch,
+			codec:  codec,
+		}
+	}
+
+	reconnectDelay := time.Second
+	if c.ReconnectDelay != nil {
+		reconnectDelay = *c.ReconnectDelay
+	}
+	maxReconnectDelay := time.Minute
+	if c.MaxReconnectDelay != nil {
+		maxReconnectDelay = *c.MaxReconnectDelay
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Notify{
+		log:               log,
+		ctx:               ctx,
+		cancel:            cancel,
+		connect:           connect,
+		reconnectDelay:    reconnectDelay,
+		maxReconnectDelay: maxReconnectDelay,
+		invoker:           invoker,
+		channels:          channels,
+	}, nil
+}
+
+func (t *Notify) Listen() error {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = t.reconnectDelay
+	b.MaxInterval = t.maxReconnectDelay
+	b.MaxElapsedTime = 0
+
+	for {
+		err := t.listen(b)
+		if t.ctx.Err() != nil {
+			return nil
+		}
+
+		delay := b.NextBackOff()
+		t.log.Error(err, "Postgres listener stopped, reconnecting", "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-t.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (t *Notify) Close() error {
+	t.cancel()
+	return nil
+}
+
+// listen connects, LISTENs on the configured channels and dispatches
+// notifications until the connection fails or the transport is shut down.
+// Notifications are handled one at a time in the order they were sent.
+func (t *Notify) listen(b backoff.BackOff) error {
+	conn, err := t.connect(t.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	for name := range t.channels {
+		if _, err := conn.Exec(t.ctx, "LISTEN "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return fmt.Errorf("could not listen on channel %q: %w", name, err)
+		}
+		t.log.Info("Listening", "channel", name)
+	}
+	b.Reset()
+
+	for {
+		n, err := conn.WaitForNotification(t.ctx)
+		if err != nil {
+			return err
+		}
+		t.handle(n)
+	}
+}
+
+func (t *Notify) handle(n *pgconn.Notification) {
+	ch, ok := t.channels[n.Channel]
+	if !ok {
+		return
+	}
+
+	input, eventType, err := ch.codec.Decode([]byte(n.Payload), ch.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode Postgres notification", "channel", n.Channel)
+		return
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if ch.config.Handler != nil {
+		h = *ch.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && ch.config.Types != nil {
+		if handler, ok := ch.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for Postgres notification", "channel", n.Channel, "type", eventType)
+		return
+	}
+
+	if _, err = t.invoker(t.ctx, h, "", input, transport.BypassAuthorization); err != nil {
+		t.log.Error(err, "error handling Postgres notification", "channel", n.Channel, "handler", h.String())
+	}
+}
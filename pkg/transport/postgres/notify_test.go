@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	"github.com/nanobus/nanobus/pkg/transport/postgres"
+)
+
+var errConnectionLost = errors.New("connection lost")
+
+type mockConn struct {
+	mu            sync.Mutex
+	statements    []string
+	notifications chan *pgconn.Notification
+	closed        bool
+}
+
+func (m *mockConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = append(m.statements, sql)
+	return pgconn.CommandTag("LISTEN"), nil
+}
+
+func (m *mockConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case n, ok := <-m.notifications:
+		if !ok {
+			return nil, errConnectionLost
+		}
+		return n, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *mockConn) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+type invocation struct {
+	handler handler.Handler
+	input   interface{}
+}
+
+func TestNotify(t *testing.T) {
+	handlerDefault := handler.Handler{
+		Interface: "Orders",
+		Operation: "onEvent",
+	}
+
+	invocations := make(chan invocation, 10)
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		invocations <- invocation{h, input}
+		return nil, nil
+	}
+	next := func() invocation {
+		select {
+		case i := <-invocations:
+			return i
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for invocation")
+		}
+		return invocation{}
+	}
+
+	// The first attempt fails and the first connection is lost after one
+	// notification, so the transport must connect three times.
+	conns := []*mockConn{
+		{notifications: make(chan *pgconn.Notification, 10)},
+		{notifications: make(chan *pgconn.Notification, 10)},
+	}
+	var mu sync.Mutex
+	attempts := 0
+	connect := func(ctx context.Context) (postgres.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return conns[attempts-2], nil
+	}
+
+	delay := time.Millisecond
+	tr, err := postgres.New(logr.Discard(), invoker, codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}, &postgres.NotifyV1Config{
+		Resource:       "db",
+		ReconnectDelay: &delay,
+		Channels: []postgres.Channel{{
+			Channel: "order_events",
+			Codec:   "json",
+			Handler: &handlerDefault,
+		}},
+	}, connect)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tr.Listen())
+	}()
+
+	conns[0].notifications <- &pgconn.Notification{Channel: "order_events", Payload: `{"id":"1"}`}
+	conns[0].notifications <- &pgconn.Notification{Channel: "other", Payload: `{"id":"0"}`}
+	close(conns[0].notifications)
+	conns[1].notifications <- &pgconn.Notification{Channel: "order_events", Payload: `{"id":"2"}`}
+
+	assert.Equal(t, invocation{handlerDefault, map[string]interface{}{"id": "1"}}, next())
+	assert.Equal(t, invocation{handlerDefault, map[string]interface{}{"id": "2"}}, next())
+
+	require.NoError(t, tr.Close())
+	wg.Wait()
+
+	for _, conn := range conns {
+		assert.Equal(t, []string{`LISTEN "order_events"`}, conn.statements)
+		assert.True(t, conn.closed)
+	}
+}
+
+func TestNotifyCodecNotFound(t *testing.T) {
+	_, err := postgres.New(logr.Discard(), nil, codec.Codecs{}, &postgres.NotifyV1Config{
+		Channels: []postgres.Channel{{
+			Channel: "order_events",
+			Codec:   "json",
+		}},
+	}, nil)
+	assert.EqualError(t, err, `could not find codec "json"`)
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/handler"
+	nanopubsub "github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+type PubSub struct {
+	log           logr.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	ps            nanopubsub.PubSub
+	invoker       transport.Invoker
+	subscriptions []*subscription
+}
+
+type subscription struct {
+	config *Subscription
+	codec  codec.Codec
+}
+
+func PubSubV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := PubSubV1Config{
+		Resource: "pubsub",
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var resources resource.Resources
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"resource:lookup", &resources,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	ps, err := resource.Get[nanopubsub.PubSub](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(log, transportInvoker, codecs, &c, ps)
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, c *PubSubV1Config, ps nanopubsub.PubSub) (*PubSub, error) {
+	subscriptions := make([]*subscription, len(c.Subscriptions))
+	for i := range c.Subscriptions {
+		s := &c.Subscriptions[i]
+		if s.Codec == "" {
+			s.Codec = "json"
+		}
+		codec, ok := codecs[string(s.Codec)]
+		if !ok {
+			return nil, fmt.Errorf("could not find codec %q", s.Codec)
+		}
+		subscriptions[i] = &subscription{
+			config: s,
+			codec:  codec,
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &PubSub{
+		log:           log,
+		ctx:           ctx,
+		cancel:        cancel,
+		ps:            ps,
+		invoker:       invoker,
+		subscriptions: subscriptions,
+	}, nil
+}
+
+func (t *PubSub) Listen() error {
+	var wg sync.WaitGroup
+	for _, s := range t.subscriptions {
+		wg.Add(1)
+		go func(s *subscription) {
+			defer wg.Done()
+			t.subscribe(s)
+		}(s)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (t *PubSub) Close() error {
+	t.cancel()
+	return nil
+}
+
+// subscribe subscribes to the topic until the transport is shut down,
+// subscribing again with exponential backoff if the subscription fails.
+func (t *PubSub) subscribe(s *subscription) {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0
+
+	topic := s.config.Topic
+	for {
+		t.log.Info("Subscribing to topic", "topic", topic)
+		err := t.ps.Subscribe(t.ctx, topic, func(ctx context.Context, msg *nanopubsub.Message) error {
+			b.Reset()
+			return t.handle(ctx, s, msg)
+		})
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		delay := b.NextBackOff()
+		t.log.Error(err, "Subscription stopped, retrying", "topic", topic, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// handle invokes the handler of a message. Messages that cannot be decoded
+// or have no handler are dropped by returning nil.
+func (t *PubSub) handle(ctx context.Context, s *subscription, msg *nanopubsub.Message) error {
+	input, eventType, err := s.codec.Decode(msg.Data, s.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode message", "topic", msg.Topic, "id", msg.ID)
+		return nil
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if s.config.Handler != nil {
+		h = *s.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && s.config.Types != nil {
+		if handler, ok := s.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for message", "topic", msg.Topic, "type", eventType)
+		return nil
+	}
+
+	metadata := msg.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	// Continue the trace of the publisher, if any.
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(metadata))
+	ctx = transport.WithVariables(ctx, transport.Variables{
+		"message": map[string]interface{}{
+			"id":    msg.ID,
+			"topic": msg.Topic,
+		},
+		"metadata": metadata,
+	})
+
+	if _, err = t.invoker(ctx, h, "", input, transport.BypassAuthorization); err != nil {
+		t.log.Error(err, "error handling message", "topic", msg.Topic, "id", msg.ID, "handler", h.String())
+		return err
+	}
+
+	return nil
+}
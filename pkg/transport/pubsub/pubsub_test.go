@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	codec_json "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/handler"
+	nanopubsub "github.com/nanobus/nanobus/pkg/pubsub"
+	"github.com/nanobus/nanobus/pkg/transport"
+	transport_pubsub "github.com/nanobus/nanobus/pkg/transport/pubsub"
+)
+
+type invocation struct {
+	handler   string
+	input     interface{}
+	variables transport.Variables
+}
+
+func TestPubSub(t *testing.T) {
+	ctx := context.Background()
+	ps := nanopubsub.NewMemory()
+
+	invocations := make(chan invocation, 10)
+	var failed bool
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		// The first call fails so that the message is delivered again.
+		if !failed {
+			failed = true
+			return nil, errors.New("test")
+		}
+		invocations <- invocation{h.String(), input, transport.VariablesFromContext(ctx)}
+		return nil, nil
+	}
+
+	created := handler.Handler{Interface: "Orders", Operation: "onCreated"}
+	other := handler.Handler{Interface: "Orders", Operation: "onOther"}
+	tr, err := transport_pubsub.New(logr.Discard(), invoker, codec.Codecs{
+		"json": codec_json.NewCodec(),
+	}, &transport_pubsub.PubSubV1Config{
+		Resource: "pubsub",
+		Subscriptions: []transport_pubsub.Subscription{
+			{
+				Topic:   "orders",
+				Handler: &other,
+				Types: map[string]handler.Handler{
+					"order.created": created,
+				},
+			},
+		},
+	}, ps)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tr.Listen())
+	}()
+	defer func() {
+		require.NoError(t, tr.Close())
+		wg.Wait()
+	}()
+
+	// Wait for the subscription before publishing.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, ps.Publish(ctx, "orders", []byte(`{"id":"1"}`), map[string]string{"source": "test"}))
+	require.NoError(t, ps.Publish(ctx, "orders", []byte(`not json`), nil))
+	require.NoError(t, ps.Publish(ctx, "orders", []byte(`{"id":"2"}`), nil))
+
+	inv := receive(t, invocations)
+	assert.Equal(t, other.String(), inv.handler)
+	assert.Equal(t, map[string]interface{}{"id": "1"}, inv.input)
+	assert.Equal(t, map[string]string{"source": "test"}, inv.variables["metadata"])
+	message := inv.variables["message"].(map[string]interface{})
+	assert.Equal(t, "orders", message["topic"])
+	assert.NotEmpty(t, message["id"])
+
+	// Messages that cannot be decoded are dropped.
+	inv = receive(t, invocations)
+	assert.Equal(t, map[string]interface{}{"id": "2"}, inv.input)
+	assert.Equal(t, map[string]string{}, inv.variables["metadata"])
+}
+
+func TestNew(t *testing.T) {
+	_, err := transport_pubsub.New(logr.Discard(), nil, codec.Codecs{}, &transport_pubsub.PubSubV1Config{
+		Subscriptions: []transport_pubsub.Subscription{{Topic: "orders", Codec: "avro"}},
+	}, nanopubsub.NewMemory())
+	assert.EqualError(t, err, `could not find codec "avro"`)
+}
+
+func receive(t *testing.T, invocations chan invocation) invocation {
+	t.Helper()
+	select {
+	case inv := <-invocations:
+		return inv
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not invoked")
+	}
+	return invocation{}
+}
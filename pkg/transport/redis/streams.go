@@ -0,0 +1,451 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-logr/logr"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	"github.com/nanobus/nanobus/pkg/config"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
+	"github.com/nanobus/nanobus/pkg/transport"
+)
+
+// Fields added to entries moved to a dead-letter stream.
+const (
+	FieldOriginalStream = "x-original-stream"
+	FieldOriginalID     = "x-original-id"
+	FieldDeliveryCount  = "x-delivery-count"
+	FieldError          = "x-error"
+)
+
+type Streams struct {
+	log           logr.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	client        redis.UniversalClient
+	invoker       transport.Invoker
+	group         string
+	consumer      string
+	startID       string
+	batchSize     int64
+	block         time.Duration
+	minIdleTime   time.Duration
+	claimInterval time.Duration
+	streams       map[string]*stream
+	// names are the stream names in configuration order.
+	names []string
+}
+
+type stream struct {
+	config *Stream
+	codec  codec.Codec
+}
+
+func StreamsV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
+	c := StreamsV1Config{
+		StartID:   "$",
+		BatchSize: 10,
+	}
+	if err := config.Decode(with, &c); err != nil {
+		return nil, err
+	}
+
+	var transportInvoker transport.Invoker
+	var codecs codec.Codecs
+	var resources resource.Resources
+	var log logr.Logger
+	if err := resolve.Resolve(resolver,
+		"transport:invoker", &transportInvoker,
+		"codec:lookup", &codecs,
+		"resource:lookup", &resources,
+		"system:logger", &log); err != nil {
+		return nil, err
+	}
+
+	client, err := resource.Get[redis.UniversalClient](resources, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(log, transportInvoker, codecs, &c, client)
+}
+
+func New(log logr.Logger, invoker transport.Invoker, codecs codec.Codecs, c *StreamsV1Config, client redis.UniversalClient) (*Streams, error) {
+	streams := make(map[string]*stream, len(c.Streams))
+	names := make([]string, 0, len(c.Streams))
+	for i := range c.Streams {
+		s := &c.Streams[i]
+		codec, ok := codecs[string(s.Codec)]
+		if !ok {
+			return nil, fmt.Errorf("could not find codec %q", s.Codec)
+		}
+		if _, exists := streams[s.Stream]; exists {
+			return nil, fmt.Errorf("stream %q is configured more than once", s.Stream)
+		}
+		if s.Field == "" {
+			s.Field = "data"
+		}
+		streams[s.Stream] = &stream{
+			config: s,
+			codec:  codec,
+		}
+		names = append(names, s.Stream)
+	}
+
+	consumer := ""
+	if c.Consumer != nil {
+		consumer = *c.Consumer
+	} else {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine consumer name: %w", err)
+		}
+		consumer = hostname
+	}
+
+	startID := c.StartID
+	if startID == "" {
+		startID = "$"
+	}
+	batchSize := int64(c.BatchSize)
+	if batchSize < 1 {
+		batchSize = 10
+	}
+	block := 5 * time.Second
+	if c.Block != nil && *c.Block >= time.Millisecond {
+		block = *c.Block
+	}
+	minIdleTime := time.Minute
+	if c.MinIdleTime != nil {
+		minIdleTime = *c.MinIdleTime
+	}
+	claimInterval := 30 * time.Second
+	if c.ClaimInterval != nil {
+		claimInterval = *c.ClaimInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Streams{
+		log:           log,
+		ctx:           ctx,
+		cancel:        cancel,
+		client:        client,
+		invoker:       invoker,
+		group:         c.Group,
+		consumer:      consumer,
+		startID:       startID,
+		batchSize:     batchSize,
+		block:         block,
+		minIdleTime:   minIdleTime,
+		claimInterval: claimInterval,
+		streams:       streams,
+		names:         names,
+	}, nil
+}
+
+func (t *Streams) Listen() error {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0
+
+	for {
+		err := t.read(b)
+		if t.ctx.Err() != nil {
+			return nil
+		}
+
+		delay := b.NextBackOff()
+		t.log.Error(err, "Redis streams consumer stopped, retrying", "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-t.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (t *Streams) Close() error {
+	t.cancel()
+	return nil
+}
+
+// read creates the consumer groups and reads entries until a command fails
+// or the transport is shut down. Entries are handled one at a time in the
+// order they were read.
+func (t *Streams) read(b backoff.BackOff) error {
+	for _, name := range t.names {
+		err := t.client.XGroupCreateMkStream(t.ctx, name, t.group, t.startID).Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("could not create consumer group for stream %q: %w", name, err)
+		}
+	}
+	t.log.Info("Reading streams", "streams", t.names, "group", t.group, "consumer", t.consumer)
+	b.Reset()
+
+	args := redis.XReadGroupArgs{
+		Group:    t.group,
+		Consumer: t.consumer,
+		Streams:  make([]string, 0, len(t.names)*2),
+		Count:    t.batchSize,
+		Block:    t.block,
+	}
+	args.Streams = append(args.Streams, t.names...)
+	for range t.names {
+		args.Streams = append(args.Streams, ">")
+	}
+
+	var lastClaim time.Time
+	for {
+		if time.Since(lastClaim) >= t.claimInterval {
+			if err := t.claim(); err != nil {
+				return err
+			}
+			lastClaim = time.Now()
+		}
+
+		streams, err := t.client.XReadGroup(t.ctx, &args).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) && t.ctx.Err() == nil {
+				continue
+			}
+			return err
+		}
+
+		for _, xs := range streams {
+			s, ok := t.streams[xs.Stream]
+			if !ok {
+				continue
+			}
+			for _, msg := range xs.Messages {
+				t.process(s, msg, 1)
+			}
+		}
+	}
+}
+
+// claim takes ownership of entries that were not acknowledged within
+// minIdleTime, for example because their handler failed or their consumer
+// stopped, and handles them again.
+func (t *Streams) claim() error {
+	for _, name := range t.names {
+		s := t.streams[name]
+		start := "0-0"
+		for t.ctx.Err() == nil {
+			msgs, next, err := t.autoClaim(name, start)
+			if err != nil {
+				return fmt.Errorf("could not claim entries of stream %q: %w", name, err)
+			}
+
+			var deliveries map[string]int64
+			if s.config.MaxDeliveries != nil && len(msgs) > 0 {
+				if deliveries, err = t.deliveries(name, msgs); err != nil {
+					return fmt.Errorf("could not read pending entries of stream %q: %w", name, err)
+				}
+			}
+
+			for _, msg := range msgs {
+				t.process(s, msg, deliveries[msg.ID])
+			}
+
+			if next == "0-0" || len(msgs) == 0 {
+				break
+			}
+			start = next
+		}
+	}
+
+	return nil
+}
+
+// autoClaim sends XAUTOCLAIM and parses the reply itself because Redis 7
+// adds a third element, the IDs of deleted entries, that the client does
+// not expect.
+func (t *Streams) autoClaim(name, start string) ([]redis.XMessage, string, error) {
+	reply, err := t.client.Do(t.ctx, "xautoclaim", name, t.group, t.consumer,
+		t.minIdleTime.Milliseconds(), start, "count", t.batchSize).Slice()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(reply) < 2 {
+		return nil, "", fmt.Errorf("unexpected XAUTOCLAIM reply with %d elements", len(reply))
+	}
+	next, _ := reply[0].(string)
+	entries, _ := reply[1].([]interface{})
+
+	msgs := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		// Entries deleted while pending are nil in Redis 6.2.
+		pair, ok := entry.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		id, _ := pair[0].(string)
+		kvs, _ := pair[1].([]interface{})
+		values := make(map[string]interface{}, len(kvs)/2)
+		for i := 0; i+1 < len(kvs); i += 2 {
+			if k, ok := kvs[i].(string); ok {
+				values[k] = kvs[i+1]
+			}
+		}
+		msgs = append(msgs, redis.XMessage{ID: id, Values: values})
+	}
+
+	return msgs, next, nil
+}
+
+// deliveries returns the number of times each claimed entry was delivered.
+func (t *Streams) deliveries(name string, msgs []redis.XMessage) (map[string]int64, error) {
+	cmds := make([]*redis.XPendingExtCmd, len(msgs))
+	if _, err := t.client.Pipelined(t.ctx, func(pipe redis.Pipeliner) error {
+		for i, msg := range msgs {
+			cmds[i] = pipe.XPendingExt(t.ctx, &redis.XPendingExtArgs{
+				Stream:   name,
+				Group:    t.group,
+				Start:    msg.ID,
+				End:      msg.ID,
+				Count:    1,
+				Consumer: t.consumer,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	deliveries := make(map[string]int64, len(msgs))
+	for _, cmd := range cmds {
+		for _, p := range cmd.Val() {
+			deliveries[p.ID] = p.RetryCount
+		}
+	}
+	return deliveries, nil
+}
+
+// process handles an entry and acknowledges it if the handler succeeds.
+// Failed entries stay pending and are claimed again after minIdleTime.
+func (t *Streams) process(s *stream, msg redis.XMessage, deliveries int64) {
+	name := s.config.Stream
+	if s.config.MaxDeliveries != nil && deliveries > int64(*s.config.MaxDeliveries) {
+		t.log.Info("Redis stream entry exceeded its deliveries", "stream", name, "id", msg.ID, "deliveries", deliveries)
+		t.deadLetter(s, msg, deliveries, nil)
+		return
+	}
+
+	fields := make(map[string]string, len(msg.Values))
+	for k, v := range msg.Values {
+		if str, ok := v.(string); ok {
+			fields[k] = str
+		}
+	}
+
+	payload, ok := fields[s.config.Field]
+	if !ok {
+		err := fmt.Errorf("entry does not have field %q", s.config.Field)
+		t.log.Error(err, "could not decode Redis stream entry", "stream", name, "id", msg.ID)
+		t.deadLetter(s, msg, deliveries, err)
+		return
+	}
+
+	input, eventType, err := s.codec.Decode([]byte(payload), s.config.CodecArgs...)
+	if err != nil {
+		t.log.Error(err, "could not decode Redis stream entry", "stream", name, "id", msg.ID)
+		t.deadLetter(s, msg, deliveries, err)
+		return
+	}
+
+	if s.config.TypeField != nil {
+		eventType = fields[*s.config.TypeField]
+	}
+
+	var h handler.Handler
+	var handlerFound bool
+
+	// Default handler
+	if s.config.Handler != nil {
+		h = *s.config.Handler
+		handlerFound = true
+	}
+
+	// Handler for event type
+	if eventType != "" && s.config.Types != nil {
+		if handler, ok := s.config.Types[eventType]; ok {
+			h = handler
+			handlerFound = true
+		}
+	}
+
+	if !handlerFound {
+		t.log.Info("No handler found for Redis stream entry", "stream", name, "type", eventType)
+		t.ack(name, msg.ID)
+		return
+	}
+
+	// Continue the trace of the producer, if any.
+	ctx := otel.GetTextMapPropagator().Extract(t.ctx, propagation.MapCarrier(fields))
+
+	if _, err = t.invoker(ctx, h, "", input, transport.BypassAuthorization); err != nil {
+		t.log.Error(err, "error handling Redis stream entry", "stream", name, "id", msg.ID, "handler", h.String())
+		return
+	}
+
+	t.ack(name, msg.ID)
+}
+
+// deadLetter adds a copy of msg to the dead-letter stream, if any, and
+// acknowledges it. The entry stays pending if it could not be copied.
+func (t *Streams) deadLetter(s *stream, msg redis.XMessage, deliveries int64, cause error) {
+	name := s.config.Stream
+	if s.config.DeadLetterStream != nil {
+		values := make(map[string]interface{}, len(msg.Values)+4)
+		for k, v := range msg.Values {
+			values[k] = v
+		}
+		values[FieldOriginalStream] = name
+		values[FieldOriginalID] = msg.ID
+		values[FieldDeliveryCount] = strconv.FormatInt(deliveries, 10)
+		if cause != nil {
+			values[FieldError] = cause.Error()
+		}
+
+		if err := t.client.XAdd(t.ctx, &redis.XAddArgs{
+			Stream: *s.config.DeadLetterStream,
+			Values: values,
+		}).Err(); err != nil {
+			t.log.Error(err, "could not add Redis stream entry to dead-letter stream", "stream", name, "id", msg.ID)
+			return
+		}
+	}
+
+	t.ack(name, msg.ID)
+}
+
+func (t *Streams) ack(name, id string) {
+	// Acknowledge even while shutting down so handled entries are not
+	// redelivered.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := t.client.XAck(ctx, name, t.group, id).Err(); err != nil {
+		t.log.Error(err, "could not acknowledge Redis stream entry", "stream", name, "id", id)
+	}
+}
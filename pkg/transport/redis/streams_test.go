@@ -0,0 +1,188 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanobus/nanobus/pkg/codec"
+	json_codec "github.com/nanobus/nanobus/pkg/codec/json"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	transport_redis "github.com/nanobus/nanobus/pkg/transport/redis"
+)
+
+type invocation struct {
+	handler string
+	input   interface{}
+}
+
+func newClient(t *testing.T, streams ...string) *redis.Client {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, client.Del(context.Background(), streams...).Err())
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func listen(t *testing.T, client *redis.Client, invoker transport.Invoker, c *transport_redis.StreamsV1Config) {
+	interval := 10 * time.Millisecond
+	consumer := "test"
+	c.Group = "test"
+	c.Consumer = &consumer
+	c.StartID = "0"
+	c.BatchSize = 10
+	c.Block = &interval
+	c.MinIdleTime = &interval
+	c.ClaimInterval = &interval
+	codecs := codec.Codecs{
+		"json": json_codec.NewCodec(),
+	}
+	tr, err := transport_redis.New(logr.Discard(), invoker, codecs, c, client)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tr.Listen())
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, tr.Close())
+		wg.Wait()
+	})
+}
+
+func add(t *testing.T, client *redis.Client, stream string, values ...interface{}) {
+	require.NoError(t, client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}).Err())
+}
+
+func TestStreams(t *testing.T) {
+	client := newClient(t, "test.orders")
+	add(t, client, "test.orders", "type", "order.created", "data", `{"id":1}`)
+	add(t, client, "test.orders", "type", "order.cancelled", "data", `{"id":2}`)
+
+	var mu sync.Mutex
+	var calls []invocation
+	done := make(chan struct{})
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, invocation{h.String(), input})
+		// The first attempt of the first entry fails.
+		if len(calls) == 1 {
+			return nil, errors.New("service unavailable")
+		}
+		if len(calls) == 3 {
+			close(done)
+		}
+		return nil, nil
+	}
+	typeField := "type"
+	listen(t, client, invoker, &transport_redis.StreamsV1Config{
+		Streams: []transport_redis.Stream{{
+			Stream:    "test.orders",
+			Codec:     "json",
+			TypeField: &typeField,
+			Types: map[string]handler.Handler{
+				"order.created":   {Interface: "Orders", Operation: "onCreated"},
+				"order.cancelled": {Interface: "Orders", Operation: "onCancelled"},
+			},
+		}},
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for entries")
+	}
+
+	mu.Lock()
+	assert.Equal(t, []invocation{
+		{"Orders::onCreated", map[string]interface{}{"id": int64(1)}},
+		{"Orders::onCancelled", map[string]interface{}{"id": int64(2)}},
+		{"Orders::onCreated", map[string]interface{}{"id": int64(1)}},
+	}, calls)
+	mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		pending, err := client.XPending(context.Background(), "test.orders", "test").Result()
+		require.NoError(t, err)
+		return pending.Count == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamsDeadLetter(t *testing.T) {
+	client := newClient(t, "test.jobs", "test.jobs.dlq")
+	add(t, client, "test.jobs", "data", `{"id":1}`)
+	add(t, client, "test.jobs", "data", `not json`)
+
+	var mu sync.Mutex
+	deliveries := 0
+	invoker := func(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries++
+		return nil, fmt.Errorf("attempt %d failed", deliveries)
+	}
+	maxDeliveries := uint32(2)
+	deadLetterStream := "test.jobs.dlq"
+	listen(t, client, invoker, &transport_redis.StreamsV1Config{
+		Streams: []transport_redis.Stream{{
+			Stream:           "test.jobs",
+			Codec:            "json",
+			Handler:          &handler.Handler{Interface: "Jobs", Operation: "run"},
+			MaxDeliveries:    &maxDeliveries,
+			DeadLetterStream: &deadLetterStream,
+		}},
+	})
+
+	var dead []redis.XMessage
+	require.Eventually(t, func() bool {
+		var err error
+		dead, err = client.XRange(context.Background(), deadLetterStream, "-", "+").Result()
+		require.NoError(t, err)
+		return len(dead) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, 2, deliveries)
+	mu.Unlock()
+
+	// The undecodable entry is moved on its first delivery.
+	assert.Equal(t, "not json", dead[0].Values["data"])
+	assert.Equal(t, "test.jobs", dead[0].Values[transport_redis.FieldOriginalStream])
+	assert.Equal(t, "1", dead[0].Values[transport_redis.FieldDeliveryCount])
+	assert.Contains(t, dead[0].Values, transport_redis.FieldError)
+
+	assert.Equal(t, `{"id":1}`, dead[1].Values["data"])
+	assert.Equal(t, "3", dead[1].Values[transport_redis.FieldDeliveryCount])
+
+	pending, err := client.XPending(context.Background(), "test.jobs", "test").Result()
+	require.NoError(t, err)
+	assert.Zero(t, pending.Count)
+}
@@ -3,12 +3,26 @@
 package time
 
 import (
+	"time"
+
+	"github.com/nanobus/nanobus/pkg/expr"
 	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/resource"
 	"github.com/nanobus/nanobus/pkg/transport"
 )
 
+// This transport invokes handlers on cron schedules. When a `store` is
+// configured, each run is claimed by a single replica and the last run of each
+// schedule is persisted so that missed runs can be caught up after downtime.
+//
+// The Postgres table of runs, nanobus_schedules, is created by a migration
+// initializer with the source URL `schedules://postgres`. Use a separate migrations table so that
+// these migrations do not interfere with the application's.
 type SchedulerV1Config struct {
 	Schedules []Schedule `json:"schedules" yaml:"schedules" msgpack:"schedules" mapstructure:"schedules" validate:"dive"`
+	// Claims runs and persists the last run of each schedule. Defaults to memory,
+	// which is only suitable for a single replica.
+	Store *Store `json:"store,omitempty" yaml:"store,omitempty" msgpack:"store,omitempty" mapstructure:"store"`
 }
 
 func SchedulerV1() (string, transport.Loader) {
@@ -16,7 +30,37 @@ func SchedulerV1() (string, transport.Loader) {
 }
 
 type Schedule struct {
-	Schedule string          `json:"schedule" yaml:"schedule" msgpack:"schedule" mapstructure:"schedule" validate:"required"`
+	// Uniquely identifies the schedule in the store. Defaults to the handler and
+	// schedule.
+	Name *string `json:"name,omitempty" yaml:"name,omitempty" msgpack:"name,omitempty" mapstructure:"name"`
+	// A cron expression with 5 fields, or 6 fields when the first is seconds.
+	// Descriptors such as `@hourly` and `@every 1m` are also supported.
+	Schedule string `json:"schedule" yaml:"schedule" msgpack:"schedule" mapstructure:"schedule" validate:"required"`
+	// The IANA time zone (e.g. America/New_York) the schedule is evaluated in.
+	// Defaults to UTC.
+	Timezone *string         `json:"timezone,omitempty" yaml:"timezone,omitempty" msgpack:"timezone,omitempty" mapstructure:"timezone"`
 	Handler  handler.Handler `json:"handler" yaml:"handler" msgpack:"handler" mapstructure:"handler" validate:"required"`
-	Repeat   uint64          `json:"repeat" yaml:"repeat" msgpack:"repeat" mapstructure:"repeat"`
+	// The ID of the entity that handles the runs when the handler is an actor. Runs
+	// are not routed to an entity by default.
+	EntityID *string `json:"entityId,omitempty" yaml:"entityId,omitempty" msgpack:"entityId,omitempty" mapstructure:"entityId"`
+	// The handler input. The variables `schedule` (the name) and `time` (the
+	// scheduled time) are available. Defaults to an empty object.
+	Input  *expr.DataExpr `json:"input,omitempty" yaml:"input,omitempty" msgpack:"input,omitempty" mapstructure:"input"`
+	Repeat uint64         `json:"repeat" yaml:"repeat" msgpack:"repeat" mapstructure:"repeat"`
+	// Delays each run by a random duration up to this value.
+	Jitter *time.Duration `json:"jitter,omitempty" yaml:"jitter,omitempty" msgpack:"jitter,omitempty" mapstructure:"jitter"`
+	// What to do when a run is due while the previous one is still running (allow,
+	// skip or queue). Defaults to allow.
+	Overlap *string `json:"overlap,omitempty" yaml:"overlap,omitempty" msgpack:"overlap,omitempty" mapstructure:"overlap" validate:"omitempty,oneof=allow skip queue"`
+	// Which runs missed since the last persisted run are replayed on startup (none,
+	// last or all). Defaults to none.
+	CatchUp *string `json:"catchUp,omitempty" yaml:"catchUp,omitempty" msgpack:"catchUp,omitempty" mapstructure:"catchUp" validate:"omitempty,oneof=none last all"`
+}
+
+type Store struct {
+	// A redis or Postgres resource.
+	Resource resource.Ref `json:"resource" yaml:"resource" msgpack:"resource" mapstructure:"resource" validate:"required"`
+	// The prefix of the redis keys used to persist runs. Defaults to
+	// nanobus:schedules:.
+	KeyPrefix *string `json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty" msgpack:"keyPrefix,omitempty" mapstructure:"keyPrefix"`
 }
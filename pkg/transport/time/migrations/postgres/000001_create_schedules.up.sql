@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS nanobus_schedules (
+  name text PRIMARY KEY,
+  last_run timestamptz NOT NULL
+);
@@ -10,38 +10,74 @@ package time
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/go-co-op/gocron"
 	"github.com/go-logr/logr"
-	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/nanobus/nanobus/pkg/config"
 	"github.com/nanobus/nanobus/pkg/resolve"
+	"github.com/nanobus/nanobus/pkg/resource"
 	"github.com/nanobus/nanobus/pkg/transport"
 )
 
+const (
+	overlapAllow = "allow"
+	overlapSkip  = "skip"
+	overlapQueue = "queue"
+
+	catchUpNone = "none"
+	catchUpLast = "last"
+	catchUpAll  = "all"
+
+	// maxCatchUpRuns bounds the runs replayed by the "all" catch-up policy.
+	maxCatchUpRuns = 1000
+	// queueSize bounds the runs waiting behind a running one with the "queue"
+	// overlap policy.
+	queueSize = 100
+)
+
+var parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 type Scheduler struct {
-	id          string
-	ctx         context.Context
-	log         logr.Logger
-	tracer      trace.Tracer
-	daemon      *gocron.Scheduler
-	lastruntime time.Time
-	numruns     int
-	invoker     transport.Invoker
-	schedules   []Schedule
+	ctx       context.Context
+	cancel    context.CancelFunc
+	log       logr.Logger
+	tracer    trace.Tracer
+	invoker   transport.Invoker
+	schedules []Schedule
+	store     RunStore
+	wg        sync.WaitGroup
+}
+
+type job struct {
+	s        *Scheduler
+	name     string
+	sched    Schedule
+	cron     cron.Schedule
+	location *time.Location
+	overlap  string
+	catchUp  string
+	runs     uint64
+	running  atomic.Bool
+	queue    chan time.Time
 }
 
 func SchedulerV1Loader(ctx context.Context, with interface{}, resolver resolve.ResolveAs) (transport.Transport, error) {
 	var log logr.Logger
 	var tracer trace.Tracer
 	var transportInvoker transport.Invoker
+	var resources resource.Resources
 	if err := resolve.Resolve(resolver,
 		"transport:invoker", &transportInvoker,
 		"system:logger", &log,
 		"system:tracer", &tracer,
+		"resource:lookup", &resources,
 	); err != nil {
 		return nil, err
 	}
@@ -52,71 +88,268 @@ func SchedulerV1Loader(ctx context.Context, with interface{}, resolver resolve.R
 		return nil, err
 	}
 
-	return NewScheduler(ctx, log, tracer, transportInvoker, c)
+	var store RunStore
+	if c.Store != nil {
+		var err error
+		if store, err = NewRunStore(ctx, resources, c.Store); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewScheduler(ctx, log, tracer, transportInvoker, c, store)
 }
 
-func NewScheduler(ctx context.Context, log logr.Logger, tracer trace.Tracer, transportInvoker transport.Invoker, config SchedulerV1Config) (*Scheduler, error) {
+// NewScheduler creates a scheduler for the schedules in config. When store is
+// nil, runs are tracked in memory.
+func NewScheduler(ctx context.Context, log logr.Logger, tracer trace.Tracer, transportInvoker transport.Invoker, config SchedulerV1Config, store RunStore) (*Scheduler, error) {
+	if store == nil {
+		store = NewMemoryRunStore()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
 	return &Scheduler{
-		id:          uuid.New().String(),
-		ctx:         ctx,
-		log:         log,
-		tracer:      tracer,
-		daemon:      nil,
-		lastruntime: time.Time{},
-		numruns:     0,
-		invoker:     transportInvoker,
-		schedules:   config.Schedules,
+		ctx:       ctx,
+		cancel:    cancel,
+		log:       log,
+		tracer:    tracer,
+		invoker:   transportInvoker,
+		schedules: config.Schedules,
+		store:     store,
 	}, nil
 }
 
 func (t *Scheduler) Listen() error {
-	input := map[string]interface{}{}
-	s := gocron.NewScheduler(time.UTC)
-
-	for _, sched := range t.schedules {
-		if err := func(sched Schedule) error {
-			t.log.Info("Scheduling", "schedule", sched.Schedule, "handler", sched.Handler)
-
-			var err error
-			if sched.Repeat > 0 {
-				_, err = s.Cron(sched.Schedule).LimitRunsTo(int(sched.Repeat)).Do(func() {
-					_, err := t.invoker(t.ctx, sched.Handler, t.id, input, transport.BypassAuthorization)
-					if err != nil {
-						t.log.Error(err, "Error in %q", sched.Handler)
-					}
-				})
-			} else {
-				_, err = s.Cron(sched.Schedule).Do(func() {
-					_, err := t.invoker(t.ctx, sched.Handler, t.id, input, transport.BypassAuthorization)
-					if err != nil {
-						t.log.Error(err, "Error in %q", sched.Handler)
-					}
-				})
-			}
-			if err != nil {
-				t.log.Error(err, "Could not schedule", "schedule", sched.Schedule)
-				return err
-			}
-
-			return nil
-		}(sched); err != nil {
+	jobs := make([]*job, len(t.schedules))
+	for i, sched := range t.schedules {
+		j, err := t.newJob(sched)
+		if err != nil {
+			t.log.Error(err, "Could not schedule", "schedule", sched.Schedule)
 			return err
 		}
+		jobs[i] = j
 	}
 
-	t.daemon = s
+	for _, j := range jobs {
+		t.log.Info("Scheduling", "name", j.name, "schedule", j.sched.Schedule, "handler", j.sched.Handler)
+		if j.queue != nil {
+			t.wg.Add(1)
+			go j.work()
+		}
+		t.wg.Add(1)
+		go j.loop()
+	}
 
 	t.log.Info("Schedule Deamon Started")
-	s.StartBlocking()
+	<-t.ctx.Done()
+	t.wg.Wait()
 
 	return nil
 }
 
 func (t *Scheduler) Close() (err error) {
-	if t.daemon != nil {
-		t.daemon.Stop()
-		t.daemon = nil
-	}
+	t.cancel()
 
 	return nil
 }
+
+func (t *Scheduler) newJob(sched Schedule) (*job, error) {
+	location := time.UTC
+	if sched.Timezone != nil {
+		var err error
+		if location, err = time.LoadLocation(*sched.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", *sched.Timezone, err)
+		}
+	}
+
+	schedule, err := parser.Parse(sched.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", sched.Schedule, err)
+	}
+
+	j := job{
+		s:        t,
+		name:     sched.Handler.String() + " " + sched.Schedule,
+		sched:    sched,
+		cron:     schedule,
+		location: location,
+		overlap:  overlapAllow,
+		catchUp:  catchUpNone,
+	}
+	if sched.Name != nil {
+		j.name = *sched.Name
+	}
+	if sched.Overlap != nil {
+		j.overlap = *sched.Overlap
+	}
+	if sched.CatchUp != nil {
+		j.catchUp = *sched.CatchUp
+	}
+	if j.overlap == overlapQueue {
+		j.queue = make(chan time.Time, queueSize)
+	}
+
+	return &j, nil
+}
+
+// loop fires the job at each scheduled time until the scheduler is closed or
+// the job has repeated the configured number of times.
+func (j *job) loop() {
+	defer j.s.wg.Done()
+	ctx := j.s.ctx
+
+	if !j.replay() {
+		return
+	}
+
+	next := j.cron.Next(time.Now().In(j.location))
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !j.fire(next) {
+			return
+		}
+		next = j.cron.Next(time.Now().In(j.location))
+	}
+}
+
+// replay fires the runs missed since the last persisted run according to the
+// catch-up policy.
+func (j *job) replay() bool {
+	if j.catchUp == catchUpNone {
+		return true
+	}
+
+	last, ok, err := j.s.store.LastRun(j.s.ctx, j.name)
+	if err != nil {
+		j.s.log.Error(err, "Could not load the last run", "name", j.name)
+		return true
+	}
+	if !ok {
+		return true
+	}
+
+	now := time.Now()
+	var missed []time.Time
+	for at := j.cron.Next(last.In(j.location)); at.Before(now); at = j.cron.Next(at) {
+		if j.catchUp == catchUpLast {
+			missed = append(missed[:0], at)
+			continue
+		}
+		if len(missed) == maxCatchUpRuns {
+			// Keep the most recent runs.
+			missed = append(missed[1:], at)
+			continue
+		}
+		missed = append(missed, at)
+	}
+
+	if len(missed) > 0 {
+		j.s.log.Info("Catching up missed runs", "name", j.name, "count", len(missed))
+	}
+	for _, at := range missed {
+		if !j.fire(at) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fire claims the run at the scheduled time and dispatches it according to the
+// overlap policy. It returns false once the job should no longer run.
+func (j *job) fire(at time.Time) bool {
+	if j.overlap == overlapSkip && j.running.Load() {
+		j.s.log.Info("Skipping run because the previous run is still running", "name", j.name, "time", at)
+		return true
+	}
+
+	claimed, err := j.s.store.Claim(j.s.ctx, j.name, at)
+	if err != nil {
+		j.s.log.Error(err, "Could not claim run", "name", j.name, "time", at)
+		return true
+	}
+	if !claimed {
+		// Another replica owns this run.
+		return true
+	}
+	j.runs++
+
+	switch j.overlap {
+	case overlapQueue:
+		select {
+		case j.queue <- at:
+		default:
+			j.s.log.Info("Dropping run because the queue is full", "name", j.name, "time", at)
+		}
+	case overlapSkip:
+		j.running.Store(true)
+		j.s.wg.Add(1)
+		go func() {
+			defer j.s.wg.Done()
+			defer j.running.Store(false)
+			j.execute(at)
+		}()
+	default:
+		j.s.wg.Add(1)
+		go func() {
+			defer j.s.wg.Done()
+			j.execute(at)
+		}()
+	}
+
+	return j.sched.Repeat == 0 || j.runs < j.sched.Repeat
+}
+
+// work executes queued runs one at a time.
+func (j *job) work() {
+	defer j.s.wg.Done()
+	for {
+		select {
+		case <-j.s.ctx.Done():
+			return
+		case at := <-j.queue:
+			j.execute(at)
+		}
+	}
+}
+
+func (j *job) execute(at time.Time) {
+	ctx := j.s.ctx
+
+	if j.sched.Jitter != nil && *j.sched.Jitter > 0 {
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(*j.sched.Jitter))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	var input interface{} = map[string]interface{}{}
+	if j.sched.Input != nil {
+		var err error
+		input, err = j.sched.Input.Eval(map[string]interface{}{
+			"schedule": j.name,
+			"time":     at,
+		})
+		if err != nil {
+			j.s.log.Error(err, "Could not evaluate input", "name", j.name)
+			return
+		}
+	}
+
+	var entityID string
+	if j.sched.EntityID != nil {
+		entityID = *j.sched.EntityID
+	}
+	if _, err := j.s.invoker(ctx, j.sched.Handler, entityID, input, transport.BypassAuthorization); err != nil {
+		j.s.log.Error(err, "Error invoking handler", "name", j.name, "handler", j.sched.Handler)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package time_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nanobus/nanobus/pkg/expr"
+	"github.com/nanobus/nanobus/pkg/handler"
+	"github.com/nanobus/nanobus/pkg/transport"
+	transport_time "github.com/nanobus/nanobus/pkg/transport/time"
+)
+
+type invocation struct {
+	handler string
+	id      string
+	input   interface{}
+}
+
+type recorder struct {
+	mu          sync.Mutex
+	invocations []invocation
+}
+
+func (r *recorder) invoke(ctx context.Context, h handler.Handler, id string, input interface{}, authorization transport.Authorization) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invocations = append(r.invocations, invocation{h.String(), id, input})
+	return nil, nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.invocations)
+}
+
+func start(t *testing.T, r *recorder, store transport_time.RunStore, schedules ...transport_time.Schedule) *transport_time.Scheduler {
+	t.Helper()
+	s, err := transport_time.NewScheduler(context.Background(), logr.Discard(), trace.NewNoopTracerProvider().Tracer("test"),
+		r.invoke, transport_time.SchedulerV1Config{Schedules: schedules}, store)
+	require.NoError(t, err)
+	go s.Listen()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestScheduler(t *testing.T) {
+	name := "greet"
+	r := recorder{}
+	start(t, &r, nil, transport_time.Schedule{
+		Name:     &name,
+		Schedule: "* * * * * *",
+		Timezone: ptr("America/New_York"),
+		Handler:  handler.Handler{Interface: "greeter", Operation: "hello"},
+		Input:    expr.MustParseDataExpr(`{"schedule": schedule, "kind": "test"}`),
+		Repeat:   2,
+	})
+
+	require.Eventually(t, func() bool { return r.count() == 2 }, 5*time.Second, 50*time.Millisecond)
+	time.Sleep(1200 * time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Len(t, r.invocations, 2)
+	assert.Equal(t, "greeter::hello", r.invocations[0].handler)
+	assert.Empty(t, r.invocations[0].id)
+	assert.Equal(t, map[string]interface{}{
+		"schedule": "greet",
+		"kind":     "test",
+	}, r.invocations[0].input)
+}
+
+func TestSchedulerEntityID(t *testing.T) {
+	r := recorder{}
+	start(t, &r, nil, transport_time.Schedule{
+		Schedule: "* * * * * *",
+		Handler:  handler.Handler{Interface: "counters", Operation: "increment"},
+		EntityID: ptr("daily"),
+		Repeat:   1,
+	})
+
+	require.Eventually(t, func() bool { return r.count() == 1 }, 5*time.Second, 50*time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Equal(t, "daily", r.invocations[0].id)
+}
+
+func TestSchedulerClaimsEachRunOnce(t *testing.T) {
+	store := transport_time.NewMemoryRunStore()
+	sched := transport_time.Schedule{
+		Schedule: "* * * * * *",
+		Handler:  handler.Handler{Interface: "greeter", Operation: "hello"},
+		Repeat:   0,
+	}
+	r := recorder{}
+	start(t, &r, store, sched)
+	start(t, &r, store, sched)
+
+	time.Sleep(2500 * time.Millisecond)
+	count := r.count()
+	assert.GreaterOrEqual(t, count, 2)
+	assert.LessOrEqual(t, count, 3)
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	name := "report"
+	store := transport_time.NewMemoryRunStore()
+	last := time.Now().Truncate(time.Hour).Add(-5 * time.Hour)
+	_, err := store.Claim(context.Background(), name, last)
+	require.NoError(t, err)
+
+	r := recorder{}
+	start(t, &r, store, transport_time.Schedule{
+		Name:     &name,
+		Schedule: "@hourly",
+		Handler:  handler.Handler{Interface: "reports", Operation: "generate"},
+		Input:    expr.MustParseDataExpr(`{"hour": time.UTC().Hour()}`),
+		Overlap:  ptr("queue"),
+		CatchUp:  ptr("all"),
+	})
+
+	require.Eventually(t, func() bool { return r.count() == 5 }, 2*time.Second, 50*time.Millisecond)
+
+	lastRun, ok, err := store.LastRun(context.Background(), name)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Now().Truncate(time.Hour).UTC(), lastRun.UTC())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Equal(t, map[string]interface{}{
+		"hour": last.Add(time.Hour).UTC().Hour(),
+	}, r.invocations[0].input)
+}
+
+func TestSchedulerInvalidTimezone(t *testing.T) {
+	s, err := transport_time.NewScheduler(context.Background(), logr.Discard(), trace.NewNoopTracerProvider().Tracer("test"),
+		(&recorder{}).invoke, transport_time.SchedulerV1Config{Schedules: []transport_time.Schedule{{
+			Schedule: "* * * * *",
+			Timezone: ptr("Mars/Olympus_Mons"),
+		}}}, nil)
+	require.NoError(t, err)
+	assert.ErrorContains(t, s.Listen(), "invalid timezone")
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestMigrations(t *testing.T) {
+	d, err := source.Open(transport_time.SourceScheme + "://postgres")
+	require.NoError(t, err)
+	defer d.Close()
+	version, err := d.First()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), version)
+
+	_, err = source.Open(transport_time.SourceScheme + "://mysql")
+	assert.EqualError(t, err, `schedules migrations are not available for "mysql"`)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package time
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/nanobus/nanobus/pkg/resource"
+)
+
+// RunStore claims scheduled runs and remembers the last run of each schedule.
+type RunStore interface {
+	// Claim records a run of the named schedule at the scheduled time. It
+	// returns false if a run at or after that time was already claimed, for
+	// example by another replica.
+	Claim(ctx context.Context, name string, at time.Time) (bool, error)
+	// LastRun returns the time of the last claimed run, if any.
+	LastRun(ctx context.Context, name string) (time.Time, bool, error)
+}
+
+const (
+	// postgresTable is the table created by the schedules://postgres
+	// migrations.
+	postgresTable    = "nanobus_schedules"
+	defaultKeyPrefix = "nanobus:schedules:"
+)
+
+// NewRunStore returns a RunStore backed by the redis or Postgres resource
+// referenced in c.
+func NewRunStore(ctx context.Context, resources resource.Resources, c *Store) (RunStore, error) {
+	res, ok := resources[string(c.Resource)]
+	if !ok {
+		return nil, fmt.Errorf("resource %q is not registered", c.Resource)
+	}
+
+	switch r := res.(type) {
+	case *pgxpool.Pool:
+		return NewPostgresRunStore(r, postgresTable), nil
+	case redis.UniversalClient:
+		keyPrefix := defaultKeyPrefix
+		if c.KeyPrefix != nil {
+			keyPrefix = *c.KeyPrefix
+		}
+		return NewRedisRunStore(r, keyPrefix), nil
+	}
+
+	return nil, fmt.Errorf("resource %q is not a redis or postgres connection", c.Resource)
+}
+
+// MemoryRunStore is a RunStore for a single replica that does not survive
+// restarts.
+type MemoryRunStore struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func NewMemoryRunStore() *MemoryRunStore {
+	return &MemoryRunStore{
+		runs: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryRunStore) Claim(ctx context.Context, name string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.runs[name]; ok && !last.Before(at) {
+		return false, nil
+	}
+	s.runs[name] = at
+
+	return true, nil
+}
+
+func (s *MemoryRunStore) LastRun(ctx context.Context, name string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.runs[name]
+	return last, ok, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package time
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type PostgresRunStore struct {
+	pool       *pgxpool.Pool
+	claimSQL   string
+	lastRunSQL string
+}
+
+// NewPostgresRunStore returns a RunStore that uses table. The table is created
+// by the migrations with the source URL schedules://postgres.
+func NewPostgresRunStore(pool *pgxpool.Pool, table string) *PostgresRunStore {
+	ident := pgx.Identifier(strings.Split(table, ".")).Sanitize()
+
+	return &PostgresRunStore{
+		pool: pool,
+		claimSQL: fmt.Sprintf(`INSERT INTO %[1]s (name, last_run) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET last_run = EXCLUDED.last_run
+WHERE %[1]s.last_run < EXCLUDED.last_run`, ident),
+		lastRunSQL: fmt.Sprintf(`SELECT last_run FROM %s WHERE name = $1`, ident),
+	}
+}
+
+func (s *PostgresRunStore) Claim(ctx context.Context, name string, at time.Time) (bool, error) {
+	tag, err := s.pool.Exec(ctx, s.claimSQL, name, at)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PostgresRunStore) LastRun(ctx context.Context, name string) (time.Time, bool, error) {
+	var last time.Time
+	err := s.pool.QueryRow(ctx, s.lastRunSQL, name).Scan(&last)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return last, true, nil
+}
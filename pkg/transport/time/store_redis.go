@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022 The NanoBus Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at https://mozilla.org/MPL/2.0/.
+ */
+
+package time
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// claimScript atomically advances the last run stored in KEYS[1] to ARGV[1]
+// (Unix milliseconds) and returns 1, or returns 0 if it is not later.
+var claimScript = redis.NewScript(`
+local last = redis.call('GET', KEYS[1])
+if last and tonumber(last) >= tonumber(ARGV[1]) then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return 1
+`)
+
+type RedisRunStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+func NewRedisRunStore(client redis.UniversalClient, keyPrefix string) *RedisRunStore {
+	return &RedisRunStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *RedisRunStore) Claim(ctx context.Context, name string, at time.Time) (bool, error) {
+	claimed, err := claimScript.Run(ctx, s.client, []string{s.keyPrefix + name}, at.UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return claimed == 1, nil
+}
+
+func (s *RedisRunStore) LastRun(ctx context.Context, name string) (time.Time, bool, error) {
+	val, err := s.client.Get(ctx, s.keyPrefix+name).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	ms, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return time.UnixMilli(ms).UTC(), true, nil
+}
@@ -45,3 +45,21 @@ func (r Registry) Register(loaders ...NamedLoader) {
 		r[name] = loader
 	}
 }
+
+type variablesKey struct{}
+
+// Variables are values describing the request, such as event metadata, that
+// are available to the pipeline alongside `input`.
+type Variables map[string]interface{}
+
+// WithVariables returns a context carrying variables for the invoker to add
+// to the pipeline data.
+func WithVariables(ctx context.Context, variables Variables) context.Context {
+	return context.WithValue(ctx, variablesKey{}, variables)
+}
+
+// VariablesFromContext returns the variables set by WithVariables, if any.
+func VariablesFromContext(ctx context.Context) Variables {
+	variables, _ := ctx.Value(variablesKey{}).(Variables)
+	return variables
+}
@@ -33,3 +33,12 @@ func TestRegistry(t *testing.T) {
 
 	assert.Equal(t, fmt.Sprintf("%v", transport.Loader(loader)), fmt.Sprintf("%p", r["test"]))
 }
+
+func TestVariables(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, transport.VariablesFromContext(ctx))
+
+	variables := transport.Variables{"metadata": map[string]string{"key": "value"}}
+	ctx = transport.WithVariables(ctx, variables)
+	assert.Equal(t, variables, transport.VariablesFromContext(ctx))
+}